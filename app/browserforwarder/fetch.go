@@ -0,0 +1,244 @@
+package browserforwarder
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/v2fly/v2ray-core/v5/common"
+)
+
+const (
+	// fetchPollTimeout is how long a poll of the browser waits for the next request.
+	fetchPollTimeout = time.Second * 30
+	// fetchTimeout is how long a request waits for the browser to send it and return the response.
+	fetchTimeout = time.Second * 30
+	// fetchTokenHeader is the header of the token of the fetch page in its requests to the
+	// forwarder. Pages of other origins can't read the token, nor send the header without a CORS
+	// preflight, which the forwarder doesn't allow.
+	fetchTokenHeader = "X-Fetch-Token"
+)
+
+// fetchPage is the page which sends the requests of the forwarder with the fetch API of the
+// browser. It polls the forwarder for the next request, and posts the response back. {{TOKEN}} is
+// replaced by the token of the forwarder.
+const fetchPage = `<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>V2Ray Browser Forwarder</title></head>
+<body>
+<p>Keep this page open to forward HTTP requests.</p>
+<script>
+const headers = {"X-Fetch-Token": "{{TOKEN}}"};
+const decode = s => Uint8Array.from(atob(s), c => c.charCodeAt(0));
+const encode = b => {
+  let s = "";
+  for (let i = 0; i < b.length; i += 0x8000) {
+    s += String.fromCharCode.apply(null, b.subarray(i, i + 0x8000));
+  }
+  return btoa(s);
+};
+
+async function send(request) {
+  const result = {id: request.id};
+  try {
+    const response = await fetch(request.url, {
+      method: request.method,
+      headers: request.header,
+      body: request.body ? decode(request.body) : undefined,
+      cache: "no-store",
+    });
+    result.status = response.status;
+    result.body = encode(new Uint8Array(await response.arrayBuffer()));
+  } catch (e) {
+    result.error = String(e);
+  }
+  await fetch("fetch/result", {method: "POST", headers, body: JSON.stringify(result)});
+}
+
+(async () => {
+  for (;;) {
+    try {
+      const response = await fetch("fetch", {cache: "no-store", headers});
+      if (response.status === 200) {
+        send(await response.json());
+      }
+    } catch (e) {
+      await new Promise(resolve => setTimeout(resolve, 1000));
+    }
+  }
+})();
+</script>
+</body>
+</html>
+`
+
+// fetchRequest is a request waiting for the browser to send it.
+type fetchRequest struct {
+	ID     string            `json:"id"`
+	Method string            `json:"method"`
+	URL    string            `json:"url"`
+	Header map[string]string `json:"header,omitempty"`
+	Body   []byte            `json:"body,omitempty"`
+
+	result chan *fetchResult
+}
+
+// fetchResult is the response to a request, posted by the browser.
+type fetchResult struct {
+	ID     string `json:"id"`
+	Status int    `json:"status"`
+	Body   []byte `json:"body"`
+	Error  string `json:"error"`
+}
+
+// fetchRelay relays HTTP requests to the fetch page of the browser.
+type fetchRelay struct {
+	token   string
+	access  sync.Mutex
+	pending map[string]*fetchRequest
+	queue   chan *fetchRequest
+}
+
+func newFetchRelay() *fetchRelay {
+	return &fetchRelay{
+		token:   randomID(),
+		pending: make(map[string]*fetchRequest),
+		queue:   make(chan *fetchRequest),
+	}
+}
+
+// randomID returns a random hex string, which is also used as IDs of requests, so that they can't
+// be guessed.
+func randomID() string {
+	var b [16]byte
+	common.Must2(rand.Read(b[:]))
+	return hex.EncodeToString(b[:])
+}
+
+// authorized returns whether the request is from the fetch page.
+func (r *fetchRelay) authorized(request *http.Request) bool {
+	token := request.Header.Get(fetchTokenHeader)
+	return subtle.ConstantTimeCompare([]byte(token), []byte(r.token)) == 1
+}
+
+// servePage returns the fetch page with the token.
+func (r *fetchRelay) servePage(writer http.ResponseWriter) {
+	writer.Header().Set("Content-Type", "text/html; charset=utf-8")
+	writer.Header().Set("Cache-Control", "no-store")
+	io.WriteString(writer, strings.ReplaceAll(fetchPage, "{{TOKEN}}", r.token))
+}
+
+func (r *fetchRelay) RoundTrip(request *http.Request) (*http.Response, error) {
+	var body []byte
+	if request.Body != nil {
+		var err error
+		body, err = io.ReadAll(request.Body)
+		request.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	header := make(map[string]string)
+	for key := range request.Header {
+		header[key] = request.Header.Get(key)
+	}
+
+	r.access.Lock()
+	fr := &fetchRequest{
+		ID:     randomID(),
+		Method: request.Method,
+		URL:    request.URL.String(),
+		Header: header,
+		Body:   body,
+		result: make(chan *fetchResult, 1),
+	}
+	r.pending[fr.ID] = fr
+	r.access.Unlock()
+	defer func() {
+		r.access.Lock()
+		delete(r.pending, fr.ID)
+		r.access.Unlock()
+	}()
+
+	timeout := time.NewTimer(fetchTimeout)
+	defer timeout.Stop()
+	select {
+	case r.queue <- fr:
+	case <-request.Context().Done():
+		return nil, request.Context().Err()
+	case <-timeout.C:
+		return nil, newError("no browser is connected to fetch ", fr.URL)
+	}
+
+	select {
+	case result := <-fr.result:
+		if result.Error != "" {
+			return nil, newError("failed to fetch ", fr.URL, ": ", result.Error)
+		}
+		return &http.Response{
+			Status:        http.StatusText(result.Status),
+			StatusCode:    result.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        make(http.Header),
+			Body:          io.NopCloser(bytes.NewReader(result.Body)),
+			ContentLength: int64(len(result.Body)),
+			Request:       request,
+		}, nil
+	case <-request.Context().Done():
+		return nil, request.Context().Err()
+	case <-timeout.C:
+		return nil, newError("timeout to fetch ", fr.URL)
+	}
+}
+
+// servePoll returns the next request to the browser.
+func (r *fetchRelay) servePoll(writer http.ResponseWriter, request *http.Request) {
+	if !r.authorized(request) {
+		writer.WriteHeader(http.StatusForbidden)
+		return
+	}
+	writer.Header().Set("Cache-Control", "no-store")
+	select {
+	case fr := <-r.queue:
+		writer.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(writer).Encode(fr); err != nil {
+			newError("failed to send request to browser").Base(err).WriteToLog()
+		}
+	case <-request.Context().Done():
+	case <-time.After(fetchPollTimeout):
+		writer.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// serveResult receives the response to a request from the browser.
+func (r *fetchRelay) serveResult(writer http.ResponseWriter, request *http.Request) {
+	if !r.authorized(request) {
+		writer.WriteHeader(http.StatusForbidden)
+		return
+	}
+	result := new(fetchResult)
+	if err := json.NewDecoder(request.Body).Decode(result); err != nil {
+		writer.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	r.access.Lock()
+	fr, found := r.pending[result.ID]
+	r.access.Unlock()
+	if found {
+		select {
+		case fr.result <- result:
+		default:
+		}
+	}
+	writer.WriteHeader(http.StatusNoContent)
+}
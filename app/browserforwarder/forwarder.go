@@ -25,6 +25,7 @@ type Forwarder struct {
 	ctx context.Context
 
 	forwarder  *handler.HTTPHandle
+	fetch      *fetchRelay
 	httpserver *http.Server
 
 	config *Config
@@ -40,6 +41,12 @@ func (f *Forwarder) ServeHTTP(writer http.ResponseWriter, request *http.Request)
 		BridgeResource(writer, request, requestPath)
 	case "link":
 		f.forwarder.ServeBridge(writer, request)
+	case "fetch.html":
+		f.fetch.servePage(writer)
+	case "fetch":
+		f.fetch.servePoll(writer, request)
+	case "fetch/result":
+		f.fetch.serveResult(writer, request)
 	}
 }
 
@@ -64,6 +71,11 @@ func (f *Forwarder) DialWebsocket(url string, header http.Header) (io.ReadWriteC
 	return f.forwarder.Dial2(url, protocolHeaderValue)
 }
 
+// RoundTrip implements http.RoundTripper. Requests are sent by the fetch page of the browser.
+func (f *Forwarder) RoundTrip(request *http.Request) (*http.Response, error) {
+	return f.fetch.RoundTrip(request)
+}
+
 func (f *Forwarder) Type() interface{} {
 	return extension.BrowserForwarderType()
 }
@@ -123,7 +135,7 @@ func BridgeResource(rw http.ResponseWriter, r *http.Request, path string) {
 }
 
 func NewForwarder(ctx context.Context, cfg *Config) *Forwarder {
-	return &Forwarder{config: cfg, ctx: ctx}
+	return &Forwarder{config: cfg, ctx: ctx, fetch: newFetchRelay()}
 }
 
 func init() {
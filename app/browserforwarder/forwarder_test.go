@@ -0,0 +1,158 @@
+package browserforwarder_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	. "github.com/v2fly/v2ray-core/v5/app/browserforwarder"
+	"github.com/v2fly/v2ray-core/v5/common"
+)
+
+var tokenPattern = regexp.MustCompile(`"X-Fetch-Token": "([0-9a-f]+)"`)
+
+// fetchToken returns the token in the fetch page.
+func fetchToken(forwarderURL string) (string, error) {
+	response, err := http.Get(forwarderURL + "/fetch.html")
+	if err != nil {
+		return "", err
+	}
+	page, err := io.ReadAll(response.Body)
+	response.Body.Close()
+	if err != nil {
+		return "", err
+	}
+	match := tokenPattern.FindSubmatch(page)
+	if match == nil {
+		return "", errors.New("no token in fetch page")
+	}
+	return string(match[1]), nil
+}
+
+// browser does what the fetch page does in browsers, for one request.
+func browser(forwarderURL string) error {
+	token, err := fetchToken(forwarderURL)
+	if err != nil {
+		return err
+	}
+	pollRequest, err := http.NewRequest(http.MethodGet, forwarderURL+"/fetch", nil)
+	if err != nil {
+		return err
+	}
+	pollRequest.Header.Set("X-Fetch-Token", token)
+	response, err := http.DefaultClient.Do(pollRequest)
+	if err != nil {
+		return err
+	}
+	var request struct {
+		ID     string            `json:"id"`
+		Method string            `json:"method"`
+		URL    string            `json:"url"`
+		Header map[string]string `json:"header"`
+		Body   []byte            `json:"body"`
+	}
+	err = json.NewDecoder(response.Body).Decode(&request)
+	response.Body.Close()
+	if err != nil {
+		return err
+	}
+
+	fetchRequest, err := http.NewRequest(request.Method, request.URL, bytes.NewReader(request.Body))
+	if err != nil {
+		return err
+	}
+	for key, value := range request.Header {
+		fetchRequest.Header.Set(key, value)
+	}
+	response, err = http.DefaultClient.Do(fetchRequest)
+	if err != nil {
+		return err
+	}
+	body, err := io.ReadAll(response.Body)
+	response.Body.Close()
+	if err != nil {
+		return err
+	}
+
+	result, err := json.Marshal(map[string]interface{}{
+		"id":     request.ID,
+		"status": response.StatusCode,
+		"body":   body,
+	})
+	if err != nil {
+		return err
+	}
+	resultRequest, err := http.NewRequest(http.MethodPost, forwarderURL+"/fetch/result", bytes.NewReader(result))
+	if err != nil {
+		return err
+	}
+	resultRequest.Header.Set("X-Fetch-Token", token)
+	response, err = http.DefaultClient.Do(resultRequest)
+	if err != nil {
+		return err
+	}
+	response.Body.Close()
+	return nil
+}
+
+func TestForwarderRoundTrip(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		body, _ := io.ReadAll(request.Body)
+		writer.WriteHeader(http.StatusAccepted)
+		writer.Write([]byte(request.Header.Get("X-Test") + strings.ToUpper(string(body))))
+	}))
+	defer target.Close()
+
+	forwarder := NewForwarder(context.Background(), &Config{})
+	server := httptest.NewServer(forwarder)
+	defer server.Close()
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- browser(server.URL)
+	}()
+
+	request, err := http.NewRequest(http.MethodPost, target.URL, strings.NewReader("payload"))
+	common.Must(err)
+	request.Header.Set("X-Test", "header-")
+	response, err := forwarder.RoundTrip(request)
+	common.Must(err)
+	common.Must(<-errChan)
+
+	body, err := io.ReadAll(response.Body)
+	common.Must(err)
+	if response.StatusCode != http.StatusAccepted {
+		t.Error("unexpected status: ", response.StatusCode)
+	}
+	if string(body) != "header-PAYLOAD" {
+		t.Error("unexpected body: ", string(body))
+	}
+}
+
+func TestForwarderUnauthorized(t *testing.T) {
+	forwarder := NewForwarder(context.Background(), &Config{})
+	server := httptest.NewServer(forwarder)
+	defer server.Close()
+
+	// Pages of other origins can't send the token of the fetch page.
+	response, err := http.Get(server.URL + "/fetch")
+	common.Must(err)
+	response.Body.Close()
+	if response.StatusCode != http.StatusForbidden {
+		t.Error("unexpected status of poll: ", response.StatusCode)
+	}
+
+	response, err = http.Post(server.URL+"/fetch/result", "text/plain", strings.NewReader(`{"id":"1","status":200}`))
+	common.Must(err)
+	response.Body.Close()
+	if response.StatusCode != http.StatusForbidden {
+		t.Error("unexpected status of result: ", response.StatusCode)
+	}
+}
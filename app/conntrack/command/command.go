@@ -0,0 +1,133 @@
+package command
+
+//go:generate go run github.com/v2fly/v2ray-core/v5/common/errors/errorgen
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+
+	core "github.com/v2fly/v2ray-core/v5"
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/features/conntrack"
+)
+
+// trackerServer is an implementation of ConnectionTrackerService.
+type trackerServer struct {
+	tracker conntrack.Tracker
+}
+
+// NewTrackerServer creates a new ConnectionTrackerServiceServer. Requests fail if tracker is nil.
+func NewTrackerServer(tracker conntrack.Tracker) ConnectionTrackerServiceServer {
+	return &trackerServer{
+		tracker: tracker,
+	}
+}
+
+// AsProtobufMessage converts a tracked connection to its protobuf message.
+func AsProtobufMessage(conn *conntrack.Connection) *Connection {
+	message := &Connection{
+		Id:          conn.ID,
+		InboundTag:  conn.InboundTag,
+		User:        conn.User,
+		Domain:      conn.Domain,
+		Protocol:    conn.Protocol,
+		OutboundTag: conn.OutboundTag,
+		StartTime:   conn.Start.Unix(),
+	}
+	if conn.Source.IsValid() {
+		message.Source = conn.Source.String()
+	}
+	if conn.Destination.IsValid() {
+		message.Destination = conn.Destination.String()
+	}
+	if conn.Uplink != nil {
+		message.Uplink = conn.Uplink.Value()
+	}
+	if conn.Downlink != nil {
+		message.Downlink = conn.Downlink.Value()
+	}
+	return message
+}
+
+func (s *trackerServer) ListConnections(ctx context.Context, request *ListConnectionsRequest) (*ListConnectionsResponse, error) {
+	if s.tracker == nil {
+		return nil, newError("connection tracking not enabled")
+	}
+	response := &ListConnectionsResponse{}
+	for _, conn := range s.tracker.List() {
+		if request.User != "" && conn.User != request.User {
+			continue
+		}
+		if request.InboundTag != "" && conn.InboundTag != request.InboundTag {
+			continue
+		}
+		response.Connections = append(response.Connections, AsProtobufMessage(conn))
+	}
+	return response, nil
+}
+
+func (s *trackerServer) SubscribeConnections(request *SubscribeConnectionsRequest, stream ConnectionTrackerService_SubscribeConnectionsServer) error {
+	if s.tracker == nil {
+		return newError("connection tracking not enabled")
+	}
+	subscriber, err := s.tracker.Subscribe()
+	if err != nil {
+		return err
+	}
+	defer s.tracker.Unsubscribe(subscriber)
+	for {
+		select {
+		case event, ok := <-subscriber:
+			if !ok {
+				return newError("Upstream closed the subscriber channel.")
+			}
+			message := &ConnectionEvent{
+				Type:       ConnectionEvent_Open,
+				Connection: AsProtobufMessage(event.Connection),
+			}
+			if event.Type == conntrack.EventClose {
+				message.Type = ConnectionEvent_Close
+			}
+			if err := stream.Send(message); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		}
+	}
+}
+
+func (s *trackerServer) CloseConnections(ctx context.Context, request *CloseConnectionsRequest) (*CloseConnectionsResponse, error) {
+	if s.tracker == nil {
+		return nil, newError("connection tracking not enabled")
+	}
+	response := &CloseConnectionsResponse{}
+	for _, id := range request.Ids {
+		if s.tracker.CloseConnection(id) {
+			response.Closed++
+		}
+	}
+	if request.User != "" {
+		response.Closed += uint32(s.tracker.CloseUser(request.User))
+	}
+	return response, nil
+}
+
+func (s *trackerServer) mustEmbedUnimplementedConnectionTrackerServiceServer() {}
+
+type service struct {
+	v *core.Instance
+}
+
+func (s *service) Register(server *grpc.Server) {
+	tracker, _ := s.v.GetFeature(conntrack.TrackerType()).(conntrack.Tracker)
+	RegisterConnectionTrackerServiceServer(server, NewTrackerServer(tracker))
+}
+
+func init() {
+	common.Must(common.RegisterConfig((*Config)(nil), func(ctx context.Context, cfg interface{}) (interface{}, error) {
+		s := core.MustFromContext(ctx)
+		return &service{v: s}, nil
+	}))
+}
@@ -0,0 +1,49 @@
+package command_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/v2fly/v2ray-core/v5/app/conntrack"
+	. "github.com/v2fly/v2ray-core/v5/app/conntrack/command"
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/net"
+	feature_conntrack "github.com/v2fly/v2ray-core/v5/features/conntrack"
+)
+
+func TestConnectionTrackerService(t *testing.T) {
+	tracker, err := conntrack.NewTracker(context.Background(), &conntrack.Config{})
+	common.Must(err)
+
+	closed := 0
+	for _, c := range []*feature_conntrack.Connection{
+		{User: "a", InboundTag: "socks", Destination: net.TCPDestination(net.DomainAddress("v2fly.org"), 443)},
+		{User: "b", InboundTag: "socks"},
+		{User: "a", InboundTag: "vmess"},
+	} {
+		c.Interrupt = func() { closed++ }
+		tracker.Track(c)
+	}
+
+	s := NewTrackerServer(tracker)
+	resp, err := s.ListConnections(context.Background(), &ListConnectionsRequest{User: "a"})
+	common.Must(err)
+	if len(resp.Connections) != 2 || resp.Connections[0].Destination != "tcp:v2fly.org:443" {
+		t.Fatal("unexpected connections: ", resp.Connections)
+	}
+	resp, err = s.ListConnections(context.Background(), &ListConnectionsRequest{User: "a", InboundTag: "vmess"})
+	common.Must(err)
+	if len(resp.Connections) != 1 || resp.Connections[0].Id != 3 {
+		t.Fatal("unexpected connections: ", resp.Connections)
+	}
+
+	closeResp, err := s.CloseConnections(context.Background(), &CloseConnectionsRequest{Ids: []uint64{2, 4}, User: "a"})
+	common.Must(err)
+	if closeResp.Closed != 3 || closed != 3 {
+		t.Error("unexpected closed connections: ", closeResp.Closed, " ", closed)
+	}
+
+	if _, err := NewTrackerServer(nil).ListConnections(context.Background(), &ListConnectionsRequest{}); err == nil {
+		t.Error("expected failure without tracker")
+	}
+}
@@ -0,0 +1,162 @@
+package conntrack
+
+//go:generate go run github.com/v2fly/v2ray-core/v5/common/errors/errorgen
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/features/conntrack"
+)
+
+// subscriberBufferSize is the number of events buffered for each subscriber. Events are dropped
+// for subscribers that fall behind.
+const subscriberBufferSize = 64
+
+// Tracker is an implementation of conntrack.Tracker.
+type Tracker struct {
+	access      sync.RWMutex
+	lastID      uint64
+	connections map[uint64]*conntrack.Connection
+	subscribers []chan conntrack.Event
+}
+
+// NewTracker creates a new Tracker.
+func NewTracker(ctx context.Context, config *Config) (*Tracker, error) {
+	return &Tracker{
+		connections: make(map[uint64]*conntrack.Connection),
+	}, nil
+}
+
+// Type implements common.HasType.
+func (*Tracker) Type() interface{} {
+	return conntrack.TrackerType()
+}
+
+func (t *Tracker) publish(event conntrack.Event) {
+	for _, sub := range t.subscribers {
+		select {
+		case sub <- event:
+		default:
+		}
+	}
+}
+
+// Track implements conntrack.Tracker.
+func (t *Tracker) Track(conn *conntrack.Connection) func() {
+	t.access.Lock()
+	defer t.access.Unlock()
+
+	t.lastID++
+	conn.ID = t.lastID
+	t.connections[conn.ID] = conn
+	t.publish(conntrack.Event{Type: conntrack.EventOpen, Connection: conn})
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			t.access.Lock()
+			defer t.access.Unlock()
+
+			delete(t.connections, conn.ID)
+			t.publish(conntrack.Event{Type: conntrack.EventClose, Connection: conn})
+		})
+	}
+}
+
+// List implements conntrack.Tracker. Connections are sorted by their IDs.
+func (t *Tracker) List() []*conntrack.Connection {
+	t.access.RLock()
+	defer t.access.RUnlock()
+
+	connections := make([]*conntrack.Connection, 0, len(t.connections))
+	for _, conn := range t.connections {
+		connections = append(connections, conn)
+	}
+	sort.Slice(connections, func(i, j int) bool {
+		return connections[i].ID < connections[j].ID
+	})
+	return connections
+}
+
+// CloseConnection implements conntrack.Tracker.
+func (t *Tracker) CloseConnection(id uint64) bool {
+	t.access.RLock()
+	conn, found := t.connections[id]
+	t.access.RUnlock()
+
+	if !found {
+		return false
+	}
+	newError("closing connection ", id).AtInfo().WriteToLog()
+	conn.Interrupt()
+	return true
+}
+
+// CloseUser implements conntrack.Tracker.
+func (t *Tracker) CloseUser(email string) int {
+	var connections []*conntrack.Connection
+	t.access.RLock()
+	for _, conn := range t.connections {
+		if conn.User == email {
+			connections = append(connections, conn)
+		}
+	}
+	t.access.RUnlock()
+
+	newError("closing ", len(connections), " connections of user ", email).AtInfo().WriteToLog()
+	for _, conn := range connections {
+		conn.Interrupt()
+	}
+	return len(connections)
+}
+
+// Subscribe implements conntrack.Tracker.
+func (t *Tracker) Subscribe() (chan conntrack.Event, error) {
+	t.access.Lock()
+	defer t.access.Unlock()
+
+	sub := make(chan conntrack.Event, subscriberBufferSize)
+	t.subscribers = append(t.subscribers, sub)
+	return sub, nil
+}
+
+// Unsubscribe implements conntrack.Tracker.
+func (t *Tracker) Unsubscribe(sub chan conntrack.Event) error {
+	t.access.Lock()
+	defer t.access.Unlock()
+
+	for i, s := range t.subscribers {
+		if s == sub {
+			t.subscribers = append(t.subscribers[:i], t.subscribers[i+1:]...)
+			close(sub)
+			return nil
+		}
+	}
+	return newError("subscriber not found")
+}
+
+// Start implements common.Runnable.
+func (*Tracker) Start() error {
+	return nil
+}
+
+// Close implements common.Closable.
+func (t *Tracker) Close() error {
+	t.access.Lock()
+	defer t.access.Unlock()
+
+	for _, sub := range t.subscribers {
+		close(sub)
+	}
+	t.subscribers = nil
+	return nil
+}
+
+func init() {
+	common.Must(common.RegisterConfig((*Config)(nil), func(ctx context.Context, config interface{}) (interface{}, error) {
+		return NewTracker(ctx, config.(*Config))
+	}))
+}
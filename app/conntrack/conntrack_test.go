@@ -0,0 +1,260 @@
+package conntrack_test
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/types/known/anypb"
+
+	core "github.com/v2fly/v2ray-core/v5"
+	. "github.com/v2fly/v2ray-core/v5/app/conntrack"
+	"github.com/v2fly/v2ray-core/v5/app/dispatcher"
+	"github.com/v2fly/v2ray-core/v5/app/proxyman"
+	_ "github.com/v2fly/v2ray-core/v5/app/proxyman/inbound"
+	_ "github.com/v2fly/v2ray-core/v5/app/proxyman/outbound"
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/common/protocol"
+	"github.com/v2fly/v2ray-core/v5/common/serial"
+	"github.com/v2fly/v2ray-core/v5/common/session"
+	"github.com/v2fly/v2ray-core/v5/common/uuid"
+	"github.com/v2fly/v2ray-core/v5/features/conntrack"
+	"github.com/v2fly/v2ray-core/v5/proxy/freedom"
+	"github.com/v2fly/v2ray-core/v5/proxy/vmess"
+	vmessinbound "github.com/v2fly/v2ray-core/v5/proxy/vmess/inbound"
+	vmessoutbound "github.com/v2fly/v2ray-core/v5/proxy/vmess/outbound"
+	"github.com/v2fly/v2ray-core/v5/testing/servers/tcp"
+	_ "github.com/v2fly/v2ray-core/v5/transport/internet/tcp"
+)
+
+func TestTrackerClose(t *testing.T) {
+	tracker, err := NewTracker(context.Background(), &Config{})
+	common.Must(err)
+
+	var interrupted []uint64
+	track := func(user string) *conntrack.Connection {
+		conn := &conntrack.Connection{User: user}
+		conn.Interrupt = func() {
+			interrupted = append(interrupted, conn.ID)
+		}
+		tracker.Track(conn)
+		return conn
+	}
+	c1 := track("a")
+	c2 := track("b")
+	c3 := track("a")
+
+	if connections := tracker.List(); len(connections) != 3 || connections[0] != c1 || connections[2] != c3 {
+		t.Fatal("unexpected connections: ", connections)
+	}
+	if !tracker.CloseConnection(c2.ID) {
+		t.Error("failed to close connection ", c2.ID)
+	}
+	if tracker.CloseConnection(100) {
+		t.Error("closed unknown connection")
+	}
+	if n := tracker.CloseUser("a"); n != 2 {
+		t.Error("expected 2 connections closed, got ", n)
+	}
+	if len(interrupted) != 3 || interrupted[0] != c2.ID {
+		t.Error("unexpected interrupted connections: ", interrupted)
+	}
+}
+
+func TestTrackerDispatch(t *testing.T) {
+	tcpServer := tcp.Server{
+		MsgProcessor: func(b []byte) []byte { return b },
+	}
+	dest, err := tcpServer.Start()
+	common.Must(err)
+	defer tcpServer.Close()
+
+	server, err := core.New(&core.Config{
+		App: []*anypb.Any{
+			serial.ToTypedMessage(&dispatcher.Config{}),
+			serial.ToTypedMessage(&proxyman.OutboundConfig{}),
+			serial.ToTypedMessage(&Config{}),
+		},
+		Outbound: []*core.OutboundHandlerConfig{
+			{
+				Tag:           "direct",
+				ProxySettings: serial.ToTypedMessage(&freedom.Config{}),
+			},
+		},
+	})
+	common.Must(err)
+	common.Must(server.Start())
+	defer server.Close()
+
+	tracker := server.GetFeature(conntrack.TrackerType()).(conntrack.Tracker)
+	events, err := tracker.Subscribe()
+	common.Must(err)
+	defer tracker.Unsubscribe(events)
+
+	ctx := session.ContextWithInbound(context.Background(), &session.Inbound{
+		Tag:    "in",
+		Source: net.TCPDestination(net.LocalHostIP, 12345),
+		User:   &protocol.MemoryUser{Email: "love@v2fly.org"},
+	})
+	conn, err := core.Dial(ctx, server, dest)
+	common.Must(err)
+	defer conn.Close()
+
+	common.Must2(conn.Write([]byte("test")))
+	common.Must2(io.ReadFull(conn, make([]byte, 4)))
+
+	event := <-events
+	if event.Type != conntrack.EventOpen {
+		t.Fatal("expected open event, got ", event.Type)
+	}
+	connections := tracker.List()
+	if len(connections) != 1 {
+		t.Fatal("expected 1 connection, got ", len(connections))
+	}
+	c := connections[0]
+	if c.InboundTag != "in" || c.User != "love@v2fly.org" || c.OutboundTag != "direct" || c.Destination != dest {
+		t.Error("unexpected connection: ", c)
+	}
+	if c.Uplink.Value() != 4 || c.Downlink.Value() != 4 {
+		t.Error("unexpected traffic: ", c.Uplink.Value(), " ", c.Downlink.Value())
+	}
+
+	if n := tracker.CloseUser("love@v2fly.org"); n != 1 {
+		t.Fatal("expected 1 connection closed, got ", n)
+	}
+	common.Must(conn.SetReadDeadline(time.Now().Add(time.Second * 5)))
+	if _, err := conn.Read(make([]byte, 4)); err == nil {
+		t.Error("expected failure on closed connection")
+	}
+	select {
+	case event := <-events:
+		if event.Type != conntrack.EventClose || event.Connection != c {
+			t.Error("unexpected event: ", event)
+		}
+	case <-time.After(time.Second * 5):
+		t.Error("timeout waiting for close event")
+	}
+}
+
+// TestTrackerDispatchMux tracks a connection of a Mux.Cool outbound, whose Dispatch returns before
+// the connection ends.
+func TestTrackerDispatchMux(t *testing.T) {
+	tcpServer := tcp.Server{
+		MsgProcessor: func(b []byte) []byte { return b },
+	}
+	dest, err := tcpServer.Start()
+	common.Must(err)
+	defer tcpServer.Close()
+
+	userID := protocol.NewID(uuid.New())
+	serverPort := tcp.PickPort()
+	server, err := core.New(&core.Config{
+		App: []*anypb.Any{
+			serial.ToTypedMessage(&dispatcher.Config{}),
+			serial.ToTypedMessage(&proxyman.InboundConfig{}),
+			serial.ToTypedMessage(&proxyman.OutboundConfig{}),
+		},
+		Inbound: []*core.InboundHandlerConfig{
+			{
+				ReceiverSettings: serial.ToTypedMessage(&proxyman.ReceiverConfig{
+					PortRange: net.SinglePortRange(serverPort),
+					Listen:    net.NewIPOrDomain(net.LocalHostIP),
+				}),
+				ProxySettings: serial.ToTypedMessage(&vmessinbound.Config{
+					User: []*protocol.User{
+						{
+							Account: serial.ToTypedMessage(&vmess.Account{
+								Id: userID.String(),
+							}),
+						},
+					},
+				}),
+			},
+		},
+		Outbound: []*core.OutboundHandlerConfig{
+			{
+				ProxySettings: serial.ToTypedMessage(&freedom.Config{}),
+			},
+		},
+	})
+	common.Must(err)
+	common.Must(server.Start())
+	defer server.Close()
+
+	client, err := core.New(&core.Config{
+		App: []*anypb.Any{
+			serial.ToTypedMessage(&dispatcher.Config{}),
+			serial.ToTypedMessage(&proxyman.OutboundConfig{}),
+			serial.ToTypedMessage(&Config{}),
+		},
+		Outbound: []*core.OutboundHandlerConfig{
+			{
+				SenderSettings: serial.ToTypedMessage(&proxyman.SenderConfig{
+					MultiplexSettings: &proxyman.MultiplexingConfig{
+						Enabled:     true,
+						Concurrency: 4,
+					},
+				}),
+				ProxySettings: serial.ToTypedMessage(&vmessoutbound.Config{
+					Receiver: []*protocol.ServerEndpoint{
+						{
+							Address: net.NewIPOrDomain(net.LocalHostIP),
+							Port:    uint32(serverPort),
+							User: []*protocol.User{
+								{
+									Account: serial.ToTypedMessage(&vmess.Account{
+										Id: userID.String(),
+										SecuritySettings: &protocol.SecurityConfig{
+											Type: protocol.SecurityType_AES128_GCM,
+										},
+									}),
+								},
+							},
+						},
+					},
+				}),
+			},
+		},
+	})
+	common.Must(err)
+	common.Must(client.Start())
+	defer client.Close()
+
+	tracker := client.GetFeature(conntrack.TrackerType()).(conntrack.Tracker)
+	events, err := tracker.Subscribe()
+	common.Must(err)
+	defer tracker.Unsubscribe(events)
+
+	conn, err := core.Dial(context.Background(), client, dest)
+	common.Must(err)
+	defer conn.Close()
+
+	common.Must2(conn.Write([]byte("test")))
+	common.Must2(io.ReadFull(conn, make([]byte, 4)))
+
+	if event := <-events; event.Type != conntrack.EventOpen {
+		t.Fatal("expected open event, got ", event.Type)
+	}
+	connections := tracker.List()
+	if len(connections) != 1 {
+		t.Fatal("expected 1 connection, got ", len(connections))
+	}
+	if c := connections[0]; c.Uplink.Value() != 4 || c.Downlink.Value() != 4 {
+		t.Error("unexpected traffic: ", c.Uplink.Value(), " ", c.Downlink.Value())
+	}
+
+	common.Must(conn.Close())
+	select {
+	case event := <-events:
+		if event.Type != conntrack.EventClose || event.Connection != connections[0] {
+			t.Error("unexpected event: ", event)
+		}
+	case <-time.After(time.Second * 5):
+		t.Error("timeout waiting for close event")
+	}
+	if connections := tracker.List(); len(connections) != 0 {
+		t.Error("expected no connections, got ", len(connections))
+	}
+}
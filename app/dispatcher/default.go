@@ -20,11 +20,14 @@ import (
 	"github.com/v2fly/v2ray-core/v5/common/protocol/quic"
 	"github.com/v2fly/v2ray-core/v5/common/protocol/tls"
 	"github.com/v2fly/v2ray-core/v5/common/session"
+	"github.com/v2fly/v2ray-core/v5/features/conntrack"
 	"github.com/v2fly/v2ray-core/v5/features/outbound"
 	"github.com/v2fly/v2ray-core/v5/features/policy"
+	"github.com/v2fly/v2ray-core/v5/features/quota"
 	"github.com/v2fly/v2ray-core/v5/features/routing"
 	routing_session "github.com/v2fly/v2ray-core/v5/features/routing/session"
 	"github.com/v2fly/v2ray-core/v5/features/stats"
+	"github.com/v2fly/v2ray-core/v5/features/tracing"
 	"github.com/v2fly/v2ray-core/v5/transport"
 	"github.com/v2fly/v2ray-core/v5/transport/pipe"
 )
@@ -92,15 +95,22 @@ func (r *cachedReader) Interrupt() {
 
 // DefaultDispatcher is a default implementation of Dispatcher.
 type DefaultDispatcher struct {
-	ohm    outbound.Manager
-	router routing.Router
-	policy policy.Manager
-	stats  stats.Manager
+	ohm     outbound.Manager
+	router  routing.Router
+	policy  policy.Manager
+	stats   stats.Manager
+	tracker conntrack.Tracker
+	quota   quota.Manager
+	tracer  tracing.Tracer
+
+	instance *core.Instance
 }
 
 func init() {
 	common.Must(common.RegisterConfig((*Config)(nil), func(ctx context.Context, config interface{}) (interface{}, error) {
-		d := new(DefaultDispatcher)
+		d := &DefaultDispatcher{
+			instance: core.FromContext(ctx),
+		}
 		if err := core.RequireFeatures(ctx, func(om outbound.Manager, router routing.Router, pm policy.Manager, sm stats.Manager) error {
 			return d.Init(config.(*Config), om, router, pm, sm)
 		}); err != nil {
@@ -125,7 +135,19 @@ func (*DefaultDispatcher) Type() interface{} {
 }
 
 // Start implements common.Runnable.
-func (*DefaultDispatcher) Start() error {
+func (d *DefaultDispatcher) Start() error {
+	// The connection tracker, quotas and tracing are optional, and may be added after the dispatcher.
+	if d.instance != nil {
+		if tracker, ok := d.instance.GetFeature(conntrack.TrackerType()).(conntrack.Tracker); ok {
+			d.tracker = tracker
+		}
+		if manager, ok := d.instance.GetFeature(quota.ManagerType()).(quota.Manager); ok {
+			d.quota = manager
+		}
+		if tracer, ok := d.instance.GetFeature(tracing.TracerType()).(tracing.Tracer); ok {
+			d.tracer = tracer
+		}
+	}
 	return nil
 }
 
@@ -208,6 +230,7 @@ func (d *DefaultDispatcher) Dispatch(ctx context.Context, destination net.Destin
 		Target: destination,
 	}
 	ctx = session.ContextWithOutbound(ctx, ob)
+	ctx, span := d.startSession(ctx, destination)
 
 	inbound, outbound := d.getLink(ctx)
 	content := session.ContentFromContext(ctx)
@@ -218,13 +241,17 @@ func (d *DefaultDispatcher) Dispatch(ctx context.Context, destination net.Destin
 	sniffingRequest := content.SniffingRequest
 	sniffer := defaultSniffers
 	if content.Protocol != "" || !sniffingRequest.Enabled && destination.Network != net.Network_UDP {
-		go d.routedDispatch(ctx, outbound, destination)
+		go func() {
+			defer span.End()
+			d.routedDispatch(ctx, outbound, destination)
+		}()
 		return inbound, nil
 	}
 	if !sniffingRequest.Enabled {
 		sniffer = udpOnlyDnsSniffers
 	}
 	go func() {
+		defer span.End()
 		cReader := &cachedReader{
 			reader: outbound.Reader.(*pipe.Reader),
 		}
@@ -258,6 +285,7 @@ func (d *DefaultDispatcher) DispatchLink(ctx context.Context, destination net.De
 		Target: destination,
 	}
 	ctx = session.ContextWithOutbound(ctx, ob)
+	ctx, span := d.startSession(ctx, destination)
 	content := session.ContentFromContext(ctx)
 	if content == nil {
 		content = new(session.Content)
@@ -265,7 +293,10 @@ func (d *DefaultDispatcher) DispatchLink(ctx context.Context, destination net.De
 	}
 
 	if _, loopLink := outbound.Reader.(*cachedReader); loopLink {
-		go d.routedDispatch(ctx, outbound, destination)
+		go func() {
+			defer span.End()
+			d.routedDispatch(ctx, outbound, destination)
+		}()
 		return nil
 	}
 
@@ -273,13 +304,17 @@ func (d *DefaultDispatcher) DispatchLink(ctx context.Context, destination net.De
 
 	sniffer := defaultSniffers
 	if content.Protocol != "" || !sniffingRequest.Enabled && destination.Network != net.Network_UDP {
-		go d.routedDispatch(ctx, outbound, destination)
+		go func() {
+			defer span.End()
+			d.routedDispatch(ctx, outbound, destination)
+		}()
 		return nil
 	}
 	if !sniffingRequest.Enabled {
 		sniffer = udpOnlyDnsSniffers
 	}
 	go func() {
+		defer span.End()
 		cReader := &cachedReader{
 			reader: outbound.Reader.(*pipe.Reader),
 		}
@@ -323,6 +358,9 @@ var udpOnlyDnsSniffers = &Sniffer{
 }
 
 func sniff(ctx context.Context, cReader *cachedReader, network net.Network, sniffer *Sniffer) (SniffResult, error) {
+	_, span := tracing.Start(ctx, "sniff")
+	defer span.End()
+
 	payload := buf.New()
 	defer payload.Release()
 
@@ -351,10 +389,44 @@ func sniff(ctx context.Context, cReader *cachedReader, network net.Network, snif
 			}
 		}
 	}()
+	if contentErr == nil {
+		span.SetAttribute("protocol", contentResult.Protocol())
+		if domain := contentResult.Domain(); domain != "" {
+			span.SetAttribute("domain", domain)
+		}
+	}
 	return contentResult, contentErr
 }
 
 func (d *DefaultDispatcher) routedDispatch(ctx context.Context, link *transport.Link, destination net.Destination) {
+	// Quotas, limits and trackers are released, and access logs of closed connections are written,
+	// when the link ends, not when Dispatch of the outbound handler returns.
+	end := new(linkEnd)
+	link = end.wrap(link)
+	if d.quota != nil {
+		quotaLink, done, err := d.acquireQuota(ctx, link)
+		if err != nil {
+			newError("connection to ", destination, " refused").Base(err).AtInfo().WriteToLog(session.ExportIDToError(ctx))
+			common.Close(link.Writer)
+			common.Interrupt(link.Reader)
+			return
+		}
+		end.add(done)
+		link = quotaLink
+	}
+	if limiter, ok := d.policy.(policy.Limiter); ok {
+		limitedLink, done, err := acquireLimit(ctx, limiter, link)
+		if err != nil {
+			newError("connection to ", destination, " refused").Base(err).AtInfo().WriteToLog(session.ExportIDToError(ctx))
+			common.Close(link.Writer)
+			common.Interrupt(link.Reader)
+			return
+		}
+		end.add(done)
+		link = limitedLink
+	}
+
+	_, routeSpan := tracing.Start(ctx, "route")
 	var handler outbound.Handler
 
 	if forcedOutboundTag := session.GetForcedOutboundTagFromContext(ctx); forcedOutboundTag != "" {
@@ -363,7 +435,10 @@ func (d *DefaultDispatcher) routedDispatch(ctx context.Context, link *transport.
 			newError("taking platform initialized detour [", forcedOutboundTag, "] for [", destination, "]").WriteToLog(session.ExportIDToError(ctx))
 			handler = h
 		} else {
-			newError("non existing tag for platform initialized detour: ", forcedOutboundTag).AtError().WriteToLog(session.ExportIDToError(ctx))
+			err := newError("non existing tag for platform initialized detour: ", forcedOutboundTag).AtError()
+			err.WriteToLog(session.ExportIDToError(ctx))
+			routeSpan.RecordError(err)
+			routeSpan.End()
 			common.Close(link.Writer)
 			common.Interrupt(link.Reader)
 			return
@@ -387,18 +462,204 @@ func (d *DefaultDispatcher) routedDispatch(ctx context.Context, link *transport.
 	}
 
 	if handler == nil {
-		newError("default outbound handler not exist").WriteToLog(session.ExportIDToError(ctx))
+		err := newError("default outbound handler not exist")
+		err.WriteToLog(session.ExportIDToError(ctx))
+		routeSpan.RecordError(err)
+		routeSpan.End()
 		common.Close(link.Writer)
 		common.Interrupt(link.Reader)
 		return
 	}
+	routeSpan.SetAttribute("outbound.tag", handler.Tag())
+	routeSpan.End()
+
+	if ob := session.OutboundFromContext(ctx); ob != nil {
+		ob.Tag = handler.Tag()
+	}
 
 	if accessMessage := log.AccessMessageFromContext(ctx); accessMessage != nil {
 		if tag := handler.Tag(); tag != "" {
 			accessMessage.Detour = tag
 		}
+		if inbound := session.InboundFromContext(ctx); inbound != nil {
+			accessMessage.InboundTag = inbound.Tag
+		}
+		accessMessage.Domain = sniffedDomain(ctx, destination)
+		accessMessage.SessionID = uint32(session.IDFromContext(ctx))
 		log.Record(accessMessage)
+
+		if log.ShouldRecordAccessClose() {
+			var done func()
+			link, done = recordAccessClose(accessMessage, link)
+			end.add(done)
+		}
+	}
+
+	if d.tracker != nil {
+		var done func()
+		link, done = d.track(ctx, link, destination, handler.Tag())
+		end.add(done)
 	}
 
 	handler.Dispatch(ctx, link)
 }
+
+// acquireQuota checks the quotas of the inbound and the user of the connection, and returns the
+// link that counts its traffic against them.
+func (d *DefaultDispatcher) acquireQuota(ctx context.Context, link *transport.Link) (*transport.Link, func(), error) {
+	var inboundTag, user string
+	if inbound := session.InboundFromContext(ctx); inbound != nil {
+		inboundTag = inbound.Tag
+		if inbound.User != nil {
+			user = inbound.User.Email
+		}
+	}
+	counter, done, err := d.quota.Acquire(inboundTag, user, func() {
+		common.Interrupt(link.Reader)
+		common.Interrupt(link.Writer)
+	})
+	if err != nil || counter == nil {
+		return link, done, err
+	}
+	return &transport.Link{
+		Reader: &SizeStatReader{
+			Counter: counter,
+			Reader:  link.Reader,
+		},
+		Writer: &SizeStatWriter{
+			Counter: counter,
+			Writer:  link.Writer,
+		},
+	}, done, nil
+}
+
+// acquireLimit checks the connection limits of the inbound and the user of the connection, and
+// returns the link that limits its rate.
+func acquireLimit(ctx context.Context, limiter policy.Limiter, link *transport.Link) (*transport.Link, func(), error) {
+	var inboundTag, email string
+	var level uint32
+	if inbound := session.InboundFromContext(ctx); inbound != nil {
+		inboundTag = inbound.Tag
+		if inbound.User != nil {
+			email = inbound.User.Email
+			level = inbound.User.Level
+		}
+	}
+	uplink, downlink, done, err := limiter.AcquireLimit(inboundTag, email, level)
+	if err != nil || uplink == nil {
+		return link, done, err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	limitedLink := &transport.Link{
+		Reader: &RateLimitedReader{
+			Context: ctx,
+			Limiter: uplink,
+			Reader:  link.Reader,
+		},
+		Writer: &RateLimitedWriter{
+			Context: ctx,
+			Limiter: downlink,
+			Writer:  link.Writer,
+		},
+	}
+	return limitedLink, func() {
+		cancel()
+		done()
+	}, nil
+}
+
+// track adds the connection to the tracker, and returns the link that counts its traffic.
+func (d *DefaultDispatcher) track(ctx context.Context, link *transport.Link, destination net.Destination, outboundTag string) (*transport.Link, func()) {
+	conn := &conntrack.Connection{
+		Destination: destination,
+		OutboundTag: outboundTag,
+		Start:       time.Now(),
+		Uplink:      new(trafficCounter),
+		Downlink:    new(trafficCounter),
+		Interrupt: func() {
+			common.Interrupt(link.Reader)
+			common.Interrupt(link.Writer)
+		},
+	}
+	if inbound := session.InboundFromContext(ctx); inbound != nil {
+		conn.InboundTag = inbound.Tag
+		conn.Source = inbound.Source
+		if inbound.User != nil {
+			conn.User = inbound.User.Email
+		}
+	}
+	if content := session.ContentFromContext(ctx); content != nil {
+		conn.Protocol = content.Protocol
+	}
+	conn.Domain = sniffedDomain(ctx, destination)
+
+	done := d.tracker.Track(conn)
+	return &transport.Link{
+		Reader: &SizeStatReader{
+			Counter: conn.Uplink,
+			Reader:  link.Reader,
+		},
+		Writer: &SizeStatWriter{
+			Counter: conn.Downlink,
+			Writer:  link.Writer,
+		},
+	}, done
+}
+
+// startSession starts the span of a dispatched session, if the dispatcher traces connections.
+// Spans of the session, such as dialing and DNS lookups of the outbound, are its children.
+func (d *DefaultDispatcher) startSession(ctx context.Context, destination net.Destination) (context.Context, tracing.Span) {
+	if d.tracer != nil {
+		ctx = tracing.ContextWithTracer(ctx, d.tracer)
+	}
+	ctx, span := tracing.Start(ctx, "session")
+	span.SetAttribute("destination", destination.String())
+	if inbound := session.InboundFromContext(ctx); inbound != nil {
+		span.SetAttribute("inbound.tag", inbound.Tag)
+		if inbound.Source.IsValid() {
+			span.SetAttribute("source", inbound.Source.String())
+		}
+		if inbound.User != nil && inbound.User.Email != "" {
+			span.SetAttribute("user", inbound.User.Email)
+		}
+	}
+	return ctx, span
+}
+
+// sniffedDomain returns the domain of the destination, or the domain sniffed for routing only.
+func sniffedDomain(ctx context.Context, destination net.Destination) string {
+	if ob := session.OutboundFromContext(ctx); ob != nil && ob.RouteTarget.IsValid() && ob.RouteTarget.Address.Family().IsDomain() {
+		return ob.RouteTarget.Address.Domain()
+	}
+	if destination.Address.Family().IsDomain() {
+		return destination.Address.Domain()
+	}
+	return ""
+}
+
+// recordAccessClose returns the link that counts the traffic of the connection, and a function to
+// record the access message of the closed connection.
+func recordAccessClose(accessMessage *log.AccessMessage, link *transport.Link) (*transport.Link, func()) {
+	start := time.Now()
+	uplink := new(trafficCounter)
+	downlink := new(trafficCounter)
+	done := func() {
+		closeMessage := *accessMessage
+		closeMessage.Status = log.AccessClosed
+		closeMessage.Reason = ""
+		closeMessage.Duration = time.Since(start)
+		closeMessage.Uplink = uplink.Value()
+		closeMessage.Downlink = downlink.Value()
+		log.Record(&closeMessage)
+	}
+	return &transport.Link{
+		Reader: &SizeStatReader{
+			Counter: uplink,
+			Reader:  link.Reader,
+		},
+		Writer: &SizeStatWriter{
+			Counter: downlink,
+			Writer:  link.Writer,
+		},
+	}, done
+}
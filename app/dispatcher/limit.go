@@ -0,0 +1,66 @@
+package dispatcher
+
+import (
+	"context"
+	"time"
+
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/buf"
+	"github.com/v2fly/v2ray-core/v5/features/policy"
+)
+
+// RateLimitedReader limits the rate of data read from a Reader.
+type RateLimitedReader struct {
+	Context context.Context
+	Limiter policy.RateLimiter
+	Reader  buf.Reader
+}
+
+func (r *RateLimitedReader) ReadMultiBuffer() (buf.MultiBuffer, error) {
+	mb, err := r.Reader.ReadMultiBuffer()
+	if waitErr := r.Limiter.Wait(r.Context, int64(mb.Len())); waitErr != nil && err == nil {
+		buf.ReleaseMulti(mb)
+		return nil, waitErr
+	}
+	return mb, err
+}
+
+func (r *RateLimitedReader) ReadMultiBufferTimeout(timeout time.Duration) (buf.MultiBuffer, error) {
+	timeoutReader, ok := r.Reader.(buf.TimeoutReader)
+	if !ok {
+		return nil, buf.ErrNotTimeoutReader
+	}
+	mb, err := timeoutReader.ReadMultiBufferTimeout(timeout)
+	if waitErr := r.Limiter.Wait(r.Context, int64(mb.Len())); waitErr != nil && err == nil {
+		buf.ReleaseMulti(mb)
+		return nil, waitErr
+	}
+	return mb, err
+}
+
+func (r *RateLimitedReader) Interrupt() {
+	common.Interrupt(r.Reader)
+}
+
+// RateLimitedWriter limits the rate of data written to a Writer.
+type RateLimitedWriter struct {
+	Context context.Context
+	Limiter policy.RateLimiter
+	Writer  buf.Writer
+}
+
+func (w *RateLimitedWriter) WriteMultiBuffer(mb buf.MultiBuffer) error {
+	if err := w.Limiter.Wait(w.Context, int64(mb.Len())); err != nil {
+		buf.ReleaseMulti(mb)
+		return err
+	}
+	return w.Writer.WriteMultiBuffer(mb)
+}
+
+func (w *RateLimitedWriter) Close() error {
+	return common.Close(w.Writer)
+}
+
+func (w *RateLimitedWriter) Interrupt() {
+	common.Interrupt(w.Writer)
+}
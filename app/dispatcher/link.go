@@ -0,0 +1,115 @@
+package dispatcher
+
+import (
+	"io"
+	"sync"
+	"time"
+
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/buf"
+	"github.com/v2fly/v2ray-core/v5/common/errors"
+	"github.com/v2fly/v2ray-core/v5/transport"
+)
+
+// linkEnd runs functions when the outbound handler is done with a link. Handlers such as Mux.Cool,
+// reverse portals and loopback keep using the link after their Dispatch returns, so the end of a
+// link is when its writer is closed or interrupted, or its reader is interrupted or fails.
+type linkEnd struct {
+	access sync.Mutex
+	funcs  []func()
+	ended  bool
+}
+
+// add registers a function to run when the link ends, in the reverse order of registration. The
+// function runs at once if the link has ended.
+func (e *linkEnd) add(f func()) {
+	e.access.Lock()
+	if e.ended {
+		e.access.Unlock()
+		f()
+		return
+	}
+	e.funcs = append(e.funcs, f)
+	e.access.Unlock()
+}
+
+func (e *linkEnd) end() {
+	e.access.Lock()
+	if e.ended {
+		e.access.Unlock()
+		return
+	}
+	e.ended = true
+	funcs := e.funcs
+	e.funcs = nil
+	e.access.Unlock()
+
+	for i := len(funcs) - 1; i >= 0; i-- {
+		funcs[i]()
+	}
+}
+
+// wrap returns the link that ends e on its end.
+func (e *linkEnd) wrap(link *transport.Link) *transport.Link {
+	return &transport.Link{
+		Reader: &linkEndReader{Reader: link.Reader, end: e},
+		Writer: &linkEndWriter{Writer: link.Writer, end: e},
+	}
+}
+
+type linkEndReader struct {
+	Reader buf.Reader
+	end    *linkEnd
+}
+
+func (r *linkEndReader) check(err error) {
+	// EOF only ends the uplink. The downlink may still be open.
+	if err != nil && errors.Cause(err) != io.EOF && err != buf.ErrReadTimeout {
+		r.end.end()
+	}
+}
+
+func (r *linkEndReader) ReadMultiBuffer() (buf.MultiBuffer, error) {
+	mb, err := r.Reader.ReadMultiBuffer()
+	r.check(err)
+	return mb, err
+}
+
+func (r *linkEndReader) ReadMultiBufferTimeout(timeout time.Duration) (buf.MultiBuffer, error) {
+	timeoutReader, ok := r.Reader.(buf.TimeoutReader)
+	if !ok {
+		return nil, buf.ErrNotTimeoutReader
+	}
+	mb, err := timeoutReader.ReadMultiBufferTimeout(timeout)
+	r.check(err)
+	return mb, err
+}
+
+func (r *linkEndReader) Interrupt() {
+	common.Interrupt(r.Reader)
+	r.end.end()
+}
+
+type linkEndWriter struct {
+	Writer buf.Writer
+	end    *linkEnd
+}
+
+func (w *linkEndWriter) WriteMultiBuffer(mb buf.MultiBuffer) error {
+	err := w.Writer.WriteMultiBuffer(mb)
+	if err != nil {
+		w.end.end()
+	}
+	return err
+}
+
+func (w *linkEndWriter) Close() error {
+	err := common.Close(w.Writer)
+	w.end.end()
+	return err
+}
+
+func (w *linkEndWriter) Interrupt() {
+	common.Interrupt(w.Writer)
+	w.end.end()
+}
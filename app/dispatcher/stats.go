@@ -1,6 +1,9 @@
 package dispatcher
 
 import (
+	"sync/atomic"
+	"time"
+
 	"github.com/v2fly/v2ray-core/v5/common"
 	"github.com/v2fly/v2ray-core/v5/common/buf"
 	"github.com/v2fly/v2ray-core/v5/features/stats"
@@ -23,3 +26,49 @@ func (w *SizeStatWriter) Close() error {
 func (w *SizeStatWriter) Interrupt() {
 	common.Interrupt(w.Writer)
 }
+
+// SizeStatReader counts the size of data read from a Reader.
+type SizeStatReader struct {
+	Counter stats.Counter
+	Reader  buf.Reader
+}
+
+func (r *SizeStatReader) ReadMultiBuffer() (buf.MultiBuffer, error) {
+	mb, err := r.Reader.ReadMultiBuffer()
+	r.Counter.Add(int64(mb.Len()))
+	return mb, err
+}
+
+func (r *SizeStatReader) ReadMultiBufferTimeout(timeout time.Duration) (buf.MultiBuffer, error) {
+	timeoutReader, ok := r.Reader.(buf.TimeoutReader)
+	if !ok {
+		return nil, buf.ErrNotTimeoutReader
+	}
+	mb, err := timeoutReader.ReadMultiBufferTimeout(timeout)
+	r.Counter.Add(int64(mb.Len()))
+	return mb, err
+}
+
+func (r *SizeStatReader) Interrupt() {
+	common.Interrupt(r.Reader)
+}
+
+// trafficCounter is a stats.Counter of the traffic of a single connection.
+type trafficCounter struct {
+	value int64
+}
+
+// Value implements stats.Counter.
+func (c *trafficCounter) Value() int64 {
+	return atomic.LoadInt64(&c.value)
+}
+
+// Set implements stats.Counter.
+func (c *trafficCounter) Set(newValue int64) int64 {
+	return atomic.SwapInt64(&c.value, newValue)
+}
+
+// Add implements stats.Counter.
+func (c *trafficCounter) Add(delta int64) int64 {
+	return atomic.AddInt64(&c.value, delta)
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/v2fly/v2ray-core/v5/common/strmatcher"
 	"github.com/v2fly/v2ray-core/v5/common/task"
 	"github.com/v2fly/v2ray-core/v5/features/dns"
+	"github.com/v2fly/v2ray-core/v5/features/tracing"
 )
 
 //go:generate go run github.com/v2fly/v2ray-core/v5/common/errors/errorgen
@@ -121,6 +122,11 @@ func (c *Client) Lookup(ctx context.Context, domain string, strategy dns.QuerySt
 		domain = domain[:len(domain)-1]
 	}
 
+	ctx, span := tracing.Start(ctx, "dns.lookup")
+	defer span.End()
+	span.SetAttribute("domain", domain)
+	span.SetAttribute("strategy", strategy.String())
+
 	var ips []net.IP
 	var cached4, cached6 bool
 	now := time.Now()
@@ -165,16 +171,20 @@ func (c *Client) Lookup(ctx context.Context, domain string, strategy dns.QuerySt
 			newStrategy = dns.QueryStrategy_USE_IP4
 		}
 	} else if len(ips) == 0 {
+		span.RecordError(dns.ErrEmptyResponse)
 		return nil, dns.ErrEmptyResponse
 	}
 
+	span.SetAttribute("cached", !query)
 	if query {
 		queried, err := c.lookup(ctx, domain, newStrategy)
 		if err != nil {
+			span.RecordError(err)
 			return nil, err
 		}
 		ips = append(ips, queried...)
 	}
+	span.SetAttribute("answers", len(ips))
 
 	if strategy == dns.QueryStrategy_PREFER_IP4 {
 		var newIPs []net.IP
@@ -25,6 +25,12 @@ func (s *LoggerServer) RestartLogger(ctx context.Context, request *RestartLogger
 	if logger == nil {
 		return nil, newError("unable to get logger instance")
 	}
+	if instance, ok := logger.(*log.Instance); ok {
+		if err := instance.Reopen(); err != nil {
+			return nil, newError("failed to reopen logger").Base(err)
+		}
+		return &RestartLoggerResponse{}, nil
+	}
 	if err := logger.Close(); err != nil {
 		return nil, newError("failed to close logger").Base(err)
 	}
@@ -7,6 +7,7 @@
 package log
 
 import (
+	routercommon "github.com/v2fly/v2ray-core/v5/app/router/routercommon"
 	log "github.com/v2fly/v2ray-core/v5/common/log"
 	_ "github.com/v2fly/v2ray-core/v5/common/protoext"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
@@ -25,10 +26,12 @@ const (
 type LogType int32
 
 const (
-	LogType_None    LogType = 0
-	LogType_Console LogType = 1
-	LogType_File    LogType = 2
-	LogType_Event   LogType = 3
+	LogType_None     LogType = 0
+	LogType_Console  LogType = 1
+	LogType_File     LogType = 2
+	LogType_Event    LogType = 3
+	LogType_Syslog   LogType = 4
+	LogType_Journald LogType = 5
 )
 
 // Enum value maps for LogType.
@@ -38,12 +41,16 @@ var (
 		1: "Console",
 		2: "File",
 		3: "Event",
+		4: "Syslog",
+		5: "Journald",
 	}
 	LogType_value = map[string]int32{
-		"None":    0,
-		"Console": 1,
-		"File":    2,
-		"Event":   3,
+		"None":     0,
+		"Console":  1,
+		"File":     2,
+		"Event":    3,
+		"Syslog":   4,
+		"Journald": 5,
 	}
 )
 
@@ -74,14 +81,126 @@ func (LogType) EnumDescriptor() ([]byte, []int) {
 	return file_app_log_config_proto_rawDescGZIP(), []int{0}
 }
 
+type LogFormat int32
+
+const (
+	LogFormat_Text   LogFormat = 0
+	LogFormat_JSON   LogFormat = 1
+	LogFormat_Logfmt LogFormat = 2
+)
+
+// Enum value maps for LogFormat.
+var (
+	LogFormat_name = map[int32]string{
+		0: "Text",
+		1: "JSON",
+		2: "Logfmt",
+	}
+	LogFormat_value = map[string]int32{
+		"Text":   0,
+		"JSON":   1,
+		"Logfmt": 2,
+	}
+)
+
+func (x LogFormat) Enum() *LogFormat {
+	p := new(LogFormat)
+	*p = x
+	return p
+}
+
+func (x LogFormat) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (LogFormat) Descriptor() protoreflect.EnumDescriptor {
+	return file_app_log_config_proto_enumTypes[1].Descriptor()
+}
+
+func (LogFormat) Type() protoreflect.EnumType {
+	return &file_app_log_config_proto_enumTypes[1]
+}
+
+func (x LogFormat) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use LogFormat.Descriptor instead.
+func (LogFormat) EnumDescriptor() ([]byte, []int) {
+	return file_app_log_config_proto_rawDescGZIP(), []int{1}
+}
+
+type LogSpecification_IPMask int32
+
+const (
+	LogSpecification_NoMask LogSpecification_IPMask = 0
+	// Only the first 24 bits of IPv4 and 48 bits of IPv6 addresses are kept.
+	LogSpecification_Subnet LogSpecification_IPMask = 1
+	LogSpecification_Full   LogSpecification_IPMask = 2
+)
+
+// Enum value maps for LogSpecification_IPMask.
+var (
+	LogSpecification_IPMask_name = map[int32]string{
+		0: "NoMask",
+		1: "Subnet",
+		2: "Full",
+	}
+	LogSpecification_IPMask_value = map[string]int32{
+		"NoMask": 0,
+		"Subnet": 1,
+		"Full":   2,
+	}
+)
+
+func (x LogSpecification_IPMask) Enum() *LogSpecification_IPMask {
+	p := new(LogSpecification_IPMask)
+	*p = x
+	return p
+}
+
+func (x LogSpecification_IPMask) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (LogSpecification_IPMask) Descriptor() protoreflect.EnumDescriptor {
+	return file_app_log_config_proto_enumTypes[2].Descriptor()
+}
+
+func (LogSpecification_IPMask) Type() protoreflect.EnumType {
+	return &file_app_log_config_proto_enumTypes[2]
+}
+
+func (x LogSpecification_IPMask) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use LogSpecification_IPMask.Descriptor instead.
+func (LogSpecification_IPMask) EnumDescriptor() ([]byte, []int) {
+	return file_app_log_config_proto_rawDescGZIP(), []int{0, 0}
+}
+
 type LogSpecification struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Type  LogType      `protobuf:"varint,1,opt,name=type,proto3,enum=v2ray.core.app.log.LogType" json:"type,omitempty"`
-	Level log.Severity `protobuf:"varint,2,opt,name=level,proto3,enum=v2ray.core.common.log.Severity" json:"level,omitempty"`
-	Path  string       `protobuf:"bytes,3,opt,name=path,proto3" json:"path,omitempty"`
+	Type   LogType      `protobuf:"varint,1,opt,name=type,proto3,enum=v2ray.core.app.log.LogType" json:"type,omitempty"`
+	Level  log.Severity `protobuf:"varint,2,opt,name=level,proto3,enum=v2ray.core.common.log.Severity" json:"level,omitempty"`
+	Path   string       `protobuf:"bytes,3,opt,name=path,proto3" json:"path,omitempty"`
+	Format LogFormat    `protobuf:"varint,4,opt,name=format,proto3,enum=v2ray.core.app.log.LogFormat" json:"format,omitempty"`
+	// Names of the fields in JSON and logfmt logs. All fields are written if
+	// empty.
+	Fields []string `protobuf:"bytes,5,rep,name=fields,proto3" json:"fields,omitempty"`
+	// Masking of the IP addresses in the fields of access logs.
+	MaskIp LogSpecification_IPMask `protobuf:"varint,6,opt,name=mask_ip,json=maskIp,proto3,enum=v2ray.core.app.log.LogSpecification_IPMask" json:"mask_ip,omitempty"`
+	// Whether to write access logs of closed connections, with their durations
+	// and traffic.
+	RecordClose bool `protobuf:"varint,7,opt,name=record_close,json=recordClose,proto3" json:"record_close,omitempty"`
+	// Rotation of the log file, for the File type.
+	Rotation *LogSpecification_Rotation `protobuf:"bytes,8,opt,name=rotation,proto3" json:"rotation,omitempty"`
+	// Syslog server for the Syslog type.
+	Syslog *LogSpecification_Syslog `protobuf:"bytes,9,opt,name=syslog,proto3" json:"syslog,omitempty"`
 }
 
 func (x *LogSpecification) Reset() {
@@ -137,6 +256,48 @@ func (x *LogSpecification) GetPath() string {
 	return ""
 }
 
+func (x *LogSpecification) GetFormat() LogFormat {
+	if x != nil {
+		return x.Format
+	}
+	return LogFormat_Text
+}
+
+func (x *LogSpecification) GetFields() []string {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+func (x *LogSpecification) GetMaskIp() LogSpecification_IPMask {
+	if x != nil {
+		return x.MaskIp
+	}
+	return LogSpecification_NoMask
+}
+
+func (x *LogSpecification) GetRecordClose() bool {
+	if x != nil {
+		return x.RecordClose
+	}
+	return false
+}
+
+func (x *LogSpecification) GetRotation() *LogSpecification_Rotation {
+	if x != nil {
+		return x.Rotation
+	}
+	return nil
+}
+
+func (x *LogSpecification) GetSyslog() *LogSpecification_Syslog {
+	if x != nil {
+		return x.Syslog
+	}
+	return nil
+}
+
 type Config struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -144,6 +305,17 @@ type Config struct {
 
 	Error  *LogSpecification `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`
 	Access *LogSpecification `protobuf:"bytes,7,opt,name=access,proto3" json:"access,omitempty"`
+	// Filters of access logs. Messages are written if they match any filter
+	// that doesn't exclude, or there is none, and match no filter that excludes.
+	AccessFilter []*AccessFilter `protobuf:"bytes,8,rep,name=access_filter,json=accessFilter,proto3" json:"access_filter,omitempty"`
+	// Fraction of connections whose access messages are written after filtering.
+	// Messages of a connection are sampled together. All are written if 0.
+	AccessSampleRate float32 `protobuf:"fixed32,9,opt,name=access_sample_rate,json=accessSampleRate,proto3" json:"access_sample_rate,omitempty"`
+	// Levels of error logs of connections of inbounds and outbounds by tags,
+	// overriding the level of error logs. The most verbose level applies if
+	// both match.
+	InboundLevel  map[string]log.Severity `protobuf:"bytes,10,rep,name=inbound_level,json=inboundLevel,proto3" json:"inbound_level,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3,enum=v2ray.core.common.log.Severity"`
+	OutboundLevel map[string]log.Severity `protobuf:"bytes,11,rep,name=outbound_level,json=outboundLevel,proto3" json:"outbound_level,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3,enum=v2ray.core.common.log.Severity"`
 }
 
 func (x *Config) Reset() {
@@ -192,6 +364,287 @@ func (x *Config) GetAccess() *LogSpecification {
 	return nil
 }
 
+func (x *Config) GetAccessFilter() []*AccessFilter {
+	if x != nil {
+		return x.AccessFilter
+	}
+	return nil
+}
+
+func (x *Config) GetAccessSampleRate() float32 {
+	if x != nil {
+		return x.AccessSampleRate
+	}
+	return 0
+}
+
+func (x *Config) GetInboundLevel() map[string]log.Severity {
+	if x != nil {
+		return x.InboundLevel
+	}
+	return nil
+}
+
+func (x *Config) GetOutboundLevel() map[string]log.Severity {
+	if x != nil {
+		return x.OutboundLevel
+	}
+	return nil
+}
+
+// AccessFilter matches access messages satisfying all of its conditions that
+// are not empty.
+type AccessFilter struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	InboundTag  []string `protobuf:"bytes,1,rep,name=inbound_tag,json=inboundTag,proto3" json:"inbound_tag,omitempty"`
+	OutboundTag []string `protobuf:"bytes,2,rep,name=outbound_tag,json=outboundTag,proto3" json:"outbound_tag,omitempty"`
+	User        []string `protobuf:"bytes,3,rep,name=user,proto3" json:"user,omitempty"`
+	// Domains of connections, sniffed if available.
+	Domain []*routercommon.Domain `protobuf:"bytes,4,rep,name=domain,proto3" json:"domain,omitempty"`
+	// "accepted", "rejected" or "closed".
+	Status []string `protobuf:"bytes,5,rep,name=status,proto3" json:"status,omitempty"`
+	// Whether to drop matched messages instead of writing them.
+	Exclude bool `protobuf:"varint,6,opt,name=exclude,proto3" json:"exclude,omitempty"`
+}
+
+func (x *AccessFilter) Reset() {
+	*x = AccessFilter{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_app_log_config_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AccessFilter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AccessFilter) ProtoMessage() {}
+
+func (x *AccessFilter) ProtoReflect() protoreflect.Message {
+	mi := &file_app_log_config_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AccessFilter.ProtoReflect.Descriptor instead.
+func (*AccessFilter) Descriptor() ([]byte, []int) {
+	return file_app_log_config_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *AccessFilter) GetInboundTag() []string {
+	if x != nil {
+		return x.InboundTag
+	}
+	return nil
+}
+
+func (x *AccessFilter) GetOutboundTag() []string {
+	if x != nil {
+		return x.OutboundTag
+	}
+	return nil
+}
+
+func (x *AccessFilter) GetUser() []string {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+func (x *AccessFilter) GetDomain() []*routercommon.Domain {
+	if x != nil {
+		return x.Domain
+	}
+	return nil
+}
+
+func (x *AccessFilter) GetStatus() []string {
+	if x != nil {
+		return x.Status
+	}
+	return nil
+}
+
+func (x *AccessFilter) GetExclude() bool {
+	if x != nil {
+		return x.Exclude
+	}
+	return false
+}
+
+type LogSpecification_Rotation struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Maximum size in bytes of a log file before it is rotated. Not rotated by
+	// size if 0.
+	MaxSize uint64 `protobuf:"varint,1,opt,name=max_size,json=maxSize,proto3" json:"max_size,omitempty"`
+	// Interval in seconds of rotating log files, aligned to UTC. Not rotated by
+	// time if 0.
+	Interval uint32 `protobuf:"varint,2,opt,name=interval,proto3" json:"interval,omitempty"`
+	// Maximum number of rotated files to keep. All are kept if 0.
+	MaxBackups uint32 `protobuf:"varint,3,opt,name=max_backups,json=maxBackups,proto3" json:"max_backups,omitempty"`
+	// Maximum age in seconds of rotated files to keep. All are kept if 0.
+	MaxAge   uint32 `protobuf:"varint,4,opt,name=max_age,json=maxAge,proto3" json:"max_age,omitempty"`
+	Compress bool   `protobuf:"varint,5,opt,name=compress,proto3" json:"compress,omitempty"`
+}
+
+func (x *LogSpecification_Rotation) Reset() {
+	*x = LogSpecification_Rotation{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_app_log_config_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LogSpecification_Rotation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogSpecification_Rotation) ProtoMessage() {}
+
+func (x *LogSpecification_Rotation) ProtoReflect() protoreflect.Message {
+	mi := &file_app_log_config_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogSpecification_Rotation.ProtoReflect.Descriptor instead.
+func (*LogSpecification_Rotation) Descriptor() ([]byte, []int) {
+	return file_app_log_config_proto_rawDescGZIP(), []int{0, 0}
+}
+
+func (x *LogSpecification_Rotation) GetMaxSize() uint64 {
+	if x != nil {
+		return x.MaxSize
+	}
+	return 0
+}
+
+func (x *LogSpecification_Rotation) GetInterval() uint32 {
+	if x != nil {
+		return x.Interval
+	}
+	return 0
+}
+
+func (x *LogSpecification_Rotation) GetMaxBackups() uint32 {
+	if x != nil {
+		return x.MaxBackups
+	}
+	return 0
+}
+
+func (x *LogSpecification_Rotation) GetMaxAge() uint32 {
+	if x != nil {
+		return x.MaxAge
+	}
+	return 0
+}
+
+func (x *LogSpecification_Rotation) GetCompress() bool {
+	if x != nil {
+		return x.Compress
+	}
+	return false
+}
+
+type LogSpecification_Syslog struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// "udp", "tcp", "unix" or "unixgram". The local syslog socket is used if
+	// empty.
+	Network string `protobuf:"bytes,1,opt,name=network,proto3" json:"network,omitempty"`
+	Address string `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`
+	// APP-NAME of messages, "v2ray" if empty. It is also the identifier of
+	// messages of the Journald type.
+	Tag string `protobuf:"bytes,3,opt,name=tag,proto3" json:"tag,omitempty"`
+	// Facility code of messages, such as 3 for daemon and 16 for local0.
+	Facility uint32 `protobuf:"varint,4,opt,name=facility,proto3" json:"facility,omitempty"`
+}
+
+func (x *LogSpecification_Syslog) Reset() {
+	*x = LogSpecification_Syslog{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_app_log_config_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LogSpecification_Syslog) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogSpecification_Syslog) ProtoMessage() {}
+
+func (x *LogSpecification_Syslog) ProtoReflect() protoreflect.Message {
+	mi := &file_app_log_config_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogSpecification_Syslog.ProtoReflect.Descriptor instead.
+func (*LogSpecification_Syslog) Descriptor() ([]byte, []int) {
+	return file_app_log_config_proto_rawDescGZIP(), []int{0, 1}
+}
+
+func (x *LogSpecification_Syslog) GetNetwork() string {
+	if x != nil {
+		return x.Network
+	}
+	return ""
+}
+
+func (x *LogSpecification_Syslog) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *LogSpecification_Syslog) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
+func (x *LogSpecification_Syslog) GetFacility() uint32 {
+	if x != nil {
+		return x.Facility
+	}
+	return 0
+}
+
 var File_app_log_config_proto protoreflect.FileDescriptor
 
 var file_app_log_config_proto_rawDesc = []byte{
@@ -201,16 +654,58 @@ var file_app_log_config_proto_rawDesc = []byte{
 	0x6f, 0x6e, 0x2f, 0x6c, 0x6f, 0x67, 0x2f, 0x6c, 0x6f, 0x67, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
 	0x1a, 0x20, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x65, 0x78,
 	0x74, 0x2f, 0x65, 0x78, 0x74, 0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x22, 0x8e, 0x01, 0x0a, 0x10, 0x4c, 0x6f, 0x67, 0x53, 0x70, 0x65, 0x63, 0x69, 0x66,
-	0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x2f, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1b, 0x2e, 0x76, 0x32, 0x72, 0x61, 0x79, 0x2e, 0x63, 0x6f,
-	0x72, 0x65, 0x2e, 0x61, 0x70, 0x70, 0x2e, 0x6c, 0x6f, 0x67, 0x2e, 0x4c, 0x6f, 0x67, 0x54, 0x79,
-	0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x35, 0x0a, 0x05, 0x6c, 0x65, 0x76, 0x65,
-	0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1f, 0x2e, 0x76, 0x32, 0x72, 0x61, 0x79, 0x2e,
-	0x63, 0x6f, 0x72, 0x65, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x6c, 0x6f, 0x67, 0x2e,
-	0x53, 0x65, 0x76, 0x65, 0x72, 0x69, 0x74, 0x79, 0x52, 0x05, 0x6c, 0x65, 0x76, 0x65, 0x6c, 0x12,
-	0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70,
-	0x61, 0x74, 0x68, 0x22, 0xb8, 0x01, 0x0a, 0x06, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x3a,
+	0x74, 0x6f, 0x1a, 0x24, 0x61, 0x70, 0x70, 0x2f, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x72, 0x2f, 0x72,
+	0x6f, 0x75, 0x74, 0x65, 0x72, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2f, 0x63, 0x6f, 0x6d, 0x6d,
+	0x6f, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x88, 0x06, 0x0a, 0x10, 0x4c, 0x6f, 0x67,
+	0x53, 0x70, 0x65, 0x63, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x2f, 0x0a,
+	0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1b, 0x2e, 0x76, 0x32,
+	0x72, 0x61, 0x79, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x61, 0x70, 0x70, 0x2e, 0x6c, 0x6f, 0x67,
+	0x2e, 0x4c, 0x6f, 0x67, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x35,
+	0x0a, 0x05, 0x6c, 0x65, 0x76, 0x65, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1f, 0x2e,
+	0x76, 0x32, 0x72, 0x61, 0x79, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f,
+	0x6e, 0x2e, 0x6c, 0x6f, 0x67, 0x2e, 0x53, 0x65, 0x76, 0x65, 0x72, 0x69, 0x74, 0x79, 0x52, 0x05,
+	0x6c, 0x65, 0x76, 0x65, 0x6c, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x12, 0x35, 0x0a, 0x06, 0x66, 0x6f, 0x72,
+	0x6d, 0x61, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1d, 0x2e, 0x76, 0x32, 0x72, 0x61,
+	0x79, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x61, 0x70, 0x70, 0x2e, 0x6c, 0x6f, 0x67, 0x2e, 0x4c,
+	0x6f, 0x67, 0x46, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x52, 0x06, 0x66, 0x6f, 0x72, 0x6d, 0x61, 0x74,
+	0x12, 0x16, 0x0a, 0x06, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x06, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x73, 0x12, 0x44, 0x0a, 0x07, 0x6d, 0x61, 0x73, 0x6b,
+	0x5f, 0x69, 0x70, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x2b, 0x2e, 0x76, 0x32, 0x72, 0x61,
+	0x79, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x61, 0x70, 0x70, 0x2e, 0x6c, 0x6f, 0x67, 0x2e, 0x4c,
+	0x6f, 0x67, 0x53, 0x70, 0x65, 0x63, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e,
+	0x49, 0x50, 0x4d, 0x61, 0x73, 0x6b, 0x52, 0x06, 0x6d, 0x61, 0x73, 0x6b, 0x49, 0x70, 0x12, 0x21,
+	0x0a, 0x0c, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x5f, 0x63, 0x6c, 0x6f, 0x73, 0x65, 0x18, 0x07,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x43, 0x6c, 0x6f, 0x73,
+	0x65, 0x12, 0x49, 0x0a, 0x08, 0x72, 0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x08, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x2d, 0x2e, 0x76, 0x32, 0x72, 0x61, 0x79, 0x2e, 0x63, 0x6f, 0x72, 0x65,
+	0x2e, 0x61, 0x70, 0x70, 0x2e, 0x6c, 0x6f, 0x67, 0x2e, 0x4c, 0x6f, 0x67, 0x53, 0x70, 0x65, 0x63,
+	0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x52, 0x6f, 0x74, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x52, 0x08, 0x72, 0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x43, 0x0a, 0x06,
+	0x73, 0x79, 0x73, 0x6c, 0x6f, 0x67, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x2b, 0x2e, 0x76,
+	0x32, 0x72, 0x61, 0x79, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x61, 0x70, 0x70, 0x2e, 0x6c, 0x6f,
+	0x67, 0x2e, 0x4c, 0x6f, 0x67, 0x53, 0x70, 0x65, 0x63, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x2e, 0x53, 0x79, 0x73, 0x6c, 0x6f, 0x67, 0x52, 0x06, 0x73, 0x79, 0x73, 0x6c, 0x6f,
+	0x67, 0x1a, 0x97, 0x01, 0x0a, 0x08, 0x52, 0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x19,
+	0x0a, 0x08, 0x6d, 0x61, 0x78, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x07, 0x6d, 0x61, 0x78, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x69, 0x6e, 0x74,
+	0x65, 0x72, 0x76, 0x61, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x69, 0x6e, 0x74,
+	0x65, 0x72, 0x76, 0x61, 0x6c, 0x12, 0x1f, 0x0a, 0x0b, 0x6d, 0x61, 0x78, 0x5f, 0x62, 0x61, 0x63,
+	0x6b, 0x75, 0x70, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x6d, 0x61, 0x78, 0x42,
+	0x61, 0x63, 0x6b, 0x75, 0x70, 0x73, 0x12, 0x17, 0x0a, 0x07, 0x6d, 0x61, 0x78, 0x5f, 0x61, 0x67,
+	0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x6d, 0x61, 0x78, 0x41, 0x67, 0x65, 0x12,
+	0x1a, 0x0a, 0x08, 0x63, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x08, 0x63, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x1a, 0x6a, 0x0a, 0x06, 0x53,
+	0x79, 0x73, 0x6c, 0x6f, 0x67, 0x12, 0x18, 0x0a, 0x07, 0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x12,
+	0x18, 0x0a, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x10, 0x0a, 0x03, 0x74, 0x61, 0x67,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x74, 0x61, 0x67, 0x12, 0x1a, 0x0a, 0x08, 0x66,
+	0x61, 0x63, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x66,
+	0x61, 0x63, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x22, 0x2a, 0x0a, 0x06, 0x49, 0x50, 0x4d, 0x61, 0x73,
+	0x6b, 0x12, 0x0a, 0x0a, 0x06, 0x4e, 0x6f, 0x4d, 0x61, 0x73, 0x6b, 0x10, 0x00, 0x12, 0x0a, 0x0a,
+	0x06, 0x53, 0x75, 0x62, 0x6e, 0x65, 0x74, 0x10, 0x01, 0x12, 0x08, 0x0a, 0x04, 0x46, 0x75, 0x6c,
+	0x6c, 0x10, 0x02, 0x22, 0x97, 0x05, 0x0a, 0x06, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x3a,
 	0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x24, 0x2e,
 	0x76, 0x32, 0x72, 0x61, 0x79, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x61, 0x70, 0x70, 0x2e, 0x6c,
 	0x6f, 0x67, 0x2e, 0x4c, 0x6f, 0x67, 0x53, 0x70, 0x65, 0x63, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74,
@@ -218,20 +713,68 @@ var file_app_log_config_proto_rawDesc = []byte{
 	0x63, 0x65, 0x73, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x24, 0x2e, 0x76, 0x32, 0x72,
 	0x61, 0x79, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x61, 0x70, 0x70, 0x2e, 0x6c, 0x6f, 0x67, 0x2e,
 	0x4c, 0x6f, 0x67, 0x53, 0x70, 0x65, 0x63, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e,
-	0x52, 0x06, 0x61, 0x63, 0x63, 0x65, 0x73, 0x73, 0x3a, 0x16, 0x82, 0xb5, 0x18, 0x09, 0x0a, 0x07,
-	0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x82, 0xb5, 0x18, 0x05, 0x12, 0x03, 0x6c, 0x6f, 0x67,
-	0x4a, 0x04, 0x08, 0x01, 0x10, 0x02, 0x4a, 0x04, 0x08, 0x02, 0x10, 0x03, 0x4a, 0x04, 0x08, 0x03,
-	0x10, 0x04, 0x4a, 0x04, 0x08, 0x04, 0x10, 0x05, 0x4a, 0x04, 0x08, 0x05, 0x10, 0x06, 0x2a, 0x35,
-	0x0a, 0x07, 0x4c, 0x6f, 0x67, 0x54, 0x79, 0x70, 0x65, 0x12, 0x08, 0x0a, 0x04, 0x4e, 0x6f, 0x6e,
-	0x65, 0x10, 0x00, 0x12, 0x0b, 0x0a, 0x07, 0x43, 0x6f, 0x6e, 0x73, 0x6f, 0x6c, 0x65, 0x10, 0x01,
-	0x12, 0x08, 0x0a, 0x04, 0x46, 0x69, 0x6c, 0x65, 0x10, 0x02, 0x12, 0x09, 0x0a, 0x05, 0x45, 0x76,
-	0x65, 0x6e, 0x74, 0x10, 0x03, 0x42, 0x57, 0x0a, 0x16, 0x63, 0x6f, 0x6d, 0x2e, 0x76, 0x32, 0x72,
-	0x61, 0x79, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x61, 0x70, 0x70, 0x2e, 0x6c, 0x6f, 0x67, 0x50,
-	0x01, 0x5a, 0x26, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x76, 0x32,
-	0x66, 0x6c, 0x79, 0x2f, 0x76, 0x32, 0x72, 0x61, 0x79, 0x2d, 0x63, 0x6f, 0x72, 0x65, 0x2f, 0x76,
-	0x35, 0x2f, 0x61, 0x70, 0x70, 0x2f, 0x6c, 0x6f, 0x67, 0xaa, 0x02, 0x12, 0x56, 0x32, 0x52, 0x61,
-	0x79, 0x2e, 0x43, 0x6f, 0x72, 0x65, 0x2e, 0x41, 0x70, 0x70, 0x2e, 0x4c, 0x6f, 0x67, 0x62, 0x06,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x52, 0x06, 0x61, 0x63, 0x63, 0x65, 0x73, 0x73, 0x12, 0x45, 0x0a, 0x0d, 0x61, 0x63, 0x63, 0x65,
+	0x73, 0x73, 0x5f, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x18, 0x08, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x20, 0x2e, 0x76, 0x32, 0x72, 0x61, 0x79, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x61, 0x70, 0x70,
+	0x2e, 0x6c, 0x6f, 0x67, 0x2e, 0x41, 0x63, 0x63, 0x65, 0x73, 0x73, 0x46, 0x69, 0x6c, 0x74, 0x65,
+	0x72, 0x52, 0x0c, 0x61, 0x63, 0x63, 0x65, 0x73, 0x73, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x12,
+	0x2c, 0x0a, 0x12, 0x61, 0x63, 0x63, 0x65, 0x73, 0x73, 0x5f, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65,
+	0x5f, 0x72, 0x61, 0x74, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x02, 0x52, 0x10, 0x61, 0x63, 0x63,
+	0x65, 0x73, 0x73, 0x53, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x52, 0x61, 0x74, 0x65, 0x12, 0x51, 0x0a,
+	0x0d, 0x69, 0x6e, 0x62, 0x6f, 0x75, 0x6e, 0x64, 0x5f, 0x6c, 0x65, 0x76, 0x65, 0x6c, 0x18, 0x0a,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x2c, 0x2e, 0x76, 0x32, 0x72, 0x61, 0x79, 0x2e, 0x63, 0x6f, 0x72,
+	0x65, 0x2e, 0x61, 0x70, 0x70, 0x2e, 0x6c, 0x6f, 0x67, 0x2e, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67,
+	0x2e, 0x49, 0x6e, 0x62, 0x6f, 0x75, 0x6e, 0x64, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x45, 0x6e, 0x74,
+	0x72, 0x79, 0x52, 0x0c, 0x69, 0x6e, 0x62, 0x6f, 0x75, 0x6e, 0x64, 0x4c, 0x65, 0x76, 0x65, 0x6c,
+	0x12, 0x54, 0x0a, 0x0e, 0x6f, 0x75, 0x74, 0x62, 0x6f, 0x75, 0x6e, 0x64, 0x5f, 0x6c, 0x65, 0x76,
+	0x65, 0x6c, 0x18, 0x0b, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x2d, 0x2e, 0x76, 0x32, 0x72, 0x61, 0x79,
+	0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x61, 0x70, 0x70, 0x2e, 0x6c, 0x6f, 0x67, 0x2e, 0x43, 0x6f,
+	0x6e, 0x66, 0x69, 0x67, 0x2e, 0x4f, 0x75, 0x74, 0x62, 0x6f, 0x75, 0x6e, 0x64, 0x4c, 0x65, 0x76,
+	0x65, 0x6c, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x0d, 0x6f, 0x75, 0x74, 0x62, 0x6f, 0x75, 0x6e,
+	0x64, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x1a, 0x60, 0x0a, 0x11, 0x49, 0x6e, 0x62, 0x6f, 0x75, 0x6e,
+	0x64, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b,
+	0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x35, 0x0a,
+	0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1f, 0x2e, 0x76,
+	0x32, 0x72, 0x61, 0x79, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e,
+	0x2e, 0x6c, 0x6f, 0x67, 0x2e, 0x53, 0x65, 0x76, 0x65, 0x72, 0x69, 0x74, 0x79, 0x52, 0x05, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x1a, 0x61, 0x0a, 0x12, 0x4f, 0x75, 0x74, 0x62,
+	0x6f, 0x75, 0x6e, 0x64, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10,
+	0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79,
+	0x12, 0x35, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32,
+	0x1f, 0x2e, 0x76, 0x32, 0x72, 0x61, 0x79, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x63, 0x6f, 0x6d,
+	0x6d, 0x6f, 0x6e, 0x2e, 0x6c, 0x6f, 0x67, 0x2e, 0x53, 0x65, 0x76, 0x65, 0x72, 0x69, 0x74, 0x79,
+	0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x3a, 0x12, 0x82, 0xb5, 0x18,
+	0x0e, 0x0a, 0x07, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x03, 0x6c, 0x6f, 0x67, 0x4a,
+	0x04, 0x08, 0x01, 0x10, 0x02, 0x4a, 0x04, 0x08, 0x02, 0x10, 0x03, 0x4a, 0x04, 0x08, 0x03, 0x10,
+	0x04, 0x4a, 0x04, 0x08, 0x04, 0x10, 0x05, 0x4a, 0x04, 0x08, 0x05, 0x10, 0x06, 0x22, 0xdc, 0x01,
+	0x0a, 0x0c, 0x41, 0x63, 0x63, 0x65, 0x73, 0x73, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x12, 0x1f,
+	0x0a, 0x0b, 0x69, 0x6e, 0x62, 0x6f, 0x75, 0x6e, 0x64, 0x5f, 0x74, 0x61, 0x67, 0x18, 0x01, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x0a, 0x69, 0x6e, 0x62, 0x6f, 0x75, 0x6e, 0x64, 0x54, 0x61, 0x67, 0x12,
+	0x21, 0x0a, 0x0c, 0x6f, 0x75, 0x74, 0x62, 0x6f, 0x75, 0x6e, 0x64, 0x5f, 0x74, 0x61, 0x67, 0x18,
+	0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0b, 0x6f, 0x75, 0x74, 0x62, 0x6f, 0x75, 0x6e, 0x64, 0x54,
+	0x61, 0x67, 0x12, 0x12, 0x0a, 0x04, 0x75, 0x73, 0x65, 0x72, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x04, 0x75, 0x73, 0x65, 0x72, 0x12, 0x42, 0x0a, 0x06, 0x64, 0x6f, 0x6d, 0x61, 0x69, 0x6e,
+	0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x2a, 0x2e, 0x76, 0x32, 0x72, 0x61, 0x79, 0x2e, 0x63,
+	0x6f, 0x72, 0x65, 0x2e, 0x61, 0x70, 0x70, 0x2e, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x72, 0x2e, 0x72,
+	0x6f, 0x75, 0x74, 0x65, 0x72, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x44, 0x6f, 0x6d, 0x61,
+	0x69, 0x6e, 0x52, 0x06, 0x64, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x65, 0x78, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x07, 0x65, 0x78, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x2a, 0x4f, 0x0a, 0x07,
+	0x4c, 0x6f, 0x67, 0x54, 0x79, 0x70, 0x65, 0x12, 0x08, 0x0a, 0x04, 0x4e, 0x6f, 0x6e, 0x65, 0x10,
+	0x00, 0x12, 0x0b, 0x0a, 0x07, 0x43, 0x6f, 0x6e, 0x73, 0x6f, 0x6c, 0x65, 0x10, 0x01, 0x12, 0x08,
+	0x0a, 0x04, 0x46, 0x69, 0x6c, 0x65, 0x10, 0x02, 0x12, 0x09, 0x0a, 0x05, 0x45, 0x76, 0x65, 0x6e,
+	0x74, 0x10, 0x03, 0x12, 0x0a, 0x0a, 0x06, 0x53, 0x79, 0x73, 0x6c, 0x6f, 0x67, 0x10, 0x04, 0x12,
+	0x0c, 0x0a, 0x08, 0x4a, 0x6f, 0x75, 0x72, 0x6e, 0x61, 0x6c, 0x64, 0x10, 0x05, 0x2a, 0x2b, 0x0a,
+	0x09, 0x4c, 0x6f, 0x67, 0x46, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x12, 0x08, 0x0a, 0x04, 0x54, 0x65,
+	0x78, 0x74, 0x10, 0x00, 0x12, 0x08, 0x0a, 0x04, 0x4a, 0x53, 0x4f, 0x4e, 0x10, 0x01, 0x12, 0x0a,
+	0x0a, 0x06, 0x4c, 0x6f, 0x67, 0x66, 0x6d, 0x74, 0x10, 0x02, 0x42, 0x57, 0x0a, 0x16, 0x63, 0x6f,
+	0x6d, 0x2e, 0x76, 0x32, 0x72, 0x61, 0x79, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x61, 0x70, 0x70,
+	0x2e, 0x6c, 0x6f, 0x67, 0x50, 0x01, 0x5a, 0x26, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63,
+	0x6f, 0x6d, 0x2f, 0x76, 0x32, 0x66, 0x6c, 0x79, 0x2f, 0x76, 0x32, 0x72, 0x61, 0x79, 0x2d, 0x63,
+	0x6f, 0x72, 0x65, 0x2f, 0x76, 0x35, 0x2f, 0x61, 0x70, 0x70, 0x2f, 0x6c, 0x6f, 0x67, 0xaa, 0x02,
+	0x12, 0x56, 0x32, 0x52, 0x61, 0x79, 0x2e, 0x43, 0x6f, 0x72, 0x65, 0x2e, 0x41, 0x70, 0x70, 0x2e,
+	0x4c, 0x6f, 0x67, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -246,24 +789,42 @@ func file_app_log_config_proto_rawDescGZIP() []byte {
 	return file_app_log_config_proto_rawDescData
 }
 
-var file_app_log_config_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_app_log_config_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_app_log_config_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
+var file_app_log_config_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
 var file_app_log_config_proto_goTypes = []interface{}{
-	(LogType)(0),             // 0: v2ray.core.app.log.LogType
-	(*LogSpecification)(nil), // 1: v2ray.core.app.log.LogSpecification
-	(*Config)(nil),           // 2: v2ray.core.app.log.Config
-	(log.Severity)(0),        // 3: v2ray.core.common.log.Severity
+	(LogType)(0),                      // 0: v2ray.core.app.log.LogType
+	(LogFormat)(0),                    // 1: v2ray.core.app.log.LogFormat
+	(LogSpecification_IPMask)(0),      // 2: v2ray.core.app.log.LogSpecification.IPMask
+	(*LogSpecification)(nil),          // 3: v2ray.core.app.log.LogSpecification
+	(*Config)(nil),                    // 4: v2ray.core.app.log.Config
+	(*AccessFilter)(nil),              // 5: v2ray.core.app.log.AccessFilter
+	(*LogSpecification_Rotation)(nil), // 6: v2ray.core.app.log.LogSpecification.Rotation
+	(*LogSpecification_Syslog)(nil),   // 7: v2ray.core.app.log.LogSpecification.Syslog
+	nil,                               // 8: v2ray.core.app.log.Config.InboundLevelEntry
+	nil,                               // 9: v2ray.core.app.log.Config.OutboundLevelEntry
+	(log.Severity)(0),                 // 10: v2ray.core.common.log.Severity
+	(*routercommon.Domain)(nil),       // 11: v2ray.core.app.router.routercommon.Domain
 }
 var file_app_log_config_proto_depIdxs = []int32{
-	0, // 0: v2ray.core.app.log.LogSpecification.type:type_name -> v2ray.core.app.log.LogType
-	3, // 1: v2ray.core.app.log.LogSpecification.level:type_name -> v2ray.core.common.log.Severity
-	1, // 2: v2ray.core.app.log.Config.error:type_name -> v2ray.core.app.log.LogSpecification
-	1, // 3: v2ray.core.app.log.Config.access:type_name -> v2ray.core.app.log.LogSpecification
-	4, // [4:4] is the sub-list for method output_type
-	4, // [4:4] is the sub-list for method input_type
-	4, // [4:4] is the sub-list for extension type_name
-	4, // [4:4] is the sub-list for extension extendee
-	0, // [0:4] is the sub-list for field type_name
+	0,  // 0: v2ray.core.app.log.LogSpecification.type:type_name -> v2ray.core.app.log.LogType
+	10, // 1: v2ray.core.app.log.LogSpecification.level:type_name -> v2ray.core.common.log.Severity
+	1,  // 2: v2ray.core.app.log.LogSpecification.format:type_name -> v2ray.core.app.log.LogFormat
+	2,  // 3: v2ray.core.app.log.LogSpecification.mask_ip:type_name -> v2ray.core.app.log.LogSpecification.IPMask
+	6,  // 4: v2ray.core.app.log.LogSpecification.rotation:type_name -> v2ray.core.app.log.LogSpecification.Rotation
+	7,  // 5: v2ray.core.app.log.LogSpecification.syslog:type_name -> v2ray.core.app.log.LogSpecification.Syslog
+	3,  // 6: v2ray.core.app.log.Config.error:type_name -> v2ray.core.app.log.LogSpecification
+	3,  // 7: v2ray.core.app.log.Config.access:type_name -> v2ray.core.app.log.LogSpecification
+	5,  // 8: v2ray.core.app.log.Config.access_filter:type_name -> v2ray.core.app.log.AccessFilter
+	8,  // 9: v2ray.core.app.log.Config.inbound_level:type_name -> v2ray.core.app.log.Config.InboundLevelEntry
+	9,  // 10: v2ray.core.app.log.Config.outbound_level:type_name -> v2ray.core.app.log.Config.OutboundLevelEntry
+	11, // 11: v2ray.core.app.log.AccessFilter.domain:type_name -> v2ray.core.app.router.routercommon.Domain
+	10, // 12: v2ray.core.app.log.Config.InboundLevelEntry.value:type_name -> v2ray.core.common.log.Severity
+	10, // 13: v2ray.core.app.log.Config.OutboundLevelEntry.value:type_name -> v2ray.core.common.log.Severity
+	14, // [14:14] is the sub-list for method output_type
+	14, // [14:14] is the sub-list for method input_type
+	14, // [14:14] is the sub-list for extension type_name
+	14, // [14:14] is the sub-list for extension extendee
+	0,  // [0:14] is the sub-list for field type_name
 }
 
 func init() { file_app_log_config_proto_init() }
@@ -296,14 +857,50 @@ func file_app_log_config_proto_init() {
 				return nil
 			}
 		}
+		file_app_log_config_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AccessFilter); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_app_log_config_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LogSpecification_Rotation); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_app_log_config_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LogSpecification_Syslog); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_app_log_config_proto_rawDesc,
-			NumEnums:      1,
-			NumMessages:   2,
+			NumEnums:      3,
+			NumMessages:   7,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
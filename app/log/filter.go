@@ -0,0 +1,170 @@
+package log
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math/rand"
+
+	"github.com/v2fly/v2ray-core/v5/app/router/routercommon"
+	"github.com/v2fly/v2ray-core/v5/common/log"
+	"github.com/v2fly/v2ray-core/v5/common/strmatcher"
+)
+
+var matcherTypeMap = map[routercommon.Domain_Type]strmatcher.Type{
+	routercommon.Domain_Plain:      strmatcher.Substr,
+	routercommon.Domain_Regex:      strmatcher.Regex,
+	routercommon.Domain_RootDomain: strmatcher.Domain,
+	routercommon.Domain_Full:       strmatcher.Full,
+}
+
+func toSet(values []string) map[string]bool {
+	if len(values) == 0 {
+		return nil
+	}
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
+
+// accessFilter is the compiled form of AccessFilter.
+type accessFilter struct {
+	inboundTags  map[string]bool
+	outboundTags map[string]bool
+	users        map[string]bool
+	statuses     map[string]bool
+	domains      *strmatcher.LinearIndexMatcher
+	exclude      bool
+}
+
+func newAccessFilter(config *AccessFilter) (*accessFilter, error) {
+	f := &accessFilter{
+		inboundTags:  toSet(config.InboundTag),
+		outboundTags: toSet(config.OutboundTag),
+		users:        toSet(config.User),
+		statuses:     toSet(config.Status),
+		exclude:      config.Exclude,
+	}
+	for status := range f.statuses {
+		switch log.AccessStatus(status) {
+		case log.AccessAccepted, log.AccessRejected, log.AccessClosed:
+		default:
+			return nil, newError("unknown access status: ", status)
+		}
+	}
+	if len(config.Domain) > 0 {
+		f.domains = strmatcher.NewLinearIndexMatcher()
+		for _, domain := range config.Domain {
+			matcherType, found := matcherTypeMap[domain.Type]
+			if !found {
+				return nil, newError("unsupported domain type ", domain.Type)
+			}
+			matcher, err := matcherType.New(domain.Value)
+			if err != nil {
+				return nil, newError("failed to create domain matcher").Base(err)
+			}
+			f.domains.Add(matcher)
+		}
+	}
+	return f, nil
+}
+
+func (f *accessFilter) match(msg *log.AccessMessage) bool {
+	if f.inboundTags != nil && !f.inboundTags[msg.InboundTag] {
+		return false
+	}
+	if f.outboundTags != nil && !f.outboundTags[msg.Detour] {
+		return false
+	}
+	if f.users != nil && !f.users[msg.Email] {
+		return false
+	}
+	if f.statuses != nil && !f.statuses[string(msg.Status)] {
+		return false
+	}
+	if f.domains != nil && (msg.Domain == "" || len(f.domains.Match(msg.Domain)) == 0) {
+		return false
+	}
+	return true
+}
+
+// accessSampler decides whether access messages are written, by filters and sampling.
+type accessSampler struct {
+	includes   []*accessFilter
+	excludes   []*accessFilter
+	sampleRate float32
+}
+
+func newAccessSampler(config *Config) (*accessSampler, error) {
+	s := &accessSampler{
+		sampleRate: config.AccessSampleRate,
+	}
+	if s.sampleRate < 0 || s.sampleRate > 1 {
+		return nil, newError("invalid access sample rate: ", s.sampleRate)
+	}
+	for _, c := range config.AccessFilter {
+		f, err := newAccessFilter(c)
+		if err != nil {
+			return nil, err
+		}
+		if f.exclude {
+			s.excludes = append(s.excludes, f)
+		} else {
+			s.includes = append(s.includes, f)
+		}
+	}
+	return s, nil
+}
+
+func (s *accessSampler) accept(msg *log.AccessMessage) bool {
+	for _, f := range s.excludes {
+		if f.match(msg) {
+			return false
+		}
+	}
+	if len(s.includes) > 0 {
+		matched := false
+		for _, f := range s.includes {
+			if f.match(msg) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if s.sampleRate > 0 && s.sampleRate < 1 {
+		return s.sample(msg.SessionID)
+	}
+	return true
+}
+
+// sample returns whether messages of the session are written. Messages of a session are sampled
+// together, so that a connection has both or none of its accepted and closed messages. Messages
+// without a session are sampled one by one.
+func (s *accessSampler) sample(id uint32) bool {
+	if id == 0 {
+		return rand.Float32() < s.sampleRate
+	}
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], id)
+	hash := fnv.New32a()
+	hash.Write(b[:])
+	return float64(hash.Sum32()) < float64(s.sampleRate)*(1<<32)
+}
+
+// errorLevel returns the level of error logs of the message, by the overrides of its handlers.
+func (g *Instance) errorLevel(msg *log.GeneralMessage) log.Severity {
+	level := g.config.Error.Level
+	if l, found := g.config.InboundLevel[msg.InboundTag]; found && msg.InboundTag != "" {
+		level = l
+		if l, found := g.config.OutboundLevel[msg.OutboundTag]; found && msg.OutboundTag != "" && l > level {
+			level = l
+		}
+	} else if l, found := g.config.OutboundLevel[msg.OutboundTag]; found && msg.OutboundTag != "" {
+		level = l
+	}
+	return level
+}
@@ -0,0 +1,215 @@
+package log
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/v2fly/v2ray-core/v5/common/log"
+	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/common/serial"
+)
+
+const (
+	textTimeFormat       = "2006/01/02 15:04:05 "
+	structuredTimeFormat = "2006-01-02T15:04:05.000Z07:00"
+)
+
+// fieldNames are the names of the fields in JSON and logfmt logs, in the order they are written.
+var fieldNames = []string{
+	"time", "level", "message",
+	"from", "to", "status", "reason", "email", "inbound", "outbound", "domain", "duration", "uplink", "downlink",
+}
+
+type field struct {
+	key string
+	// value is a string, an int64 or a float64.
+	value interface{}
+}
+
+type formatter struct {
+	encode func([]field) string
+	// fields is nil if all fields are written.
+	fields map[string]bool
+	maskIP LogSpecification_IPMask
+}
+
+// newFormatter returns the formatter of the log specification, or nil if messages are written as
+// they are.
+func newFormatter(spec *LogSpecification) (log.Formatter, error) {
+	f := &formatter{
+		maskIP: spec.MaskIp,
+	}
+	switch spec.Format {
+	case LogFormat_Text:
+		if f.maskIP == LogSpecification_NoMask {
+			return nil, nil
+		}
+		return f.formatText, nil
+	case LogFormat_JSON:
+		f.encode = encodeJSON
+	case LogFormat_Logfmt:
+		f.encode = encodeLogfmt
+	default:
+		return nil, newError("unknown log format: ", spec.Format)
+	}
+
+	if len(spec.Fields) > 0 {
+		f.fields = make(map[string]bool)
+		for _, name := range spec.Fields {
+			found := false
+			for _, n := range fieldNames {
+				if n == name {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return nil, newError("unknown log field: ", name)
+			}
+			f.fields[name] = true
+		}
+	}
+	return f.format, nil
+}
+
+// maskAddress masks the IP address in an address like "1.2.3.4:5678" or "tcp:1.2.3.4:5678".
+func (f *formatter) maskAddress(addr string) string {
+	var network string
+	for _, prefix := range []string{"tcp:", "udp:", "unix:"} {
+		if strings.HasPrefix(addr, prefix) {
+			network = prefix
+			addr = addr[len(prefix):]
+			break
+		}
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		host, port = addr, ""
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return network + addr
+	}
+	switch f.maskIP {
+	case LogSpecification_Subnet:
+		if ip4 := ip.To4(); ip4 != nil {
+			host = ip4.Mask(net.CIDRMask(24, 32)).String()
+		} else {
+			host = ip.Mask(net.CIDRMask(48, 128)).String()
+		}
+	case LogSpecification_Full:
+		host = "*"
+	}
+	if port == "" {
+		return network + host
+	}
+	return network + net.JoinHostPort(host, port)
+}
+
+func (f *formatter) maskAccessMessage(msg *log.AccessMessage) *log.AccessMessage {
+	if f.maskIP == LogSpecification_NoMask {
+		return msg
+	}
+	masked := *msg
+	masked.From = f.maskAddress(serial.ToString(msg.From))
+	masked.To = f.maskAddress(serial.ToString(msg.To))
+	return &masked
+}
+
+func (f *formatter) formatText(msg log.Message) string {
+	if accessMessage, ok := msg.(*log.AccessMessage); ok {
+		msg = f.maskAccessMessage(accessMessage)
+	}
+	return time.Now().Format(textTimeFormat) + msg.String()
+}
+
+func (f *formatter) format(msg log.Message) string {
+	var fields []field
+	add := func(key string, value interface{}) {
+		if s, ok := value.(string); ok && s == "" {
+			return
+		}
+		if f.fields == nil || f.fields[key] {
+			fields = append(fields, field{key: key, value: value})
+		}
+	}
+
+	add("time", time.Now().Format(structuredTimeFormat))
+	switch msg := msg.(type) {
+	case *log.AccessMessage:
+		msg = f.maskAccessMessage(msg)
+		add("from", serial.ToString(msg.From))
+		add("to", serial.ToString(msg.To))
+		add("status", string(msg.Status))
+		add("reason", serial.ToString(msg.Reason))
+		add("email", msg.Email)
+		add("inbound", msg.InboundTag)
+		add("outbound", msg.Detour)
+		add("domain", msg.Domain)
+		if msg.Status == log.AccessClosed {
+			add("duration", msg.Duration.Seconds())
+			add("uplink", msg.Uplink)
+			add("downlink", msg.Downlink)
+		}
+	case *log.GeneralMessage:
+		add("level", strings.ToLower(msg.Severity.String()))
+		add("message", serial.ToString(msg.Content))
+	default:
+		add("message", msg.String())
+	}
+	return f.encode(fields)
+}
+
+func formatNumber(value interface{}) string {
+	switch value := value.(type) {
+	case int64:
+		return strconv.FormatInt(value, 10)
+	case float64:
+		return strconv.FormatFloat(value, 'f', 3, 64)
+	default:
+		return ""
+	}
+}
+
+func encodeJSON(fields []field) string {
+	builder := strings.Builder{}
+	builder.WriteByte('{')
+	for i, f := range fields {
+		if i > 0 {
+			builder.WriteByte(',')
+		}
+		key, _ := json.Marshal(f.key)
+		builder.Write(key)
+		builder.WriteByte(':')
+		if s, ok := f.value.(string); ok {
+			value, _ := json.Marshal(s)
+			builder.Write(value)
+		} else {
+			builder.WriteString(formatNumber(f.value))
+		}
+	}
+	builder.WriteByte('}')
+	return builder.String()
+}
+
+func encodeLogfmt(fields []field) string {
+	builder := strings.Builder{}
+	for i, f := range fields {
+		if i > 0 {
+			builder.WriteByte(' ')
+		}
+		builder.WriteString(f.key)
+		builder.WriteByte('=')
+		if s, ok := f.value.(string); ok {
+			if strings.ContainsAny(s, " =\"\\") || strconv.Quote(s) != "\""+s+"\"" {
+				s = strconv.Quote(s)
+			}
+			builder.WriteString(s)
+		} else {
+			builder.WriteString(formatNumber(f.value))
+		}
+	}
+	return builder.String()
+}
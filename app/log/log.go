@@ -19,6 +19,8 @@ type Instance struct {
 	errorLogger  log.Handler
 	followers    map[reflect.Value]func(msg log.Message)
 	active       bool
+
+	accessSampler *accessSampler
 }
 
 // New creates a new log.Instance based on the given config.
@@ -31,9 +33,15 @@ func New(ctx context.Context, config *Config) (*Instance, error) {
 		config.Access = &LogSpecification{Type: LogType_None}
 	}
 
+	sampler, err := newAccessSampler(config)
+	if err != nil {
+		return nil, newError("invalid access log filter").Base(err)
+	}
+
 	g := &Instance{
-		config: config,
-		active: false,
+		config:        config,
+		active:        false,
+		accessSampler: sampler,
 	}
 	log.RegisterHandler(g)
 
@@ -48,8 +56,15 @@ func New(ctx context.Context, config *Config) (*Instance, error) {
 }
 
 func (g *Instance) initAccessLogger() error {
+	formatter, err := newFormatter(g.config.Access)
+	if err != nil {
+		return err
+	}
 	handler, err := createHandler(g.config.Access.Type, HandlerCreatorOptions{
-		Path: g.config.Access.Path,
+		Path:      g.config.Access.Path,
+		Formatter: formatter,
+		Rotation:  g.config.Access.Rotation,
+		Syslog:    g.config.Access.Syslog,
 	})
 	if err != nil {
 		return err
@@ -59,8 +74,15 @@ func (g *Instance) initAccessLogger() error {
 }
 
 func (g *Instance) initErrorLogger() error {
+	formatter, err := newFormatter(g.config.Error)
+	if err != nil {
+		return err
+	}
 	handler, err := createHandler(g.config.Error.Type, HandlerCreatorOptions{
-		Path: g.config.Error.Path,
+		Path:      g.config.Error.Path,
+		Formatter: formatter,
+		Rotation:  g.config.Error.Rotation,
+		Syslog:    g.config.Error.Syslog,
 	})
 	if err != nil {
 		return err
@@ -99,6 +121,38 @@ func (g *Instance) Start() error {
 	return g.startInternal()
 }
 
+// Reopen recreates the log handlers of the started logger, which reopens log files and
+// reconnects to syslog servers, such as after log files are rotated externally.
+func (g *Instance) Reopen() error {
+	g.Lock()
+	defer g.Unlock()
+
+	if !g.active {
+		return newError("logger is not started")
+	}
+
+	accessLogger, errorLogger := g.accessLogger, g.errorLogger
+	if err := g.initAccessLogger(); err != nil {
+		return newError("failed to initialize access logger").Base(err).AtWarning()
+	}
+	if err := g.initErrorLogger(); err != nil {
+		common.Close(g.accessLogger)
+		g.accessLogger = accessLogger
+		return newError("failed to initialize error logger").Base(err).AtWarning()
+	}
+	common.Close(accessLogger)
+	common.Close(errorLogger)
+	return nil
+}
+
+// HandlesAccessClose implements log.AccessCloseHandler.
+func (g *Instance) HandlesAccessClose() bool {
+	g.RLock()
+	defer g.RUnlock()
+
+	return g.active && g.accessLogger != nil && g.config.Access.RecordClose
+}
+
 // AddFollower implements log.Follower.
 func (g *Instance) AddFollower(f func(msg log.Message)) {
 	g.Lock()
@@ -131,11 +185,11 @@ func (g *Instance) Handle(msg log.Message) {
 
 	switch msg := msg.(type) {
 	case *log.AccessMessage:
-		if g.accessLogger != nil {
+		if g.accessLogger != nil && g.accessSampler.accept(msg) {
 			g.accessLogger.Handle(msg)
 		}
 	case *log.GeneralMessage:
-		if g.errorLogger != nil && msg.Severity <= g.config.Error.Level {
+		if g.errorLogger != nil && msg.Severity <= g.errorLevel(msg) {
 			g.errorLogger.Handle(msg)
 		}
 	default:
@@ -1,12 +1,18 @@
 package log
 
 import (
+	"time"
+
 	"github.com/v2fly/v2ray-core/v5/common"
 	"github.com/v2fly/v2ray-core/v5/common/log"
 )
 
 type HandlerCreatorOptions struct {
 	Path string
+	// Formatter formats messages if not nil, and lines written are not prefixed with timestamps.
+	Formatter log.Formatter
+	Rotation  *LogSpecification_Rotation
+	Syslog    *LogSpecification_Syslog
 }
 
 type HandlerCreator func(LogType, HandlerCreatorOptions) (log.Handler, error)
@@ -30,12 +36,47 @@ func createHandler(logType LogType, options HandlerCreatorOptions) (log.Handler,
 	return creator(logType, options)
 }
 
+// newPlainLogger creates a logger for sinks which record the time of messages themselves.
+func newPlainLogger(creator log.WriterCreator, formatter log.Formatter) log.Handler {
+	if formatter == nil {
+		formatter = log.Message.String
+	}
+	return log.NewFormattedLogger(creator, formatter)
+}
+
 func init() {
 	common.Must(RegisterHandlerCreator(LogType_Console, func(lt LogType, options HandlerCreatorOptions) (log.Handler, error) {
+		if options.Formatter != nil {
+			return log.NewFormattedLogger(log.CreatePlainStdoutLogWriter(), options.Formatter), nil
+		}
 		return log.NewLogger(log.CreateStdoutLogWriter()), nil
 	}))
 
 	common.Must(RegisterHandlerCreator(LogType_File, func(lt LogType, options HandlerCreatorOptions) (log.Handler, error) {
+		if r := options.Rotation; r != nil {
+			creator, err := log.CreateRotatingFileLogWriter(options.Path, log.RotationConfig{
+				MaxSize:    int64(r.MaxSize),
+				Interval:   time.Duration(r.Interval) * time.Second,
+				MaxBackups: int(r.MaxBackups),
+				MaxAge:     time.Duration(r.MaxAge) * time.Second,
+				Compress:   r.Compress,
+			})
+			if err != nil {
+				return nil, err
+			}
+			f := options.Formatter
+			if f == nil {
+				f = (&formatter{}).formatText
+			}
+			return log.NewFormattedLogger(creator, f), nil
+		}
+		if options.Formatter != nil {
+			creator, err := log.CreatePlainFileLogWriter(options.Path)
+			if err != nil {
+				return nil, err
+			}
+			return log.NewFormattedLogger(creator, options.Formatter), nil
+		}
 		creator, err := log.CreateFileLogWriter(options.Path)
 		if err != nil {
 			return nil, err
@@ -43,6 +84,35 @@ func init() {
 		return log.NewLogger(creator), nil
 	}))
 
+	common.Must(RegisterHandlerCreator(LogType_Syslog, func(lt LogType, options HandlerCreatorOptions) (log.Handler, error) {
+		config := log.SyslogConfig{
+			Facility: 3,
+		}
+		if s := options.Syslog; s != nil {
+			config.Network = s.Network
+			config.Address = s.Address
+			config.Tag = s.Tag
+			config.Facility = int(s.Facility)
+		}
+		creator, err := log.CreateSyslogLogWriter(config)
+		if err != nil {
+			return nil, err
+		}
+		return newPlainLogger(creator, options.Formatter), nil
+	}))
+
+	common.Must(RegisterHandlerCreator(LogType_Journald, func(lt LogType, options HandlerCreatorOptions) (log.Handler, error) {
+		var identifier string
+		if options.Syslog != nil {
+			identifier = options.Syslog.Tag
+		}
+		creator, err := log.CreateJournaldLogWriter(identifier)
+		if err != nil {
+			return nil, err
+		}
+		return newPlainLogger(creator, options.Formatter), nil
+	}))
+
 	common.Must(RegisterHandlerCreator(LogType_None, func(lt LogType, options HandlerCreatorOptions) (log.Handler, error) {
 		return nil, nil
 	}))
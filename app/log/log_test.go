@@ -2,13 +2,20 @@ package log_test
 
 import (
 	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 
 	"github.com/v2fly/v2ray-core/v5/app/log"
+	"github.com/v2fly/v2ray-core/v5/app/router/routercommon"
 	"github.com/v2fly/v2ray-core/v5/common"
 	clog "github.com/v2fly/v2ray-core/v5/common/log"
+	"github.com/v2fly/v2ray-core/v5/common/net"
 	"github.com/v2fly/v2ray-core/v5/testing/mocks"
 )
 
@@ -50,3 +57,227 @@ func TestCustomLogHandler(t *testing.T) {
 
 	common.Must(logger.Close())
 }
+
+func TestStructuredLog(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	defer mockCtl.Finish()
+
+	formatters := make(map[string]clog.Formatter)
+	log.RegisterHandlerCreator(log.LogType_Event, func(lt log.LogType, options log.HandlerCreatorOptions) (clog.Handler, error) {
+		formatters[options.Path] = options.Formatter
+		mockHandler := mocks.NewLogHandler(mockCtl)
+		mockHandler.EXPECT().Handle(gomock.Any()).AnyTimes()
+		return mockHandler, nil
+	})
+
+	logger, err := log.New(context.Background(), &log.Config{
+		Error: &log.LogSpecification{
+			Type:   log.LogType_Event,
+			Level:  clog.Severity_Debug,
+			Path:   "error",
+			Format: log.LogFormat_Logfmt,
+			Fields: []string{"level", "message"},
+		},
+		Access: &log.LogSpecification{
+			Type:        log.LogType_Event,
+			Path:        "access",
+			Format:      log.LogFormat_JSON,
+			Fields:      []string{"from", "to", "status", "email", "inbound", "domain", "duration", "uplink"},
+			MaskIp:      log.LogSpecification_Subnet,
+			RecordClose: true,
+		},
+	})
+	common.Must(err)
+	defer logger.Close()
+
+	if !clog.ShouldRecordAccessClose() {
+		t.Error("expected access logs of closed connections to be recorded")
+	}
+
+	if line := formatters["error"](&clog.GeneralMessage{
+		Severity: clog.Severity_Warning,
+		Content:  "connection refused",
+	}); line != `level=warning message="connection refused"` {
+		t.Error("unexpected error log: ", line)
+	}
+
+	line := formatters["access"](&clog.AccessMessage{
+		From:       net.TCPDestination(net.ParseAddress("192.168.1.100"), 5678),
+		To:         net.TCPDestination(net.ParseAddress("2001:db8:1:2::1"), 443),
+		Status:     clog.AccessClosed,
+		Email:      "love@v2fly.org",
+		InboundTag: "in",
+		Duration:   1500 * time.Millisecond,
+		Uplink:     1024,
+	})
+	if expected := `{"from":"tcp:192.168.1.0:5678","to":"tcp:[2001:db8:1::]:443","status":"closed","email":"love@v2fly.org","inbound":"in","duration":1.500,"uplink":1024}`; line != expected {
+		t.Error("unexpected access log: ", line)
+	}
+}
+
+func TestInvalidLogField(t *testing.T) {
+	_, err := log.New(context.Background(), &log.Config{
+		Error: &log.LogSpecification{
+			Type:   log.LogType_None,
+			Format: log.LogFormat_JSON,
+			Fields: []string{"unknown"},
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "unknown log field") {
+		t.Error("expected error of unknown field, but got ", err)
+	}
+}
+
+func TestLoggerReopen(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vtest")
+	common.Must(err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "error.log")
+
+	logger, err := log.New(context.Background(), &log.Config{
+		Error:  &log.LogSpecification{Type: log.LogType_File, Level: clog.Severity_Debug, Path: path},
+		Access: &log.LogSpecification{Type: log.LogType_None},
+	})
+	common.Must(err)
+	defer logger.Close()
+
+	clog.Record(&clog.GeneralMessage{Severity: clog.Severity_Info, Content: "before rotation"})
+	time.Sleep(500 * time.Millisecond)
+	common.Must(os.Rename(path, path+".1"))
+
+	common.Must(logger.Reopen())
+	clog.Record(&clog.GeneralMessage{Severity: clog.Severity_Info, Content: "after rotation"})
+	time.Sleep(500 * time.Millisecond)
+
+	b, err := ioutil.ReadFile(path)
+	common.Must(err)
+	if !strings.Contains(string(b), "after rotation") || strings.Contains(string(b), "before rotation") {
+		t.Error("unexpected log after reopening: ", string(b))
+	}
+}
+
+func TestAccessFilter(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	defer mockCtl.Finish()
+
+	var accessLogs, errorLogs []clog.Message
+	log.RegisterHandlerCreator(log.LogType_Event, func(lt log.LogType, options log.HandlerCreatorOptions) (clog.Handler, error) {
+		mockHandler := mocks.NewLogHandler(mockCtl)
+		mockHandler.EXPECT().Handle(gomock.Any()).AnyTimes().DoAndReturn(func(msg clog.Message) {
+			if options.Path == "access" {
+				accessLogs = append(accessLogs, msg)
+			} else {
+				errorLogs = append(errorLogs, msg)
+			}
+		})
+		return mockHandler, nil
+	})
+
+	logger, err := log.New(context.Background(), &log.Config{
+		Error:  &log.LogSpecification{Type: log.LogType_Event, Level: clog.Severity_Warning, Path: "error"},
+		Access: &log.LogSpecification{Type: log.LogType_Event, Path: "access"},
+		AccessFilter: []*log.AccessFilter{
+			{
+				InboundTag: []string{"in"},
+			},
+			{
+				Domain: []*routercommon.Domain{{Type: routercommon.Domain_RootDomain, Value: "v2fly.org"}},
+			},
+			{
+				Status:  []string{"rejected"},
+				User:    []string{"love@v2fly.org"},
+				Exclude: true,
+			},
+		},
+		InboundLevel: map[string]clog.Severity{
+			"in": clog.Severity_Debug,
+		},
+		OutboundLevel: map[string]clog.Severity{
+			"direct": clog.Severity_Error,
+		},
+	})
+	common.Must(err)
+	defer logger.Close()
+
+	for _, msg := range []*clog.AccessMessage{
+		{Status: clog.AccessAccepted, InboundTag: "in"},
+		{Status: clog.AccessAccepted, InboundTag: "other"},
+		{Status: clog.AccessAccepted, InboundTag: "other", Domain: "www.v2fly.org"},
+		{Status: clog.AccessRejected, InboundTag: "in", Email: "love@v2fly.org"},
+		{Status: clog.AccessRejected, InboundTag: "in"},
+	} {
+		clog.Record(msg)
+	}
+	if len(accessLogs) != 3 {
+		t.Error("expected 3 access logs, but got ", accessLogs)
+	}
+
+	for _, msg := range []*clog.GeneralMessage{
+		{Severity: clog.Severity_Debug, Content: "written", InboundTag: "in"},
+		{Severity: clog.Severity_Debug, Content: "dropped", InboundTag: "other"},
+		{Severity: clog.Severity_Warning, Content: "dropped", OutboundTag: "direct"},
+		{Severity: clog.Severity_Debug, Content: "written", InboundTag: "in", OutboundTag: "direct"},
+		{Severity: clog.Severity_Warning, Content: "written"},
+	} {
+		clog.Record(msg)
+	}
+	for _, msg := range errorLogs {
+		if !strings.Contains(msg.String(), "written") {
+			t.Error("unexpected error log: ", msg)
+		}
+	}
+	if len(errorLogs) != 3 {
+		t.Error("expected 3 error logs, but got ", errorLogs)
+	}
+}
+
+func TestInvalidAccessFilter(t *testing.T) {
+	_, err := log.New(context.Background(), &log.Config{
+		AccessFilter: []*log.AccessFilter{
+			{
+				Status: []string{"unknown"},
+			},
+		},
+	})
+	if err == nil {
+		t.Error("expected error of unknown status")
+	}
+}
+
+func TestAccessSampleRate(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	defer mockCtl.Finish()
+
+	written := make(map[uint32]int)
+	log.RegisterHandlerCreator(log.LogType_Event, func(lt log.LogType, options log.HandlerCreatorOptions) (clog.Handler, error) {
+		mockHandler := mocks.NewLogHandler(mockCtl)
+		mockHandler.EXPECT().Handle(gomock.Any()).AnyTimes().DoAndReturn(func(msg clog.Message) {
+			if msg, ok := msg.(*clog.AccessMessage); ok {
+				written[msg.SessionID]++
+			}
+		})
+		return mockHandler, nil
+	})
+
+	logger, err := log.New(context.Background(), &log.Config{
+		Error:            &log.LogSpecification{Type: log.LogType_None},
+		Access:           &log.LogSpecification{Type: log.LogType_Event},
+		AccessSampleRate: 0.5,
+	})
+	common.Must(err)
+	defer logger.Close()
+
+	for id := uint32(1); id <= 200; id++ {
+		clog.Record(&clog.AccessMessage{Status: clog.AccessAccepted, SessionID: id})
+		clog.Record(&clog.AccessMessage{Status: clog.AccessClosed, SessionID: id})
+	}
+	// Messages of a connection are written or dropped together.
+	for id, count := range written {
+		if count != 2 {
+			t.Error("expected 2 messages of session ", id, ", but got ", count)
+		}
+	}
+	if len(written) == 0 || len(written) == 200 {
+		t.Error("unexpected number of sampled sessions: ", len(written))
+	}
+}
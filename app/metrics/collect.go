@@ -0,0 +1,117 @@
+package metrics
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/v2fly/v2ray-core/v5/app/observatory"
+	"github.com/v2fly/v2ray-core/v5/app/stats"
+	"github.com/v2fly/v2ray-core/v5/features/extension"
+	"github.com/v2fly/v2ray-core/v5/features/routing"
+	feature_stats "github.com/v2fly/v2ray-core/v5/features/stats"
+)
+
+// collectStats converts counters of the stats manager into labeled series. Names of counters are
+// parts separated by ">>>", like "inbound>>>tag>>>traffic>>>uplink".
+func collectStats(r *registry, manager feature_stats.Manager) {
+	m, ok := manager.(*stats.Manager)
+	if !ok {
+		return
+	}
+	m.VisitCounters(func(name string, c feature_stats.Counter) bool {
+		value := float64(c.Value())
+		parts := strings.Split(name, ">>>")
+		switch {
+		case len(parts) == 4 && parts[0] == "inbound" && parts[2] == "traffic":
+			r.add("v2ray_inbound_traffic_bytes", typeCounter, "Traffic of inbounds in bytes.", value,
+				"tag", parts[1], "direction", parts[3])
+		case len(parts) == 4 && parts[0] == "outbound" && parts[2] == "traffic":
+			r.add("v2ray_outbound_traffic_bytes", typeCounter, "Traffic of outbounds in bytes.", value,
+				"tag", parts[1], "direction", parts[3])
+		case len(parts) == 4 && parts[0] == "user" && parts[2] == "traffic":
+			r.add("v2ray_user_traffic_bytes", typeCounter, "Traffic of users in bytes.", value,
+				"user", parts[1], "direction", parts[3])
+		case len(parts) == 6 && parts[0] == "portal" && parts[2] == "bridge" && parts[4] == "traffic":
+			r.add("v2ray_portal_traffic_bytes", typeCounter, "Traffic of bridges of portals in bytes.", value,
+				"tag", parts[1], "bridge", parts[3], "direction", parts[5])
+		default:
+			r.add("v2ray_stats_counter", typeGauge, "Value of other counters of the stats manager.", value,
+				"name", name)
+		}
+		return true
+	})
+}
+
+// collectObservatory adds the status of outbounds probed by the observatory.
+func collectObservatory(ctx context.Context, r *registry, o extension.Observatory) {
+	message, err := o.GetObservation(ctx)
+	if err != nil {
+		newError("failed to get observation").Base(err).AtDebug().WriteToLog()
+		return
+	}
+	result, ok := message.(*observatory.ObservationResult)
+	if !ok {
+		return
+	}
+	for _, status := range result.Status {
+		alive := 0.0
+		if status.Alive {
+			alive = 1
+		}
+		r.add("v2ray_observatory_alive", typeGauge, "Whether outbounds are alive.", alive,
+			"outbound", status.OutboundTag)
+		r.add("v2ray_observatory_delay_seconds", typeGauge, "Delay of probes of outbounds in seconds.",
+			float64(status.Delay)/1000, "outbound", status.OutboundTag)
+		if status.LastSeenTime != 0 {
+			r.add("v2ray_observatory_last_seen_timestamp_seconds", typeGauge, "Last time outbounds are known to be alive.",
+				float64(status.LastSeenTime), "outbound", status.OutboundTag)
+		}
+		if status.LastTryTime != 0 {
+			r.add("v2ray_observatory_last_try_timestamp_seconds", typeGauge, "Last time outbounds are probed.",
+				float64(status.LastTryTime), "outbound", status.OutboundTag)
+		}
+	}
+}
+
+// collectBalancers adds the outbounds selected by the balancers of the router.
+func collectBalancers(r *registry, router routing.Router) {
+	lister, ok := router.(routing.BalancerLister)
+	if !ok {
+		return
+	}
+	for _, tag := range lister.ListBalancers() {
+		if principle, ok := router.(routing.BalancerPrincipleTarget); ok {
+			if targets, err := principle.GetPrincipleTarget(tag); err == nil {
+				for _, target := range targets {
+					r.add("v2ray_balancer_principle_target", typeGauge, "Outbounds selected by the strategy of balancers.", 1,
+						"balancer", tag, "outbound", target)
+				}
+			}
+		}
+		if overrider, ok := router.(routing.BalancerOverrider); ok {
+			if target, err := overrider.GetOverrideTarget(tag); err == nil && target != "" {
+				r.add("v2ray_balancer_override_target", typeGauge, "Outbounds overriding the selection of balancers.", 1,
+					"balancer", tag, "outbound", target)
+			}
+		}
+	}
+}
+
+// collectRuntime adds the numbers of SysStatsResponse of the stats service.
+func collectRuntime(r *registry, startTime time.Time) {
+	var rtm runtime.MemStats
+	runtime.ReadMemStats(&rtm)
+
+	r.add("v2ray_uptime_seconds", typeGauge, "Time since the start of V2Ray in seconds.", time.Since(startTime).Seconds())
+	r.add("v2ray_goroutines", typeGauge, "Number of goroutines.", float64(runtime.NumGoroutine()))
+	r.add("v2ray_memory_alloc_bytes", typeGauge, "Bytes of allocated heap objects.", float64(rtm.Alloc))
+	r.add("v2ray_memory_allocated_bytes", typeCounter, "Cumulative bytes allocated for heap objects.", float64(rtm.TotalAlloc))
+	r.add("v2ray_memory_sys_bytes", typeGauge, "Bytes of memory obtained from the OS.", float64(rtm.Sys))
+	r.add("v2ray_memory_mallocs", typeCounter, "Cumulative count of heap objects allocated.", float64(rtm.Mallocs))
+	r.add("v2ray_memory_frees", typeCounter, "Cumulative count of heap objects freed.", float64(rtm.Frees))
+	r.add("v2ray_memory_live_objects", typeGauge, "Number of live heap objects.", float64(rtm.Mallocs-rtm.Frees))
+	r.add("v2ray_gc", typeCounter, "Number of completed GC cycles.", float64(rtm.NumGC))
+	r.add("v2ray_gc_pause_seconds", typeCounter, "Cumulative time of GC pauses in seconds.", float64(rtm.PauseTotalNs)/1e9)
+}
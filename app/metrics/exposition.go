@@ -0,0 +1,143 @@
+package metrics
+
+import (
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	typeCounter = "counter"
+	typeGauge   = "gauge"
+	typeInfo    = "info"
+)
+
+type sample struct {
+	labels []string
+	value  float64
+}
+
+// family is a metric family. Names of counters and infos do not include their suffixes, which
+// are added to their samples.
+type family struct {
+	name    string
+	help    string
+	typ     string
+	samples []sample
+}
+
+func (f *family) sampleName() string {
+	switch f.typ {
+	case typeCounter:
+		return f.name + "_total"
+	case typeInfo:
+		return f.name + "_info"
+	default:
+		return f.name
+	}
+}
+
+// registry collects the samples of one scrape.
+type registry struct {
+	families map[string]*family
+}
+
+func newRegistry() *registry {
+	return &registry{
+		families: make(map[string]*family),
+	}
+}
+
+// add adds a sample to a family. labels are pairs of label names and values.
+func (r *registry) add(name string, typ string, help string, value float64, labels ...string) {
+	f, found := r.families[name]
+	if !found {
+		f = &family{
+			name: name,
+			help: help,
+			typ:  typ,
+		}
+		r.families[name] = f
+	}
+	f.samples = append(f.samples, sample{
+		labels: labels,
+		value:  value,
+	})
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatLabels(labels []string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteByte('{')
+	for i := 0; i+1 < len(labels); i += 2 {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(labels[i])
+		sb.WriteString(`="`)
+		sb.WriteString(labelValueReplacer.Replace(labels[i+1]))
+		sb.WriteByte('"')
+	}
+	sb.WriteByte('}')
+	return sb.String()
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+}
+
+// write writes all families sorted by name in the Prometheus text format, or the OpenMetrics text
+// format if openMetrics is true.
+func (r *registry) write(w io.Writer, openMetrics bool) error {
+	names := make([]string, 0, len(r.families))
+	for name := range r.families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	for _, name := range names {
+		f := r.families[name]
+		typ := f.typ
+		typeName := f.name
+		if !openMetrics {
+			// The Prometheus text format has neither infos nor names without suffixes.
+			if typ == typeInfo {
+				typ = typeGauge
+			}
+			typeName = f.sampleName()
+		}
+		sb.WriteString("# HELP " + typeName + " " + f.help + "\n")
+		sb.WriteString("# TYPE " + typeName + " " + typ + "\n")
+
+		lines := make([]string, 0, len(f.samples))
+		for _, s := range f.samples {
+			lines = append(lines, f.sampleName()+formatLabels(s.labels)+" "+formatValue(s.value)+"\n")
+		}
+		sort.Strings(lines)
+		for _, line := range lines {
+			sb.WriteString(line)
+		}
+	}
+	if openMetrics {
+		sb.WriteString("# EOF\n")
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
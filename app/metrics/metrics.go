@@ -0,0 +1,158 @@
+package metrics
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	core "github.com/v2fly/v2ray-core/v5"
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/features/extension"
+	"github.com/v2fly/v2ray-core/v5/features/routing"
+	feature_stats "github.com/v2fly/v2ray-core/v5/features/stats"
+	"github.com/v2fly/v2ray-core/v5/transport/internet"
+)
+
+//go:generate go run github.com/v2fly/v2ray-core/v5/common/errors/errorgen
+
+// Service serves metrics of stats, the observatory, balancers and the runtime over HTTP.
+type Service struct {
+	access    sync.Mutex
+	ctx       context.Context
+	config    *Config
+	server    *http.Server
+	startTime time.Time
+
+	stats feature_stats.Manager
+}
+
+// Type implements common.HasType.
+func (s *Service) Type() interface{} {
+	return (*struct{})(nil)
+}
+
+func (s *Service) path() string {
+	if s.config.Path == "" {
+		return "/metrics"
+	}
+	return s.config.Path
+}
+
+func (s *Service) authorized(request *http.Request) bool {
+	if s.config.AuthToken == "" {
+		return true
+	}
+	text := strings.SplitN(request.Header.Get("Authorization"), " ", 2)
+	return len(text) == 2 && text[0] == "Bearer" &&
+		subtle.ConstantTimeCompare([]byte(text[1]), []byte(s.config.AuthToken)) == 1
+}
+
+func (s *Service) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	if request.URL.Path != s.path() {
+		http.NotFound(writer, request)
+		return
+	}
+	if !s.authorized(request) {
+		writer.Header().Set("WWW-Authenticate", "Bearer")
+		writer.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	r := newRegistry()
+	s.collect(request.Context(), r)
+
+	openMetrics := strings.Contains(request.Header.Get("Accept"), "application/openmetrics-text")
+	if openMetrics {
+		writer.Header().Set("Content-Type", "application/openmetrics-text; version=1.0.0; charset=utf-8")
+	} else {
+		writer.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	}
+	if err := r.write(writer, openMetrics); err != nil {
+		newError("failed to write metrics").Base(err).AtDebug().WriteToLog()
+	}
+}
+
+func (s *Service) collect(ctx context.Context, r *registry) {
+	r.add("v2ray_build", typeInfo, "Version of V2Ray.", 1, "version", core.Version())
+	collectRuntime(r, s.startTime)
+	collectStats(r, s.stats)
+
+	instance := core.FromContext(s.ctx)
+	if instance == nil {
+		return
+	}
+	if o, ok := instance.GetFeature(extension.ObservatoryType()).(extension.Observatory); ok {
+		collectObservatory(ctx, r, o)
+	}
+	if router, ok := instance.GetFeature(routing.RouterType()).(routing.Router); ok {
+		collectBalancers(r, router)
+	}
+}
+
+// Start implements common.Runnable.
+func (s *Service) Start() error {
+	s.access.Lock()
+	defer s.access.Unlock()
+
+	var listener net.Listener
+	var err error
+	address := net.ParseAddress(s.config.ListenAddr)
+	switch {
+	case address.Family().IsIP():
+		listener, err = internet.ListenSystem(s.ctx, &net.TCPAddr{IP: address.IP(), Port: int(s.config.ListenPort)}, nil)
+	case strings.EqualFold(address.Domain(), "localhost"):
+		listener, err = internet.ListenSystem(s.ctx, &net.TCPAddr{IP: net.IP{127, 0, 0, 1}, Port: int(s.config.ListenPort)}, nil)
+	default:
+		return newError("metrics cannot listen on the address: ", address)
+	}
+	if err != nil {
+		return newError("metrics cannot listen on the port ", s.config.ListenPort).Base(err)
+	}
+
+	s.server = &http.Server{
+		Handler:           s,
+		ReadHeaderTimeout: time.Second * 4,
+	}
+	go func() {
+		if err := s.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			newError("failed to serve metrics").Base(err).AtWarning().WriteToLog()
+		}
+	}()
+	return nil
+}
+
+// Close implements common.Closable.
+func (s *Service) Close() error {
+	s.access.Lock()
+	defer s.access.Unlock()
+
+	if s.server != nil {
+		return s.server.Close()
+	}
+	return nil
+}
+
+// NewService creates a new metrics service.
+func NewService(ctx context.Context, config *Config) (*Service, error) {
+	s := &Service{
+		ctx:       ctx,
+		config:    config,
+		startTime: time.Now(),
+	}
+	if err := core.RequireFeatures(ctx, func(stats feature_stats.Manager) {
+		s.stats = stats
+	}); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func init() {
+	common.Must(common.RegisterConfig((*Config)(nil), func(ctx context.Context, config interface{}) (interface{}, error) {
+		return NewService(ctx, config.(*Config))
+	}))
+}
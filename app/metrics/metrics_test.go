@@ -0,0 +1,98 @@
+package metrics_test
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/types/known/anypb"
+
+	core "github.com/v2fly/v2ray-core/v5"
+	. "github.com/v2fly/v2ray-core/v5/app/metrics"
+	"github.com/v2fly/v2ray-core/v5/app/stats"
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/serial"
+	feature_stats "github.com/v2fly/v2ray-core/v5/features/stats"
+	"github.com/v2fly/v2ray-core/v5/testing/servers/tcp"
+)
+
+func scrape(t *testing.T, url string, token string, accept string) (int, string) {
+	request, err := http.NewRequest(http.MethodGet, url, nil)
+	common.Must(err)
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+	if accept != "" {
+		request.Header.Set("Accept", accept)
+	}
+	response, err := http.DefaultClient.Do(request)
+	common.Must(err)
+	defer response.Body.Close()
+	body, err := io.ReadAll(response.Body)
+	common.Must(err)
+	return response.StatusCode, string(body)
+}
+
+func TestMetrics(t *testing.T) {
+	port := tcp.PickPort()
+	server, err := core.New(&core.Config{
+		App: []*anypb.Any{
+			serial.ToTypedMessage(&stats.Config{}),
+			serial.ToTypedMessage(&Config{
+				ListenAddr: "127.0.0.1",
+				ListenPort: int32(port),
+				AuthToken:  "token",
+			}),
+		},
+	})
+	common.Must(err)
+	common.Must(server.Start())
+	defer server.Close()
+
+	manager := server.GetFeature(feature_stats.ManagerType()).(feature_stats.Manager)
+	for name, value := range map[string]int64{
+		"inbound>>>socks>>>traffic>>>uplink":       1,
+		"outbound>>>direct>>>traffic>>>downlink":   2,
+		"user>>>love@v2fly.org>>>traffic>>>uplink": 3,
+		"mkcp>>>conn>>>rtt":                        4,
+	} {
+		c, err := manager.RegisterCounter(name)
+		common.Must(err)
+		c.Set(value)
+	}
+
+	url := "http://127.0.0.1:" + port.String() + "/metrics"
+	if status, _ := scrape(t, url, "", ""); status != http.StatusUnauthorized {
+		t.Error("expected unauthorized, got ", status)
+	}
+
+	status, body := scrape(t, url, "token", "")
+	if status != http.StatusOK {
+		t.Fatal("unexpected status ", status)
+	}
+	for _, line := range []string{
+		"# TYPE v2ray_inbound_traffic_bytes_total counter",
+		`v2ray_inbound_traffic_bytes_total{tag="socks",direction="uplink"} 1`,
+		`v2ray_outbound_traffic_bytes_total{tag="direct",direction="downlink"} 2`,
+		`v2ray_user_traffic_bytes_total{user="love@v2fly.org",direction="uplink"} 3`,
+		`v2ray_stats_counter{name="mkcp>>>conn>>>rtt"} 4`,
+		"# TYPE v2ray_goroutines gauge",
+		"# TYPE v2ray_build_info gauge",
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Error("missing line: ", line)
+		}
+	}
+
+	_, body = scrape(t, url, "token", "application/openmetrics-text; version=1.0.0")
+	for _, line := range []string{
+		"# TYPE v2ray_inbound_traffic_bytes counter",
+		"# TYPE v2ray_build info",
+		"# EOF",
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Error("missing line: ", line)
+		}
+	}
+}
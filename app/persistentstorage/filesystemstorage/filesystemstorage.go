@@ -0,0 +1,117 @@
+package filesystemstorage
+
+//go:generate go run github.com/v2fly/v2ray-core/v5/common/errors/errorgen
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/features/extension"
+)
+
+// Storage is an implementation of extension.PersistentStorageEngine. Each value is kept in a file
+// named by the hex encoded key.
+type Storage struct {
+	access    sync.Mutex
+	directory string
+}
+
+// NewStorage creates a new Storage in the directory of the config.
+func NewStorage(ctx context.Context, config *Config) (*Storage, error) {
+	if config.Directory == "" {
+		return nil, newError("storage directory is not specified")
+	}
+	if err := os.MkdirAll(config.Directory, 0o700); err != nil {
+		return nil, newError("failed to create storage directory ", config.Directory).Base(err)
+	}
+	return &Storage{
+		directory: config.Directory,
+	}, nil
+}
+
+// Type implements common.HasType.
+func (*Storage) Type() interface{} {
+	return extension.PersistentStorageEngineType()
+}
+
+// PersistentStorageEngine implements extension.PersistentStorageEngine.
+func (*Storage) PersistentStorageEngine() {}
+
+func (s *Storage) path(key []byte) string {
+	return filepath.Join(s.directory, hex.EncodeToString(key))
+}
+
+// Put implements extension.PersistentStorageEngine. The value is written to a temporary file
+// first, so that an interrupted write never leaves a partial value.
+func (s *Storage) Put(ctx context.Context, key []byte, value []byte) error {
+	s.access.Lock()
+	defer s.access.Unlock()
+
+	path := s.path(key)
+	if err := os.WriteFile(path+".tmp", value, 0o600); err != nil {
+		return newError("failed to write ", path).Base(err)
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return newError("failed to write ", path).Base(err)
+	}
+	return nil
+}
+
+// Get implements extension.PersistentStorageEngine.
+func (s *Storage) Get(ctx context.Context, key []byte) ([]byte, error) {
+	s.access.Lock()
+	defer s.access.Unlock()
+
+	value, err := os.ReadFile(s.path(key))
+	if err != nil {
+		return nil, newError("failed to read key ", string(key)).Base(err)
+	}
+	return value, nil
+}
+
+// List implements extension.PersistentStorageEngine. Keys are sorted.
+func (s *Storage) List(ctx context.Context, keyPrefix []byte) ([][]byte, error) {
+	s.access.Lock()
+	defer s.access.Unlock()
+
+	entries, err := os.ReadDir(s.directory)
+	if err != nil {
+		return nil, newError("failed to list ", s.directory).Base(err)
+	}
+	var keys [][]byte
+	for _, entry := range entries {
+		key, err := hex.DecodeString(entry.Name())
+		if err != nil || entry.IsDir() {
+			continue
+		}
+		if bytes.HasPrefix(key, keyPrefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return bytes.Compare(keys[i], keys[j]) < 0
+	})
+	return keys, nil
+}
+
+// Start implements common.Runnable.
+func (*Storage) Start() error {
+	return nil
+}
+
+// Close implements common.Closable.
+func (*Storage) Close() error {
+	return nil
+}
+
+func init() {
+	common.Must(common.RegisterConfig((*Config)(nil), func(ctx context.Context, config interface{}) (interface{}, error) {
+		return NewStorage(ctx, config.(*Config))
+	}))
+}
@@ -0,0 +1,37 @@
+package filesystemstorage_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	. "github.com/v2fly/v2ray-core/v5/app/persistentstorage/filesystemstorage"
+	"github.com/v2fly/v2ray-core/v5/common"
+)
+
+func TestStorage(t *testing.T) {
+	ctx := context.Background()
+	storage, err := NewStorage(ctx, &Config{Directory: t.TempDir()})
+	common.Must(err)
+
+	common.Must(storage.Put(ctx, []byte("quota>>>b"), []byte("2")))
+	common.Must(storage.Put(ctx, []byte("quota>>>a"), []byte("1")))
+	common.Must(storage.Put(ctx, []byte("other"), []byte("3")))
+	common.Must(storage.Put(ctx, []byte("quota>>>a"), []byte("4")))
+
+	value, err := storage.Get(ctx, []byte("quota>>>a"))
+	common.Must(err)
+	if string(value) != "4" {
+		t.Error("unexpected value: ", string(value))
+	}
+	if _, err := storage.Get(ctx, []byte("none")); err == nil {
+		t.Error("expected failure for missing key")
+	}
+
+	keys, err := storage.List(ctx, []byte("quota>>>"))
+	common.Must(err)
+	if r := cmp.Diff(keys, [][]byte{[]byte("quota>>>a"), []byte("quota>>>b")}); r != "" {
+		t.Error(r)
+	}
+}
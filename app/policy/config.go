@@ -58,6 +58,13 @@ func (p *Policy) overrideWith(another *Policy) {
 			Connection: another.Buffer.Connection,
 		}
 	}
+	if another.Limit != nil {
+		p.Limit = &Policy_Limit{
+			Uplink:      another.Limit.Uplink,
+			Downlink:    another.Limit.Downlink,
+			Connections: another.Limit.Connections,
+		}
+	}
 }
 
 // ToCorePolicy converts this Policy to policy.Session.
@@ -77,9 +84,24 @@ func (p *Policy) ToCorePolicy() policy.Session {
 	if p.Buffer != nil {
 		cp.Buffer.PerConnection = p.Buffer.Connection
 	}
+	if p.Limit != nil {
+		cp.Limit = p.Limit.ToCoreLimit()
+	}
 	return cp
 }
 
+// ToCoreLimit converts this Limit to policy.Limit.
+func (l *Policy_Limit) ToCoreLimit() policy.Limit {
+	if l == nil {
+		return policy.Limit{}
+	}
+	return policy.Limit{
+		Uplink:      l.Uplink,
+		Downlink:    l.Downlink,
+		Connections: l.Connections,
+	}
+}
+
 // ToCorePolicy converts this SystemPolicy to policy.System.
 func (p *SystemPolicy) ToCorePolicy() policy.System {
 	return policy.System{
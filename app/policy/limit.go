@@ -0,0 +1,268 @@
+package policy
+
+import (
+	"context"
+
+	"golang.org/x/time/rate"
+
+	"github.com/v2fly/v2ray-core/v5/features/policy"
+)
+
+// rateLimiter is a token bucket of bytes, which allows a burst of one second of traffic.
+type rateLimiter struct {
+	limiter *rate.Limiter
+}
+
+func newRateLimiter(bytesPerSecond uint64) *rateLimiter {
+	l := &rateLimiter{
+		limiter: rate.NewLimiter(rate.Inf, 0),
+	}
+	l.setRate(bytesPerSecond)
+	return l
+}
+
+// setRate changes the rate of the bucket. 0 for unlimited.
+func (l *rateLimiter) setRate(bytesPerSecond uint64) {
+	if bytesPerSecond == 0 {
+		l.limiter.SetLimit(rate.Inf)
+		return
+	}
+	burst := int(bytesPerSecond)
+	if burst < minBurst {
+		burst = minBurst
+	}
+	l.limiter.SetBurst(burst)
+	l.limiter.SetLimit(rate.Limit(bytesPerSecond))
+}
+
+// Wait implements policy.RateLimiter.
+func (l *rateLimiter) Wait(ctx context.Context, size int64) error {
+	for size > 0 {
+		n := size
+		if burst := int64(l.limiter.Burst()); n > burst && l.limiter.Limit() != rate.Inf {
+			n = burst
+		}
+		if err := l.limiter.WaitN(ctx, int(n)); err != nil {
+			if ctx.Err() == nil && n > int64(l.limiter.Burst()) {
+				// The burst is lowered by setRate since it is read. Retry with the new burst.
+				continue
+			}
+			return err
+		}
+		size -= n
+	}
+	return nil
+}
+
+// minBurst is the smallest burst of buckets, so that a buffer can always pass.
+const minBurst = 16 * 1024
+
+// multiRateLimiter waits for all of its rate limiters.
+type multiRateLimiter []policy.RateLimiter
+
+// Wait implements policy.RateLimiter.
+func (m multiRateLimiter) Wait(ctx context.Context, size int64) error {
+	for _, l := range m {
+		if err := l.Wait(ctx, size); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// limitState is the state of the limits of a user or an inbound with open connections.
+type limitState struct {
+	uplink         *rateLimiter
+	downlink       *rateLimiter
+	maxConnections uint32
+	connections    uint32
+	// level of the user, for the states of users.
+	level uint32
+}
+
+func newLimitState(limit policy.Limit) *limitState {
+	return &limitState{
+		uplink:         newRateLimiter(limit.Uplink),
+		downlink:       newRateLimiter(limit.Downlink),
+		maxConnections: limit.Connections,
+	}
+}
+
+func (s *limitState) update(limit policy.Limit) {
+	s.uplink.setRate(limit.Uplink)
+	s.downlink.setRate(limit.Downlink)
+	s.maxConnections = limit.Connections
+}
+
+func (s *limitState) full() bool {
+	return s.maxConnections > 0 && s.connections >= s.maxConnections
+}
+
+// userLimit returns the limit of a user, or of the level for connections without emails. It must
+// be called with m.access held.
+func (m *Instance) userLimit(email string, level uint32) policy.Limit {
+	if l, found := m.users[email]; found && email != "" {
+		return l.ToCoreLimit()
+	}
+	if p, found := m.levels[level]; found {
+		return p.Limit.ToCoreLimit()
+	}
+	return policy.Limit{}
+}
+
+// limited returns whether any of the limits is set.
+func limited(limit policy.Limit) bool {
+	return limit.Uplink > 0 || limit.Downlink > 0 || limit.Connections > 0
+}
+
+// rateLimited returns whether the rate of the state is limited in either direction.
+func (s *limitState) rateLimited() bool {
+	return s.uplink.limiter.Limit() != rate.Inf || s.downlink.limiter.Limit() != rate.Inf
+}
+
+// AcquireLimit implements policy.Limiter. Connections without any limits are not counted, and
+// connections without rate limits get no rate limiters, so that later changes of the limits do
+// not affect them.
+func (m *Instance) AcquireLimit(inboundTag string, email string, level uint32) (policy.RateLimiter, policy.RateLimiter, func(), error) {
+	m.access.Lock()
+	defer m.access.Unlock()
+
+	userState := m.userStates[email]
+	if email == "" {
+		userState = m.levelStates[level]
+	}
+	if userState == nil {
+		if limit := m.userLimit(email, level); limited(limit) {
+			userState = newLimitState(limit)
+			userState.level = level
+		}
+	}
+	if userState != nil && userState.full() {
+		if email == "" {
+			return nil, nil, nil, newError("too many connections of level ", level)
+		}
+		return nil, nil, nil, newError("too many connections of user ", email)
+	}
+	var inboundState *limitState
+	if inboundTag != "" {
+		inboundState = m.inboundStates[inboundTag]
+		if limit := m.inbounds[inboundTag].ToCoreLimit(); inboundState == nil && limited(limit) {
+			inboundState = newLimitState(limit)
+		}
+		if inboundState != nil && inboundState.full() {
+			return nil, nil, nil, newError("too many connections of inbound ", inboundTag)
+		}
+	}
+	if userState == nil && inboundState == nil {
+		return nil, nil, func() {}, nil
+	}
+
+	var states []*limitState
+	if userState != nil {
+		userState.connections++
+		if email == "" {
+			m.levelStates[level] = userState
+		} else {
+			m.userStates[email] = userState
+		}
+		states = append(states, userState)
+	}
+	if inboundState != nil {
+		inboundState.connections++
+		m.inboundStates[inboundTag] = inboundState
+		states = append(states, inboundState)
+	}
+
+	done := func() {
+		m.access.Lock()
+		defer m.access.Unlock()
+
+		if userState != nil {
+			if userState.connections--; userState.connections == 0 {
+				if email == "" {
+					delete(m.levelStates, level)
+				} else {
+					delete(m.userStates, email)
+				}
+			}
+		}
+		if inboundState != nil {
+			if inboundState.connections--; inboundState.connections == 0 {
+				delete(m.inboundStates, inboundTag)
+			}
+		}
+	}
+
+	rateLimited := false
+	for _, state := range states {
+		rateLimited = rateLimited || state.rateLimited()
+	}
+	switch {
+	case !rateLimited:
+		return nil, nil, done, nil
+	case len(states) == 1:
+		return states[0].uplink, states[0].downlink, done, nil
+	default:
+		var uplink, downlink multiRateLimiter
+		for _, state := range states {
+			uplink = append(uplink, state.uplink)
+			downlink = append(downlink, state.downlink)
+		}
+		return uplink, downlink, done, nil
+	}
+}
+
+// SetLevelLimit changes the limits of each user in a level, and of connections of the level without
+// emails. Open connections with rate limits are affected.
+func (m *Instance) SetLevelLimit(level uint32, limit *Policy_Limit) {
+	m.access.Lock()
+	defer m.access.Unlock()
+
+	p, found := m.levels[level]
+	if !found {
+		p = defaultPolicy()
+		m.levels[level] = p
+	}
+	p.Limit = limit
+
+	for email, s := range m.userStates {
+		if _, found := m.users[email]; !found && s.level == level {
+			s.update(limit.ToCoreLimit())
+		}
+	}
+	if s, found := m.levelStates[level]; found {
+		s.update(limit.ToCoreLimit())
+	}
+}
+
+// SetUserLimit changes the limits of a user, or removes them if limit is nil, so that the user
+// has the limits of its level. Open connections with rate limits are affected.
+func (m *Instance) SetUserLimit(email string, limit *Policy_Limit) {
+	m.access.Lock()
+	defer m.access.Unlock()
+
+	if limit == nil {
+		delete(m.users, email)
+	} else {
+		m.users[email] = limit
+	}
+	if s, found := m.userStates[email]; found {
+		s.update(m.userLimit(email, s.level))
+	}
+}
+
+// SetInboundLimit changes the limits of an inbound, or removes them if limit is nil. Open
+// connections with rate limits are affected.
+func (m *Instance) SetInboundLimit(tag string, limit *Policy_Limit) {
+	m.access.Lock()
+	defer m.access.Unlock()
+
+	if limit == nil {
+		delete(m.inbounds, tag)
+	} else {
+		m.inbounds[tag] = limit
+	}
+	if s, found := m.inboundStates[tag]; found {
+		s.update(limit.ToCoreLimit())
+	}
+}
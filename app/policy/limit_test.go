@@ -0,0 +1,128 @@
+package policy_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/v2fly/v2ray-core/v5/app/policy"
+	"github.com/v2fly/v2ray-core/v5/common"
+)
+
+func TestLimitConnections(t *testing.T) {
+	manager, err := New(context.Background(), &Config{
+		Level: map[uint32]*Policy{
+			0: {
+				Limit: &Policy_Limit{
+					Connections: 2,
+				},
+			},
+		},
+		Inbound: map[string]*Policy_Limit{
+			"in": {
+				Connections: 3,
+			},
+		},
+	})
+	common.Must(err)
+
+	_, _, done1, err := manager.AcquireLimit("in", "a@v2fly.org", 0)
+	common.Must(err)
+	_, _, done2, err := manager.AcquireLimit("in", "a@v2fly.org", 0)
+	common.Must(err)
+	if _, _, _, err := manager.AcquireLimit("in", "a@v2fly.org", 0); err == nil {
+		t.Error("expected error of too many connections of user")
+	}
+	_, _, done3, err := manager.AcquireLimit("in", "b@v2fly.org", 0)
+	common.Must(err)
+	if _, _, _, err := manager.AcquireLimit("in", "c@v2fly.org", 0); err == nil {
+		t.Error("expected error of too many connections of inbound")
+	}
+
+	done1()
+	_, _, done4, err := manager.AcquireLimit("in", "a@v2fly.org", 0)
+	common.Must(err)
+
+	manager.SetUserLimit("a@v2fly.org", &Policy_Limit{Connections: 1})
+	manager.SetInboundLimit("in", nil)
+	if _, _, _, err := manager.AcquireLimit("in", "a@v2fly.org", 0); err == nil {
+		t.Error("expected error of too many connections of user")
+	}
+	_, _, done5, err := manager.AcquireLimit("in", "c@v2fly.org", 0)
+	common.Must(err)
+
+	for _, done := range []func(){done2, done3, done4, done5} {
+		done()
+	}
+}
+
+func TestLimitLevel(t *testing.T) {
+	manager, err := New(context.Background(), &Config{
+		Level: map[uint32]*Policy{
+			1: {
+				Limit: &Policy_Limit{
+					Connections: 1,
+				},
+			},
+		},
+	})
+	common.Must(err)
+
+	// Connections without emails share the limits of their level.
+	_, _, done1, err := manager.AcquireLimit("in", "", 1)
+	common.Must(err)
+	if _, _, _, err := manager.AcquireLimit("in", "", 1); err == nil {
+		t.Error("expected error of too many connections of level")
+	}
+	_, _, done2, err := manager.AcquireLimit("in", "", 0)
+	common.Must(err)
+
+	manager.SetLevelLimit(1, &Policy_Limit{Connections: 2})
+	_, _, done3, err := manager.AcquireLimit("in", "", 1)
+	common.Must(err)
+
+	for _, done := range []func(){done1, done2, done3} {
+		done()
+	}
+}
+
+func TestLimitRate(t *testing.T) {
+	manager, err := New(context.Background(), &Config{})
+	common.Must(err)
+
+	uplink, downlink, done, err := manager.AcquireLimit("in", "a@v2fly.org", 0)
+	common.Must(err)
+	done()
+	if uplink != nil || downlink != nil {
+		t.Error("expected no rate limiters without limits")
+	}
+
+	manager.SetUserLimit("a@v2fly.org", &Policy_Limit{Downlink: 1024 * 1024 * 1024})
+	uplink, downlink, done, err = manager.AcquireLimit("in", "a@v2fly.org", 0)
+	common.Must(err)
+	defer done()
+	if uplink == nil || downlink == nil {
+		t.Fatal("expected rate limiters of user")
+	}
+
+	ctx := context.Background()
+	start := time.Now()
+	common.Must(downlink.Wait(ctx, 1024*1024))
+	if d := time.Since(start); d > 100*time.Millisecond {
+		t.Error("expected no wait within the burst, but waited ", d)
+	}
+
+	manager.SetUserLimit("a@v2fly.org", &Policy_Limit{Downlink: 64 * 1024})
+	common.Must(downlink.Wait(ctx, 64*1024))
+	start = time.Now()
+	common.Must(downlink.Wait(ctx, 32*1024))
+	if d := time.Since(start); d < 300*time.Millisecond {
+		t.Error("expected to wait for about 500ms, but waited ", d)
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	if err := downlink.Wait(timeoutCtx, 1024*1024); err == nil {
+		t.Error("expected error of timeout")
+	}
+}
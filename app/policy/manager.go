@@ -2,6 +2,7 @@ package policy
 
 import (
 	"context"
+	"sync"
 
 	"github.com/v2fly/v2ray-core/v5/common"
 	"github.com/v2fly/v2ray-core/v5/features/policy"
@@ -9,15 +10,29 @@ import (
 
 // Instance is an instance of Policy manager.
 type Instance struct {
-	levels map[uint32]*Policy
-	system *SystemPolicy
+	access   sync.RWMutex
+	levels   map[uint32]*Policy
+	system   *SystemPolicy
+	users    map[string]*Policy_Limit
+	inbounds map[string]*Policy_Limit
+
+	userStates    map[string]*limitState
+	inboundStates map[string]*limitState
+	// levelStates are the states of connections without emails, which share the limits of their
+	// levels as one user.
+	levelStates map[uint32]*limitState
 }
 
 // New creates new Policy manager instance.
 func New(ctx context.Context, config *Config) (*Instance, error) {
 	m := &Instance{
-		levels: make(map[uint32]*Policy),
-		system: config.System,
+		levels:        make(map[uint32]*Policy),
+		system:        config.System,
+		users:         make(map[string]*Policy_Limit),
+		inbounds:      make(map[string]*Policy_Limit),
+		userStates:    make(map[string]*limitState),
+		inboundStates: make(map[string]*limitState),
+		levelStates:   make(map[uint32]*limitState),
 	}
 	if len(config.Level) > 0 {
 		for lv, p := range config.Level {
@@ -26,6 +41,12 @@ func New(ctx context.Context, config *Config) (*Instance, error) {
 			m.levels[lv] = pp
 		}
 	}
+	for email, l := range config.User {
+		m.users[email] = l
+	}
+	for tag, l := range config.Inbound {
+		m.inbounds[tag] = l
+	}
 
 	return m, nil
 }
@@ -37,6 +58,9 @@ func (*Instance) Type() interface{} {
 
 // ForLevel implements policy.Manager.
 func (m *Instance) ForLevel(level uint32) policy.Session {
+	m.access.RLock()
+	defer m.access.RUnlock()
+
 	if p, ok := m.levels[level]; ok {
 		return p.ToCorePolicy()
 	}
@@ -6,10 +6,12 @@ import (
 	grpc "google.golang.org/grpc"
 
 	core "github.com/v2fly/v2ray-core/v5"
+	"github.com/v2fly/v2ray-core/v5/app/policy"
 	"github.com/v2fly/v2ray-core/v5/common"
 	"github.com/v2fly/v2ray-core/v5/common/serial"
 	"github.com/v2fly/v2ray-core/v5/features/inbound"
 	"github.com/v2fly/v2ray-core/v5/features/outbound"
+	feature_policy "github.com/v2fly/v2ray-core/v5/features/policy"
 	"github.com/v2fly/v2ray-core/v5/proxy"
 )
 
@@ -124,6 +126,25 @@ func (s *handlerServer) AlterOutbound(ctx context.Context, request *AlterOutboun
 	return &AlterOutboundResponse{}, operation.ApplyOutbound(ctx, handler)
 }
 
+func (s *handlerServer) SetLimit(ctx context.Context, request *SetLimitRequest) (*SetLimitResponse, error) {
+	manager, ok := s.s.GetFeature(feature_policy.ManagerType()).(*policy.Instance)
+	if !ok {
+		return nil, newError("policy does not support limits")
+	}
+
+	switch target := request.Target.(type) {
+	case *SetLimitRequest_Level:
+		manager.SetLevelLimit(target.Level, request.Limit)
+	case *SetLimitRequest_User:
+		manager.SetUserLimit(target.User, request.Limit)
+	case *SetLimitRequest_InboundTag:
+		manager.SetInboundLimit(target.InboundTag, request.Limit)
+	default:
+		return nil, newError("no target of limit")
+	}
+	return &SetLimitResponse{}, nil
+}
+
 func (s *handlerServer) mustEmbedUnimplementedHandlerServiceServer() {}
 
 type service struct {
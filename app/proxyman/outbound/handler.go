@@ -8,6 +8,7 @@ import (
 	"github.com/v2fly/v2ray-core/v5/common"
 	"github.com/v2fly/v2ray-core/v5/common/buf"
 	"github.com/v2fly/v2ray-core/v5/common/mux"
+	"github.com/v2fly/v2ray-core/v5/common/mux/multiplex"
 	"github.com/v2fly/v2ray-core/v5/common/net"
 	"github.com/v2fly/v2ray-core/v5/common/net/packetaddr"
 	"github.com/v2fly/v2ray-core/v5/common/net/pingproto"
@@ -50,6 +51,11 @@ func getStatCounter(v *core.Instance, tag string) (stats.Counter, stats.Counter)
 	return uplinkCounter, downlinkCounter
 }
 
+// muxClientManager dispatches links over multiplexed connections.
+type muxClientManager interface {
+	Dispatch(ctx context.Context, link *transport.Link) error
+}
+
 // Handler is an implements of outbound.Handler.
 type Handler struct {
 	tag               string
@@ -58,11 +64,13 @@ type Handler struct {
 	proxy             proxy.Outbound
 	outboundManager   outbound.Manager
 	dnsClient         dns.NewClient
-	mux               *mux.ClientManager
+	mux               muxClientManager
+	muxEnabled        bool
 	uplinkCounter     stats.Counter
 	downlinkCounter   stats.Counter
 	muxPacketEncoding packetaddr.PacketAddrType
 	pingManager       ping.Manager
+	viaPool           []net.Address
 }
 
 // NewHandler create a new Handler based on the given configuration.
@@ -93,6 +101,13 @@ func NewHandler(ctx context.Context, config *core.OutboundHandlerConfig) (outbou
 				return nil, newError("failed to parse stream settings").Base(err).AtWarning()
 			}
 			h.streamSettings = mss
+			for _, via := range s.ViaPool {
+				address := via.AsAddress()
+				if !address.Family().IsIP() {
+					return nil, newError("unable to send through: ", address).AtWarning()
+				}
+				h.viaPool = append(h.viaPool, address)
+			}
 		default:
 			return nil, newError("settings is not SenderConfig")
 		}
@@ -118,20 +133,54 @@ func NewHandler(ctx context.Context, config *core.OutboundHandlerConfig) (outbou
 		if config.Concurrency < 1 || config.Concurrency > 1024 {
 			return nil, newError("invalid mux concurrency: ", config.Concurrency).AtWarning()
 		}
-		h.muxPacketEncoding = h.senderSettings.MultiplexSettings.PacketEncoding
-		h.mux = &mux.ClientManager{
-			Enabled: h.senderSettings.MultiplexSettings.Enabled,
-			Picker: &mux.IncrementalWorkerPicker{
-				Factory: mux.NewDialingWorkerFactory(
-					ctx,
-					proxyHandler,
-					h,
-					mux.ClientStrategy{
-						MaxConcurrency: config.Concurrency,
-						MaxConnection:  128,
-					},
-				),
-			},
+		h.muxEnabled = config.Enabled
+		switch config.Protocol {
+		case proxyman.MultiplexingProtocol_MuxCool:
+			if config.Padding {
+				return nil, newError("padding is not supported by Mux.Cool").AtWarning()
+			}
+			h.muxPacketEncoding = config.PacketEncoding
+			h.mux = &mux.ClientManager{
+				Enabled: config.Enabled,
+				Picker: &mux.IncrementalWorkerPicker{
+					Factory: mux.NewDialingWorkerFactory(
+						ctx,
+						proxyHandler,
+						h,
+						mux.ClientStrategy{
+							MaxConcurrency: config.Concurrency,
+							MaxConnection:  128,
+						},
+					),
+				},
+			}
+		default:
+			strategy := multiplex.ClientStrategy{
+				SmuxVersion: int(config.SmuxVersion),
+				Padding:     config.Padding,
+				MaxStreams:  config.Concurrency,
+			}
+			switch config.Protocol {
+			case proxyman.MultiplexingProtocol_H2Mux:
+				strategy.Protocol = multiplex.ProtocolH2Mux
+			case proxyman.MultiplexingProtocol_Smux:
+				strategy.Protocol = multiplex.ProtocolSmux
+				if config.SmuxVersion > 2 {
+					return nil, newError("invalid smux version: ", config.SmuxVersion).AtWarning()
+				}
+			case proxyman.MultiplexingProtocol_Yamux:
+				strategy.Protocol = multiplex.ProtocolYamux
+			default:
+				return nil, newError("unknown multiplexing protocol: ", config.Protocol).AtWarning()
+			}
+			switch config.PacketEncoding {
+			case packetaddr.PacketAddrType_None:
+			case packetaddr.PacketAddrType_Packet:
+				strategy.PacketAddr = true
+			default:
+				return nil, newError("packet encoding ", config.PacketEncoding, " is not supported by ", strategy.Protocol).AtWarning()
+			}
+			h.mux = multiplex.NewClientManager(ctx, proxyHandler, h, strategy)
 		}
 	}
 
@@ -148,8 +197,9 @@ func (h *Handler) Tag() string {
 func (h *Handler) Dispatch(ctx context.Context, link *transport.Link) {
 	outbound := session.OutboundFromContext(ctx)
 	destination := outbound.Target
-	if h.mux != nil && (h.mux.Enabled || session.MuxPreferedFromContext(ctx)) {
-		if destination.Network == net.Network_UDP {
+	if h.mux != nil && (h.muxEnabled || session.MuxPreferedFromContext(ctx)) {
+		// Other multiplexers carry the address of packets by themselves.
+		if _, isMuxCool := h.mux.(*mux.ClientManager); isMuxCool && destination.Network == net.Network_UDP {
 			switch h.muxPacketEncoding {
 			case packetaddr.PacketAddrType_None:
 				link.Reader = &buf.EndpointErasureReader{Reader: link.Reader}
@@ -216,6 +266,10 @@ func (h *Handler) Dispatch(ctx context.Context, link *transport.Link) {
 
 // Address implements internet.Dialer.
 func (h *Handler) Address() net.Address {
+	if len(h.viaPool) > 0 {
+		// The address depends on the destination.
+		return nil
+	}
 	if h.senderSettings == nil || h.senderSettings.Via == nil {
 		return nil
 	}
@@ -253,13 +307,17 @@ func (h *Handler) Dial(ctx context.Context, dest net.Destination) (internet.Conn
 			newError("failed to get outbound handler with tag: ", tag).AtWarning().WriteToLog(session.ExportIDToError(ctx))
 		}
 
-		if h.senderSettings.Via != nil {
+		if len(h.viaPool) > 0 || h.senderSettings.Via != nil {
 			outbound := session.OutboundFromContext(ctx)
 			if outbound == nil {
 				outbound = new(session.Outbound)
 				ctx = session.ContextWithOutbound(ctx, outbound)
 			}
-			outbound.Gateway = h.senderSettings.Via.AsAddress()
+			if len(h.viaPool) > 0 {
+				outbound.Gateway = internet.SelectAddressFromPool(ctx, h.viaPool, h.senderSettings.ViaPoolStrategy, dest)
+			} else {
+				outbound.Gateway = h.senderSettings.Via.AsAddress()
+			}
 		}
 	}
 	enablePacketAddrCapture := true
@@ -0,0 +1,85 @@
+package command
+
+//go:generate go run github.com/v2fly/v2ray-core/v5/common/errors/errorgen
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+
+	core "github.com/v2fly/v2ray-core/v5"
+	"github.com/v2fly/v2ray-core/v5/app/quota"
+	"github.com/v2fly/v2ray-core/v5/common"
+	feature_quota "github.com/v2fly/v2ray-core/v5/features/quota"
+)
+
+// quotaServer is an implementation of QuotaService.
+type quotaServer struct {
+	manager *quota.Manager
+}
+
+// NewQuotaServer creates a new QuotaServiceServer. Requests fail if manager is nil.
+func NewQuotaServer(manager *quota.Manager) QuotaServiceServer {
+	return &quotaServer{
+		manager: manager,
+	}
+}
+
+func (s *quotaServer) QueryQuotas(ctx context.Context, request *QueryQuotasRequest) (*QueryQuotasResponse, error) {
+	if s.manager == nil {
+		return nil, newError("quota not enabled")
+	}
+	names := make(map[string]bool, len(request.Names))
+	for _, name := range request.Names {
+		names[name] = true
+	}
+
+	response := &QueryQuotasResponse{}
+	for _, status := range s.manager.List() {
+		if len(names) > 0 && !names[status.Name] {
+			continue
+		}
+		q := &Quota{
+			Rule:        status.Rule,
+			Name:        status.Name,
+			Limit:       status.Limit,
+			Grace:       status.Grace,
+			Used:        status.Used,
+			PeriodStart: status.PeriodStart.Unix(),
+			Exceeded:    status.Used >= status.Limit,
+		}
+		if !status.NextReset.IsZero() {
+			q.NextReset = status.NextReset.Unix()
+		}
+		response.Quotas = append(response.Quotas, q)
+	}
+	return response, nil
+}
+
+func (s *quotaServer) ResetQuota(ctx context.Context, request *ResetQuotaRequest) (*ResetQuotaResponse, error) {
+	if s.manager == nil {
+		return nil, newError("quota not enabled")
+	}
+	if s.manager.Reset(request.Name) == 0 {
+		return nil, newError("quota ", request.Name, " not found")
+	}
+	return &ResetQuotaResponse{}, nil
+}
+
+func (s *quotaServer) mustEmbedUnimplementedQuotaServiceServer() {}
+
+type service struct {
+	v *core.Instance
+}
+
+func (s *service) Register(server *grpc.Server) {
+	manager, _ := s.v.GetFeature(feature_quota.ManagerType()).(*quota.Manager)
+	RegisterQuotaServiceServer(server, NewQuotaServer(manager))
+}
+
+func init() {
+	common.Must(common.RegisterConfig((*Config)(nil), func(ctx context.Context, cfg interface{}) (interface{}, error) {
+		s := core.MustFromContext(ctx)
+		return &service{v: s}, nil
+	}))
+}
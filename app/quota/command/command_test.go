@@ -0,0 +1,44 @@
+package command_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/v2fly/v2ray-core/v5/app/quota"
+	. "github.com/v2fly/v2ray-core/v5/app/quota/command"
+	"github.com/v2fly/v2ray-core/v5/common"
+)
+
+func TestQuotaService(t *testing.T) {
+	m, err := quota.NewManager(context.Background(), &quota.Config{
+		Rules: []*quota.Rule{
+			{
+				Users:       []string{"love@v2fly.org"},
+				InboundTags: []string{"socks"},
+				Limit:       100,
+			},
+		},
+	})
+	common.Must(err)
+	counter, done, err := m.Acquire("socks", "love@v2fly.org", func() {})
+	common.Must(err)
+	defer done()
+	counter.Add(100)
+
+	s := NewQuotaServer(m)
+	resp, err := s.QueryQuotas(context.Background(), &QueryQuotasRequest{Names: []string{"user>>>love@v2fly.org"}})
+	common.Must(err)
+	if len(resp.Quotas) != 1 || resp.Quotas[0].Used != 100 || !resp.Quotas[0].Exceeded || resp.Quotas[0].NextReset != 0 {
+		t.Fatal("unexpected quotas: ", resp.Quotas)
+	}
+
+	common.Must2(s.ResetQuota(context.Background(), &ResetQuotaRequest{Name: "user>>>love@v2fly.org"}))
+	resp, err = s.QueryQuotas(context.Background(), &QueryQuotasRequest{})
+	common.Must(err)
+	if len(resp.Quotas) != 2 || resp.Quotas[0].Used != 100 || resp.Quotas[1].Used != 0 {
+		t.Fatal("unexpected quotas: ", resp.Quotas)
+	}
+	if _, err := s.ResetQuota(context.Background(), &ResetQuotaRequest{Name: "user>>>none"}); err == nil {
+		t.Error("expected failure for unknown quota")
+	}
+}
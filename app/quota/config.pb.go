@@ -0,0 +1,433 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.27.1
+// 	protoc        v3.19.2
+// source: app/quota/config.proto
+
+package quota
+
+import (
+	_ "github.com/v2fly/v2ray-core/v5/common/protoext"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Period int32
+
+const (
+	// The quota is never reset.
+	Period_Total   Period = 0
+	Period_Daily   Period = 1
+	Period_Weekly  Period = 2
+	Period_Monthly Period = 3
+)
+
+// Enum value maps for Period.
+var (
+	Period_name = map[int32]string{
+		0: "Total",
+		1: "Daily",
+		2: "Weekly",
+		3: "Monthly",
+	}
+	Period_value = map[string]int32{
+		"Total":   0,
+		"Daily":   1,
+		"Weekly":  2,
+		"Monthly": 3,
+	}
+)
+
+func (x Period) Enum() *Period {
+	p := new(Period)
+	*p = x
+	return p
+}
+
+func (x Period) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Period) Descriptor() protoreflect.EnumDescriptor {
+	return file_app_quota_config_proto_enumTypes[0].Descriptor()
+}
+
+func (Period) Type() protoreflect.EnumType {
+	return &file_app_quota_config_proto_enumTypes[0]
+}
+
+func (x Period) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Period.Descriptor instead.
+func (Period) EnumDescriptor() ([]byte, []int) {
+	return file_app_quota_config_proto_rawDescGZIP(), []int{0}
+}
+
+// Rule is a traffic quota, which applies to each of the users and each of the
+// inbounds separately.
+type Rule struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Tag of the rule, which identifies its state in the storage. The index of
+	// the rule is used if empty.
+	Tag string `protobuf:"bytes,1,opt,name=tag,proto3" json:"tag,omitempty"`
+	// Emails of the users.
+	Users       []string `protobuf:"bytes,2,rep,name=users,proto3" json:"users,omitempty"`
+	InboundTags []string `protobuf:"bytes,3,rep,name=inbound_tags,json=inboundTags,proto3" json:"inbound_tags,omitempty"`
+	// Bytes of uplink and downlink traffic allowed in each period. New
+	// connections are refused once it is reached.
+	Limit  uint64 `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
+	Period Period `protobuf:"varint,5,opt,name=period,proto3,enum=v2ray.core.app.quota.Period" json:"period,omitempty"`
+	// Day of the week for weekly quotas, where 0 is Sunday, or day of the month
+	// for monthly quotas, from 1 to 28. Quotas are reset at midnight local time.
+	ResetDay uint32 `protobuf:"varint,6,opt,name=reset_day,json=resetDay,proto3" json:"reset_day,omitempty"`
+	// Bytes allowed over the limit before open connections are closed.
+	Grace uint64 `protobuf:"varint,7,opt,name=grace,proto3" json:"grace,omitempty"`
+}
+
+func (x *Rule) Reset() {
+	*x = Rule{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_app_quota_config_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Rule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Rule) ProtoMessage() {}
+
+func (x *Rule) ProtoReflect() protoreflect.Message {
+	mi := &file_app_quota_config_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Rule.ProtoReflect.Descriptor instead.
+func (*Rule) Descriptor() ([]byte, []int) {
+	return file_app_quota_config_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Rule) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
+func (x *Rule) GetUsers() []string {
+	if x != nil {
+		return x.Users
+	}
+	return nil
+}
+
+func (x *Rule) GetInboundTags() []string {
+	if x != nil {
+		return x.InboundTags
+	}
+	return nil
+}
+
+func (x *Rule) GetLimit() uint64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *Rule) GetPeriod() Period {
+	if x != nil {
+		return x.Period
+	}
+	return Period_Total
+}
+
+func (x *Rule) GetResetDay() uint32 {
+	if x != nil {
+		return x.ResetDay
+	}
+	return 0
+}
+
+func (x *Rule) GetGrace() uint64 {
+	if x != nil {
+		return x.Grace
+	}
+	return 0
+}
+
+type Config struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Rules []*Rule `protobuf:"bytes,1,rep,name=rules,proto3" json:"rules,omitempty"`
+	// Seconds between saving the state of quotas to the persistent storage, if
+	// any. Default value is 60.
+	SaveInterval uint32 `protobuf:"varint,2,opt,name=save_interval,json=saveInterval,proto3" json:"save_interval,omitempty"`
+}
+
+func (x *Config) Reset() {
+	*x = Config{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_app_quota_config_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Config) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Config) ProtoMessage() {}
+
+func (x *Config) ProtoReflect() protoreflect.Message {
+	mi := &file_app_quota_config_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Config.ProtoReflect.Descriptor instead.
+func (*Config) Descriptor() ([]byte, []int) {
+	return file_app_quota_config_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Config) GetRules() []*Rule {
+	if x != nil {
+		return x.Rules
+	}
+	return nil
+}
+
+func (x *Config) GetSaveInterval() uint32 {
+	if x != nil {
+		return x.SaveInterval
+	}
+	return 0
+}
+
+// State is the state of a quota kept in the persistent storage.
+type State struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Used uint64 `protobuf:"varint,1,opt,name=used,proto3" json:"used,omitempty"`
+	// Start time of the period in Unix seconds.
+	PeriodStart int64 `protobuf:"varint,2,opt,name=period_start,json=periodStart,proto3" json:"period_start,omitempty"`
+}
+
+func (x *State) Reset() {
+	*x = State{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_app_quota_config_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *State) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*State) ProtoMessage() {}
+
+func (x *State) ProtoReflect() protoreflect.Message {
+	mi := &file_app_quota_config_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use State.ProtoReflect.Descriptor instead.
+func (*State) Descriptor() ([]byte, []int) {
+	return file_app_quota_config_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *State) GetUsed() uint64 {
+	if x != nil {
+		return x.Used
+	}
+	return 0
+}
+
+func (x *State) GetPeriodStart() int64 {
+	if x != nil {
+		return x.PeriodStart
+	}
+	return 0
+}
+
+var File_app_quota_config_proto protoreflect.FileDescriptor
+
+var file_app_quota_config_proto_rawDesc = []byte{
+	0x0a, 0x16, 0x61, 0x70, 0x70, 0x2f, 0x71, 0x75, 0x6f, 0x74, 0x61, 0x2f, 0x63, 0x6f, 0x6e, 0x66,
+	0x69, 0x67, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x14, 0x76, 0x32, 0x72, 0x61, 0x79, 0x2e,
+	0x63, 0x6f, 0x72, 0x65, 0x2e, 0x61, 0x70, 0x70, 0x2e, 0x71, 0x75, 0x6f, 0x74, 0x61, 0x1a, 0x20,
+	0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x65, 0x78, 0x74, 0x2f,
+	0x65, 0x78, 0x74, 0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x22, 0xd0, 0x01, 0x0a, 0x04, 0x52, 0x75, 0x6c, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x74, 0x61, 0x67,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x74, 0x61, 0x67, 0x12, 0x14, 0x0a, 0x05, 0x75,
+	0x73, 0x65, 0x72, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x05, 0x75, 0x73, 0x65, 0x72,
+	0x73, 0x12, 0x21, 0x0a, 0x0c, 0x69, 0x6e, 0x62, 0x6f, 0x75, 0x6e, 0x64, 0x5f, 0x74, 0x61, 0x67,
+	0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0b, 0x69, 0x6e, 0x62, 0x6f, 0x75, 0x6e, 0x64,
+	0x54, 0x61, 0x67, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x34, 0x0a, 0x06, 0x70, 0x65,
+	0x72, 0x69, 0x6f, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1c, 0x2e, 0x76, 0x32, 0x72,
+	0x61, 0x79, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x61, 0x70, 0x70, 0x2e, 0x71, 0x75, 0x6f, 0x74,
+	0x61, 0x2e, 0x50, 0x65, 0x72, 0x69, 0x6f, 0x64, 0x52, 0x06, 0x70, 0x65, 0x72, 0x69, 0x6f, 0x64,
+	0x12, 0x1b, 0x0a, 0x09, 0x72, 0x65, 0x73, 0x65, 0x74, 0x5f, 0x64, 0x61, 0x79, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x08, 0x72, 0x65, 0x73, 0x65, 0x74, 0x44, 0x61, 0x79, 0x12, 0x14, 0x0a,
+	0x05, 0x67, 0x72, 0x61, 0x63, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x67, 0x72,
+	0x61, 0x63, 0x65, 0x22, 0x75, 0x0a, 0x06, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x30, 0x0a,
+	0x05, 0x72, 0x75, 0x6c, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x76,
+	0x32, 0x72, 0x61, 0x79, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x61, 0x70, 0x70, 0x2e, 0x71, 0x75,
+	0x6f, 0x74, 0x61, 0x2e, 0x52, 0x75, 0x6c, 0x65, 0x52, 0x05, 0x72, 0x75, 0x6c, 0x65, 0x73, 0x12,
+	0x23, 0x0a, 0x0d, 0x73, 0x61, 0x76, 0x65, 0x5f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0c, 0x73, 0x61, 0x76, 0x65, 0x49, 0x6e, 0x74, 0x65,
+	0x72, 0x76, 0x61, 0x6c, 0x3a, 0x14, 0x82, 0xb5, 0x18, 0x10, 0x0a, 0x07, 0x73, 0x65, 0x72, 0x76,
+	0x69, 0x63, 0x65, 0x12, 0x05, 0x71, 0x75, 0x6f, 0x74, 0x61, 0x22, 0x3e, 0x0a, 0x05, 0x53, 0x74,
+	0x61, 0x74, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x75, 0x73, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x04, 0x75, 0x73, 0x65, 0x64, 0x12, 0x21, 0x0a, 0x0c, 0x70, 0x65, 0x72, 0x69, 0x6f,
+	0x64, 0x5f, 0x73, 0x74, 0x61, 0x72, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0b, 0x70,
+	0x65, 0x72, 0x69, 0x6f, 0x64, 0x53, 0x74, 0x61, 0x72, 0x74, 0x2a, 0x37, 0x0a, 0x06, 0x50, 0x65,
+	0x72, 0x69, 0x6f, 0x64, 0x12, 0x09, 0x0a, 0x05, 0x54, 0x6f, 0x74, 0x61, 0x6c, 0x10, 0x00, 0x12,
+	0x09, 0x0a, 0x05, 0x44, 0x61, 0x69, 0x6c, 0x79, 0x10, 0x01, 0x12, 0x0a, 0x0a, 0x06, 0x57, 0x65,
+	0x65, 0x6b, 0x6c, 0x79, 0x10, 0x02, 0x12, 0x0b, 0x0a, 0x07, 0x4d, 0x6f, 0x6e, 0x74, 0x68, 0x6c,
+	0x79, 0x10, 0x03, 0x42, 0x5d, 0x0a, 0x18, 0x63, 0x6f, 0x6d, 0x2e, 0x76, 0x32, 0x72, 0x61, 0x79,
+	0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x61, 0x70, 0x70, 0x2e, 0x71, 0x75, 0x6f, 0x74, 0x61, 0x50,
+	0x01, 0x5a, 0x28, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x76, 0x32,
+	0x66, 0x6c, 0x79, 0x2f, 0x76, 0x32, 0x72, 0x61, 0x79, 0x2d, 0x63, 0x6f, 0x72, 0x65, 0x2f, 0x76,
+	0x35, 0x2f, 0x61, 0x70, 0x70, 0x2f, 0x71, 0x75, 0x6f, 0x74, 0x61, 0xaa, 0x02, 0x14, 0x56, 0x32,
+	0x52, 0x61, 0x79, 0x2e, 0x43, 0x6f, 0x72, 0x65, 0x2e, 0x41, 0x70, 0x70, 0x2e, 0x51, 0x75, 0x6f,
+	0x74, 0x61, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_app_quota_config_proto_rawDescOnce sync.Once
+	file_app_quota_config_proto_rawDescData = file_app_quota_config_proto_rawDesc
+)
+
+func file_app_quota_config_proto_rawDescGZIP() []byte {
+	file_app_quota_config_proto_rawDescOnce.Do(func() {
+		file_app_quota_config_proto_rawDescData = protoimpl.X.CompressGZIP(file_app_quota_config_proto_rawDescData)
+	})
+	return file_app_quota_config_proto_rawDescData
+}
+
+var file_app_quota_config_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_app_quota_config_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_app_quota_config_proto_goTypes = []interface{}{
+	(Period)(0),    // 0: v2ray.core.app.quota.Period
+	(*Rule)(nil),   // 1: v2ray.core.app.quota.Rule
+	(*Config)(nil), // 2: v2ray.core.app.quota.Config
+	(*State)(nil),  // 3: v2ray.core.app.quota.State
+}
+var file_app_quota_config_proto_depIdxs = []int32{
+	0, // 0: v2ray.core.app.quota.Rule.period:type_name -> v2ray.core.app.quota.Period
+	1, // 1: v2ray.core.app.quota.Config.rules:type_name -> v2ray.core.app.quota.Rule
+	2, // [2:2] is the sub-list for method output_type
+	2, // [2:2] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_app_quota_config_proto_init() }
+func file_app_quota_config_proto_init() {
+	if File_app_quota_config_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_app_quota_config_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Rule); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_app_quota_config_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Config); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_app_quota_config_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*State); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_app_quota_config_proto_rawDesc,
+			NumEnums:      1,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_app_quota_config_proto_goTypes,
+		DependencyIndexes: file_app_quota_config_proto_depIdxs,
+		EnumInfos:         file_app_quota_config_proto_enumTypes,
+		MessageInfos:      file_app_quota_config_proto_msgTypes,
+	}.Build()
+	File_app_quota_config_proto = out.File
+	file_app_quota_config_proto_rawDesc = nil
+	file_app_quota_config_proto_goTypes = nil
+	file_app_quota_config_proto_depIdxs = nil
+}
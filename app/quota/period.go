@@ -0,0 +1,45 @@
+package quota
+
+import (
+	"time"
+)
+
+// periodStart returns the start of the period of the rule at the time. Quotas that are never reset
+// have a single period, starting at the zero time.
+func periodStart(rule *Rule, now time.Time) time.Time {
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch rule.Period {
+	case Period_Daily:
+		return midnight
+	case Period_Weekly:
+		days := (int(now.Weekday()) - int(rule.ResetDay%7) + 7) % 7
+		return midnight.AddDate(0, 0, -days)
+	case Period_Monthly:
+		day := int(rule.ResetDay)
+		if day < 1 || day > 28 {
+			day = 1
+		}
+		start := time.Date(now.Year(), now.Month(), day, 0, 0, 0, 0, now.Location())
+		if now.Before(start) {
+			start = start.AddDate(0, -1, 0)
+		}
+		return start
+	default:
+		return time.Time{}
+	}
+}
+
+// nextReset returns the end of the period of the rule starting at start, or the zero time if the
+// quota is never reset.
+func nextReset(rule *Rule, start time.Time) time.Time {
+	switch rule.Period {
+	case Period_Daily:
+		return start.AddDate(0, 0, 1)
+	case Period_Weekly:
+		return start.AddDate(0, 0, 7)
+	case Period_Monthly:
+		return start.AddDate(0, 1, 0)
+	default:
+		return time.Time{}
+	}
+}
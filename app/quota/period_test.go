@@ -0,0 +1,53 @@
+package quota
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPeriodStart(t *testing.T) {
+	now := time.Date(2022, time.March, 10, 15, 4, 5, 0, time.UTC) // Thursday
+	testCases := []struct {
+		rule  *Rule
+		start time.Time
+		next  time.Time
+	}{
+		{
+			rule: &Rule{Period: Period_Total},
+		},
+		{
+			rule:  &Rule{Period: Period_Daily},
+			start: time.Date(2022, time.March, 10, 0, 0, 0, 0, time.UTC),
+			next:  time.Date(2022, time.March, 11, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			rule:  &Rule{Period: Period_Weekly, ResetDay: 1},
+			start: time.Date(2022, time.March, 7, 0, 0, 0, 0, time.UTC),
+			next:  time.Date(2022, time.March, 14, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			rule:  &Rule{Period: Period_Weekly, ResetDay: 4},
+			start: time.Date(2022, time.March, 10, 0, 0, 0, 0, time.UTC),
+			next:  time.Date(2022, time.March, 17, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			rule:  &Rule{Period: Period_Monthly},
+			start: time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC),
+			next:  time.Date(2022, time.April, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			rule:  &Rule{Period: Period_Monthly, ResetDay: 15},
+			start: time.Date(2022, time.February, 15, 0, 0, 0, 0, time.UTC),
+			next:  time.Date(2022, time.March, 15, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, tc := range testCases {
+		start := periodStart(tc.rule, now)
+		if !start.Equal(tc.start) {
+			t.Error("unexpected start of ", tc.rule, ": ", start)
+		}
+		if next := nextReset(tc.rule, start); !next.Equal(tc.next) {
+			t.Error("unexpected next reset of ", tc.rule, ": ", next)
+		}
+	}
+}
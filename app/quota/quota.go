@@ -0,0 +1,326 @@
+package quota
+
+//go:generate go run github.com/v2fly/v2ray-core/v5/common/errors/errorgen
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	core "github.com/v2fly/v2ray-core/v5"
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/task"
+	"github.com/v2fly/v2ray-core/v5/features/extension"
+	"github.com/v2fly/v2ray-core/v5/features/quota"
+	"github.com/v2fly/v2ray-core/v5/features/stats"
+)
+
+// quotaState is the state of a rule for a single user or inbound.
+type quotaState struct {
+	rule    *Rule
+	ruleTag string
+	// name is "user>>>" followed by the email of the user, or "inbound>>>" followed by the tag of
+	// the inbound.
+	name string
+	used uint64
+
+	// Fields below are guarded by Manager.access.
+	start       time.Time
+	connections map[uint64]func()
+}
+
+func (q *quotaState) storageKey() []byte {
+	return []byte("quota>>>" + q.ruleTag + ">>>" + q.name)
+}
+
+func (q *quotaState) threshold() uint64 {
+	return q.rule.Limit + q.rule.Grace
+}
+
+// Status is the state of a quota.
+type Status struct {
+	Rule        string
+	Name        string
+	Limit       uint64
+	Grace       uint64
+	Used        uint64
+	PeriodStart time.Time
+	NextReset   time.Time
+}
+
+// Manager is an implementation of quota.Manager.
+type Manager struct {
+	access   sync.Mutex
+	config   *Config
+	instance *core.Instance
+	quotas   []*quotaState
+	byName   map[string][]*quotaState
+	lastID   uint64
+
+	storage  extension.PersistentStorageEngine
+	saveTask *task.Periodic
+}
+
+// NewManager creates a new Manager with the rules of the config.
+func NewManager(ctx context.Context, config *Config) (*Manager, error) {
+	m := &Manager{
+		config:   config,
+		instance: core.FromContext(ctx),
+		byName:   make(map[string][]*quotaState),
+	}
+	for i, rule := range config.Rules {
+		if rule.Limit == 0 {
+			return nil, newError("quota limit of rule ", i, " is not specified")
+		}
+		tag := rule.Tag
+		if tag == "" {
+			tag = strconv.Itoa(i)
+		}
+		var names []string
+		for _, user := range rule.Users {
+			names = append(names, "user>>>"+user)
+		}
+		for _, inbound := range rule.InboundTags {
+			names = append(names, "inbound>>>"+inbound)
+		}
+		if len(names) == 0 {
+			return nil, newError("quota rule ", tag, " has no user or inbound")
+		}
+		for _, name := range names {
+			q := &quotaState{
+				rule:        rule,
+				ruleTag:     tag,
+				name:        name,
+				connections: make(map[uint64]func()),
+			}
+			m.quotas = append(m.quotas, q)
+			m.byName[name] = append(m.byName[name], q)
+		}
+	}
+	return m, nil
+}
+
+// Type implements common.HasType.
+func (*Manager) Type() interface{} {
+	return quota.ManagerType()
+}
+
+// refresh starts a new period of the quota if the current one has ended.
+func (m *Manager) refresh(q *quotaState, now time.Time) {
+	if start := periodStart(q.rule, now); start.After(q.start) {
+		q.start = start
+		atomic.StoreUint64(&q.used, 0)
+	}
+}
+
+// Acquire implements quota.Manager.
+func (m *Manager) Acquire(inboundTag string, user string, interrupt func()) (stats.Counter, func(), error) {
+	m.access.Lock()
+	defer m.access.Unlock()
+
+	var quotas []*quotaState
+	if user != "" {
+		quotas = append(quotas, m.byName["user>>>"+user]...)
+	}
+	if inboundTag != "" {
+		quotas = append(quotas, m.byName["inbound>>>"+inboundTag]...)
+	}
+	if len(quotas) == 0 {
+		return nil, func() {}, nil
+	}
+
+	now := time.Now()
+	for _, q := range quotas {
+		m.refresh(q, now)
+		if atomic.LoadUint64(&q.used) >= q.rule.Limit {
+			return nil, nil, newError("quota of ", q.name, " exceeded")
+		}
+	}
+
+	m.lastID++
+	id := m.lastID
+	for _, q := range quotas {
+		q.connections[id] = interrupt
+	}
+
+	var once sync.Once
+	done := func() {
+		once.Do(func() {
+			m.access.Lock()
+			defer m.access.Unlock()
+
+			for _, q := range quotas {
+				delete(q.connections, id)
+			}
+		})
+	}
+	return &connectionCounter{manager: m, quotas: quotas}, done, nil
+}
+
+// enforce closes all open connections of the quota.
+func (m *Manager) enforce(q *quotaState) {
+	m.access.Lock()
+	interrupts := make([]func(), 0, len(q.connections))
+	for _, interrupt := range q.connections {
+		interrupts = append(interrupts, interrupt)
+	}
+	m.access.Unlock()
+
+	newError("quota of ", q.name, " exceeded, closing ", len(interrupts), " connections").AtWarning().WriteToLog()
+	for _, interrupt := range interrupts {
+		interrupt()
+	}
+}
+
+// List returns the states of all quotas.
+func (m *Manager) List() []Status {
+	m.access.Lock()
+	defer m.access.Unlock()
+
+	now := time.Now()
+	statuses := make([]Status, 0, len(m.quotas))
+	for _, q := range m.quotas {
+		m.refresh(q, now)
+		statuses = append(statuses, Status{
+			Rule:        q.ruleTag,
+			Name:        q.name,
+			Limit:       q.rule.Limit,
+			Grace:       q.rule.Grace,
+			Used:        atomic.LoadUint64(&q.used),
+			PeriodStart: q.start,
+			NextReset:   nextReset(q.rule, q.start),
+		})
+	}
+	sort.SliceStable(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+	return statuses
+}
+
+// Reset clears the used traffic of all quotas of a user or an inbound, by the name in the form of
+// "user>>>email" or "inbound>>>tag", and returns the number of quotas reset.
+func (m *Manager) Reset(name string) int {
+	m.access.Lock()
+	defer m.access.Unlock()
+
+	for _, q := range m.byName[name] {
+		atomic.StoreUint64(&q.used, 0)
+	}
+	return len(m.byName[name])
+}
+
+func (m *Manager) load() {
+	m.access.Lock()
+	defer m.access.Unlock()
+
+	now := time.Now()
+	for _, q := range m.quotas {
+		m.refresh(q, now)
+		value, err := m.storage.Get(context.Background(), q.storageKey())
+		if err != nil {
+			continue
+		}
+		state := new(State)
+		if err := proto.Unmarshal(value, state); err != nil {
+			newError("invalid state of quota ", q.name).Base(err).AtWarning().WriteToLog()
+			continue
+		}
+		// State of a previous period is discarded.
+		if state.PeriodStart == q.start.Unix() {
+			atomic.StoreUint64(&q.used, state.Used)
+		}
+	}
+}
+
+func (m *Manager) save() error {
+	m.access.Lock()
+	defer m.access.Unlock()
+
+	now := time.Now()
+	for _, q := range m.quotas {
+		m.refresh(q, now)
+		value, err := proto.Marshal(&State{
+			Used:        atomic.LoadUint64(&q.used),
+			PeriodStart: q.start.Unix(),
+		})
+		common.Must(err)
+		if err := m.storage.Put(context.Background(), q.storageKey(), value); err != nil {
+			newError("failed to save state of quota ", q.name).Base(err).AtWarning().WriteToLog()
+		}
+	}
+	return nil
+}
+
+// Start implements common.Runnable.
+func (m *Manager) Start() error {
+	if m.instance != nil {
+		if storage, ok := m.instance.GetFeature(extension.PersistentStorageEngineType()).(extension.PersistentStorageEngine); ok {
+			m.storage = storage
+		}
+	}
+	if m.storage == nil {
+		newError("no persistent storage, quotas are reset on restart").AtWarning().WriteToLog()
+		return nil
+	}
+	m.load()
+
+	interval := time.Duration(m.config.SaveInterval) * time.Second
+	if interval == 0 {
+		interval = time.Minute
+	}
+	m.saveTask = &task.Periodic{
+		Interval: interval,
+		Execute:  m.save,
+	}
+	return m.saveTask.Start()
+}
+
+// Close implements common.Closable.
+func (m *Manager) Close() error {
+	if m.saveTask == nil {
+		return nil
+	}
+	if err := m.saveTask.Close(); err != nil {
+		return err
+	}
+	return m.save()
+}
+
+// connectionCounter adds the traffic of a connection to its quotas.
+type connectionCounter struct {
+	manager *Manager
+	quotas  []*quotaState
+	value   int64
+}
+
+// Value implements stats.Counter.
+func (c *connectionCounter) Value() int64 {
+	return atomic.LoadInt64(&c.value)
+}
+
+// Set implements stats.Counter. It only sets the traffic of the connection, not its quotas.
+func (c *connectionCounter) Set(newValue int64) int64 {
+	return atomic.SwapInt64(&c.value, newValue)
+}
+
+// Add implements stats.Counter.
+func (c *connectionCounter) Add(delta int64) int64 {
+	for _, q := range c.quotas {
+		used := atomic.AddUint64(&q.used, uint64(delta))
+		if threshold := q.threshold(); used >= threshold && used-uint64(delta) < threshold {
+			go c.manager.enforce(q)
+		}
+	}
+	return atomic.AddInt64(&c.value, delta)
+}
+
+func init() {
+	common.Must(common.RegisterConfig((*Config)(nil), func(ctx context.Context, config interface{}) (interface{}, error) {
+		return NewManager(ctx, config.(*Config))
+	}))
+}
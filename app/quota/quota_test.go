@@ -0,0 +1,106 @@
+package quota_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/types/known/anypb"
+
+	core "github.com/v2fly/v2ray-core/v5"
+	"github.com/v2fly/v2ray-core/v5/app/persistentstorage/filesystemstorage"
+	. "github.com/v2fly/v2ray-core/v5/app/quota"
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/serial"
+	feature_quota "github.com/v2fly/v2ray-core/v5/features/quota"
+)
+
+func TestQuotaEnforcement(t *testing.T) {
+	m, err := NewManager(context.Background(), &Config{
+		Rules: []*Rule{
+			{
+				Users:  []string{"love@v2fly.org"},
+				Limit:  100,
+				Grace:  50,
+				Period: Period_Daily,
+			},
+		},
+	})
+	common.Must(err)
+
+	counter, _, err := m.Acquire("in", "other@v2fly.org", func() {})
+	common.Must(err)
+	if counter != nil {
+		t.Error("expected no quota for other users")
+	}
+
+	interrupted := make(chan struct{})
+	counter, done, err := m.Acquire("in", "love@v2fly.org", func() { close(interrupted) })
+	common.Must(err)
+	defer done()
+
+	counter.Add(100)
+	if _, _, err := m.Acquire("in", "love@v2fly.org", func() {}); err == nil {
+		t.Error("expected new connection to be refused")
+	}
+	select {
+	case <-interrupted:
+		t.Fatal("connection closed within grace")
+	case <-time.After(time.Millisecond * 100):
+	}
+
+	counter.Add(50)
+	select {
+	case <-interrupted:
+	case <-time.After(time.Second * 5):
+		t.Fatal("connection not closed")
+	}
+
+	statuses := m.List()
+	if len(statuses) != 1 || statuses[0].Name != "user>>>love@v2fly.org" || statuses[0].Used != 150 {
+		t.Fatal("unexpected statuses: ", statuses)
+	}
+	if m.Reset("user>>>love@v2fly.org") != 1 {
+		t.Fatal("failed to reset quota")
+	}
+	_, done2, err := m.Acquire("in", "love@v2fly.org", func() {})
+	common.Must(err)
+	done2()
+}
+
+func TestQuotaPersistence(t *testing.T) {
+	config := &core.Config{
+		App: []*anypb.Any{
+			serial.ToTypedMessage(&filesystemstorage.Config{Directory: t.TempDir()}),
+			serial.ToTypedMessage(&Config{
+				Rules: []*Rule{
+					{
+						InboundTags: []string{"in"},
+						Limit:       1000,
+						Period:      Period_Monthly,
+						ResetDay:    1,
+					},
+				},
+			}),
+		},
+	}
+
+	server, err := core.New(config)
+	common.Must(err)
+	common.Must(server.Start())
+	manager := server.GetFeature(feature_quota.ManagerType()).(feature_quota.Manager)
+	counter, done, err := manager.Acquire("in", "", func() {})
+	common.Must(err)
+	counter.Add(300)
+	done()
+	common.Must(server.Close())
+
+	server, err = core.New(config)
+	common.Must(err)
+	common.Must(server.Start())
+	defer server.Close()
+	statuses := server.GetFeature(feature_quota.ManagerType()).(*Manager).List()
+	if len(statuses) != 1 || statuses[0].Used != 300 {
+		t.Error("unexpected statuses: ", statuses)
+	}
+}
@@ -6,9 +6,12 @@ import (
 
 	"google.golang.org/protobuf/proto"
 
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/buf"
 	"github.com/v2fly/v2ray-core/v5/common/mux"
 	"github.com/v2fly/v2ray-core/v5/common/net"
 	"github.com/v2fly/v2ray-core/v5/common/session"
+	"github.com/v2fly/v2ray-core/v5/common/signal/done"
 	"github.com/v2fly/v2ray-core/v5/common/task"
 	"github.com/v2fly/v2ray-core/v5/features/routing"
 	"github.com/v2fly/v2ray-core/v5/transport"
@@ -21,8 +24,31 @@ type Bridge struct {
 	dispatcher  routing.Dispatcher
 	tag         string
 	domain      string
+	name        string
+	secret      []byte
 	workers     []*BridgeWorker
 	monitorTask *task.Periodic
+
+	// failures is the number of consecutive workers that failed to reach the portal.
+	failures   uint32
+	retryAfter time.Time
+}
+
+const (
+	minRetryDelay = time.Second * 2
+	maxRetryDelay = time.Minute * 2
+)
+
+// retryDelay returns the delay before creating a new worker after the given number of failures.
+func retryDelay(failures uint32) time.Duration {
+	delay := minRetryDelay
+	for i := uint32(1); i < failures && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+	return delay
 }
 
 // NewBridge creates a new Bridge instance.
@@ -39,6 +65,11 @@ func NewBridge(ctx context.Context, config *BridgeConfig, dispatcher routing.Dis
 		dispatcher: dispatcher,
 		tag:        config.Tag,
 		domain:     config.Domain,
+		name:       config.Name,
+		secret:     []byte(config.Secret),
+	}
+	if b.name == "" {
+		b.name = config.Tag
 	}
 	b.monitorTask = &task.Periodic{
 		Execute:  b.monitor,
@@ -50,11 +81,22 @@ func NewBridge(ctx context.Context, config *BridgeConfig, dispatcher routing.Dis
 func (b *Bridge) cleanup() {
 	var activeWorkers []*BridgeWorker
 
+	failed := false
 	for _, w := range b.workers {
-		if w.IsActive() {
+		switch {
+		case w.IsActive():
 			activeWorkers = append(activeWorkers, w)
+			if w.connected.Done() {
+				b.failures = 0
+			}
+		case !w.connected.Done():
+			b.failures++
+			failed = true
 		}
 	}
+	if failed {
+		b.onFailure()
+	}
 
 	if len(activeWorkers) != len(b.workers) {
 		b.workers = activeWorkers
@@ -75,9 +117,14 @@ func (b *Bridge) monitor() error {
 	}
 
 	if numWorker == 0 || numConnections/numWorker > 16 {
-		worker, err := NewBridgeWorker(b.ctx, b.domain, b.tag, b.dispatcher)
+		if time.Now().Before(b.retryAfter) {
+			return nil
+		}
+		worker, err := NewBridgeWorker(b.ctx, b.domain, b.tag, b.name, b.secret, b.dispatcher)
 		if err != nil {
 			newError("failed to create bridge worker").Base(err).AtWarning().WriteToLog()
+			b.failures++
+			b.onFailure()
 			return nil
 		}
 		b.workers = append(b.workers, worker)
@@ -86,6 +133,12 @@ func (b *Bridge) monitor() error {
 	return nil
 }
 
+func (b *Bridge) onFailure() {
+	delay := retryDelay(b.failures)
+	b.retryAfter = time.Now().Add(delay)
+	newError("bridge ", b.tag, " failed to reach portal ", b.failures, " time(s), retrying in ", delay).AtInfo().WriteToLog()
+}
+
 func (b *Bridge) Start() error {
 	return b.monitorTask.Start()
 }
@@ -96,12 +149,17 @@ func (b *Bridge) Close() error {
 
 type BridgeWorker struct {
 	tag        string
+	name       string
+	secret     []byte
 	worker     *mux.ServerWorker
 	dispatcher routing.Dispatcher
 	state      Control_State
+	// connected is closed once the portal accepts this worker. It is closed in the goroutine of
+	// the internal connection and checked by Bridge.cleanup.
+	connected *done.Instance
 }
 
-func NewBridgeWorker(ctx context.Context, domain string, tag string, d routing.Dispatcher) (*BridgeWorker, error) {
+func NewBridgeWorker(ctx context.Context, domain string, tag string, name string, secret []byte, d routing.Dispatcher) (*BridgeWorker, error) {
 	bridgeCtx := session.ContextWithInbound(ctx, &session.Inbound{
 		Tag: tag,
 	})
@@ -117,6 +175,9 @@ func NewBridgeWorker(ctx context.Context, domain string, tag string, d routing.D
 	w := &BridgeWorker{
 		dispatcher: d,
 		tag:        tag,
+		name:       name,
+		secret:     secret,
+		connected:  done.New(),
 	}
 
 	worker, err := mux.NewServerWorker(ctx, w, link)
@@ -165,11 +226,30 @@ func (w *BridgeWorker) handleInternalConn(link transport.Link) {
 				if ctl.State != w.state {
 					w.state = ctl.State
 				}
+				if len(ctl.Challenge) == 0 {
+					w.connected.Close()
+					continue
+				}
+				if err := w.respond(link.Writer, ctl.Challenge); err != nil {
+					newError("failed to respond to portal").Base(err).WriteToLog()
+				}
 			}
 		}
 	}()
 }
 
+func (w *BridgeWorker) respond(writer buf.Writer, challenge []byte) error {
+	msg := &Control{
+		State:      w.state,
+		Response:   computeResponse(w.secret, challenge),
+		BridgeName: w.name,
+	}
+	msg.FillInRandom()
+	b, err := proto.Marshal(msg)
+	common.Must(err)
+	return writer.WriteMultiBuffer(buf.MergeBytes(nil, b))
+}
+
 func (w *BridgeWorker) Dispatch(ctx context.Context, dest net.Destination) (*transport.Link, error) {
 	if !isInternalDomain(dest) {
 		ctx = session.ContextWithInbound(ctx, &session.Inbound{
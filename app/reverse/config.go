@@ -1,7 +1,9 @@
 package reverse
 
 import (
+	"crypto/hmac"
 	"crypto/rand"
+	"crypto/sha256"
 	"io"
 
 	"github.com/v2fly/v2ray-core/v5/common/dice"
@@ -12,3 +14,16 @@ func (c *Control) FillInRandom() {
 	c.Random = make([]byte, randomLength)
 	io.ReadFull(rand.Reader, c.Random)
 }
+
+func newChallenge() []byte {
+	challenge := make([]byte, 32)
+	io.ReadFull(rand.Reader, challenge)
+	return challenge
+}
+
+// computeResponse returns the response to a portal challenge with the given secret.
+func computeResponse(secret []byte, challenge []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(challenge)
+	return mac.Sum(nil)
+}
@@ -2,6 +2,8 @@ package reverse
 
 import (
 	"context"
+	"crypto/hmac"
+	"math"
 	"sync"
 	"time"
 
@@ -12,8 +14,10 @@ import (
 	"github.com/v2fly/v2ray-core/v5/common/mux"
 	"github.com/v2fly/v2ray-core/v5/common/net"
 	"github.com/v2fly/v2ray-core/v5/common/session"
+	"github.com/v2fly/v2ray-core/v5/common/signal/done"
 	"github.com/v2fly/v2ray-core/v5/common/task"
 	"github.com/v2fly/v2ray-core/v5/features/outbound"
+	"github.com/v2fly/v2ray-core/v5/features/stats"
 	"github.com/v2fly/v2ray-core/v5/transport"
 	"github.com/v2fly/v2ray-core/v5/transport/pipe"
 )
@@ -21,13 +25,18 @@ import (
 type Portal struct {
 	ctx    context.Context
 	ohm    outbound.Manager
+	stats  stats.Manager
 	tag    string
 	domain string
+	secret []byte
 	picker *StaticMuxPicker
 	client *mux.ClientManager
 }
 
-func NewPortal(ctx context.Context, config *PortalConfig, ohm outbound.Manager) (*Portal, error) {
+// authTimeout is the time a bridge has to respond to the challenge of a portal with a secret.
+const authTimeout = time.Second * 16
+
+func NewPortal(ctx context.Context, config *PortalConfig, ohm outbound.Manager, sm stats.Manager) (*Portal, error) {
 	if config.Tag == "" {
 		return nil, newError("portal tag is empty")
 	}
@@ -44,8 +53,10 @@ func NewPortal(ctx context.Context, config *PortalConfig, ohm outbound.Manager)
 	return &Portal{
 		ctx:    ctx,
 		ohm:    ohm,
+		stats:  sm,
 		tag:    config.Tag,
 		domain: config.Domain,
+		secret: []byte(config.Secret),
 		picker: picker,
 		client: &mux.ClientManager{
 			Picker: picker,
@@ -71,7 +82,11 @@ func (p *Portal) HandleConnection(ctx context.Context, link *transport.Link) err
 	}
 
 	if isDomain(outboundMeta.Target, p.domain) {
-		muxClient, err := mux.NewClientWorker(*link, mux.ClientStrategy{})
+		traffic := &bridgeTraffic{}
+		muxClient, err := mux.NewClientWorker(transport.Link{
+			Reader: &trafficReader{Reader: link.Reader, traffic: traffic},
+			Writer: &trafficWriter{Writer: link.Writer, traffic: traffic},
+		}, mux.ClientStrategy{})
 		if err != nil {
 			return newError("failed to create mux client worker").Base(err).AtWarning()
 		}
@@ -81,13 +96,142 @@ func (p *Portal) HandleConnection(ctx context.Context, link *transport.Link) err
 			return newError("failed to create portal worker").Base(err)
 		}
 
-		p.picker.AddWorker(worker)
+		if len(p.secret) == 0 {
+			p.picker.AddWorker(worker)
+		}
+		go p.authenticate(ctx, link, worker, traffic)
 		return nil
 	}
 
 	return p.client.Dispatch(ctx, link)
 }
 
+// authenticate waits for the bridge to respond to the challenge of the worker, and drains the
+// control connection afterwards. Bridges that fail to authenticate in time are disconnected, if
+// the portal has a secret.
+func (p *Portal) authenticate(ctx context.Context, link *transport.Link, worker *PortalWorker, traffic *bridgeTraffic) {
+	disconnect := func() {
+		common.Interrupt(link.Reader)
+		common.Interrupt(link.Writer)
+	}
+
+	if len(p.secret) > 0 {
+		timer := time.AfterFunc(authTimeout, func() {
+			if !worker.authenticated.Done() {
+				newError("bridge failed to authenticate in time").AtWarning().WriteToLog(session.ExportIDToError(ctx))
+				disconnect()
+			}
+		})
+		defer timer.Stop()
+	}
+
+	for {
+		ctl, err := worker.readControl()
+		if err != nil {
+			return
+		}
+		if ctl == nil || len(ctl.Response) == 0 || worker.authenticated.Done() {
+			continue
+		}
+		if len(p.secret) > 0 && !hmac.Equal(ctl.Response, computeResponse(p.secret, worker.challenge)) {
+			newError("failed to authenticate bridge ", ctl.BridgeName).AtWarning().WriteToLog(session.ExportIDToError(ctx))
+			disconnect()
+			return
+		}
+		worker.authenticated.Close()
+		newError("bridge ", ctl.BridgeName, " connected to portal ", p.tag).AtInfo().WriteToLog(session.ExportIDToError(ctx))
+
+		if ctl.BridgeName != "" {
+			traffic.setCounters(p.getBridgeCounters(ctl.BridgeName))
+		}
+		if len(p.secret) > 0 {
+			p.picker.AddWorker(worker)
+		}
+		p.picker.setBridge(worker, ctl.BridgeName)
+	}
+}
+
+func (p *Portal) getBridgeCounters(bridge string) (stats.Counter, stats.Counter) {
+	prefix := "portal>>>" + p.tag + ">>>bridge>>>" + bridge + ">>>traffic>>>"
+	uplink, _ := stats.GetOrRegisterCounter(p.stats, prefix+"uplink")
+	downlink, _ := stats.GetOrRegisterCounter(p.stats, prefix+"downlink")
+	return uplink, downlink
+}
+
+// bridgeTraffic counts the traffic of a bridge connection. Traffic before the name of the bridge
+// is known is added to the counters once they are set.
+type bridgeTraffic struct {
+	access          sync.Mutex
+	uplinkCounter   stats.Counter
+	downlinkCounter stats.Counter
+	uplink          int64
+	downlink        int64
+}
+
+func (t *bridgeTraffic) setCounters(uplink, downlink stats.Counter) {
+	t.access.Lock()
+	defer t.access.Unlock()
+
+	t.uplinkCounter = uplink
+	t.downlinkCounter = downlink
+	t.addLocked(0, 0)
+}
+
+func (t *bridgeTraffic) add(uplink, downlink int64) {
+	t.access.Lock()
+	defer t.access.Unlock()
+
+	t.addLocked(uplink, downlink)
+}
+
+func (t *bridgeTraffic) addLocked(uplink, downlink int64) {
+	t.uplink += uplink
+	t.downlink += downlink
+	if t.uplinkCounter != nil {
+		t.uplinkCounter.Add(t.uplink)
+		t.uplink = 0
+	}
+	if t.downlinkCounter != nil {
+		t.downlinkCounter.Add(t.downlink)
+		t.downlink = 0
+	}
+}
+
+// trafficWriter counts the traffic sent to a bridge.
+type trafficWriter struct {
+	buf.Writer
+	traffic *bridgeTraffic
+}
+
+func (w *trafficWriter) WriteMultiBuffer(mb buf.MultiBuffer) error {
+	w.traffic.add(int64(mb.Len()), 0)
+	return w.Writer.WriteMultiBuffer(mb)
+}
+
+func (w *trafficWriter) Close() error {
+	return common.Close(w.Writer)
+}
+
+func (w *trafficWriter) Interrupt() {
+	common.Interrupt(w.Writer)
+}
+
+// trafficReader counts the traffic received from a bridge.
+type trafficReader struct {
+	buf.Reader
+	traffic *bridgeTraffic
+}
+
+func (r *trafficReader) ReadMultiBuffer() (buf.MultiBuffer, error) {
+	mb, err := r.Reader.ReadMultiBuffer()
+	r.traffic.add(0, int64(mb.Len()))
+	return mb, err
+}
+
+func (r *trafficReader) Interrupt() {
+	common.Interrupt(r.Reader)
+}
+
 type Outbound struct {
 	portal *Portal
 	tag    string
@@ -146,6 +290,8 @@ func (p *StaticMuxPicker) cleanup() error {
 	return nil
 }
 
+// PickAvailable returns the worker with the least active connections on the bridge with the least
+// active connections.
 func (p *StaticMuxPicker) PickAvailable() (*mux.ClientWorker, error) {
 	p.access.Lock()
 	defer p.access.Unlock()
@@ -154,14 +300,24 @@ func (p *StaticMuxPicker) PickAvailable() (*mux.ClientWorker, error) {
 		return nil, newError("empty worker list")
 	}
 
+	bridgeConn := make(map[interface{}]uint32)
+	for _, w := range p.workers {
+		if !w.draining {
+			bridgeConn[w.bridgeKey()] += w.client.ActiveConnections()
+		}
+	}
+
 	minIdx := -1
-	var minConn uint32 = 9999
+	var minConn uint32 = math.MaxUint32
+	var minBridgeConn uint32 = math.MaxUint32
 	for i, w := range p.workers {
 		if w.draining {
 			continue
 		}
-		if w.client.ActiveConnections() < minConn {
-			minConn = w.client.ActiveConnections()
+		conn := w.client.ActiveConnections()
+		if b := bridgeConn[w.bridgeKey()]; b < minBridgeConn || (b == minBridgeConn && conn < minConn) {
+			minBridgeConn = b
+			minConn = conn
 			minIdx = i
 		}
 	}
@@ -192,12 +348,25 @@ func (p *StaticMuxPicker) AddWorker(worker *PortalWorker) {
 	p.workers = append(p.workers, worker)
 }
 
+func (p *StaticMuxPicker) setBridge(worker *PortalWorker, bridge string) {
+	p.access.Lock()
+	defer p.access.Unlock()
+
+	worker.bridge = bridge
+}
+
 type PortalWorker struct {
 	client   *mux.ClientWorker
 	control  *task.Periodic
 	writer   buf.Writer
 	reader   buf.Reader
 	draining bool
+
+	// bridge is the name reported by the bridge. Guarded by the picker.
+	bridge        string
+	challenge     []byte
+	authenticated *done.Instance
+	pending       buf.MultiBuffer
 }
 
 func NewPortalWorker(ctx context.Context, client *mux.ClientWorker) (*PortalWorker, error) {
@@ -216,9 +385,11 @@ func NewPortalWorker(ctx context.Context, client *mux.ClientWorker) (*PortalWork
 		return nil, newError("unable to dispatch control connection")
 	}
 	w := &PortalWorker{
-		client: client,
-		reader: downlinkReader,
-		writer: uplinkWriter,
+		client:        client,
+		reader:        downlinkReader,
+		writer:        uplinkWriter,
+		challenge:     newChallenge(),
+		authenticated: done.New(),
 	}
 	w.control = &task.Periodic{
 		Execute:  w.heartbeat,
@@ -239,6 +410,9 @@ func (w *PortalWorker) heartbeat() error {
 
 	msg := &Control{}
 	msg.FillInRandom()
+	if !w.authenticated.Done() {
+		msg.Challenge = w.challenge
+	}
 
 	if w.client.TotalConnections() > 256 {
 		w.draining = true
@@ -257,6 +431,35 @@ func (w *PortalWorker) heartbeat() error {
 	return w.writer.WriteMultiBuffer(mb)
 }
 
+func (w *PortalWorker) readControl() (*Control, error) {
+	for w.pending.IsEmpty() {
+		mb, err := w.reader.ReadMultiBuffer()
+		if err != nil {
+			return nil, err
+		}
+		w.pending = mb
+	}
+
+	var b *buf.Buffer
+	w.pending, b = buf.SplitFirst(w.pending)
+	defer b.Release()
+
+	ctl := new(Control)
+	if err := proto.Unmarshal(b.Bytes(), ctl); err != nil {
+		newError("failed to parse proto message").Base(err).WriteToLog()
+		return nil, nil
+	}
+	return ctl, nil
+}
+
+// bridgeKey groups the workers of the same bridge. Workers of unnamed bridges are not grouped.
+func (w *PortalWorker) bridgeKey() interface{} {
+	if w.bridge == "" {
+		return w
+	}
+	return w.bridge
+}
+
 func (w *PortalWorker) IsFull() bool {
 	return w.client.IsFull()
 }
@@ -1,10 +1,19 @@
 package reverse_test
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/v2fly/v2ray-core/v5/app/reverse"
+	"github.com/v2fly/v2ray-core/v5/app/stats"
 	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/errors"
+	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/common/session"
+	"github.com/v2fly/v2ray-core/v5/features/routing"
+	"github.com/v2fly/v2ray-core/v5/transport"
+	"github.com/v2fly/v2ray-core/v5/transport/pipe"
 )
 
 func TestStaticPickerEmpty(t *testing.T) {
@@ -18,3 +27,76 @@ func TestStaticPickerEmpty(t *testing.T) {
 		t.Error("expected nil worker, but not nil")
 	}
 }
+
+type portalDispatcher struct {
+	portal *reverse.Portal
+}
+
+func (portalDispatcher) Type() interface{} {
+	return routing.DispatcherType()
+}
+
+func (portalDispatcher) Start() error {
+	return nil
+}
+
+func (portalDispatcher) Close() error {
+	return nil
+}
+
+func (d portalDispatcher) Dispatch(ctx context.Context, dest net.Destination) (*transport.Link, error) {
+	uplinkReader, uplinkWriter := pipe.New(pipe.WithSizeLimit(64 * 1024))
+	downlinkReader, downlinkWriter := pipe.New(pipe.WithSizeLimit(64 * 1024))
+	ctx = session.ContextWithOutbound(ctx, &session.Outbound{Target: dest})
+	if err := d.portal.HandleConnection(ctx, &transport.Link{Reader: uplinkReader, Writer: downlinkWriter}); err != nil {
+		return nil, err
+	}
+	return &transport.Link{Reader: downlinkReader, Writer: uplinkWriter}, nil
+}
+
+func (d portalDispatcher) DispatchLink(ctx context.Context, dest net.Destination, link *transport.Link) error {
+	return errors.New("not implemented")
+}
+
+func TestPortalAuthentication(t *testing.T) {
+	testCases := []struct {
+		portalSecret string
+		bridgeSecret string
+		accepted     bool
+	}{
+		{portalSecret: "secret", bridgeSecret: "secret", accepted: true},
+		{portalSecret: "secret", bridgeSecret: "wrong", accepted: false},
+		{portalSecret: "", bridgeSecret: "any", accepted: true},
+	}
+
+	for _, tc := range testCases {
+		statsManager, err := stats.NewManager(context.Background(), &stats.Config{})
+		common.Must(err)
+
+		portal, err := reverse.NewPortal(context.Background(), &reverse.PortalConfig{
+			Tag:    "portal",
+			Domain: "reverse.v2fly.org",
+			Secret: tc.portalSecret,
+		}, nil, statsManager)
+		common.Must(err)
+
+		bridge, err := reverse.NewBridge(context.Background(), &reverse.BridgeConfig{
+			Tag:    "bridge",
+			Domain: "reverse.v2fly.org",
+			Name:   "office",
+			Secret: tc.bridgeSecret,
+		}, portalDispatcher{portal: portal})
+		common.Must(err)
+		common.Must(bridge.Start())
+
+		time.Sleep(time.Millisecond * 500)
+		counter := statsManager.GetCounter("portal>>>portal>>>bridge>>>office>>>traffic>>>uplink")
+		if tc.accepted && counter == nil {
+			t.Error("expected bridge to be accepted with secret ", tc.bridgeSecret)
+		}
+		if !tc.accepted && counter != nil {
+			t.Error("expected bridge to be rejected with secret ", tc.bridgeSecret)
+		}
+		common.Must(bridge.Close())
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/v2fly/v2ray-core/v5/common/net"
 	"github.com/v2fly/v2ray-core/v5/features/outbound"
 	"github.com/v2fly/v2ray-core/v5/features/routing"
+	"github.com/v2fly/v2ray-core/v5/features/stats"
 )
 
 const (
@@ -28,8 +29,8 @@ func isInternalDomain(dest net.Destination) bool {
 func init() {
 	common.Must(common.RegisterConfig((*Config)(nil), func(ctx context.Context, config interface{}) (interface{}, error) {
 		r := new(Reverse)
-		if err := core.RequireFeatures(ctx, func(d routing.Dispatcher, om outbound.Manager) error {
-			return r.Init(ctx, config.(*Config), d, om)
+		if err := core.RequireFeatures(ctx, func(d routing.Dispatcher, om outbound.Manager, sm stats.Manager) error {
+			return r.Init(ctx, config.(*Config), d, om, sm)
 		}); err != nil {
 			return nil, err
 		}
@@ -42,7 +43,7 @@ type Reverse struct {
 	portals []*Portal
 }
 
-func (r *Reverse) Init(ctx context.Context, config *Config, d routing.Dispatcher, ohm outbound.Manager) error {
+func (r *Reverse) Init(ctx context.Context, config *Config, d routing.Dispatcher, ohm outbound.Manager, sm stats.Manager) error {
 	for _, bConfig := range config.BridgeConfig {
 		b, err := NewBridge(ctx, bConfig, d)
 		if err != nil {
@@ -52,7 +53,7 @@ func (r *Reverse) Init(ctx context.Context, config *Config, d routing.Dispatcher
 	}
 
 	for _, pConfig := range config.PortalConfig {
-		p, err := NewPortal(ctx, pConfig, ohm)
+		p, err := NewPortal(ctx, pConfig, ohm, sm)
 		if err != nil {
 			return err
 		}
@@ -5,6 +5,7 @@ package router
 
 import (
 	"context"
+	"sort"
 
 	"github.com/v2fly/v2ray-core/v5/features/extension"
 	"github.com/v2fly/v2ray-core/v5/features/outbound"
@@ -85,6 +86,16 @@ func (r *Router) GetPrincipleTarget(tag string) ([]string, error) {
 	return nil, newError("cannot find tag")
 }
 
+// ListBalancers implements routing.BalancerLister
+func (r *Router) ListBalancers() []string {
+	tags := make([]string, 0, len(r.balancers))
+	for tag := range r.balancers {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
 // SetOverrideTarget implements routing.BalancerOverrider
 func (r *Router) SetOverrideTarget(tag, target string) error {
 	if b, ok := r.balancers[tag]; ok {
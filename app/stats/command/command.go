@@ -5,13 +5,17 @@ package command
 import (
 	"context"
 	"runtime"
+	"sort"
 	"time"
 
 	grpc "google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
 
 	core "github.com/v2fly/v2ray-core/v5"
 	"github.com/v2fly/v2ray-core/v5/app/stats"
 	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/serial"
 	"github.com/v2fly/v2ray-core/v5/common/strmatcher"
 	feature_stats "github.com/v2fly/v2ray-core/v5/features/stats"
 )
@@ -113,6 +117,173 @@ func (s *statsServer) GetSysStats(ctx context.Context, request *SysStatsRequest)
 	return response, nil
 }
 
+// newNameMatcher returns the matcher of names of counters by the patterns.
+func newNameMatcher(patterns []string, regexp bool) (*strmatcher.LinearIndexMatcher, error) {
+	mgroup := &strmatcher.LinearIndexMatcher{}
+	t := strmatcher.Substr
+	if regexp {
+		t = strmatcher.Regex
+	}
+	for _, p := range patterns {
+		m, err := t.New(p)
+		if err != nil {
+			return nil, err
+		}
+		mgroup.Add(m)
+	}
+	return mgroup, nil
+}
+
+func (s *statsServer) QueryHistory(ctx context.Context, request *QueryHistoryRequest) (*QueryHistoryResponse, error) {
+	mgroup, err := newNameMatcher(request.Patterns, request.Regexp)
+	if err != nil {
+		return nil, err
+	}
+
+	manager, ok := s.stats.(*stats.Manager)
+	if !ok {
+		return nil, newError("QueryHistory only works its own stats.Manager.")
+	}
+
+	var resolution stats.Resolution
+	switch request.Resolution {
+	case QueryHistoryRequest_Minute:
+		resolution = stats.Minute
+	case QueryHistoryRequest_Hour:
+		resolution = stats.Hour
+	case QueryHistoryRequest_Day:
+		resolution = stats.Day
+	default:
+		return nil, newError("unknown resolution: ", request.Resolution)
+	}
+	var since time.Time
+	if request.Since > 0 {
+		since = time.Unix(request.Since, 0)
+	}
+
+	response := &QueryHistoryResponse{}
+	manager.VisitHistories(func(name string, h *stats.History) bool {
+		if mgroup.Size() == 0 || len(mgroup.Match(name)) > 0 {
+			history := &History{
+				Name: name,
+			}
+			for _, p := range h.Points(resolution, since) {
+				history.Points = append(history.Points, &HistoryPoint{
+					Time:  p.Time.Unix(),
+					Value: p.Value,
+				})
+			}
+			response.History = append(response.History, history)
+		}
+		return true
+	})
+	sort.Slice(response.History, func(i, j int) bool {
+		return response.History[i].Name < response.History[j].Name
+	})
+
+	return response, nil
+}
+
+func (s *statsServer) SubscribeStats(request *SubscribeStatsRequest, stream StatsService_SubscribeStatsServer) error {
+	mgroup, err := newNameMatcher(request.Patterns, request.Regexp)
+	if err != nil {
+		return err
+	}
+	manager, ok := s.stats.(*stats.Manager)
+	if !ok {
+		return newError("SubscribeStats only works its own stats.Manager.")
+	}
+	interval := time.Duration(request.Interval) * time.Second
+	if interval == 0 {
+		interval = time.Second
+	}
+
+	// Deltas are computed from the last values of counters, without resetting them. Counters
+	// reset by others in an interval, such as by GetStats with reset, lose the traffic counted
+	// before the reset. A reset is only noticed if the counter is below its last value, otherwise
+	// the delta is short by the last value.
+	last := make(map[string]int64)
+	collect := func() []*Stat {
+		var deltas []*Stat
+		current := make(map[string]int64, len(last))
+		manager.VisitCounters(func(name string, c feature_stats.Counter) bool {
+			if mgroup.Size() > 0 && len(mgroup.Match(name)) == 0 {
+				return true
+			}
+			value := c.Value()
+			current[name] = value
+			delta := value - last[name]
+			if delta < 0 {
+				// The counter was reset in the interval. Only the traffic since the reset is known.
+				delta = value
+			}
+			if delta != 0 {
+				deltas = append(deltas, &Stat{
+					Name:  name,
+					Value: delta,
+				})
+			}
+			return true
+		})
+		last = current
+		sort.Slice(deltas, func(i, j int) bool {
+			return deltas[i].Name < deltas[j].Name
+		})
+		return deltas
+	}
+	collect()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case now := <-ticker.C:
+			if err := stream.Send(&SubscribeStatsResponse{
+				Time: now.Unix(),
+				Stat: collect(),
+			}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *statsServer) SubscribeChannel(request *SubscribeChannelRequest, stream StatsService_SubscribeChannelServer) error {
+	c := s.stats.GetChannel(request.Name)
+	if c == nil {
+		return newError("channel ", request.Name, " not found.")
+	}
+	subscriber, err := feature_stats.SubscribeRunnableChannel(c)
+	if err != nil {
+		return newError("failed to subscribe channel ", request.Name).Base(err)
+	}
+	defer feature_stats.UnsubscribeClosableChannel(c, subscriber)
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case msg, ok := <-subscriber:
+			if !ok {
+				return nil
+			}
+			response := &SubscribeChannelResponse{}
+			if m, ok := msg.(proto.Message); ok {
+				if response.Message, err = anypb.New(m); err != nil {
+					return newError("failed to marshal message").Base(err)
+				}
+			} else {
+				response.Text = serial.ToString(msg)
+			}
+			if err := stream.Send(response); err != nil {
+				return err
+			}
+		}
+	}
+}
+
 func (s *statsServer) mustEmbedUnimplementedStatsServiceServer() {}
 
 type service struct {
@@ -25,6 +25,9 @@ type StatsServiceClient interface {
 	GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*GetStatsResponse, error)
 	QueryStats(ctx context.Context, in *QueryStatsRequest, opts ...grpc.CallOption) (*QueryStatsResponse, error)
 	GetSysStats(ctx context.Context, in *SysStatsRequest, opts ...grpc.CallOption) (*SysStatsResponse, error)
+	QueryHistory(ctx context.Context, in *QueryHistoryRequest, opts ...grpc.CallOption) (*QueryHistoryResponse, error)
+	SubscribeStats(ctx context.Context, in *SubscribeStatsRequest, opts ...grpc.CallOption) (StatsService_SubscribeStatsClient, error)
+	SubscribeChannel(ctx context.Context, in *SubscribeChannelRequest, opts ...grpc.CallOption) (StatsService_SubscribeChannelClient, error)
 }
 
 type statsServiceClient struct {
@@ -62,6 +65,79 @@ func (c *statsServiceClient) GetSysStats(ctx context.Context, in *SysStatsReques
 	return out, nil
 }
 
+func (c *statsServiceClient) QueryHistory(ctx context.Context, in *QueryHistoryRequest, opts ...grpc.CallOption) (*QueryHistoryResponse, error) {
+	out := new(QueryHistoryResponse)
+	err := c.cc.Invoke(ctx, "/v2ray.core.app.stats.command.StatsService/QueryHistory", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *statsServiceClient) SubscribeStats(ctx context.Context, in *SubscribeStatsRequest, opts ...grpc.CallOption) (StatsService_SubscribeStatsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &StatsService_ServiceDesc.Streams[0], "/v2ray.core.app.stats.command.StatsService/SubscribeStats", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &statsServiceSubscribeStatsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type StatsService_SubscribeStatsClient interface {
+	Recv() (*SubscribeStatsResponse, error)
+	grpc.ClientStream
+}
+
+type statsServiceSubscribeStatsClient struct {
+	grpc.ClientStream
+}
+
+func (x *statsServiceSubscribeStatsClient) Recv() (*SubscribeStatsResponse, error) {
+	m := new(SubscribeStatsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *statsServiceClient) SubscribeChannel(ctx context.Context, in *SubscribeChannelRequest, opts ...grpc.CallOption) (StatsService_SubscribeChannelClient, error) {
+	stream, err := c.cc.NewStream(ctx, &StatsService_ServiceDesc.Streams[1], "/v2ray.core.app.stats.command.StatsService/SubscribeChannel", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &statsServiceSubscribeChannelClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type StatsService_SubscribeChannelClient interface {
+	Recv() (*SubscribeChannelResponse, error)
+	grpc.ClientStream
+}
+
+type statsServiceSubscribeChannelClient struct {
+	grpc.ClientStream
+}
+
+func (x *statsServiceSubscribeChannelClient) Recv() (*SubscribeChannelResponse, error) {
+	m := new(SubscribeChannelResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // StatsServiceServer is the server API for StatsService service.
 // All implementations must embed UnimplementedStatsServiceServer
 // for forward compatibility
@@ -69,6 +145,9 @@ type StatsServiceServer interface {
 	GetStats(context.Context, *GetStatsRequest) (*GetStatsResponse, error)
 	QueryStats(context.Context, *QueryStatsRequest) (*QueryStatsResponse, error)
 	GetSysStats(context.Context, *SysStatsRequest) (*SysStatsResponse, error)
+	QueryHistory(context.Context, *QueryHistoryRequest) (*QueryHistoryResponse, error)
+	SubscribeStats(*SubscribeStatsRequest, StatsService_SubscribeStatsServer) error
+	SubscribeChannel(*SubscribeChannelRequest, StatsService_SubscribeChannelServer) error
 	mustEmbedUnimplementedStatsServiceServer()
 }
 
@@ -85,6 +164,15 @@ func (UnimplementedStatsServiceServer) QueryStats(context.Context, *QueryStatsRe
 func (UnimplementedStatsServiceServer) GetSysStats(context.Context, *SysStatsRequest) (*SysStatsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetSysStats not implemented")
 }
+func (UnimplementedStatsServiceServer) QueryHistory(context.Context, *QueryHistoryRequest) (*QueryHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method QueryHistory not implemented")
+}
+func (UnimplementedStatsServiceServer) SubscribeStats(*SubscribeStatsRequest, StatsService_SubscribeStatsServer) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeStats not implemented")
+}
+func (UnimplementedStatsServiceServer) SubscribeChannel(*SubscribeChannelRequest, StatsService_SubscribeChannelServer) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeChannel not implemented")
+}
 func (UnimplementedStatsServiceServer) mustEmbedUnimplementedStatsServiceServer() {}
 
 // UnsafeStatsServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -152,6 +240,66 @@ func _StatsService_GetSysStats_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _StatsService_QueryHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StatsServiceServer).QueryHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v2ray.core.app.stats.command.StatsService/QueryHistory",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StatsServiceServer).QueryHistory(ctx, req.(*QueryHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StatsService_SubscribeStats_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeStatsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(StatsServiceServer).SubscribeStats(m, &statsServiceSubscribeStatsServer{stream})
+}
+
+type StatsService_SubscribeStatsServer interface {
+	Send(*SubscribeStatsResponse) error
+	grpc.ServerStream
+}
+
+type statsServiceSubscribeStatsServer struct {
+	grpc.ServerStream
+}
+
+func (x *statsServiceSubscribeStatsServer) Send(m *SubscribeStatsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _StatsService_SubscribeChannel_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeChannelRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(StatsServiceServer).SubscribeChannel(m, &statsServiceSubscribeChannelServer{stream})
+}
+
+type StatsService_SubscribeChannelServer interface {
+	Send(*SubscribeChannelResponse) error
+	grpc.ServerStream
+}
+
+type statsServiceSubscribeChannelServer struct {
+	grpc.ServerStream
+}
+
+func (x *statsServiceSubscribeChannelServer) Send(m *SubscribeChannelResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 // StatsService_ServiceDesc is the grpc.ServiceDesc for StatsService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -171,7 +319,22 @@ var StatsService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetSysStats",
 			Handler:    _StatsService_GetSysStats_Handler,
 		},
+		{
+			MethodName: "QueryHistory",
+			Handler:    _StatsService_QueryHistory_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SubscribeStats",
+			Handler:       _StatsService_SubscribeStats_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "SubscribeChannel",
+			Handler:       _StatsService_SubscribeChannel_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "app/stats/command/command.proto",
 }
@@ -2,10 +2,14 @@ package command_test
 
 import (
 	"context"
+	"net"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
 
 	"github.com/v2fly/v2ray-core/v5/app/stats"
 	. "github.com/v2fly/v2ray-core/v5/app/stats/command"
@@ -90,3 +94,108 @@ func TestQueryStats(t *testing.T) {
 		t.Error(r)
 	}
 }
+
+func TestQueryHistory(t *testing.T) {
+	m, err := stats.NewManager(context.Background(), &stats.Config{
+		History: &stats.HistoryConfig{},
+	})
+	common.Must(err)
+
+	sc1, err := m.RegisterCounter("test_counter")
+	common.Must(err)
+	sc1.Add(1)
+
+	sc2, err := m.RegisterCounter("test_counter_2")
+	common.Must(err)
+	sc2.Add(2)
+
+	s := NewStatsServer(m)
+	resp, err := s.QueryHistory(context.Background(), &QueryHistoryRequest{
+		Patterns:   []string{"counter_2"},
+		Resolution: QueryHistoryRequest_Hour,
+	})
+	common.Must(err)
+	if len(resp.History) != 1 || resp.History[0].Name != "test_counter_2" {
+		t.Fatal("unexpected histories: ", resp.History)
+	}
+	if points := resp.History[0].Points; len(points) != 1 || points[0].Value != 2 {
+		t.Error("unexpected points: ", points)
+	}
+
+	if _, err := s.QueryHistory(context.Background(), &QueryHistoryRequest{Resolution: 3}); err == nil {
+		t.Error("expected error of unknown resolution")
+	}
+}
+
+func TestSubscribeStats(t *testing.T) {
+	m, err := stats.NewManager(context.Background(), &stats.Config{})
+	common.Must(err)
+	common.Must(m.Start())
+	defer m.Close()
+
+	sc1, err := m.RegisterCounter("user>>>a>>>traffic>>>uplink")
+	common.Must(err)
+	sc1.Add(100)
+	sc2, err := m.RegisterCounter("inbound>>>in>>>traffic>>>uplink")
+	common.Must(err)
+	ch, err := m.RegisterChannel("routing")
+	common.Must(err)
+
+	lis := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	RegisterStatsServiceServer(server, NewStatsServer(m))
+	go server.Serve(lis)
+	defer server.Stop()
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet", grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+		return lis.Dial()
+	}), grpc.WithInsecure())
+	common.Must(err)
+	defer conn.Close()
+	client := NewStatsServiceClient(conn)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	statsStream, err := client.SubscribeStats(ctx, &SubscribeStatsRequest{
+		Patterns: []string{"user>>>"},
+		Interval: 1,
+	})
+	common.Must(err)
+	channelStream, err := client.SubscribeChannel(ctx, &SubscribeChannelRequest{Name: "routing"})
+	common.Must(err)
+
+	// The first response follows the last values being taken, so that later traffic is in the next one.
+	resp, err := statsStream.Recv()
+	common.Must(err)
+	if len(resp.Stat) != 0 {
+		t.Error("unexpected stats: ", resp.Stat)
+	}
+
+	sc1.Add(20)
+	sc1.Set(0)
+	sc1.Add(10)
+	sc2.Add(30)
+	resp, err = statsStream.Recv()
+	common.Must(err)
+	if r := cmp.Diff(resp.Stat, []*Stat{{Name: "user>>>a>>>traffic>>>uplink", Value: 10}}, cmpopts.IgnoreUnexported(Stat{})); r != "" {
+		t.Error(r)
+	}
+
+	for len(ch.Subscribers()) == 0 {
+		time.Sleep(10 * time.Millisecond)
+	}
+	ch.Publish(context.Background(), &Stat{Name: "published"})
+	ch.Publish(context.Background(), "text")
+	msg, err := channelStream.Recv()
+	common.Must(err)
+	stat := new(Stat)
+	common.Must(msg.Message.UnmarshalTo(stat))
+	if stat.Name != "published" {
+		t.Error("unexpected channel message: ", stat)
+	}
+	msg, err = channelStream.Recv()
+	common.Must(err)
+	if msg.Text != "text" {
+		t.Error("unexpected channel message: ", msg)
+	}
+}
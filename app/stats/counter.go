@@ -4,7 +4,8 @@ import "sync/atomic"
 
 // Counter is an implementation of stats.Counter.
 type Counter struct {
-	value int64
+	value   int64
+	history *History
 }
 
 // Value implements stats.Counter.
@@ -19,5 +20,8 @@ func (c *Counter) Set(newValue int64) int64 {
 
 // Add implements stats.Counter.
 func (c *Counter) Add(delta int64) int64 {
+	if c.history != nil {
+		c.history.add(delta)
+	}
 	return atomic.AddInt64(&c.value, delta)
 }
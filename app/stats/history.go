@@ -0,0 +1,126 @@
+package stats
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Resolution is the duration of the buckets of a history.
+type Resolution int
+
+const (
+	Minute Resolution = iota
+	Hour
+	Day
+)
+
+func (r Resolution) duration() time.Duration {
+	switch r {
+	case Hour:
+		return time.Hour
+	case Day:
+		return 24 * time.Hour
+	default:
+		return time.Minute
+	}
+}
+
+// Point is the traffic added to a counter in a bucket starting at Time.
+type Point struct {
+	Time  time.Time
+	Value int64
+}
+
+// series is the buckets of a resolution, in the order of time. Buckets without traffic are omitted.
+type series struct {
+	resolution Resolution
+	size       int
+	points     []Point
+}
+
+func (s *series) add(now time.Time, delta int64) {
+	start := now.Truncate(s.resolution.duration())
+	if n := len(s.points); n > 0 && s.points[n-1].Time.Equal(start) {
+		s.points[n-1].Value += delta
+	} else {
+		s.points = append(s.points, Point{Time: start, Value: delta})
+	}
+	s.expire(now)
+}
+
+// expire removes the buckets that are older than the retention of the series.
+func (s *series) expire(now time.Time) {
+	oldest := now.Truncate(s.resolution.duration()).Add(-time.Duration(s.size-1) * s.resolution.duration())
+	i := 0
+	for i < len(s.points) && s.points[i].Time.Before(oldest) {
+		i++
+	}
+	if i > 0 {
+		s.points = append(s.points[:0], s.points[i:]...)
+	}
+}
+
+// History keeps the traffic of a counter in buckets of each resolution. It is not affected by
+// resetting the counter. Buckets are aligned to UTC.
+type History struct {
+	// pending is the traffic not yet added to buckets.
+	pending int64
+
+	access sync.Mutex
+	series [3]*series
+}
+
+func newHistory(config *HistoryConfig) *History {
+	sizeOf := func(size uint32, defaultSize int) int {
+		if size == 0 {
+			return defaultSize
+		}
+		return int(size)
+	}
+	return &History{
+		series: [3]*series{
+			{resolution: Minute, size: sizeOf(config.Minutes, 60)},
+			{resolution: Hour, size: sizeOf(config.Hours, 48)},
+			{resolution: Day, size: sizeOf(config.Days, 30)},
+		},
+	}
+}
+
+func (h *History) add(delta int64) {
+	atomic.AddInt64(&h.pending, delta)
+}
+
+// flush adds the pending traffic to the buckets of the time.
+func (h *History) flush(now time.Time) {
+	h.access.Lock()
+	defer h.access.Unlock()
+
+	delta := atomic.SwapInt64(&h.pending, 0)
+	for _, s := range h.series {
+		if delta != 0 {
+			s.add(now, delta)
+		} else {
+			s.expire(now)
+		}
+	}
+}
+
+// Points returns the buckets of the resolution starting at or after since.
+func (h *History) Points(resolution Resolution, since time.Time) []Point {
+	if resolution < Minute || resolution > Day {
+		return nil
+	}
+	h.flush(time.Now())
+
+	h.access.Lock()
+	defer h.access.Unlock()
+
+	var points []Point
+	for _, p := range h.series[resolution].points {
+		if !p.Time.Before(since) {
+			points = append(points, p)
+		}
+	}
+	return points
+}
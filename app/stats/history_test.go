@@ -0,0 +1,29 @@
+package stats
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSeriesRetention(t *testing.T) {
+	s := &series{resolution: Minute, size: 3}
+	start := time.Date(2022, 1, 1, 0, 0, 30, 0, time.UTC)
+
+	s.add(start, 1)
+	s.add(start.Add(10*time.Second), 2)
+	s.add(start.Add(time.Minute), 4)
+	s.add(start.Add(2*time.Minute), 8)
+	if len(s.points) != 3 || s.points[0].Value != 3 || !s.points[0].Time.Equal(start.Truncate(time.Minute)) {
+		t.Error("unexpected points: ", s.points)
+	}
+
+	s.add(start.Add(3*time.Minute), 16)
+	if len(s.points) != 3 || s.points[0].Value != 4 || s.points[2].Value != 16 {
+		t.Error("unexpected points: ", s.points)
+	}
+
+	s.expire(start.Add(10 * time.Minute))
+	if len(s.points) != 0 {
+		t.Error("expected all points expired, but got ", s.points)
+	}
+}
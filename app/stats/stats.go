@@ -5,9 +5,12 @@ package stats
 import (
 	"context"
 	"sync"
+	"time"
 
 	"github.com/v2fly/v2ray-core/v5/common"
 	"github.com/v2fly/v2ray-core/v5/common/errors"
+	"github.com/v2fly/v2ray-core/v5/common/strmatcher"
+	"github.com/v2fly/v2ray-core/v5/common/task"
 	"github.com/v2fly/v2ray-core/v5/features/stats"
 )
 
@@ -17,8 +20,15 @@ type Manager struct {
 	counters map[string]*Counter
 	channels map[string]*Channel
 	running  bool
+
+	history        *HistoryConfig
+	historyMatcher *strmatcher.LinearIndexMatcher
+	historyTask    *task.Periodic
 }
 
+// historyFlushInterval is the interval of adding traffic of counters to their histories.
+const historyFlushInterval = 10 * time.Second
+
 // NewManager creates an instance of Statistics Manager.
 func NewManager(ctx context.Context, config *Config) (*Manager, error) {
 	m := &Manager{
@@ -26,6 +36,26 @@ func NewManager(ctx context.Context, config *Config) (*Manager, error) {
 		channels: make(map[string]*Channel),
 	}
 
+	if config.History != nil {
+		m.history = config.History
+		m.historyMatcher = &strmatcher.LinearIndexMatcher{}
+		t := strmatcher.Substr
+		if config.History.Regexp {
+			t = strmatcher.Regex
+		}
+		for _, p := range config.History.Patterns {
+			matcher, err := t.New(p)
+			if err != nil {
+				return nil, newError("invalid history pattern: ", p).Base(err)
+			}
+			m.historyMatcher.Add(matcher)
+		}
+		m.historyTask = &task.Periodic{
+			Interval: historyFlushInterval,
+			Execute:  m.flushHistories,
+		}
+	}
+
 	return m, nil
 }
 
@@ -44,6 +74,9 @@ func (m *Manager) RegisterCounter(name string) (stats.Counter, error) {
 	}
 	newError("create new counter ", name).AtDebug().WriteToLog()
 	c := new(Counter)
+	if m.history != nil && (m.historyMatcher.Size() == 0 || len(m.historyMatcher.Match(name)) > 0) {
+		c.history = newHistory(m.history)
+	}
 	m.counters[name] = c
 	return c, nil
 }
@@ -83,6 +116,41 @@ func (m *Manager) VisitCounters(visitor func(string, stats.Counter) bool) {
 	}
 }
 
+// GetHistory returns the history of a counter, or nil if the counter has no history.
+func (m *Manager) GetHistory(name string) *History {
+	m.access.RLock()
+	defer m.access.RUnlock()
+
+	if c, found := m.counters[name]; found && c.history != nil {
+		return c.history
+	}
+	return nil
+}
+
+// VisitHistories calls visitor function on all counters with histories.
+func (m *Manager) VisitHistories(visitor func(string, *History) bool) {
+	m.access.RLock()
+	defer m.access.RUnlock()
+
+	for name, c := range m.counters {
+		if c.history == nil {
+			continue
+		}
+		if !visitor(name, c.history) {
+			break
+		}
+	}
+}
+
+func (m *Manager) flushHistories() error {
+	now := time.Now()
+	m.VisitHistories(func(_ string, h *History) bool {
+		h.flush(now)
+		return true
+	})
+	return nil
+}
+
 // RegisterChannel implements stats.Manager.
 func (m *Manager) RegisterChannel(name string) (stats.Channel, error) {
 	m.access.Lock()
@@ -126,6 +194,12 @@ func (m *Manager) GetChannel(name string) stats.Channel {
 
 // Start implements common.Runnable.
 func (m *Manager) Start() error {
+	if m.historyTask != nil {
+		if err := m.historyTask.Start(); err != nil {
+			return err
+		}
+	}
+
 	m.access.Lock()
 	defer m.access.Unlock()
 	m.running = true
@@ -143,6 +217,10 @@ func (m *Manager) Start() error {
 
 // Close implement common.Closable.
 func (m *Manager) Close() error {
+	if m.historyTask != nil {
+		common.Must(m.historyTask.Close())
+	}
+
 	m.access.Lock()
 	defer m.access.Unlock()
 	m.running = false
@@ -84,3 +84,40 @@ func TestStatsChannelRunnable(t *testing.T) {
 		t.Fatalf("unexpected running channel: test.channel.%d", 3)
 	}
 }
+
+func TestStatsHistory(t *testing.T) {
+	m, err := NewManager(context.Background(), &Config{
+		History: &HistoryConfig{
+			Patterns: []string{"user>>>"},
+		},
+	})
+	common.Must(err)
+
+	c1, err := m.RegisterCounter("user>>>a@v2fly.org>>>traffic>>>uplink")
+	common.Must(err)
+	c2, err := m.RegisterCounter("inbound>>>in>>>traffic>>>uplink")
+	common.Must(err)
+
+	if m.GetHistory("inbound>>>in>>>traffic>>>uplink") != nil {
+		t.Error("unexpected history of inbound counter")
+	}
+	h := m.GetHistory("user>>>a@v2fly.org>>>traffic>>>uplink")
+	if h == nil {
+		t.Fatal("expected history of user counter")
+	}
+
+	c1.Add(100)
+	c1.Set(0)
+	c1.Add(50)
+	c2.Add(10)
+
+	for _, resolution := range []Resolution{Minute, Hour, Day} {
+		points := h.Points(resolution, time.Time{})
+		if len(points) != 1 || points[0].Value != 150 {
+			t.Error("unexpected history of resolution ", resolution, ": ", points)
+		}
+	}
+	if points := h.Points(Minute, time.Now().Add(time.Minute)); len(points) != 0 {
+		t.Error("unexpected history in the future: ", points)
+	}
+}
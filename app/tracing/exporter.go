@@ -0,0 +1,157 @@
+package tracing
+
+import (
+	"context"
+	gonet "net"
+	"sync"
+	"time"
+
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+
+	core "github.com/v2fly/v2ray-core/v5"
+	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/common/task"
+	"github.com/v2fly/v2ray-core/v5/transport/internet"
+)
+
+const (
+	exportInterval = time.Second * 5
+	exportTimeout  = time.Second * 10
+	// maxQueuedSpans is the number of spans kept between exports. Further spans are dropped.
+	maxQueuedSpans = 4096
+)
+
+// exporter sends spans to the collector in batches, with the OTLP/gRPC protocol.
+type exporter struct {
+	ctx    context.Context
+	config *Config
+
+	access  sync.Mutex
+	spans   []*tracepb.Span
+	dropped int
+
+	conn   *grpc.ClientConn
+	client coltracepb.TraceServiceClient
+	task   *task.Periodic
+}
+
+func newExporter(ctx context.Context, config *Config) *exporter {
+	e := &exporter{
+		ctx:    ctx,
+		config: config,
+	}
+	e.task = &task.Periodic{
+		Interval: exportInterval,
+		Execute: func() error {
+			if err := e.export(); err != nil {
+				newError("failed to export spans").Base(err).AtWarning().WriteToLog()
+			}
+			return nil
+		},
+	}
+	return e
+}
+
+func (e *exporter) dial(_ context.Context, s string) (gonet.Conn, error) {
+	dest, err := net.ParseDestination("tcp:" + s)
+	if err != nil {
+		return nil, err
+	}
+	return internet.DialSystem(core.ToBackgroundDetachedContext(e.ctx), dest, nil)
+}
+
+func (e *exporter) start() error {
+	options := []grpc.DialOption{
+		grpc.WithContextDialer(e.dial),
+	}
+	if e.config.Insecure {
+		options = append(options, grpc.WithInsecure())
+	} else {
+		options = append(options, grpc.WithTransportCredentials(credentials.NewClientTLSFromCert(nil, "")))
+	}
+	conn, err := grpc.Dial(e.config.Endpoint, options...)
+	if err != nil {
+		return newError("failed to connect to the collector ", e.config.Endpoint).Base(err)
+	}
+	e.conn = conn
+	e.client = coltracepb.NewTraceServiceClient(conn)
+	return e.task.Start()
+}
+
+func (e *exporter) add(s *tracepb.Span) {
+	e.access.Lock()
+	defer e.access.Unlock()
+
+	if len(e.spans) >= maxQueuedSpans {
+		e.dropped++
+		return
+	}
+	e.spans = append(e.spans, s)
+}
+
+func (e *exporter) resource() *resourcepb.Resource {
+	serviceName := e.config.ServiceName
+	if serviceName == "" {
+		serviceName = "v2ray"
+	}
+	return &resourcepb.Resource{
+		Attributes: []*commonpb.KeyValue{
+			{Key: "service.name", Value: toAnyValue(serviceName)},
+			{Key: "service.version", Value: toAnyValue(core.Version())},
+		},
+	}
+}
+
+// export sends the queued spans to the collector.
+func (e *exporter) export() error {
+	e.access.Lock()
+	spans := e.spans
+	dropped := e.dropped
+	e.spans = nil
+	e.dropped = 0
+	e.access.Unlock()
+
+	if dropped > 0 {
+		newError(dropped, " spans are dropped as the export queue is full").AtWarning().WriteToLog()
+	}
+	if len(spans) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), exportTimeout)
+	defer cancel()
+	if len(e.config.Headers) > 0 {
+		ctx = metadata.NewOutgoingContext(ctx, metadata.New(e.config.Headers))
+	}
+	_, err := e.client.Export(ctx, &coltracepb.ExportTraceServiceRequest{
+		ResourceSpans: []*tracepb.ResourceSpans{{
+			Resource: e.resource(),
+			InstrumentationLibrarySpans: []*tracepb.InstrumentationLibrarySpans{{
+				InstrumentationLibrary: &commonpb.InstrumentationLibrary{
+					Name:    "github.com/v2fly/v2ray-core/v5",
+					Version: core.Version(),
+				},
+				Spans: spans,
+			}},
+		}},
+	})
+	return err
+}
+
+// close exports the remaining spans and closes the connection to the collector.
+func (e *exporter) close() error {
+	if e.conn == nil {
+		return nil
+	}
+	e.task.Close()
+	if err := e.export(); err != nil {
+		newError("failed to export spans").Base(err).AtWarning().WriteToLog()
+	}
+	return e.conn.Close()
+}
@@ -0,0 +1,199 @@
+package tracing
+
+//go:generate go run github.com/v2fly/v2ray-core/v5/common/errors/errorgen
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"sync"
+	"time"
+
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/serial"
+	"github.com/v2fly/v2ray-core/v5/features/tracing"
+)
+
+// Tracer records spans of connections, and exports them to an OpenTelemetry collector.
+type Tracer struct {
+	config   *Config
+	exporter *exporter
+}
+
+// Type implements common.HasType.
+func (t *Tracer) Type() interface{} {
+	return tracing.TracerType()
+}
+
+// sample returns whether a new trace is recorded, by the sample ratio.
+func (t *Tracer) sample(traceID [16]byte) bool {
+	ratio := t.config.SampleRatio
+	if ratio <= 0 || ratio >= 1 {
+		return true
+	}
+	// As the trace ID is random, its lower bits decide sampling evenly.
+	return float64(binary.BigEndian.Uint64(traceID[8:])>>11)/(1<<53) < float64(ratio)
+}
+
+// StartSpan implements tracing.Tracer.
+func (t *Tracer) StartSpan(ctx context.Context, name string) (context.Context, tracing.Span) {
+	parent, _ := tracing.SpanFromContext(ctx).(*span)
+	if parent != nil && !parent.sampled {
+		// Operations of connections that are not sampled are not recorded either.
+		return ctx, parent
+	}
+
+	s := &span{
+		tracer:  t,
+		sampled: true,
+		name:    name,
+		start:   time.Now(),
+	}
+	common.Must2(rand.Read(s.id[:]))
+	if parent != nil {
+		s.traceID = parent.traceID
+		s.parentID = parent.id[:]
+	} else {
+		common.Must2(rand.Read(s.traceID[:]))
+		s.sampled = t.sample(s.traceID)
+	}
+	return tracing.ContextWithSpan(ctx, s), s
+}
+
+// Start implements common.Runnable.
+func (t *Tracer) Start() error {
+	return t.exporter.start()
+}
+
+// Close implements common.Closable.
+func (t *Tracer) Close() error {
+	return t.exporter.close()
+}
+
+// span implements tracing.Span.
+type span struct {
+	tracer   *Tracer
+	sampled  bool
+	traceID  [16]byte
+	id       [8]byte
+	parentID []byte
+	name     string
+	start    time.Time
+
+	access     sync.Mutex
+	attributes []*commonpb.KeyValue
+	status     *tracepb.Status
+	ended      bool
+}
+
+func toAnyValue(value interface{}) *commonpb.AnyValue {
+	switch value := value.(type) {
+	case string:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: value}}
+	case bool:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_BoolValue{BoolValue: value}}
+	case int:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: int64(value)}}
+	case int32:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: int64(value)}}
+	case int64:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: value}}
+	case uint32:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: int64(value)}}
+	case float64:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_DoubleValue{DoubleValue: value}}
+	default:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: serial.ToString(value)}}
+	}
+}
+
+// SetAttribute implements tracing.Span.
+func (s *span) SetAttribute(key string, value interface{}) {
+	if !s.sampled {
+		return
+	}
+	s.access.Lock()
+	defer s.access.Unlock()
+
+	for _, attribute := range s.attributes {
+		if attribute.Key == key {
+			attribute.Value = toAnyValue(value)
+			return
+		}
+	}
+	s.attributes = append(s.attributes, &commonpb.KeyValue{Key: key, Value: toAnyValue(value)})
+}
+
+// RecordError implements tracing.Span.
+func (s *span) RecordError(err error) {
+	if !s.sampled || err == nil {
+		return
+	}
+	s.access.Lock()
+	defer s.access.Unlock()
+
+	s.status = &tracepb.Status{
+		Code:    tracepb.Status_STATUS_CODE_ERROR,
+		Message: err.Error(),
+	}
+}
+
+// IsRecording implements tracing.Span.
+func (s *span) IsRecording() bool {
+	return s.sampled
+}
+
+// End implements tracing.Span.
+func (s *span) End() {
+	if !s.sampled {
+		return
+	}
+	end := time.Now()
+	s.access.Lock()
+	if s.ended {
+		s.access.Unlock()
+		return
+	}
+	s.ended = true
+	kind := tracepb.Span_SPAN_KIND_INTERNAL
+	if s.parentID == nil {
+		kind = tracepb.Span_SPAN_KIND_SERVER
+	}
+	exported := &tracepb.Span{
+		TraceId:           s.traceID[:],
+		SpanId:            s.id[:],
+		ParentSpanId:      s.parentID,
+		Name:              s.name,
+		Kind:              kind,
+		StartTimeUnixNano: uint64(s.start.UnixNano()),
+		EndTimeUnixNano:   uint64(end.UnixNano()),
+		Attributes:        s.attributes,
+		Status:            s.status,
+	}
+	s.access.Unlock()
+
+	s.tracer.exporter.add(exported)
+}
+
+// NewTracer creates a new Tracer.
+func NewTracer(ctx context.Context, config *Config) (*Tracer, error) {
+	if config.Endpoint == "" {
+		return nil, newError("tracing endpoint is not specified")
+	}
+	if config.SampleRatio < 0 || config.SampleRatio > 1 {
+		return nil, newError("invalid tracing sample ratio: ", config.SampleRatio)
+	}
+	return &Tracer{
+		config:   config,
+		exporter: newExporter(ctx, config),
+	}, nil
+}
+
+func init() {
+	common.Must(common.RegisterConfig((*Config)(nil), func(ctx context.Context, config interface{}) (interface{}, error) {
+		return NewTracer(ctx, config.(*Config))
+	}))
+}
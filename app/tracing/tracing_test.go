@@ -0,0 +1,180 @@
+package tracing_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	core "github.com/v2fly/v2ray-core/v5"
+	. "github.com/v2fly/v2ray-core/v5/app/tracing"
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/common/serial"
+	"github.com/v2fly/v2ray-core/v5/features/tracing"
+	"github.com/v2fly/v2ray-core/v5/testing/servers/tcp"
+)
+
+// collector is a stand-in of an OpenTelemetry collector, which keeps the exported spans.
+type collector struct {
+	coltracepb.UnimplementedTraceServiceServer
+
+	access        sync.Mutex
+	spans         []*tracepb.Span
+	serviceName   string
+	authorization []string
+}
+
+func (c *collector) Export(ctx context.Context, request *coltracepb.ExportTraceServiceRequest) (*coltracepb.ExportTraceServiceResponse, error) {
+	c.access.Lock()
+	defer c.access.Unlock()
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		c.authorization = md.Get("authorization")
+	}
+	for _, resourceSpans := range request.ResourceSpans {
+		for _, attribute := range resourceSpans.Resource.Attributes {
+			if attribute.Key == "service.name" {
+				c.serviceName = attribute.Value.GetStringValue()
+			}
+		}
+		for _, librarySpans := range resourceSpans.InstrumentationLibrarySpans {
+			c.spans = append(c.spans, librarySpans.Spans...)
+		}
+	}
+	return &coltracepb.ExportTraceServiceResponse{}, nil
+}
+
+func startCollector(t *testing.T) (*collector, net.Port) {
+	port := tcp.PickPort()
+	listener, err := net.Listen("tcp", net.LocalHostIP.String()+":"+port.String())
+	common.Must(err)
+	c := &collector{}
+	server := grpc.NewServer()
+	coltracepb.RegisterTraceServiceServer(server, c)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+	return c, port
+}
+
+func attribute(span *tracepb.Span, key string) string {
+	for _, attribute := range span.Attributes {
+		if attribute.Key == key {
+			return attribute.Value.GetStringValue()
+		}
+	}
+	return ""
+}
+
+func TestTracing(t *testing.T) {
+	c, port := startCollector(t)
+	server, err := core.New(&core.Config{
+		App: []*anypb.Any{
+			serial.ToTypedMessage(&Config{
+				Endpoint:    "127.0.0.1:" + port.String(),
+				Insecure:    true,
+				Headers:     map[string]string{"authorization": "token"},
+				ServiceName: "proxy",
+			}),
+		},
+	})
+	common.Must(err)
+	common.Must(server.Start())
+
+	tracer := server.GetFeature(tracing.TracerType()).(tracing.Tracer)
+	ctx := tracing.ContextWithTracer(context.Background(), tracer)
+	ctx, session := tracing.Start(ctx, "session")
+	session.SetAttribute("inbound.tag", "socks")
+	_, dial := tracing.Start(ctx, "transport.dial")
+	dial.RecordError(errors.New("connection refused"))
+	dial.End()
+	session.End()
+	session.End()
+
+	// Remaining spans are exported on close.
+	common.Must(server.Close())
+
+	c.access.Lock()
+	defer c.access.Unlock()
+	if len(c.spans) != 2 {
+		t.Fatal("expected 2 spans, but got ", len(c.spans))
+	}
+	if c.serviceName != "proxy" {
+		t.Error("unexpected service name: ", c.serviceName)
+	}
+	if len(c.authorization) != 1 || c.authorization[0] != "token" {
+		t.Error("unexpected authorization: ", c.authorization)
+	}
+	dialSpan, sessionSpan := c.spans[0], c.spans[1]
+	if sessionSpan.Name != "session" || dialSpan.Name != "transport.dial" {
+		t.Fatal("unexpected spans: ", sessionSpan.Name, " ", dialSpan.Name)
+	}
+	if !bytes.Equal(dialSpan.TraceId, sessionSpan.TraceId) || !bytes.Equal(dialSpan.ParentSpanId, sessionSpan.SpanId) {
+		t.Error("dial span is not a child of the session span")
+	}
+	if len(sessionSpan.ParentSpanId) != 0 {
+		t.Error("session span has a parent")
+	}
+	if v := attribute(sessionSpan, "inbound.tag"); v != "socks" {
+		t.Error("unexpected inbound tag: ", v)
+	}
+	if dialSpan.Status.GetCode() != tracepb.Status_STATUS_CODE_ERROR || dialSpan.Status.GetMessage() != "connection refused" {
+		t.Error("unexpected dial status: ", dialSpan.Status)
+	}
+	if sessionSpan.Status.GetCode() != tracepb.Status_STATUS_CODE_UNSET {
+		t.Error("unexpected session status: ", sessionSpan.Status)
+	}
+}
+
+func TestUntracedContext(t *testing.T) {
+	ctx, span := tracing.Start(context.Background(), "session")
+	if ctx != context.Background() {
+		t.Error("untraced context is changed")
+	}
+	span.SetAttribute("key", "value")
+	span.End()
+}
+
+func TestIsRecording(t *testing.T) {
+	if tracing.IsRecording(context.Background()) {
+		t.Error("untraced context is recording")
+	}
+
+	tracer, err := NewTracer(context.Background(), &Config{Endpoint: "127.0.0.1:4317"})
+	common.Must(err)
+	ctx := tracing.ContextWithTracer(context.Background(), tracer)
+	if tracing.IsRecording(ctx) {
+		t.Error("context without span is recording")
+	}
+	ctx, session := tracing.Start(ctx, "session")
+	if !session.IsRecording() || !tracing.IsRecording(ctx) {
+		t.Error("sampled session is not recording")
+	}
+
+	tracer, err = NewTracer(context.Background(), &Config{Endpoint: "127.0.0.1:4317", SampleRatio: 1e-9})
+	common.Must(err)
+	ctx, session = tracing.Start(tracing.ContextWithTracer(context.Background(), tracer), "session")
+	if session.IsRecording() || tracing.IsRecording(ctx) {
+		t.Error("unsampled session is recording")
+	}
+	ctx, dial := tracing.Start(ctx, "transport.dial")
+	if dial.IsRecording() || tracing.IsRecording(ctx) {
+		t.Error("span of unsampled session is recording")
+	}
+}
+
+func TestInvalidConfig(t *testing.T) {
+	if _, err := NewTracer(context.Background(), &Config{}); err == nil {
+		t.Error("expected error of missing endpoint")
+	}
+	if _, err := NewTracer(context.Background(), &Config{Endpoint: "127.0.0.1:4317", SampleRatio: 2}); err == nil {
+		t.Error("expected error of invalid sample ratio")
+	}
+}
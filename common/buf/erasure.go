@@ -1,5 +1,7 @@
 package buf
 
+import "github.com/v2fly/v2ray-core/v5/common"
+
 type EndpointErasureReader struct {
 	Reader
 }
@@ -14,6 +16,10 @@ func (r *EndpointErasureReader) ReadMultiBuffer() (MultiBuffer, error) {
 	return mb, err
 }
 
+func (r *EndpointErasureReader) Interrupt() {
+	common.Interrupt(r.Reader)
+}
+
 type EndpointErasureWriter struct {
 	Writer
 }
@@ -24,3 +30,11 @@ func (w *EndpointErasureWriter) WriteMultiBuffer(mb MultiBuffer) error {
 	}
 	return w.Writer.WriteMultiBuffer(mb)
 }
+
+func (w *EndpointErasureWriter) Close() error {
+	return common.Close(w.Writer)
+}
+
+func (w *EndpointErasureWriter) Interrupt() {
+	common.Interrupt(w.Writer)
+}
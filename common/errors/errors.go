@@ -141,13 +141,17 @@ func (err *Error) WriteToLog(opts ...ExportOption) {
 	}
 
 	log.Record(&log.GeneralMessage{
-		Severity: GetSeverity(err),
-		Content:  err,
+		Severity:    GetSeverity(err),
+		Content:     err,
+		InboundTag:  holder.InboundTag,
+		OutboundTag: holder.OutboundTag,
 	})
 }
 
 type ExportOptionHolder struct {
-	SessionID uint32
+	SessionID   uint32
+	InboundTag  string
+	OutboundTag string
 }
 
 type ExportOption func(*ExportOptionHolder)
@@ -3,6 +3,7 @@ package log
 import (
 	"context"
 	"strings"
+	"time"
 
 	"github.com/v2fly/v2ray-core/v5/common/serial"
 )
@@ -18,6 +19,7 @@ type AccessStatus string
 const (
 	AccessAccepted = AccessStatus("accepted")
 	AccessRejected = AccessStatus("rejected")
+	AccessClosed   = AccessStatus("closed")
 )
 
 type AccessMessage struct {
@@ -27,6 +29,16 @@ type AccessMessage struct {
 	Reason interface{}
 	Email  string
 	Detour string
+
+	InboundTag string
+	Domain     string
+	// SessionID is the ID of the session of the connection, so that its messages are sampled
+	// together. 0 if unknown.
+	SessionID uint32
+	// Duration and traffic of the connection, only for closed connections.
+	Duration time.Duration
+	Uplink   int64
+	Downlink int64
 }
 
 func (m *AccessMessage) String() string {
@@ -53,9 +65,30 @@ func (m *AccessMessage) String() string {
 		builder.WriteString(m.Email)
 	}
 
+	if m.Status == AccessClosed {
+		builder.WriteString(serial.Concat(" duration: ", m.Duration, " uplink: ", m.Uplink, " downlink: ", m.Downlink))
+	}
+
 	return builder.String()
 }
 
+// AccessCloseHandler is a Handler that tells whether it handles AccessMessages of closed
+// connections.
+type AccessCloseHandler interface {
+	Handler
+	HandlesAccessClose() bool
+}
+
+// ShouldRecordAccessClose returns whether AccessMessages of closed connections should be recorded,
+// which is only if the current log handler handles them.
+func ShouldRecordAccessClose() bool {
+	logHandler.RLock()
+	defer logHandler.RUnlock()
+
+	h, ok := logHandler.Handler.(AccessCloseHandler)
+	return ok && h.HandlesAccessClose()
+}
+
 func ContextWithAccessMessage(ctx context.Context, accessMessage *AccessMessage) context.Context {
 	return context.WithValue(ctx, accessMessageKey, accessMessage)
 }
@@ -0,0 +1,113 @@
+package log
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// journaldSocket is the socket of the native protocol of systemd-journald.
+const journaldSocket = "/run/systemd/journal/socket"
+
+type journaldWriter struct {
+	identifier string
+	conn       net.Conn
+}
+
+func (w *journaldWriter) connect() error {
+	if w.conn != nil {
+		w.conn.Close()
+		w.conn = nil
+	}
+	conn, err := net.Dial("unixgram", journaldSocket)
+	if err != nil {
+		return err
+	}
+	w.conn = conn
+	return nil
+}
+
+// appendField appends a field in the native protocol of journald. Values with line breaks are
+// written with their lengths.
+func appendField(b *bytes.Buffer, key string, value string) {
+	b.WriteString(key)
+	if !strings.Contains(value, "\n") {
+		b.WriteByte('=')
+		b.WriteString(value)
+		b.WriteByte('\n')
+		return
+	}
+	b.WriteByte('\n')
+	var size [8]byte
+	binary.LittleEndian.PutUint64(size[:], uint64(len(value)))
+	b.Write(size[:])
+	b.WriteString(value)
+	b.WriteByte('\n')
+}
+
+func (w *journaldWriter) Write(s string) error {
+	s = strings.TrimRight(s, "\r\n")
+	return w.WriteMessage(&GeneralMessage{Severity: Severity_Info, Content: s}, s)
+}
+
+// WriteMessage implements MessageWriter.
+func (w *journaldWriter) WriteMessage(msg Message, line string) error {
+	b := new(bytes.Buffer)
+	appendField(b, "MESSAGE", line)
+	appendField(b, "PRIORITY", strconv.Itoa(severityOf(msg)))
+	appendField(b, "SYSLOG_IDENTIFIER", w.identifier)
+	if msg, ok := msg.(*AccessMessage); ok {
+		appendField(b, "V2RAY_ACCESS_STATUS", string(msg.Status))
+		if msg.Email != "" {
+			appendField(b, "V2RAY_EMAIL", msg.Email)
+		}
+	}
+	if w.conn != nil {
+		if _, err := w.conn.Write(b.Bytes()); err == nil {
+			return nil
+		}
+	}
+	// Reconnect once, such as after journald restarted.
+	if err := w.connect(); err != nil {
+		return err
+	}
+	_, err := w.conn.Write(b.Bytes())
+	return err
+}
+
+func (w *journaldWriter) Close() error {
+	if w.conn == nil {
+		return nil
+	}
+	return w.conn.Close()
+}
+
+// CreateJournaldLogWriter returns a LogWriterCreator that creates LogWriter for journald, with the
+// identifier of messages, "v2ray" if empty. It returns an error if journald is unavailable.
+func CreateJournaldLogWriter(identifier string) (WriterCreator, error) {
+	if identifier == "" {
+		identifier = "v2ray"
+	}
+	w := &journaldWriter{
+		identifier: identifier,
+	}
+	if err := w.connect(); err != nil {
+		return nil, errors.New("failed to connect to journald: " + err.Error())
+	}
+	conn := w.conn
+	return func() Writer {
+		w := &journaldWriter{
+			identifier: identifier,
+			conn:       conn,
+		}
+		conn = nil
+		if w.conn == nil {
+			// If journald is unavailable now, writes connect again.
+			w.connect()
+		}
+		return w
+	}, nil
+}
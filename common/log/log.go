@@ -26,6 +26,9 @@ type Follower interface {
 type GeneralMessage struct {
 	Severity Severity
 	Content  interface{}
+	// Tags of the handlers of the connection that the message is about, if any.
+	InboundTag  string
+	OutboundTag string
 }
 
 // String implements Message.
@@ -17,23 +17,40 @@ type Writer interface {
 	io.Closer
 }
 
+// MessageWriter is a Writer that writes lines with their messages, such as for the severities of
+// the messages. Lines are not terminated with line separators.
+type MessageWriter interface {
+	Writer
+	WriteMessage(msg Message, line string) error
+}
+
 // WriterCreator is a function to create LogWriters.
 type WriterCreator func() Writer
 
+// Formatter is a function to format log messages into lines.
+type Formatter func(Message) string
+
 type generalLogger struct {
-	creator WriterCreator
-	buffer  chan Message
-	access  *semaphore.Instance
-	done    *done.Instance
+	creator   WriterCreator
+	formatter Formatter
+	buffer    chan Message
+	access    *semaphore.Instance
+	done      *done.Instance
 }
 
 // NewLogger returns a generic log handler that can handle all type of messages.
 func NewLogger(logWriterCreator WriterCreator) Handler {
+	return NewFormattedLogger(logWriterCreator, Message.String)
+}
+
+// NewFormattedLogger returns a generic log handler that formats messages with the formatter.
+func NewFormattedLogger(logWriterCreator WriterCreator, formatter Formatter) Handler {
 	return &generalLogger{
-		creator: logWriterCreator,
-		buffer:  make(chan Message, 16),
-		access:  semaphore.New(1),
-		done:    done.New(),
+		creator:   logWriterCreator,
+		formatter: formatter,
+		buffer:    make(chan Message, 16),
+		access:    semaphore.New(1),
+		done:      done.New(),
 	}
 }
 
@@ -55,7 +72,11 @@ func (l *generalLogger) run() {
 		case <-l.done.Wait():
 			return
 		case msg := <-l.buffer:
-			logger.Write(msg.String() + platform.LineSeparator())
+			if w, ok := logger.(MessageWriter); ok {
+				w.WriteMessage(msg, l.formatter(msg))
+			} else {
+				logger.Write(l.formatter(msg) + platform.LineSeparator())
+			}
 			dataWritten = true
 		case <-ticker.C:
 			if !dataWritten {
@@ -112,9 +133,19 @@ func (w *fileLogWriter) Close() error {
 
 // CreateStdoutLogWriter returns a LogWriterCreator that creates LogWriter for stdout.
 func CreateStdoutLogWriter() WriterCreator {
+	return createStdoutLogWriter(log.Ldate | log.Ltime)
+}
+
+// CreatePlainStdoutLogWriter returns a LogWriterCreator that creates LogWriter for stdout, which
+// doesn't prefix lines with timestamps.
+func CreatePlainStdoutLogWriter() WriterCreator {
+	return createStdoutLogWriter(0)
+}
+
+func createStdoutLogWriter(flag int) WriterCreator {
 	return func() Writer {
 		return &consoleLogWriter{
-			logger: log.New(os.Stdout, "", log.Ldate|log.Ltime),
+			logger: log.New(os.Stdout, "", flag),
 		}
 	}
 }
@@ -130,6 +161,16 @@ func CreateStderrLogWriter() WriterCreator {
 
 // CreateFileLogWriter returns a LogWriterCreator that creates LogWriter for the given file.
 func CreateFileLogWriter(path string) (WriterCreator, error) {
+	return createFileLogWriter(path, log.Ldate|log.Ltime)
+}
+
+// CreatePlainFileLogWriter returns a LogWriterCreator that creates LogWriter for the given file,
+// which doesn't prefix lines with timestamps.
+func CreatePlainFileLogWriter(path string) (WriterCreator, error) {
+	return createFileLogWriter(path, 0)
+}
+
+func createFileLogWriter(path string, flag int) (WriterCreator, error) {
 	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0o600)
 	if err != nil {
 		return nil, err
@@ -142,7 +183,7 @@ func CreateFileLogWriter(path string) (WriterCreator, error) {
 		}
 		return &fileLogWriter{
 			file:   file,
-			logger: log.New(file, "", log.Ldate|log.Ltime),
+			logger: log.New(file, "", flag),
 		}
 	}, nil
 }
@@ -2,7 +2,10 @@ package log_test
 
 import (
 	"io/ioutil"
+	"net"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -37,3 +40,104 @@ func TestFileLogger(t *testing.T) {
 		t.Fatal("Expect log text contains 'Test Log', but actually: ", string(b))
 	}
 }
+
+func TestRotatingFileLogger(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vtest")
+	common.Must(err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "access.log")
+
+	creator, err := CreateRotatingFileLogWriter(path, RotationConfig{
+		MaxSize:    64,
+		MaxBackups: 2,
+		Compress:   true,
+	})
+	common.Must(err)
+
+	writer := creator()
+	for i := 0; i < 8; i++ {
+		common.Must(writer.Write(strings.Repeat("a", 40) + "\n"))
+		time.Sleep(10 * time.Millisecond)
+	}
+	common.Must(writer.Close())
+	time.Sleep(500 * time.Millisecond)
+
+	backups, err := filepath.Glob(path + ".*.gz")
+	common.Must(err)
+	if len(backups) != 2 {
+		t.Error("expected 2 rotated files, but got ", backups)
+	}
+	info, err := os.Stat(path)
+	common.Must(err)
+	if info.Size() != 41 {
+		t.Error("expected a single line in the current file, but got ", info.Size(), " bytes")
+	}
+}
+
+func TestRotatingFileLoggerRecovery(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vtest")
+	common.Must(err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "access.log")
+
+	creator, err := CreateRotatingFileLogWriter(path, RotationConfig{
+		MaxSize: 64,
+	})
+	common.Must(err)
+
+	writer := creator()
+	defer writer.Close()
+	line := strings.Repeat("a", 40) + "\n"
+	common.Must(writer.Write(line))
+
+	// Neither renaming nor reopening the file works without the directory.
+	common.Must(os.RemoveAll(dir))
+	if err := writer.Write(line); err == nil {
+		t.Error("expected error of missing directory")
+	}
+
+	common.Must(os.Mkdir(dir, 0o700))
+	common.Must(writer.Write(line))
+	b, err := ioutil.ReadFile(path)
+	common.Must(err)
+	if string(b) != line {
+		t.Error("expected a single line in the reopened file, but got ", len(b), " bytes")
+	}
+}
+
+func TestSyslogLogger(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	common.Must(err)
+	defer conn.Close()
+
+	creator, err := CreateSyslogLogWriter(SyslogConfig{
+		Network:  "udp",
+		Address:  conn.LocalAddr().String(),
+		Facility: 16,
+	})
+	common.Must(err)
+
+	handler := NewLogger(creator)
+	handler.Handle(&GeneralMessage{Severity: Severity_Warning, Content: "Test Log"})
+	defer common.Close(handler)
+
+	b := make([]byte, 1024)
+	common.Must(conn.SetReadDeadline(time.Now().Add(2 * time.Second)))
+	n, _, err := conn.ReadFrom(b)
+	common.Must(err)
+	message := string(b[:n])
+	if !strings.HasPrefix(message, "<132>1 ") || !strings.HasSuffix(message, " v2ray "+strconv.Itoa(os.Getpid())+" - - [Warning] Test Log") {
+		t.Error("unexpected syslog message: ", message)
+	}
+}
+
+func TestSyslogLoggerUnreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	common.Must(err)
+	address := listener.Addr().String()
+	common.Must(listener.Close())
+
+	if _, err := CreateSyslogLogWriter(SyslogConfig{Network: "tcp", Address: address}); err == nil {
+		t.Error("expected error of unreachable syslog server")
+	}
+}
@@ -0,0 +1,243 @@
+package log
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RotationConfig is the config of rotating log files.
+type RotationConfig struct {
+	// MaxSize is the size in bytes of a log file before it is rotated. Not rotated by size if 0.
+	MaxSize int64
+	// Interval is the period of log files, aligned to UTC. Not rotated by time if 0.
+	Interval time.Duration
+	// MaxBackups is the number of rotated files to keep. All are kept if 0.
+	MaxBackups int
+	// MaxAge is the duration to keep rotated files. All are kept if 0.
+	MaxAge time.Duration
+	// Compress is whether to compress rotated files with gzip.
+	Compress bool
+}
+
+// backupTimeFormat is the format of the time in names of rotated files, which sorts by time.
+const backupTimeFormat = "20060102T150405.000"
+
+// rotatingFile is a log file that is rotated by size and time. Rotated files are renamed to the
+// path with the time of rotation, such as "access.log.20220101T000000.000".
+type rotatingFile struct {
+	path   string
+	config RotationConfig
+
+	// file is nil if the path could not be opened on rotation. It is opened again on writes.
+	file   *os.File
+	size   int64
+	period time.Time
+}
+
+func openRotatingFile(path string, config RotationConfig) (*rotatingFile, error) {
+	f := &rotatingFile{
+		path:   path,
+		config: config,
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *rotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0o600)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	f.file = file
+	f.size = info.Size()
+	start := time.Now()
+	if f.size > 0 {
+		start = info.ModTime()
+	}
+	f.period = f.periodOf(start)
+	return nil
+}
+
+func (f *rotatingFile) periodOf(t time.Time) time.Time {
+	if f.config.Interval <= 0 {
+		return time.Time{}
+	}
+	return t.Truncate(f.config.Interval)
+}
+
+func (f *rotatingFile) shouldRotate(now time.Time, size int) bool {
+	if f.size == 0 {
+		return false
+	}
+	if f.config.MaxSize > 0 && f.size+int64(size) > f.config.MaxSize {
+		return true
+	}
+	return !f.periodOf(now).Equal(f.period)
+}
+
+// Write implements io.Writer.
+func (f *rotatingFile) Write(b []byte) (int, error) {
+	if now := time.Now(); f.file != nil && f.shouldRotate(now, len(b)) {
+		f.rotate(now)
+	}
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(b)
+	f.size += int64(n)
+	return n, err
+}
+
+// rotate renames the file with the time, and opens a new file at the path. The file is closed
+// before it is renamed, as open files can not be renamed on Windows. If it fails to be renamed,
+// the path is reopened to be written on, and rotated again after another period or MaxSize bytes.
+// If the path fails to be opened, f.file is nil until it is opened by a later write.
+func (f *rotatingFile) rotate(now time.Time) {
+	f.file.Close()
+	f.file = nil
+	backup := f.path + "." + now.Format(backupTimeFormat)
+	renameErr := os.Rename(f.path, backup)
+	if renameErr != nil {
+		os.Stderr.WriteString("failed to rotate log file " + f.path + ": " + renameErr.Error() + "\n")
+	}
+	if err := f.open(); err != nil {
+		os.Stderr.WriteString("failed to open log file " + f.path + ": " + err.Error() + "\n")
+		return
+	}
+	if renameErr != nil {
+		f.size = 0
+		f.period = f.periodOf(now)
+		return
+	}
+	go f.cleanup(backup)
+}
+
+// cleanupAccess serializes compression and removal of rotated files.
+var cleanupAccess sync.Mutex
+
+// cleanup compresses the newly rotated file if required, and removes expired files.
+func (f *rotatingFile) cleanup(backup string) {
+	cleanupAccess.Lock()
+	defer cleanupAccess.Unlock()
+
+	if f.config.Compress {
+		if err := compressFile(backup); err != nil {
+			os.Stderr.WriteString("failed to compress log file " + backup + ": " + err.Error() + "\n")
+		}
+	}
+	if f.config.MaxBackups <= 0 && f.config.MaxAge <= 0 {
+		return
+	}
+
+	matches, err := filepath.Glob(f.path + ".*")
+	if err != nil {
+		return
+	}
+	var backups []string
+	for _, name := range matches {
+		suffix := strings.TrimSuffix(strings.TrimPrefix(name, f.path+"."), ".gz")
+		if _, err := time.Parse(backupTimeFormat, suffix); err == nil {
+			backups = append(backups, name)
+		}
+	}
+	// Names of backups are sorted by time, newest first.
+	sort.Sort(sort.Reverse(sort.StringSlice(backups)))
+	for i, name := range backups {
+		expired := f.config.MaxBackups > 0 && i >= f.config.MaxBackups
+		if !expired && f.config.MaxAge > 0 {
+			if info, err := os.Stat(name); err == nil && time.Since(info.ModTime()) > f.config.MaxAge {
+				expired = true
+			}
+		}
+		if expired {
+			os.Remove(name)
+		}
+	}
+}
+
+func compressFile(name string) error {
+	if strings.HasSuffix(name, ".gz") {
+		return nil
+	}
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(name+".gz", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(dst)
+	if _, err := io.Copy(gz, src); err != nil {
+		gz.Close()
+		dst.Close()
+		os.Remove(name + ".gz")
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		dst.Close()
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+	src.Close()
+	return os.Remove(name)
+}
+
+// Close implements io.Closer.
+func (f *rotatingFile) Close() error {
+	if f.file == nil {
+		return nil
+	}
+	return f.file.Close()
+}
+
+type rotatingFileLogWriter struct {
+	file *rotatingFile
+}
+
+func (w *rotatingFileLogWriter) Write(s string) error {
+	_, err := io.WriteString(w.file, s)
+	return err
+}
+
+func (w *rotatingFileLogWriter) Close() error {
+	return w.file.Close()
+}
+
+// CreateRotatingFileLogWriter returns a LogWriterCreator that creates LogWriter for the given file,
+// which is rotated by the config. Lines are not prefixed with timestamps.
+func CreateRotatingFileLogWriter(path string, config RotationConfig) (WriterCreator, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	file.Close()
+	return func() Writer {
+		file, err := openRotatingFile(path, config)
+		if err != nil {
+			return nil
+		}
+		return &rotatingFileLogWriter{
+			file: file,
+		}
+	}, nil
+}
@@ -0,0 +1,173 @@
+package log
+
+import (
+	"errors"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SyslogConfig is the config of writing logs to a syslog server.
+type SyslogConfig struct {
+	// Network is "udp", "tcp", "unix" or "unixgram". The local syslog socket is used if empty.
+	Network string
+	Address string
+	// Tag is the APP-NAME of messages, "v2ray" if empty.
+	Tag string
+	// Facility is the facility code of messages.
+	Facility int
+}
+
+// localSyslogAddresses are the paths of local syslog sockets on common systems.
+var localSyslogAddresses = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// severityOf returns the syslog severity of a message. Access messages are informational.
+func severityOf(msg Message) int {
+	if msg, ok := msg.(*GeneralMessage); ok {
+		switch msg.Severity {
+		case Severity_Error:
+			return 3
+		case Severity_Warning:
+			return 4
+		case Severity_Debug:
+			return 7
+		}
+	}
+	return 6
+}
+
+type syslogWriter struct {
+	config   SyslogConfig
+	hostname string
+	conn     net.Conn
+}
+
+func (w *syslogWriter) connect() error {
+	if w.conn != nil {
+		w.conn.Close()
+		w.conn = nil
+	}
+	if w.config.Network != "" {
+		conn, err := net.Dial(w.config.Network, w.config.Address)
+		if err != nil {
+			return err
+		}
+		w.conn = conn
+		return nil
+	}
+	var lastErr error
+	for _, address := range localSyslogAddresses {
+		for _, network := range []string{"unixgram", "unix"} {
+			conn, err := net.Dial(network, address)
+			if err == nil {
+				w.conn = conn
+				return nil
+			}
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+// format returns the message in the format of RFC 5424, framed by octet counting of RFC 6587 for
+// stream connections.
+func (w *syslogWriter) format(msg Message, line string) string {
+	msgID := "-"
+	if _, ok := msg.(*AccessMessage); ok {
+		msgID = "access"
+	}
+	builder := strings.Builder{}
+	builder.WriteByte('<')
+	builder.WriteString(strconv.Itoa(w.config.Facility*8 + severityOf(msg)))
+	builder.WriteString(">1 ")
+	builder.WriteString(time.Now().Format("2006-01-02T15:04:05.000000Z07:00"))
+	builder.WriteByte(' ')
+	builder.WriteString(w.hostname)
+	builder.WriteByte(' ')
+	builder.WriteString(w.config.Tag)
+	builder.WriteByte(' ')
+	builder.WriteString(strconv.Itoa(os.Getpid()))
+	builder.WriteByte(' ')
+	builder.WriteString(msgID)
+	builder.WriteString(" - ")
+	builder.WriteString(line)
+
+	s := builder.String()
+	switch w.config.Network {
+	case "tcp", "tcp4", "tcp6", "unix":
+		return strconv.Itoa(len(s)) + " " + s
+	default:
+		return s
+	}
+}
+
+func (w *syslogWriter) Write(s string) error {
+	s = strings.TrimRight(s, "\r\n")
+	return w.WriteMessage(&GeneralMessage{Severity: Severity_Info, Content: s}, s)
+}
+
+// WriteMessage implements MessageWriter.
+func (w *syslogWriter) WriteMessage(msg Message, line string) error {
+	b := []byte(w.format(msg, line))
+	if w.conn != nil {
+		if _, err := w.conn.Write(b); err == nil {
+			return nil
+		}
+	}
+	// Reconnect once, such as after the syslog server restarted.
+	if err := w.connect(); err != nil {
+		return err
+	}
+	_, err := w.conn.Write(b)
+	return err
+}
+
+func (w *syslogWriter) Close() error {
+	if w.conn == nil {
+		return nil
+	}
+	return w.conn.Close()
+}
+
+// CreateSyslogLogWriter returns a LogWriterCreator that creates LogWriter for the syslog server.
+func CreateSyslogLogWriter(config SyslogConfig) (WriterCreator, error) {
+	switch config.Network {
+	case "", "udp", "udp4", "udp6", "tcp", "tcp4", "tcp6", "unix", "unixgram":
+	default:
+		return nil, errors.New("unknown syslog network: " + config.Network)
+	}
+	if config.Network != "" && config.Address == "" {
+		return nil, errors.New("syslog address is not specified")
+	}
+	if config.Tag == "" {
+		config.Tag = "v2ray"
+	}
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "-"
+	}
+	// The server is connected once here, so that loggers to unreachable servers fail to be created.
+	w := &syslogWriter{
+		config:   config,
+		hostname: hostname,
+	}
+	if err := w.connect(); err != nil {
+		return nil, errors.New("failed to connect to syslog: " + err.Error())
+	}
+	conn := w.conn
+	return func() Writer {
+		w := &syslogWriter{
+			config:   config,
+			hostname: hostname,
+			conn:     conn,
+		}
+		conn = nil
+		if w.conn == nil {
+			// If the server is unavailable now, writes connect again.
+			w.connect()
+		}
+		return w
+	}, nil
+}
@@ -259,6 +259,7 @@ func fetchInput(ctx context.Context, s *Session, output buf.Writer) {
 	}
 	s.transferType = transferType
 	writer := NewWriter(s.ID, dest, output, transferType)
+	writer.window = s.sendWindow
 	defer s.Close()
 	defer writer.Close()
 
@@ -310,11 +311,19 @@ func (m *ClientWorker) Dispatch(ctx context.Context, link *transport.Link) bool
 	}
 	s.input = link.Reader
 	s.output = link.Writer
+	// Flow control is advertised to the server, and enabled once acknowledged.
+	s.sendWindow = newSendWindow(false)
+	s.receiveQueue = newReceiveQueue()
 	go fetchInput(ctx, s, m.link.Writer)
 	return true
 }
 
 func (m *ClientWorker) handleStatueKeepAlive(meta *FrameMetadata, reader *buf.BufferedReader) error {
+	if meta.Option.Has(OptionFlowControl) {
+		if s, found := m.sessionManager.Get(meta.SessionID); found && s.sendWindow != nil {
+			s.sendWindow.grant(meta.WindowIncrement)
+		}
+	}
 	if meta.Option.Has(OptionData) {
 		return buf.Copy(NewStreamReader(reader), buf.Discard)
 	}
@@ -329,6 +338,12 @@ func (m *ClientWorker) handleStatusNew(meta *FrameMetadata, reader *buf.Buffered
 }
 
 func (m *ClientWorker) handleStatusKeep(meta *FrameMetadata, reader *buf.BufferedReader) error {
+	if meta.Option.Has(OptionFlowControl) {
+		if s, found := m.sessionManager.Get(meta.SessionID); found && s.receiveQueue != nil && s.receiveQueue.activate() {
+			s.sendWindow.enable()
+			go s.receive(m.link.Writer)
+		}
+	}
 	if !meta.Option.Has(OptionData) {
 		return nil
 	}
@@ -343,6 +358,9 @@ func (m *ClientWorker) handleStatusKeep(meta *FrameMetadata, reader *buf.Buffere
 	}
 
 	rr := s.NewReader(reader, &meta.Target)
+	if s.receiveQueue.isActive() {
+		return s.receiveFrom(rr, meta.Option.Has(OptionFlowControl), m.link.Writer)
+	}
 	err := buf.Copy(rr, s.output)
 	if err != nil && buf.IsWriteError(err) {
 		newError("failed to write to downstream. closing session ", s.ID).Base(err).WriteToLog()
@@ -363,6 +381,9 @@ func (m *ClientWorker) handleStatusKeep(meta *FrameMetadata, reader *buf.Buffere
 func (m *ClientWorker) handleStatusEnd(meta *FrameMetadata, reader *buf.BufferedReader) error {
 	if s, found := m.sessionManager.Get(meta.SessionID); found {
 		if meta.Option.Has(OptionError) {
+			if s.receiveQueue != nil {
+				s.receiveQueue.interrupt()
+			}
 			common.Interrupt(s.input)
 			common.Interrupt(s.output)
 		}
@@ -0,0 +1,339 @@
+package mux
+
+import (
+	"io"
+	"sync"
+
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/buf"
+	"github.com/v2fly/v2ray-core/v5/common/errors"
+	"github.com/v2fly/v2ray-core/v5/common/protocol"
+	"github.com/v2fly/v2ray-core/v5/common/signal/done"
+)
+
+const (
+	// defaultWindowSize is the receive window of each session with flow control.
+	defaultWindowSize = 256 * 1024
+	// windowUpdateThreshold is the amount of consumed data that triggers a window update.
+	windowUpdateThreshold = defaultWindowSize / 4
+	// windowSlack is the data a compliant peer may send past the window, as packets are never
+	// split and a chunk may be taken from the window just before it is acknowledged.
+	windowSlack = 64 * 1024
+)
+
+var errWindowExceeded = newError("peer exceeded the receive window")
+
+// sendWindow limits the data sent on a session to what the peer has granted. A disabled window
+// keeps counting but never blocks, until the peer acknowledges flow control.
+type sendWindow struct {
+	access  sync.Mutex
+	size    int64
+	enabled bool
+	updated chan struct{}
+	done    *done.Instance
+}
+
+func newSendWindow(enabled bool) *sendWindow {
+	return &sendWindow{
+		size:    defaultWindowSize,
+		enabled: enabled,
+		updated: make(chan struct{}, 1),
+		done:    done.New(),
+	}
+}
+
+func (w *sendWindow) notify() {
+	select {
+	case w.updated <- struct{}{}:
+	default:
+	}
+}
+
+// acquire blocks until the window is open, and takes up to max bytes of it.
+func (w *sendWindow) acquire(max int32) (int32, error) {
+	for {
+		w.access.Lock()
+		if !w.enabled || w.size >= int64(max) {
+			w.size -= int64(max)
+			w.access.Unlock()
+			return max, nil
+		}
+		if w.size > 0 {
+			n := int32(w.size)
+			w.size = 0
+			w.access.Unlock()
+			return n, nil
+		}
+		w.access.Unlock()
+
+		select {
+		case <-w.updated:
+		case <-w.done.Wait():
+			return 0, io.ErrClosedPipe
+		}
+	}
+}
+
+func (w *sendWindow) isEnabled() bool {
+	w.access.Lock()
+	defer w.access.Unlock()
+
+	return w.enabled
+}
+
+// consume takes n bytes of the window regardless of its size.
+func (w *sendWindow) consume(n int32) {
+	w.access.Lock()
+	w.size -= int64(n)
+	w.access.Unlock()
+}
+
+func (w *sendWindow) grant(n uint32) {
+	w.access.Lock()
+	w.size += int64(n)
+	w.access.Unlock()
+	w.notify()
+}
+
+func (w *sendWindow) enable() {
+	w.access.Lock()
+	w.enabled = true
+	w.access.Unlock()
+	w.notify()
+}
+
+// Close implements common.Closable.
+func (w *sendWindow) Close() error {
+	return w.done.Close()
+}
+
+// receiveQueue buffers incoming data of a session with flow control, so that a slow reader
+// on one session never blocks the other sessions on the same connection. The peer keeps the
+// amount of buffered data within the window.
+//
+// The peer doesn't block on the window until it receives the acknowledgement of flow control, and
+// marks the frames it sends afterwards with OptionFlowControl. Data sent before is still taken
+// from its window, so the buffered data is within the window, or the data sent before the
+// acknowledgement if larger.
+type receiveQueue struct {
+	access  sync.Mutex
+	data    buf.MultiBuffer
+	active  bool
+	closed  bool
+	updated chan struct{}
+	// size is the amount of buffered data.
+	size int64
+	// received is the amount of data received before the acknowledgement.
+	received int64
+	// limit of the buffered data once the peer sends acknowledged frames, or 0 before.
+	limit int64
+}
+
+func newReceiveQueue() *receiveQueue {
+	return &receiveQueue{
+		updated: make(chan struct{}, 1),
+	}
+}
+
+func (q *receiveQueue) notify() {
+	select {
+	case q.updated <- struct{}{}:
+	default:
+	}
+}
+
+// activate returns true if the queue is activated by this call.
+func (q *receiveQueue) activate() bool {
+	q.access.Lock()
+	defer q.access.Unlock()
+
+	if q.active || q.closed {
+		return false
+	}
+	q.active = true
+	return true
+}
+
+func (q *receiveQueue) isActive() bool {
+	if q == nil {
+		return false
+	}
+
+	q.access.Lock()
+	defer q.access.Unlock()
+
+	return q.active
+}
+
+// push adds data of a frame to the queue. acknowledged is whether the frame is sent after the peer
+// receives the acknowledgement of flow control. It returns errWindowExceeded if the peer sends
+// more than the window.
+func (q *receiveQueue) push(mb buf.MultiBuffer, acknowledged bool) error {
+	q.access.Lock()
+	if q.closed {
+		q.access.Unlock()
+		buf.ReleaseMulti(mb)
+		return nil
+	}
+	n := int64(mb.Len())
+	if q.limit == 0 {
+		if acknowledged {
+			q.limit = defaultWindowSize
+			if q.received > q.limit {
+				q.limit = q.received
+			}
+			q.limit += windowSlack
+		} else {
+			q.received += n
+		}
+	}
+	if q.limit > 0 && q.size+n > q.limit {
+		q.access.Unlock()
+		buf.ReleaseMulti(mb)
+		return errWindowExceeded
+	}
+	q.data = append(q.data, mb...)
+	q.size += n
+	q.access.Unlock()
+	q.notify()
+	return nil
+}
+
+// pop blocks until there is data in the queue. It returns false if the queue is closed and empty.
+func (q *receiveQueue) pop() (buf.MultiBuffer, bool) {
+	for {
+		q.access.Lock()
+		if !q.data.IsEmpty() {
+			mb := q.data
+			q.data = nil
+			q.size = 0
+			q.access.Unlock()
+			return mb, true
+		}
+		closed := q.closed
+		q.access.Unlock()
+
+		if closed {
+			return nil, false
+		}
+		<-q.updated
+	}
+}
+
+// close stops the queue after all buffered data is consumed. It returns true if the queue is
+// active, in which case the consumer closes the output of the session.
+func (q *receiveQueue) close() bool {
+	q.access.Lock()
+	q.closed = true
+	active := q.active
+	q.access.Unlock()
+	q.notify()
+	return active
+}
+
+// interrupt stops the queue and discards all buffered data.
+func (q *receiveQueue) interrupt() {
+	q.access.Lock()
+	q.closed = true
+	q.data = buf.ReleaseMulti(q.data)
+	q.size = 0
+	q.access.Unlock()
+	q.notify()
+}
+
+func (q *receiveQueue) readFrom(reader buf.Reader, acknowledged bool) error {
+	for {
+		mb, err := reader.ReadMultiBuffer()
+		if err != nil {
+			if errors.Cause(err) == io.EOF {
+				return nil
+			}
+			return err
+		}
+		if err := q.push(mb, acknowledged); err != nil {
+			return err
+		}
+	}
+}
+
+// receiveFrom moves data of a frame to the receive queue. If the peer exceeds the window, the
+// session is ended with an error, and the rest of the frame is discarded.
+func (s *Session) receiveFrom(reader buf.Reader, acknowledged bool, link buf.Writer) error {
+	err := s.receiveQueue.readFrom(reader, acknowledged)
+	if err != errWindowExceeded {
+		return err
+	}
+	newError("closing session ", s.ID).Base(err).AtWarning().WriteToLog()
+
+	// Notify remote peer of the failure of this session.
+	closingWriter := NewResponseWriter(s.ID, link, protocol.TransferTypeStream)
+	closingWriter.hasError = true
+	closingWriter.Close()
+
+	s.receiveQueue.interrupt()
+	common.Interrupt(s.input)
+	common.Interrupt(s.output)
+	s.Close()
+	return buf.Copy(reader, buf.Discard)
+}
+
+func writeFlowControlAck(writer buf.Writer, id uint16) error {
+	meta := FrameMetadata{
+		SessionID:     id,
+		SessionStatus: SessionStatusKeep,
+		Option:        OptionFlowControl,
+	}
+	frame := buf.New()
+	common.Must(meta.WriteTo(frame))
+	return writer.WriteMultiBuffer(buf.MultiBuffer{frame})
+}
+
+func writeWindowUpdate(writer buf.Writer, id uint16, increment uint32) error {
+	meta := FrameMetadata{
+		SessionID:       id,
+		SessionStatus:   SessionStatusKeepAlive,
+		Option:          OptionFlowControl,
+		WindowIncrement: increment,
+	}
+	frame := buf.New()
+	common.Must(meta.WriteTo(frame))
+	return writer.WriteMultiBuffer(buf.MultiBuffer{frame})
+}
+
+// receive moves data from the receive queue to the output of the session, and grants the peer
+// the consumed window back.
+func (s *Session) receive(link buf.Writer) {
+	var consumed uint32
+	for {
+		mb, ok := s.receiveQueue.pop()
+		if !ok {
+			common.Close(s.output)
+			return
+		}
+
+		n := uint32(mb.Len())
+		if err := s.output.WriteMultiBuffer(mb); err != nil {
+			newError("failed to write to downstream. closing session ", s.ID).Base(err).WriteToLog()
+
+			// Notify remote peer to close this session.
+			closingWriter := NewResponseWriter(s.ID, link, protocol.TransferTypeStream)
+			closingWriter.Close()
+
+			s.receiveQueue.interrupt()
+			common.Interrupt(s.input)
+			common.Interrupt(s.output)
+			s.Close()
+			return
+		}
+
+		consumed += n
+		if consumed >= windowUpdateThreshold {
+			if err := writeWindowUpdate(link, s.ID, consumed); err != nil {
+				s.receiveQueue.interrupt()
+				common.Interrupt(s.output)
+				return
+			}
+			consumed = 0
+		}
+	}
+}
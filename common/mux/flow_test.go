@@ -0,0 +1,357 @@
+package mux_test
+
+import (
+	"context"
+	"crypto/rand"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/buf"
+	. "github.com/v2fly/v2ray-core/v5/common/mux"
+	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/common/serial"
+	"github.com/v2fly/v2ray-core/v5/common/session"
+	"github.com/v2fly/v2ray-core/v5/features/routing"
+	"github.com/v2fly/v2ray-core/v5/transport"
+	"github.com/v2fly/v2ray-core/v5/transport/pipe"
+)
+
+func TestFrameWindowUpdate(t *testing.T) {
+	meta := FrameMetadata{
+		SessionID:       3,
+		SessionStatus:   SessionStatusKeepAlive,
+		Option:          OptionFlowControl,
+		WindowIncrement: 65536,
+	}
+	b := buf.New()
+	defer b.Release()
+	common.Must(meta.WriteTo(b))
+
+	var actual FrameMetadata
+	common.Must(actual.Unmarshal(b))
+	if r := cmp.Diff(actual, meta); r != "" {
+		t.Error(r)
+	}
+}
+
+var (
+	stalledDestination = net.TCPDestination(net.DomainAddress("stalled.v2fly.org"), 80)
+	echoDestination    = net.TCPDestination(net.DomainAddress("echo.v2fly.org"), 80)
+)
+
+// testDispatcher never reads the uplink of stalledDestination, and echoes everything sent to
+// echoDestination.
+type testDispatcher struct{}
+
+func (testDispatcher) Type() interface{} {
+	return routing.DispatcherType()
+}
+
+func (testDispatcher) Start() error {
+	return nil
+}
+
+func (testDispatcher) Close() error {
+	return nil
+}
+
+func (d testDispatcher) Dispatch(ctx context.Context, dest net.Destination) (*transport.Link, error) {
+	uplinkReader, uplinkWriter := pipe.New(pipe.WithSizeLimit(1024))
+	downlinkReader, downlinkWriter := pipe.New(pipe.WithSizeLimit(1024))
+	if dest == echoDestination {
+		go func() {
+			buf.Copy(uplinkReader, downlinkWriter)
+			downlinkWriter.Close()
+		}()
+	}
+	return &transport.Link{Reader: downlinkReader, Writer: uplinkWriter}, nil
+}
+
+func (d testDispatcher) DispatchLink(ctx context.Context, dest net.Destination, link *transport.Link) error {
+	return nil
+}
+
+type testSession struct {
+	input  *pipe.Writer
+	output *pipe.Reader
+}
+
+func newTestWorkers(t testing.TB) *ClientWorker {
+	uplinkReader, uplinkWriter := pipe.New(pipe.WithSizeLimit(64 * 1024))
+	downlinkReader, downlinkWriter := pipe.New(pipe.WithSizeLimit(64 * 1024))
+
+	_, err := NewServerWorker(context.Background(), testDispatcher{}, &transport.Link{
+		Reader: uplinkReader,
+		Writer: downlinkWriter,
+	})
+	common.Must(err)
+
+	client, err := NewClientWorker(transport.Link{
+		Reader: downlinkReader,
+		Writer: uplinkWriter,
+	}, ClientStrategy{})
+	common.Must(err)
+	return client
+}
+
+func dispatchTestSession(client *ClientWorker, dest net.Destination) *testSession {
+	inputReader, inputWriter := pipe.New(pipe.WithSizeLimit(64 * 1024))
+	outputReader, outputWriter := pipe.New(pipe.WithSizeLimit(64 * 1024))
+	ctx := session.ContextWithOutbound(context.Background(), &session.Outbound{Target: dest})
+	if !client.Dispatch(ctx, &transport.Link{Reader: inputReader, Writer: outputWriter}) {
+		panic("failed to dispatch")
+	}
+	return &testSession{input: inputWriter, output: outputReader}
+}
+
+func (s *testSession) echo(payload []byte) error {
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- s.input.WriteMultiBuffer(buf.MergeBytes(nil, payload))
+	}()
+
+	received := 0
+	for received < len(payload) {
+		mb, err := s.output.ReadMultiBuffer()
+		if err != nil {
+			return err
+		}
+		received += int(mb.Len())
+		buf.ReleaseMulti(mb)
+	}
+	return <-errChan
+}
+
+func stallSession(s *testSession) {
+	payload := make([]byte, 8*1024)
+	go func() {
+		for {
+			if err := s.input.WriteMultiBuffer(buf.MergeBytes(nil, payload)); err != nil {
+				return
+			}
+		}
+	}()
+}
+
+func TestSessionFlowControl(t *testing.T) {
+	client := newTestWorkers(t)
+
+	stalled := dispatchTestSession(client, stalledDestination)
+	defer stalled.input.Close()
+	stallSession(stalled)
+
+	echo := dispatchTestSession(client, echoDestination)
+	defer echo.input.Close()
+
+	payload := make([]byte, 1024*1024)
+	common.Must2(io.ReadFull(rand.Reader, payload))
+
+	done := make(chan error, 1)
+	go func() {
+		done <- echo.echo(payload)
+	}()
+
+	select {
+	case err := <-done:
+		common.Must(err)
+	case <-time.After(time.Second * 10):
+		t.Fatal("session is blocked by another stalled session")
+	}
+}
+
+func BenchmarkSessionFairness(b *testing.B) {
+	client := newTestWorkers(b)
+
+	stalled := dispatchTestSession(client, stalledDestination)
+	defer stalled.input.Close()
+	stallSession(stalled)
+
+	echo := dispatchTestSession(client, echoDestination)
+	defer echo.input.Close()
+
+	payload := make([]byte, 64*1024)
+	b.SetBytes(int64(len(payload)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		common.Must(echo.echo(payload))
+	}
+}
+
+// rawPeer speaks the frames of Mux.Cool directly, as a non-compliant or old peer.
+type rawPeer struct {
+	writer buf.Writer
+	reader *buf.BufferedReader
+}
+
+func (p *rawPeer) writeFrame(meta FrameMetadata, data []byte) {
+	frame := buf.New()
+	if len(data) > 0 {
+		meta.Option.Set(OptionData)
+	}
+	common.Must(meta.WriteTo(frame))
+	mb := buf.MultiBuffer{frame}
+	if len(data) > 0 {
+		common.Must2(serial.WriteUint16(frame, uint16(len(data))))
+		mb = buf.MergeBytes(mb, data)
+	}
+	common.Must(p.writer.WriteMultiBuffer(mb))
+}
+
+// readFrame returns the next frame and its data.
+func (p *rawPeer) readFrame() (FrameMetadata, []byte, error) {
+	var meta FrameMetadata
+	if err := meta.Unmarshal(p.reader); err != nil {
+		return meta, nil, err
+	}
+	if !meta.Option.Has(OptionData) {
+		return meta, nil, nil
+	}
+	var data []byte
+	reader := NewStreamReader(p.reader)
+	for {
+		mb, err := reader.ReadMultiBuffer()
+		if err == io.EOF {
+			return meta, data, nil
+		}
+		if err != nil {
+			return meta, nil, err
+		}
+		b := make([]byte, mb.Len())
+		mb.Copy(b)
+		buf.ReleaseMulti(mb)
+		data = append(data, b...)
+	}
+}
+
+// newRawClient returns a raw peer connected to a server worker.
+func newRawClient() *rawPeer {
+	uplinkReader, uplinkWriter := pipe.New(pipe.WithSizeLimit(64 * 1024))
+	downlinkReader, downlinkWriter := pipe.New(pipe.WithSizeLimit(64 * 1024))
+	_, err := NewServerWorker(context.Background(), testDispatcher{}, &transport.Link{
+		Reader: uplinkReader,
+		Writer: downlinkWriter,
+	})
+	common.Must(err)
+	return &rawPeer{
+		writer: uplinkWriter,
+		reader: &buf.BufferedReader{Reader: downlinkReader},
+	}
+}
+
+func TestSessionWindowExceeded(t *testing.T) {
+	client := newRawClient()
+	client.writeFrame(FrameMetadata{
+		SessionID:     1,
+		SessionStatus: SessionStatusNew,
+		Option:        OptionFlowControl,
+		Target:        stalledDestination,
+	}, nil)
+	meta, _, err := client.readFrame()
+	common.Must(err)
+	if meta.SessionStatus != SessionStatusKeep || !meta.Option.Has(OptionFlowControl) {
+		t.Fatal("expected acknowledgement of flow control, got ", meta)
+	}
+
+	chunk := make([]byte, 8*1024)
+	go func() {
+		// Data sent before the acknowledgement is received may exceed the window.
+		for i := 0; i < 48; i++ {
+			client.writeFrame(FrameMetadata{SessionID: 1, SessionStatus: SessionStatusKeep}, chunk)
+		}
+		for i := 0; i < 128; i++ {
+			client.writeFrame(FrameMetadata{SessionID: 1, SessionStatus: SessionStatusKeep, Option: OptionFlowControl}, chunk)
+		}
+	}()
+
+	meta, _, err = client.readFrame()
+	common.Must(err)
+	if meta.SessionID != 1 || meta.SessionStatus != SessionStatusEnd || !meta.Option.Has(OptionError) {
+		t.Error("expected end of session with error, got ", meta)
+	}
+}
+
+// TestSessionWithoutFlowControl runs an old client, which doesn't advertise flow control, against
+// the server.
+func TestSessionWithoutFlowControl(t *testing.T) {
+	client := newRawClient()
+	payload := make([]byte, 1024*1024)
+	common.Must2(io.ReadFull(rand.Reader, payload))
+
+	go func() {
+		meta := FrameMetadata{
+			SessionID:     1,
+			SessionStatus: SessionStatusNew,
+			Target:        echoDestination,
+		}
+		for i := 0; i < len(payload); i += 8 * 1024 {
+			client.writeFrame(meta, payload[i:i+8*1024])
+			meta.SessionStatus = SessionStatusKeep
+		}
+	}()
+
+	var received []byte
+	for len(received) < len(payload) {
+		meta, data, err := client.readFrame()
+		common.Must(err)
+		if meta.Option.Has(OptionFlowControl) {
+			t.Fatal("unexpected flow control in frame ", meta)
+		}
+		received = append(received, data...)
+	}
+	if r := cmp.Diff(received, payload); r != "" {
+		t.Error(r)
+	}
+}
+
+// TestSessionWithOldServer runs the client against an old server, which ignores flow control and
+// echoes all data.
+func TestSessionWithOldServer(t *testing.T) {
+	uplinkReader, uplinkWriter := pipe.New(pipe.WithSizeLimit(64 * 1024))
+	downlinkReader, downlinkWriter := pipe.New(pipe.WithSizeLimit(64 * 1024))
+	server := &rawPeer{
+		writer: downlinkWriter,
+		reader: &buf.BufferedReader{Reader: uplinkReader},
+	}
+	go func() {
+		for {
+			meta, data, err := server.readFrame()
+			if err != nil {
+				return
+			}
+			switch meta.SessionStatus {
+			case SessionStatusNew, SessionStatusKeep:
+				if len(data) > 0 {
+					server.writeFrame(FrameMetadata{SessionID: meta.SessionID, SessionStatus: SessionStatusKeep}, data)
+				}
+			case SessionStatusEnd:
+				server.writeFrame(FrameMetadata{SessionID: meta.SessionID, SessionStatus: SessionStatusEnd}, nil)
+			}
+		}
+	}()
+
+	client, err := NewClientWorker(transport.Link{
+		Reader: downlinkReader,
+		Writer: uplinkWriter,
+	}, ClientStrategy{})
+	common.Must(err)
+
+	echo := dispatchTestSession(client, echoDestination)
+	defer echo.input.Close()
+
+	payload := make([]byte, 1024*1024)
+	common.Must2(io.ReadFull(rand.Reader, payload))
+	done := make(chan error, 1)
+	go func() {
+		done <- echo.echo(payload)
+	}()
+	select {
+	case err := <-done:
+		common.Must(err)
+	case <-time.After(time.Second * 10):
+		t.Fatal("session is blocked by the window without flow control of the server")
+	}
+}
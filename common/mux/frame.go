@@ -24,6 +24,10 @@ const (
 const (
 	OptionData  bitmask.Byte = 0x01
 	OptionError bitmask.Byte = 0x02
+	// OptionFlowControl advertises per-session flow control on a New frame, acknowledges it
+	// on the first Keep frame of the response, marks Keep frames sent after the acknowledgement,
+	// and marks window updates on KeepAlive frames.
+	OptionFlowControl bitmask.Byte = 0x04
 )
 
 type TargetNetwork byte
@@ -51,13 +55,18 @@ Frame format
 2 bytes - port
 n bytes - address
 
+or, for window updates
+
+4 bytes - window increment
+
 */
 
 type FrameMetadata struct {
-	Target        net.Destination
-	SessionID     uint16
-	Option        bitmask.Byte
-	SessionStatus SessionStatus
+	Target          net.Destination
+	SessionID       uint16
+	Option          bitmask.Byte
+	SessionStatus   SessionStatus
+	WindowIncrement uint32
 }
 
 func (f FrameMetadata) WriteTo(b *buf.Buffer) error {
@@ -70,7 +79,9 @@ func (f FrameMetadata) WriteTo(b *buf.Buffer) error {
 	common.Must(b.WriteByte(byte(f.SessionStatus)))
 	common.Must(b.WriteByte(byte(f.Option)))
 
-	if f.SessionStatus == SessionStatusNew {
+	if f.SessionStatus == SessionStatusKeepAlive && f.Option.Has(OptionFlowControl) {
+		binary.BigEndian.PutUint32(b.Extend(4), f.WindowIncrement)
+	} else if f.SessionStatus == SessionStatusNew {
 		switch f.Target.Network {
 		case net.Network_TCP:
 			common.Must(b.WriteByte(byte(TargetNetworkTCP)))
@@ -121,6 +132,15 @@ func (f *FrameMetadata) UnmarshalFromBuffer(b *buf.Buffer) error {
 	f.SessionStatus = SessionStatus(b.Byte(2))
 	f.Option = bitmask.Byte(b.Byte(3))
 	f.Target.Network = net.Network_Unknown
+	f.WindowIncrement = 0
+
+	if f.SessionStatus == SessionStatusKeepAlive && f.Option.Has(OptionFlowControl) {
+		if b.Len() < 8 {
+			return newError("insufficient buffer: ", b.Len())
+		}
+		f.WindowIncrement = binary.BigEndian.Uint32(b.BytesRange(4, 8))
+		return nil
+	}
 
 	if f.SessionStatus == SessionStatusNew || (f.SessionStatus == SessionStatusKeep && b.Len() != 4) {
 		if b.Len() < 8 {
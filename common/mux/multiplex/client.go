@@ -0,0 +1,239 @@
+package multiplex
+
+import (
+	"bufio"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/buf"
+	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/common/session"
+	"github.com/v2fly/v2ray-core/v5/common/task"
+	"github.com/v2fly/v2ray-core/v5/proxy"
+	"github.com/v2fly/v2ray-core/v5/transport"
+	"github.com/v2fly/v2ray-core/v5/transport/internet"
+	"github.com/v2fly/v2ray-core/v5/transport/pipe"
+)
+
+type ClientStrategy struct {
+	Protocol Protocol
+	// SmuxVersion is the version of smux, 1 or 2.
+	SmuxVersion int
+	Padding     bool
+	// MaxStreams is the max number of concurrent streams on one connection.
+	MaxStreams uint32
+	// PacketAddr sends the address of each UDP packet.
+	PacketAddr bool
+}
+
+type clientSession struct {
+	muxSession
+	idle bool
+}
+
+// ClientManager dispatches links as streams over multiplexed connections of the proxy.
+type ClientManager struct {
+	ctx      context.Context
+	proxy    proxy.Outbound
+	dialer   internet.Dialer
+	strategy ClientStrategy
+
+	access      sync.Mutex
+	sessions    []*clientSession
+	cleanupTask *task.Periodic
+}
+
+func NewClientManager(ctx context.Context, proxy proxy.Outbound, dialer internet.Dialer, strategy ClientStrategy) *ClientManager {
+	m := &ClientManager{
+		ctx:      ctx,
+		proxy:    proxy,
+		dialer:   dialer,
+		strategy: strategy,
+	}
+	m.cleanupTask = &task.Periodic{
+		Interval: time.Second * 30,
+		Execute:  m.cleanup,
+	}
+	return m
+}
+
+// cleanup closes connections that stay idle for a whole interval.
+func (m *ClientManager) cleanup() error {
+	m.access.Lock()
+	defer m.access.Unlock()
+
+	var active []*clientSession
+	for _, s := range m.sessions {
+		if s.NumStreams() > 0 {
+			s.idle = false
+		} else if s.idle {
+			s.Close()
+		} else {
+			s.idle = true
+		}
+		if !s.IsClosed() {
+			active = append(active, s)
+		}
+	}
+	m.sessions = active
+
+	if len(m.sessions) == 0 {
+		return newError("no session")
+	}
+	return nil
+}
+
+func (m *ClientManager) pick() (*clientSession, error) {
+	s, created, err := m.pickInternal()
+	if created {
+		common.Must(m.cleanupTask.Start())
+	}
+	return s, err
+}
+
+func (m *ClientManager) pickInternal() (*clientSession, bool, error) {
+	m.access.Lock()
+	defer m.access.Unlock()
+
+	var active []*clientSession
+	for _, s := range m.sessions {
+		if !s.IsClosed() {
+			active = append(active, s)
+		}
+	}
+	m.sessions = active
+
+	for _, s := range m.sessions {
+		if uint32(s.NumStreams()) < m.strategy.MaxStreams {
+			s.idle = false
+			return s, false, nil
+		}
+	}
+
+	s, err := m.createSession()
+	if err != nil {
+		return nil, false, err
+	}
+	m.sessions = append(m.sessions, s)
+	return s, true, nil
+}
+
+func (m *ClientManager) createSession() (*clientSession, error) {
+	opts := []pipe.Option{pipe.WithSizeLimit(64 * 1024)}
+	uplinkReader, uplinkWriter := pipe.New(opts...)
+	downlinkReader, downlinkWriter := pipe.New(opts...)
+	conn := buf.NewConnection(buf.ConnectionInputMulti(uplinkWriter), buf.ConnectionOutputMulti(downlinkReader))
+
+	go func() {
+		ctx := session.ContextWithOutbound(m.ctx, &session.Outbound{
+			Target: net.TCPDestination(Address, Port),
+		})
+		ctx, cancel := context.WithCancel(ctx)
+
+		if err := m.proxy.Process(ctx, &transport.Link{Reader: uplinkReader, Writer: downlinkWriter}, m.dialer); err != nil {
+			newError("failed to handle multiplexed connection").Base(err).WriteToLog()
+		}
+		conn.Close()
+		cancel()
+	}()
+
+	if err := writeSessionRequest(conn, sessionRequest{
+		Protocol: m.strategy.Protocol,
+		Padding:  m.strategy.Padding,
+	}); err != nil {
+		conn.Close()
+		return nil, newError("failed to write session request").Base(err)
+	}
+	if m.strategy.Padding {
+		conn = newPaddingConn(conn)
+	}
+	s, err := newClientSession(conn, m.strategy.Protocol, m.strategy.SmuxVersion)
+	if err != nil {
+		conn.Close()
+		return nil, newError("failed to create ", m.strategy.Protocol, " session").Base(err)
+	}
+	return &clientSession{muxSession: s}, nil
+}
+
+// Close implements common.Closable.
+func (m *ClientManager) Close() error {
+	m.access.Lock()
+	defer m.access.Unlock()
+
+	for _, s := range m.sessions {
+		s.Close()
+	}
+	m.sessions = nil
+	return m.cleanupTask.Close()
+}
+
+// Dispatch sends the link as a new stream, and returns when the stream ends.
+func (m *ClientManager) Dispatch(ctx context.Context, link *transport.Link) error {
+	var stream net.Conn
+	for i := 0; i < 16 && stream == nil; i++ {
+		s, err := m.pick()
+		if err != nil {
+			return err
+		}
+		stream, err = s.Open()
+		if err != nil {
+			newError("failed to open stream").Base(err).WriteToLog(session.ExportIDToError(ctx))
+			s.Close()
+		}
+	}
+	if stream == nil {
+		return newError("unable to open a stream").AtWarning()
+	}
+	defer stream.Close()
+
+	dest := session.OutboundFromContext(ctx).Target
+	packetAddr := dest.Network == net.Network_UDP && m.strategy.PacketAddr
+	newError("dispatching request to ", dest, " over ", m.strategy.Protocol).WriteToLog(session.ExportIDToError(ctx))
+	if err := writeStreamRequest(stream, streamRequest{Destination: dest, PacketAddr: packetAddr}); err != nil {
+		return newError("failed to write stream request").Base(err)
+	}
+
+	requestDone := func() error {
+		var writer buf.Writer
+		if dest.Network == net.Network_UDP {
+			writer = &packetWriter{writer: stream, packetAddr: packetAddr, dest: dest}
+		} else {
+			writer = buf.NewWriter(stream)
+		}
+		if err := buf.Copy(link.Reader, writer); err != nil {
+			return newError("failed to transfer request").Base(err)
+		}
+		// Streams that cannot be half closed stay open until the response ends, as the server
+		// closes them then.
+		if cw, ok := stream.(closeWriter); ok {
+			cw.CloseWrite()
+		}
+		return nil
+	}
+
+	responseDone := func() error {
+		reader := bufio.NewReader(stream)
+		if err := readStreamResponse(reader); err != nil {
+			return err
+		}
+		var responseReader buf.Reader
+		if dest.Network == net.Network_UDP {
+			responseReader = &packetReader{reader: reader, packetAddr: packetAddr}
+		} else {
+			responseReader = buf.NewReader(reader)
+		}
+		if err := buf.Copy(responseReader, link.Writer); err != nil {
+			return newError("failed to transfer response").Base(err)
+		}
+		return nil
+	}
+
+	if err := task.Run(ctx, requestDone, task.OnSuccess(responseDone, task.Close(link.Writer))); err != nil {
+		common.Interrupt(link.Reader)
+		common.Interrupt(link.Writer)
+		return newError("connection ends").Base(err)
+	}
+	return nil
+}
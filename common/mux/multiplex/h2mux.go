@@ -0,0 +1,305 @@
+package multiplex
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/http2"
+
+	"github.com/v2fly/v2ray-core/v5/common/signal/done"
+)
+
+// h2MuxClientSession carries each stream in a CONNECT request over HTTP/2.
+type h2MuxClientSession struct {
+	conn       net.Conn
+	clientConn *http2.ClientConn
+	streams    int32
+}
+
+func newH2MuxClientSession(conn net.Conn) (muxSession, error) {
+	transport := &http2.Transport{}
+	clientConn, err := transport.NewClientConn(conn)
+	if err != nil {
+		return nil, newError("failed to create HTTP/2 client").Base(err)
+	}
+	return &h2MuxClientSession{
+		conn:       conn,
+		clientConn: clientConn,
+	}, nil
+}
+
+// Open implements muxSession.
+func (s *h2MuxClientSession) Open() (net.Conn, error) {
+	if !s.clientConn.CanTakeNewRequest() {
+		return nil, newError("HTTP/2 connection is closed")
+	}
+
+	pipeReader, pipeWriter := io.Pipe()
+	request := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Scheme: "https", Host: "localhost"},
+		Host:   "localhost",
+		Header: make(http.Header),
+		Body:   pipeReader,
+	}
+	stream := &h2MuxClientStream{
+		h2MuxStream: h2MuxStream{
+			conn:   s.conn,
+			writer: pipeWriter,
+		},
+		ready: make(chan struct{}),
+	}
+	atomic.AddInt32(&s.streams, 1)
+	stream.onClose = func() {
+		atomic.AddInt32(&s.streams, -1)
+	}
+
+	go func() {
+		response, err := s.clientConn.RoundTrip(request)
+		if err == nil && response.StatusCode != http.StatusOK {
+			response.Body.Close()
+			err = newError("unexpected status: ", response.Status)
+		}
+		if err != nil {
+			stream.err = err
+			pipeReader.CloseWithError(err)
+		} else {
+			stream.reader = response.Body
+		}
+		close(stream.ready)
+	}()
+	return stream, nil
+}
+
+// Accept implements muxSession.
+func (s *h2MuxClientSession) Accept() (net.Conn, error) {
+	return nil, newError("not supported")
+}
+
+// NumStreams implements muxSession.
+func (s *h2MuxClientSession) NumStreams() int {
+	return int(atomic.LoadInt32(&s.streams))
+}
+
+// IsClosed implements muxSession.
+func (s *h2MuxClientSession) IsClosed() bool {
+	return !s.clientConn.CanTakeNewRequest()
+}
+
+// Close implements muxSession.
+func (s *h2MuxClientSession) Close() error {
+	s.clientConn.Close()
+	return s.conn.Close()
+}
+
+// h2MuxServerSession accepts streams from CONNECT requests over HTTP/2.
+type h2MuxServerSession struct {
+	conn    net.Conn
+	accept  chan net.Conn
+	done    *done.Instance
+	streams int32
+}
+
+func newH2MuxServerSession(conn net.Conn) muxSession {
+	s := &h2MuxServerSession{
+		conn:   conn,
+		accept: make(chan net.Conn),
+		done:   done.New(),
+	}
+	go func() {
+		server := &http2.Server{}
+		server.ServeConn(conn, &http2.ServeConnOpts{Handler: s})
+		s.Close()
+	}()
+	return s
+}
+
+// ServeHTTP implements http.Handler.
+func (s *h2MuxServerSession) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodConnect {
+		writer.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	flusher, ok := writer.(http.Flusher)
+	if !ok {
+		writer.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	writer.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	closed := done.New()
+	responseWriter := &flushWriter{writer: writer, flusher: flusher}
+	stream := &h2MuxStream{
+		conn:    s.conn,
+		reader:  request.Body,
+		writer:  responseWriter,
+		onClose: func() { closed.Close() },
+	}
+	atomic.AddInt32(&s.streams, 1)
+	defer atomic.AddInt32(&s.streams, -1)
+	// The response writer must not be used after the handler returns.
+	defer responseWriter.finish()
+
+	select {
+	case s.accept <- stream:
+	case <-s.done.Wait():
+		return
+	}
+	select {
+	case <-closed.Wait():
+	case <-s.done.Wait():
+	}
+}
+
+// Open implements muxSession.
+func (s *h2MuxServerSession) Open() (net.Conn, error) {
+	return nil, newError("not supported")
+}
+
+// Accept implements muxSession.
+func (s *h2MuxServerSession) Accept() (net.Conn, error) {
+	select {
+	case stream := <-s.accept:
+		return stream, nil
+	case <-s.done.Wait():
+		return nil, io.ErrClosedPipe
+	}
+}
+
+// NumStreams implements muxSession.
+func (s *h2MuxServerSession) NumStreams() int {
+	return int(atomic.LoadInt32(&s.streams))
+}
+
+// IsClosed implements muxSession.
+func (s *h2MuxServerSession) IsClosed() bool {
+	return s.done.Done()
+}
+
+// Close implements muxSession.
+func (s *h2MuxServerSession) Close() error {
+	if s.done.Done() {
+		return nil
+	}
+	s.done.Close()
+	return s.conn.Close()
+}
+
+type flushWriter struct {
+	access   sync.Mutex
+	writer   io.Writer
+	flusher  http.Flusher
+	finished bool
+}
+
+func (w *flushWriter) Write(p []byte) (int, error) {
+	w.access.Lock()
+	defer w.access.Unlock()
+
+	if w.finished {
+		return 0, io.ErrClosedPipe
+	}
+	n, err := w.writer.Write(p)
+	if err == nil {
+		w.flusher.Flush()
+	}
+	return n, err
+}
+
+func (w *flushWriter) finish() {
+	w.access.Lock()
+	w.finished = true
+	w.access.Unlock()
+}
+
+// h2MuxStream is a stream over an HTTP/2 request and its response.
+type h2MuxStream struct {
+	conn      net.Conn
+	reader    io.ReadCloser
+	writer    io.Writer
+	onClose   func()
+	closeOnce sync.Once
+}
+
+func (s *h2MuxStream) Read(p []byte) (int, error) {
+	return s.reader.Read(p)
+}
+
+func (s *h2MuxStream) Write(p []byte) (int, error) {
+	return s.writer.Write(p)
+}
+
+func (s *h2MuxStream) Close() error {
+	s.closeOnce.Do(func() {
+		if closer, ok := s.writer.(io.Closer); ok {
+			closer.Close()
+		}
+		if s.reader != nil {
+			s.reader.Close()
+		}
+		if s.onClose != nil {
+			s.onClose()
+		}
+	})
+	return nil
+}
+
+func (s *h2MuxStream) LocalAddr() net.Addr {
+	return s.conn.LocalAddr()
+}
+
+func (s *h2MuxStream) RemoteAddr() net.Addr {
+	return s.conn.RemoteAddr()
+}
+
+func (s *h2MuxStream) SetDeadline(time.Time) error {
+	return nil
+}
+
+func (s *h2MuxStream) SetReadDeadline(time.Time) error {
+	return nil
+}
+
+func (s *h2MuxStream) SetWriteDeadline(time.Time) error {
+	return nil
+}
+
+// h2MuxClientStream is a stream whose response is not yet received when it is opened.
+type h2MuxClientStream struct {
+	h2MuxStream
+	ready chan struct{}
+	err   error
+}
+
+func (s *h2MuxClientStream) Read(p []byte) (int, error) {
+	<-s.ready
+	if s.err != nil {
+		return 0, s.err
+	}
+	return s.h2MuxStream.Read(p)
+}
+
+// CloseWrite implements closeWriter.
+func (s *h2MuxClientStream) CloseWrite() error {
+	return s.writer.(io.Closer).Close()
+}
+
+func (s *h2MuxClientStream) Close() error {
+	s.closeOnce.Do(func() {
+		s.writer.(io.Closer).Close()
+		go func() {
+			<-s.ready
+			if s.reader != nil {
+				s.reader.Close()
+			}
+		}()
+		s.onClose()
+	})
+	return nil
+}
@@ -0,0 +1,98 @@
+package multiplex
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"golang.org/x/net/http2"
+
+	"github.com/v2fly/v2ray-core/v5/common"
+)
+
+// The peers below send and serve streams as CONNECT requests of plain HTTP/2, as sing-mux does.
+
+func TestH2MuxServerInterop(t *testing.T) {
+	clientConn, serverConn := net.Pipe()
+	session := newH2MuxServerSession(serverConn)
+	defer session.Close()
+
+	clientTransport, err := (&http2.Transport{}).NewClientConn(clientConn)
+	common.Must(err)
+	bodyReader, bodyWriter := io.Pipe()
+	responses := make(chan *http.Response, 1)
+	go func() {
+		response, err := clientTransport.RoundTrip(&http.Request{
+			Method: http.MethodConnect,
+			URL:    &url.URL{Scheme: "https", Host: "localhost"},
+			Header: make(http.Header),
+			Body:   bodyReader,
+		})
+		common.Must(err)
+		responses <- response
+	}()
+
+	stream, err := session.Accept()
+	common.Must(err)
+	response := <-responses
+	if response.StatusCode != http.StatusOK {
+		t.Fatal("unexpected status: ", response.Status)
+	}
+
+	common.Must2(bodyWriter.Write([]byte("request")))
+	request := make([]byte, len("request"))
+	common.Must2(io.ReadFull(stream, request))
+	if string(request) != "request" {
+		t.Error("unexpected request: ", string(request))
+	}
+	common.Must2(stream.Write([]byte("response")))
+	common.Must(stream.Close())
+	body, err := io.ReadAll(response.Body)
+	common.Must(err)
+	if string(body) != "response" {
+		t.Error("unexpected response: ", string(body))
+	}
+}
+
+func TestH2MuxClientInterop(t *testing.T) {
+	clientConn, serverConn := net.Pipe()
+	go (&http2.Server{}).ServeConn(serverConn, &http2.ServeConnOpts{
+		Handler: http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			if request.Method != http.MethodConnect {
+				writer.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			writer.WriteHeader(http.StatusOK)
+			writer.(http.Flusher).Flush()
+			// Echo the request until it is closed.
+			b := make([]byte, 1024)
+			for {
+				n, err := request.Body.Read(b)
+				if n > 0 {
+					writer.Write(b[:n])
+					writer.(http.Flusher).Flush()
+				}
+				if err != nil {
+					return
+				}
+			}
+		}),
+	})
+
+	session, err := newH2MuxClientSession(clientConn)
+	common.Must(err)
+	defer session.Close()
+
+	stream, err := session.Open()
+	common.Must(err)
+	common.Must2(stream.Write([]byte("request")))
+	common.Must(stream.(closeWriter).CloseWrite())
+	response, err := io.ReadAll(stream)
+	common.Must(err)
+	if string(response) != "request" {
+		t.Error("unexpected response: ", string(response))
+	}
+	common.Must(stream.Close())
+}
@@ -0,0 +1,262 @@
+package multiplex_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/buf"
+	"github.com/v2fly/v2ray-core/v5/common/errors"
+	. "github.com/v2fly/v2ray-core/v5/common/mux/multiplex"
+	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/common/session"
+	"github.com/v2fly/v2ray-core/v5/common/task"
+	"github.com/v2fly/v2ray-core/v5/features/routing"
+	"github.com/v2fly/v2ray-core/v5/transport"
+	"github.com/v2fly/v2ray-core/v5/transport/internet"
+	"github.com/v2fly/v2ray-core/v5/transport/pipe"
+)
+
+var (
+	refusedDestination = net.TCPDestination(net.DomainAddress("refused.v2fly.org"), 80)
+	delayedDestination = net.TCPDestination(net.DomainAddress("delayed.v2fly.org"), 80)
+)
+
+// echoDispatcher echoes everything sent to any destination other than refusedDestination and
+// delayedDestination. delayedDestination reads a request, and responds a while after it.
+type echoDispatcher struct{}
+
+func (echoDispatcher) Type() interface{} {
+	return routing.DispatcherType()
+}
+
+func (echoDispatcher) Start() error {
+	return nil
+}
+
+func (echoDispatcher) Close() error {
+	return nil
+}
+
+func (echoDispatcher) Dispatch(ctx context.Context, dest net.Destination) (*transport.Link, error) {
+	if dest == refusedDestination {
+		return nil, errors.New("connection refused")
+	}
+	if dest == delayedDestination {
+		requestReader, requestWriter := pipe.New(pipe.WithSizeLimit(64 * 1024))
+		responseReader, responseWriter := pipe.New(pipe.WithSizeLimit(64 * 1024))
+		go func() {
+			defer responseWriter.Close()
+			mb, err := requestReader.ReadMultiBuffer()
+			if err != nil {
+				return
+			}
+			buf.ReleaseMulti(mb)
+			time.Sleep(time.Millisecond * 100)
+			b := buf.New()
+			b.WriteString("response")
+			responseWriter.WriteMultiBuffer(buf.MultiBuffer{b})
+		}()
+		return &transport.Link{Reader: responseReader, Writer: requestWriter}, nil
+	}
+	reader, writer := pipe.New(pipe.WithSizeLimit(64 * 1024))
+	return &transport.Link{Reader: reader, Writer: writer}, nil
+}
+
+func (echoDispatcher) DispatchLink(ctx context.Context, dest net.Destination, link *transport.Link) error {
+	return errors.New("not implemented")
+}
+
+// testProxy relays multiplexed connections to a server on the same process.
+type testProxy struct{}
+
+func (testProxy) Process(ctx context.Context, link *transport.Link, dialer internet.Dialer) error {
+	uplinkReader, uplinkWriter := pipe.New(pipe.WithSizeLimit(64 * 1024))
+	downlinkReader, downlinkWriter := pipe.New(pipe.WithSizeLimit(64 * 1024))
+
+	dest := session.OutboundFromContext(ctx).Target
+	if dest != net.TCPDestination(Address, Port) {
+		return errors.New("unexpected destination: ", dest)
+	}
+	// The inbound has a source but no user, as of inbounds without authentication.
+	serverCtx := session.ContextWithInbound(ctx, &session.Inbound{Source: net.TCPDestination(net.LocalHostIP, 10086)})
+	ServeLink(serverCtx, echoDispatcher{}, &transport.Link{Reader: uplinkReader, Writer: downlinkWriter})
+
+	return task.Run(ctx, func() error {
+		defer uplinkWriter.Close()
+		return buf.Copy(link.Reader, uplinkWriter)
+	}, func() error {
+		defer link.Writer.(common.Closable).Close()
+		return buf.Copy(downlinkReader, link.Writer)
+	})
+}
+
+type testStream struct {
+	input  *pipe.Writer
+	output *pipe.Reader
+	done   chan error
+	// cancel ends the stream, as inbounds do when the connection is closed or times out.
+	cancel context.CancelFunc
+}
+
+func dispatch(client *ClientManager, dest net.Destination) *testStream {
+	inputReader, inputWriter := pipe.New(pipe.WithSizeLimit(64 * 1024))
+	outputReader, outputWriter := pipe.New(pipe.WithSizeLimit(64 * 1024))
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx = session.ContextWithOutbound(ctx, &session.Outbound{Target: dest})
+	s := &testStream{input: inputWriter, output: outputReader, done: make(chan error, 1), cancel: cancel}
+	go func() {
+		s.done <- client.Dispatch(ctx, &transport.Link{Reader: inputReader, Writer: outputWriter})
+	}()
+	return s
+}
+
+func testTCP(client *ClientManager) error {
+	s := dispatch(client, net.TCPDestination(net.DomainAddress("www.v2fly.org"), 80))
+
+	payload := make([]byte, 1024*1024)
+	common.Must2(io.ReadFull(rand.Reader, payload))
+	go s.input.WriteMultiBuffer(buf.MergeBytes(nil, payload))
+
+	received := make([]byte, 0, len(payload))
+	for len(received) < len(payload) {
+		mb, err := s.output.ReadMultiBuffer()
+		if err != nil {
+			return err
+		}
+		for _, b := range mb {
+			received = append(received, b.Bytes()...)
+		}
+		buf.ReleaseMulti(mb)
+	}
+	if !bytes.Equal(received, payload) {
+		return errors.New("content mismatch")
+	}
+	// The echo only ends after the request on streams that can be half closed, so the stream is
+	// canceled as a whole.
+	s.input.Close()
+	s.cancel()
+	if err := <-s.done; err != nil && errors.Cause(err) != context.Canceled {
+		return err
+	}
+	return nil
+}
+
+func testUDP(client *ClientManager) error {
+	dest := net.UDPDestination(net.LocalHostIP, 53)
+	endpoints := []net.Destination{
+		dest,
+		net.UDPDestination(net.LocalHostIP, 5353),
+	}
+	s := dispatch(client, dest)
+	defer s.cancel()
+	defer s.input.Close()
+
+	for _, endpoint := range endpoints {
+		endpoint := endpoint
+		payload := "packet to " + endpoint.String()
+		b := buf.New()
+		b.WriteString(payload)
+		b.Endpoint = &endpoint
+		common.Must(s.input.WriteMultiBuffer(buf.MultiBuffer{b}))
+
+		mb, err := s.output.ReadMultiBuffer()
+		if err != nil {
+			return err
+		}
+		if mb.String() != payload {
+			return fmt.Errorf("unexpected packet: %s", mb.String())
+		}
+		if mb[0].Endpoint == nil || *mb[0].Endpoint != endpoint {
+			return fmt.Errorf("unexpected endpoint: %v", mb[0].Endpoint)
+		}
+		buf.ReleaseMulti(mb)
+	}
+	return nil
+}
+
+func testRefused(client *ClientManager) error {
+	s := dispatch(client, refusedDestination)
+	defer s.cancel()
+	defer s.input.Close()
+
+	if _, err := s.output.ReadMultiBuffer(); err == nil {
+		return errors.New("expected stream to be refused")
+	}
+	if err := <-s.done; err == nil {
+		return errors.New("expected error from refused stream")
+	}
+	return nil
+}
+
+// testDelayedResponse closes the request before the response arrives, and expects the response.
+func testDelayedResponse(client *ClientManager) error {
+	s := dispatch(client, delayedDestination)
+	defer s.cancel()
+
+	b := buf.New()
+	b.WriteString("request")
+	common.Must(s.input.WriteMultiBuffer(buf.MultiBuffer{b}))
+	s.input.Close()
+
+	var response buf.MultiBuffer
+	for {
+		mb, err := s.output.ReadMultiBuffer()
+		if err != nil {
+			break
+		}
+		response = append(response, mb...)
+	}
+	if response.String() != "response" {
+		return fmt.Errorf("unexpected response: %q", response.String())
+	}
+	buf.ReleaseMulti(response)
+	return <-s.done
+}
+
+func TestMultiplex(t *testing.T) {
+	testCases := []ClientStrategy{
+		{Protocol: ProtocolH2Mux},
+		{Protocol: ProtocolSmux},
+		{Protocol: ProtocolSmux, SmuxVersion: 2},
+		{Protocol: ProtocolYamux},
+		{Protocol: ProtocolH2Mux, Padding: true},
+		{Protocol: ProtocolSmux, Padding: true},
+		{Protocol: ProtocolYamux, Padding: true},
+	}
+
+	for _, strategy := range testCases {
+		strategy := strategy
+		strategy.MaxStreams = 2
+		strategy.PacketAddr = true
+		t.Run(fmt.Sprint(strategy.Protocol, strategy.SmuxVersion, strategy.Padding), func(t *testing.T) {
+			client := NewClientManager(context.Background(), testProxy{}, nil, strategy)
+			defer client.Close()
+
+			var tasks []func() error
+			for i := 0; i < 4; i++ {
+				tasks = append(tasks, func() error { return testTCP(client) })
+			}
+			tasks = append(tasks,
+				func() error { return testUDP(client) },
+				func() error { return testRefused(client) },
+				func() error { return testDelayedResponse(client) })
+
+			errChan := make(chan error, 1)
+			go func() {
+				errChan <- task.Run(context.Background(), tasks...)
+			}()
+			select {
+			case err := <-errChan:
+				common.Must(err)
+			case <-time.After(time.Second * 20):
+				t.Fatal("timeout")
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package multiplex
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+
+	"github.com/v2fly/v2ray-core/v5/common/buf"
+	"github.com/v2fly/v2ray-core/v5/common/net"
+)
+
+// packetWriter writes UDP packets to a stream, each prefixed by its length, and by its address
+// if packetAddr is set.
+type packetWriter struct {
+	writer     io.Writer
+	packetAddr bool
+	dest       net.Destination
+}
+
+// WriteMultiBuffer implements buf.Writer.
+func (w *packetWriter) WriteMultiBuffer(mb buf.MultiBuffer) error {
+	defer buf.ReleaseMulti(mb)
+
+	for _, b := range mb {
+		if b.Len() > 65535 {
+			continue
+		}
+		var packet bytes.Buffer
+		if w.packetAddr {
+			dest := w.dest
+			if b.Endpoint != nil {
+				dest = *b.Endpoint
+			}
+			if err := addrParser.WriteAddressPort(&packet, dest.Address, dest.Port); err != nil {
+				return err
+			}
+		}
+		var length [2]byte
+		binary.BigEndian.PutUint16(length[:], uint16(b.Len()))
+		packet.Write(length[:])
+		packet.Write(b.Bytes())
+		if err := buf.WriteAllBytes(w.writer, packet.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// packetReader reads UDP packets written by packetWriter.
+type packetReader struct {
+	reader     io.Reader
+	packetAddr bool
+}
+
+// ReadMultiBuffer implements buf.Reader.
+func (r *packetReader) ReadMultiBuffer() (buf.MultiBuffer, error) {
+	for {
+		var endpoint *net.Destination
+		if r.packetAddr {
+			address, port, err := addrParser.ReadAddressPort(nil, r.reader)
+			if err != nil {
+				return nil, err
+			}
+			dest := net.UDPDestination(address, port)
+			endpoint = &dest
+		}
+
+		var header [2]byte
+		if _, err := io.ReadFull(r.reader, header[:]); err != nil {
+			return nil, err
+		}
+		length := int32(binary.BigEndian.Uint16(header[:]))
+		if length > buf.Size {
+			// Packets larger than a buffer are dropped.
+			if _, err := io.CopyN(io.Discard, r.reader, int64(length)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		b := buf.New()
+		if _, err := b.ReadFullFrom(r.reader, length); err != nil {
+			b.Release()
+			return nil, err
+		}
+		b.Endpoint = endpoint
+		return buf.MultiBuffer{b}, nil
+	}
+}
@@ -0,0 +1,99 @@
+package multiplex
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+
+	"github.com/v2fly/v2ray-core/v5/common/buf"
+	"github.com/v2fly/v2ray-core/v5/common/dice"
+)
+
+// paddedWrites is the number of writes, and reads, that are padded on each connection.
+const paddedWrites = 16
+
+func randomPaddingLength() int {
+	return 256 + dice.Roll(512)
+}
+
+// paddingConn pads the first reads and writes of a connection. Each padded write is prefixed
+// by the length of the payload and the length of the padding that follows it.
+type paddingConn struct {
+	net.Conn
+	readPadding      int
+	writePadding     int
+	readRemaining    int
+	paddingRemaining int
+}
+
+func newPaddingConn(conn net.Conn) net.Conn {
+	return &paddingConn{Conn: conn}
+}
+
+func (c *paddingConn) Read(p []byte) (int, error) {
+	if c.readRemaining > 0 {
+		if len(p) > c.readRemaining {
+			p = p[:c.readRemaining]
+		}
+		n, err := c.Conn.Read(p)
+		c.readRemaining -= n
+		return n, err
+	}
+	if c.paddingRemaining > 0 {
+		if _, err := io.CopyN(io.Discard, c.Conn, int64(c.paddingRemaining)); err != nil {
+			return 0, err
+		}
+		c.paddingRemaining = 0
+	}
+	if c.readPadding >= paddedWrites {
+		return c.Conn.Read(p)
+	}
+
+	var header [4]byte
+	if _, err := io.ReadFull(c.Conn, header[:]); err != nil {
+		return 0, err
+	}
+	c.readPadding++
+	dataLen := int(binary.BigEndian.Uint16(header[:2]))
+	c.paddingRemaining = int(binary.BigEndian.Uint16(header[2:]))
+	if dataLen == 0 {
+		return c.Read(p)
+	}
+	if len(p) > dataLen {
+		p = p[:dataLen]
+	}
+	n, err := c.Conn.Read(p)
+	c.readRemaining = dataLen - n
+	return n, err
+}
+
+func (c *paddingConn) Write(p []byte) (int, error) {
+	var n int
+	for len(p) > 0 {
+		data := p
+		if len(data) > 65535 {
+			data = data[:65535]
+		}
+		if err := c.write(data); err != nil {
+			return n, err
+		}
+		n += len(data)
+		p = p[len(data):]
+	}
+	return n, nil
+}
+
+func (c *paddingConn) write(p []byte) error {
+	if c.writePadding >= paddedWrites {
+		_, err := c.Conn.Write(p)
+		return err
+	}
+	c.writePadding++
+
+	paddingLen := randomPaddingLength()
+	b := make([]byte, 4+len(p)+paddingLen)
+	binary.BigEndian.PutUint16(b[:2], uint16(len(p)))
+	binary.BigEndian.PutUint16(b[2:4], uint16(paddingLen))
+	copy(b[4:], p)
+	return buf.WriteAllBytes(c.Conn, b)
+}
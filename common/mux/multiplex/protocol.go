@@ -0,0 +1,213 @@
+// Package multiplex implements multiplexing over smux, yamux and HTTP/2, in the format of sing-mux,
+// as an alternative to Mux.Cool.
+package multiplex
+
+//go:generate go run github.com/v2fly/v2ray-core/v5/common/errors/errorgen
+
+import (
+	"encoding/binary"
+	"io"
+
+	"github.com/v2fly/v2ray-core/v5/common/buf"
+	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/common/protocol"
+)
+
+// Protocol is the multiplexer used in a connection.
+type Protocol byte
+
+const (
+	ProtocolH2Mux Protocol = iota
+	ProtocolSmux
+	ProtocolYamux
+)
+
+func (p Protocol) String() string {
+	switch p {
+	case ProtocolH2Mux:
+		return "h2mux"
+	case ProtocolSmux:
+		return "smux"
+	case ProtocolYamux:
+		return "yamux"
+	default:
+		return "unknown"
+	}
+}
+
+var (
+	// Address is the destination of multiplexed connections.
+	Address = net.DomainAddress("sp.mux.sing-box.arpa")
+	Port    = net.Port(444)
+)
+
+const (
+	version0 byte = 0
+	// version1 adds padding.
+	version1 byte = 1
+)
+
+const (
+	flagUDP  uint16 = 1
+	flagAddr uint16 = 2
+)
+
+const (
+	statusSuccess byte = 0
+	statusError   byte = 1
+)
+
+var addrParser = protocol.NewAddressParser(
+	protocol.AddressFamilyByte(0x01, net.AddressFamilyIPv4),
+	protocol.AddressFamilyByte(0x04, net.AddressFamilyIPv6),
+	protocol.AddressFamilyByte(0x03, net.AddressFamilyDomain),
+)
+
+type sessionRequest struct {
+	Protocol Protocol
+	Padding  bool
+}
+
+func writeSessionRequest(writer io.Writer, request sessionRequest) error {
+	b := buf.New()
+	defer b.Release()
+
+	if !request.Padding {
+		b.WriteByte(version0)
+		b.WriteByte(byte(request.Protocol))
+		return buf.WriteAllBytes(writer, b.Bytes())
+	}
+
+	b.WriteByte(version1)
+	b.WriteByte(byte(request.Protocol))
+	b.WriteByte(1)
+	paddingLen := randomPaddingLength()
+	binary.BigEndian.PutUint16(b.Extend(2), uint16(paddingLen))
+	padding := b.Extend(int32(paddingLen))
+	for i := range padding {
+		padding[i] = 0
+	}
+	return buf.WriteAllBytes(writer, b.Bytes())
+}
+
+func readSessionRequest(reader io.Reader) (sessionRequest, error) {
+	var request sessionRequest
+	var header [2]byte
+	if _, err := io.ReadFull(reader, header[:]); err != nil {
+		return request, newError("failed to read session request").Base(err)
+	}
+	if header[0] > version1 {
+		return request, newError("unknown version: ", header[0])
+	}
+	request.Protocol = Protocol(header[1])
+	if request.Protocol > ProtocolYamux {
+		return request, newError("unknown protocol: ", header[1])
+	}
+	if header[0] == version0 {
+		return request, nil
+	}
+
+	if _, err := io.ReadFull(reader, header[:1]); err != nil {
+		return request, newError("failed to read session request").Base(err)
+	}
+	request.Padding = header[0] != 0
+	if request.Padding {
+		if _, err := io.ReadFull(reader, header[:]); err != nil {
+			return request, newError("failed to read session request").Base(err)
+		}
+		if _, err := io.CopyN(io.Discard, reader, int64(binary.BigEndian.Uint16(header[:]))); err != nil {
+			return request, newError("failed to read session request").Base(err)
+		}
+	}
+	return request, nil
+}
+
+type streamRequest struct {
+	Destination net.Destination
+	// PacketAddr is set if each UDP packet carries its own address.
+	PacketAddr bool
+}
+
+func writeStreamRequest(writer io.Writer, request streamRequest) error {
+	b := buf.New()
+	defer b.Release()
+
+	var flags uint16
+	if request.Destination.Network == net.Network_UDP {
+		flags |= flagUDP
+		if request.PacketAddr {
+			flags |= flagAddr
+		}
+	}
+	binary.BigEndian.PutUint16(b.Extend(2), flags)
+	if err := addrParser.WriteAddressPort(b, request.Destination.Address, request.Destination.Port); err != nil {
+		return err
+	}
+	return buf.WriteAllBytes(writer, b.Bytes())
+}
+
+func readStreamRequest(reader io.Reader) (streamRequest, error) {
+	var request streamRequest
+	var header [2]byte
+	if _, err := io.ReadFull(reader, header[:]); err != nil {
+		return request, newError("failed to read stream request").Base(err)
+	}
+	address, port, err := addrParser.ReadAddressPort(nil, reader)
+	if err != nil {
+		return request, newError("failed to read stream request").Base(err)
+	}
+	flags := binary.BigEndian.Uint16(header[:])
+	if flags&flagUDP != 0 {
+		request.Destination = net.UDPDestination(address, port)
+		request.PacketAddr = flags&flagAddr != 0
+	} else {
+		request.Destination = net.TCPDestination(address, port)
+	}
+	return request, nil
+}
+
+func writeStreamResponse(writer io.Writer, err error) error {
+	if err == nil {
+		return buf.WriteAllBytes(writer, []byte{statusSuccess})
+	}
+
+	message := err.Error()
+	b := make([]byte, 1+binary.MaxVarintLen64+len(message))
+	b[0] = statusError
+	n := 1 + binary.PutUvarint(b[1:], uint64(len(message)))
+	n += copy(b[n:], message)
+	return buf.WriteAllBytes(writer, b[:n])
+}
+
+type byteReader struct {
+	io.Reader
+}
+
+func (r byteReader) ReadByte() (byte, error) {
+	var b [1]byte
+	_, err := io.ReadFull(r.Reader, b[:])
+	return b[0], err
+}
+
+func readStreamResponse(reader io.Reader) error {
+	status, err := byteReader{reader}.ReadByte()
+	if err != nil {
+		return newError("failed to read stream response").Base(err)
+	}
+	if status == statusSuccess {
+		return nil
+	}
+
+	length, err := binary.ReadUvarint(byteReader{reader})
+	if err != nil {
+		return newError("failed to read stream response").Base(err)
+	}
+	if length > 1024 {
+		return newError("remote error message too long: ", length)
+	}
+	message := make([]byte, length)
+	if _, err := io.ReadFull(reader, message); err != nil {
+		return newError("failed to read stream response").Base(err)
+	}
+	return newError("remote error: ", string(message))
+}
@@ -0,0 +1,124 @@
+package multiplex
+
+import (
+	"bufio"
+	"context"
+
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/buf"
+	"github.com/v2fly/v2ray-core/v5/common/log"
+	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/common/session"
+	"github.com/v2fly/v2ray-core/v5/common/task"
+	"github.com/v2fly/v2ray-core/v5/features/routing"
+	"github.com/v2fly/v2ray-core/v5/transport"
+)
+
+// ServeLink handles a multiplexed connection on the link. The protocol of the connection is
+// detected from its session request.
+func ServeLink(ctx context.Context, dispatcher routing.Dispatcher, link *transport.Link) {
+	conn := buf.NewConnection(buf.ConnectionInputMulti(link.Writer), buf.ConnectionOutputMulti(link.Reader))
+	go serve(ctx, dispatcher, conn)
+}
+
+func serve(ctx context.Context, dispatcher routing.Dispatcher, conn net.Conn) {
+	request, err := readSessionRequest(conn)
+	if err != nil {
+		newError("failed to read session request").Base(err).WriteToLog(session.ExportIDToError(ctx))
+		conn.Close()
+		return
+	}
+	if request.Padding {
+		conn = newPaddingConn(conn)
+	}
+	s, err := newServerSession(conn, request.Protocol)
+	if err != nil {
+		newError("failed to create ", request.Protocol, " session").Base(err).WriteToLog(session.ExportIDToError(ctx))
+		conn.Close()
+		return
+	}
+	defer s.Close()
+
+	for {
+		stream, err := s.Accept()
+		if err != nil {
+			return
+		}
+		go handleStream(session.ContextWithID(ctx, session.NewID()), dispatcher, stream)
+	}
+}
+
+func handleStream(ctx context.Context, dispatcher routing.Dispatcher, stream net.Conn) {
+	defer stream.Close()
+
+	reader := bufio.NewReader(stream)
+	request, err := readStreamRequest(reader)
+	if err != nil {
+		newError("failed to read stream request").Base(err).WriteToLog(session.ExportIDToError(ctx))
+		return
+	}
+	dest := request.Destination
+
+	newError("received request for ", dest).WriteToLog(session.ExportIDToError(ctx))
+	{
+		msg := &log.AccessMessage{
+			To:     dest,
+			Status: log.AccessAccepted,
+			Reason: "",
+		}
+		if inbound := session.InboundFromContext(ctx); inbound != nil && inbound.Source.IsValid() {
+			msg.From = inbound.Source
+			if inbound.User != nil {
+				msg.Email = inbound.User.Email
+			}
+		}
+		ctx = log.ContextWithAccessMessage(ctx, msg)
+	}
+	link, err := dispatcher.Dispatch(ctx, dest)
+	if err != nil {
+		writeStreamResponse(stream, err)
+		newError("failed to dispatch request to ", dest).Base(err).WriteToLog(session.ExportIDToError(ctx))
+		return
+	}
+	if err := writeStreamResponse(stream, nil); err != nil {
+		common.Interrupt(link.Reader)
+		common.Interrupt(link.Writer)
+		return
+	}
+
+	requestDone := func() error {
+		var requestReader buf.Reader
+		if dest.Network == net.Network_UDP {
+			requestReader = &packetReader{reader: reader, packetAddr: request.PacketAddr}
+		} else {
+			requestReader = buf.NewReader(reader)
+		}
+		if err := buf.Copy(requestReader, link.Writer); err != nil {
+			return newError("failed to transfer request").Base(err)
+		}
+		return nil
+	}
+
+	responseDone := func() error {
+		var writer buf.Writer
+		if dest.Network == net.Network_UDP {
+			writer = &packetWriter{writer: stream, packetAddr: request.PacketAddr, dest: dest}
+		} else {
+			writer = buf.NewWriter(stream)
+		}
+		if err := buf.Copy(link.Reader, writer); err != nil {
+			return newError("failed to transfer response").Base(err)
+		}
+		// Streams that cannot be half closed are closed as a whole once the response ends.
+		if cw, ok := stream.(closeWriter); ok {
+			return cw.CloseWrite()
+		}
+		return stream.Close()
+	}
+
+	if err := task.Run(ctx, task.OnSuccess(requestDone, task.Close(link.Writer)), responseDone); err != nil {
+		common.Interrupt(link.Reader)
+		common.Interrupt(link.Writer)
+		newError("stream ends").Base(err).WriteToLog(session.ExportIDToError(ctx))
+	}
+}
@@ -0,0 +1,94 @@
+package multiplex
+
+import (
+	"bufio"
+	"net"
+
+	"github.com/xtaci/smux"
+)
+
+// muxSession is a connection carrying multiple streams.
+type muxSession interface {
+	Open() (net.Conn, error)
+	Accept() (net.Conn, error)
+	NumStreams() int
+	IsClosed() bool
+	Close() error
+}
+
+// closeWriter is implemented by streams that can be half closed.
+type closeWriter interface {
+	CloseWrite() error
+}
+
+func smuxConfig(version int) *smux.Config {
+	config := smux.DefaultConfig()
+	config.Version = version
+	config.KeepAliveDisabled = true
+	return config
+}
+
+type smuxSession struct {
+	*smux.Session
+}
+
+func (s smuxSession) Open() (net.Conn, error) {
+	return s.OpenStream()
+}
+
+func (s smuxSession) Accept() (net.Conn, error) {
+	return s.AcceptStream()
+}
+
+func newClientSession(conn net.Conn, protocol Protocol, smuxVersion int) (muxSession, error) {
+	switch protocol {
+	case ProtocolH2Mux:
+		return newH2MuxClientSession(conn)
+	case ProtocolSmux:
+		if smuxVersion == 0 {
+			smuxVersion = 1
+		}
+		session, err := smux.Client(conn, smuxConfig(smuxVersion))
+		if err != nil {
+			return nil, err
+		}
+		return smuxSession{session}, nil
+	case ProtocolYamux:
+		return newYamuxSession(conn, true)
+	default:
+		return nil, newError("unknown protocol: ", protocol)
+	}
+}
+
+// bufferedConn is a net.Conn with data read ahead.
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.reader.Read(p)
+}
+
+func newServerSession(conn net.Conn, protocol Protocol) (muxSession, error) {
+	switch protocol {
+	case ProtocolH2Mux:
+		return newH2MuxServerSession(conn), nil
+	case ProtocolSmux:
+		// Detect the version of smux from the first frame.
+		reader := bufio.NewReader(conn)
+		header, err := reader.Peek(1)
+		if err != nil {
+			return nil, newError("failed to read smux version").Base(err)
+		}
+		session, err := smux.Server(&bufferedConn{Conn: conn, reader: reader}, smuxConfig(int(header[0])))
+		if err != nil {
+			return nil, err
+		}
+		return smuxSession{session}, nil
+	case ProtocolYamux:
+		return newYamuxSession(conn, false)
+	default:
+		return nil, newError("unknown protocol: ", protocol)
+	}
+}
@@ -0,0 +1,31 @@
+package multiplex
+
+import (
+	"io"
+	"net"
+
+	"github.com/hashicorp/yamux"
+)
+
+// yamuxConfig returns the config of yamux sessions, as of sing-mux.
+func yamuxConfig() *yamux.Config {
+	config := yamux.DefaultConfig()
+	config.LogOutput = io.Discard
+	return config
+}
+
+// newYamuxSession creates a yamux session over the connection. Streams of yamux can not be half
+// closed, as they return EOF on reads once closed locally.
+func newYamuxSession(conn net.Conn, client bool) (muxSession, error) {
+	var session *yamux.Session
+	var err error
+	if client {
+		session, err = yamux.Client(conn, yamuxConfig())
+	} else {
+		session, err = yamux.Server(conn, yamuxConfig())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
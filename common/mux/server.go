@@ -9,6 +9,7 @@ import (
 	"github.com/v2fly/v2ray-core/v5/common/buf"
 	"github.com/v2fly/v2ray-core/v5/common/errors"
 	"github.com/v2fly/v2ray-core/v5/common/log"
+	"github.com/v2fly/v2ray-core/v5/common/mux/multiplex"
 	"github.com/v2fly/v2ray-core/v5/common/net"
 	"github.com/v2fly/v2ray-core/v5/common/protocol"
 	"github.com/v2fly/v2ray-core/v5/common/session"
@@ -37,7 +38,7 @@ func (s *Server) Type() interface{} {
 
 // Dispatch implements routing.Dispatcher
 func (s *Server) Dispatch(ctx context.Context, dest net.Destination) (*transport.Link, error) {
-	if dest.Address != muxCoolAddress {
+	if dest.Address != muxCoolAddress && dest.Address != multiplex.Address {
 		return s.dispatcher.Dispatch(ctx, dest)
 	}
 
@@ -45,6 +46,14 @@ func (s *Server) Dispatch(ctx context.Context, dest net.Destination) (*transport
 	uplinkReader, uplinkWriter := pipe.New(opts...)
 	downlinkReader, downlinkWriter := pipe.New(opts...)
 
+	if dest.Address == multiplex.Address {
+		multiplex.ServeLink(ctx, s.dispatcher, &transport.Link{
+			Reader: uplinkReader,
+			Writer: downlinkWriter,
+		})
+		return &transport.Link{Reader: downlinkReader, Writer: uplinkWriter}, nil
+	}
+
 	_, err := NewServerWorker(ctx, s.dispatcher, &transport.Link{
 		Reader: uplinkReader,
 		Writer: downlinkWriter,
@@ -58,6 +67,10 @@ func (s *Server) Dispatch(ctx context.Context, dest net.Destination) (*transport
 
 // DispatchLink implements routing.Dispatcher
 func (s *Server) DispatchLink(ctx context.Context, dest net.Destination, outbound *transport.Link) error {
+	if dest.Address == multiplex.Address {
+		multiplex.ServeLink(ctx, s.dispatcher, outbound)
+		return nil
+	}
 	if dest.Address != muxCoolAddress {
 		return s.dispatcher.DispatchLink(ctx, dest, outbound)
 	}
@@ -93,6 +106,7 @@ func NewServerWorker(ctx context.Context, d routing.Dispatcher, link *transport.
 
 func handle(ctx context.Context, s *Session, output buf.Writer) {
 	writer := NewResponseWriter(s.ID, output, s.transferType)
+	writer.window = s.sendWindow
 	if err := buf.Copy(s.input, &endpointWrapperWriter{Writer: writer, Session: s}); err != nil {
 		newError("session ", s.ID, " ends.").Base(err).WriteToLog(session.ExportIDToError(ctx))
 		writer.hasError = true
@@ -111,6 +125,11 @@ func (w *ServerWorker) Closed() bool {
 }
 
 func (w *ServerWorker) handleStatusKeepAlive(meta *FrameMetadata, reader *buf.BufferedReader) error {
+	if meta.Option.Has(OptionFlowControl) {
+		if s, found := w.sessionManager.Get(meta.SessionID); found && s.sendWindow != nil {
+			s.sendWindow.grant(meta.WindowIncrement)
+		}
+	}
 	if meta.Option.Has(OptionData) {
 		return buf.Copy(NewStreamReader(reader), buf.Discard)
 	}
@@ -149,13 +168,32 @@ func (w *ServerWorker) handleStatusNew(ctx context.Context, meta *FrameMetadata,
 	if meta.Target.Network == net.Network_UDP {
 		s.transferType = protocol.TransferTypePacket
 	}
+	if meta.Option.Has(OptionFlowControl) {
+		s.sendWindow = newSendWindow(true)
+		s.receiveQueue = newReceiveQueue()
+		s.receiveQueue.activate()
+	}
 	w.sessionManager.Add(s)
+	if s.receiveQueue != nil {
+		// The acknowledgement must precede any response data.
+		if err := writeFlowControlAck(w.link.Writer, s.ID); err != nil {
+			s.receiveQueue.interrupt()
+			common.Interrupt(s.input)
+			common.Interrupt(s.output)
+			s.Close()
+			return newError("failed to acknowledge flow control").Base(err)
+		}
+		go s.receive(w.link.Writer)
+	}
 	go handle(ctx, s, w.link.Writer)
 	if !meta.Option.Has(OptionData) {
 		return nil
 	}
 
 	rr := s.NewReader(reader, &meta.Target)
+	if s.receiveQueue != nil {
+		return s.receiveFrom(rr, false, w.link.Writer)
+	}
 	if err := buf.Copy(rr, s.output); err != nil {
 		buf.Copy(rr, buf.Discard)
 		common.Interrupt(s.input)
@@ -191,6 +229,9 @@ func (w *ServerWorker) handleStatusKeep(meta *FrameMetadata, reader *buf.Buffere
 	}
 
 	rr := s.NewReader(reader, &meta.Target)
+	if s.receiveQueue != nil {
+		return s.receiveFrom(rr, meta.Option.Has(OptionFlowControl), w.link.Writer)
+	}
 	err := buf.Copy(rr, s.output)
 
 	if err != nil && buf.IsWriteError(err) {
@@ -212,6 +253,9 @@ func (w *ServerWorker) handleStatusKeep(meta *FrameMetadata, reader *buf.Buffere
 func (w *ServerWorker) handleStatusEnd(meta *FrameMetadata, reader *buf.BufferedReader) error {
 	if s, found := w.sessionManager.Get(meta.SessionID); found {
 		if meta.Option.Has(OptionError) {
+			if s.receiveQueue != nil {
+				s.receiveQueue.interrupt()
+			}
 			common.Interrupt(s.input)
 			common.Interrupt(s.output)
 		}
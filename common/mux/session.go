@@ -127,6 +127,12 @@ func (m *SessionManager) Close() error {
 	m.closed = true
 
 	for _, s := range m.sessions {
+		if s.receiveQueue != nil {
+			s.receiveQueue.interrupt()
+		}
+		if s.sendWindow != nil {
+			s.sendWindow.Close()
+		}
 		common.Close(s.input)
 		common.Close(s.output)
 	}
@@ -144,11 +150,20 @@ type Session struct {
 	transferType protocol.TransferType
 	endpoint     net.Destination
 	sendEndpoint int
+
+	// Flow control, only if both peers support it.
+	sendWindow   *sendWindow
+	receiveQueue *receiveQueue
 }
 
 // Close closes all resources associated with this session.
 func (s *Session) Close() error {
-	common.Close(s.output)
+	if s.receiveQueue == nil || !s.receiveQueue.close() {
+		common.Close(s.output)
+	}
+	if s.sendWindow != nil {
+		s.sendWindow.Close()
+	}
 	common.Close(s.input)
 	s.parent.Remove(s.ID)
 	return nil
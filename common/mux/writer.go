@@ -16,6 +16,7 @@ type Writer struct {
 	followup     bool
 	hasError     bool
 	transferType protocol.TransferType
+	window       *sendWindow
 }
 
 func NewWriter(id uint16, dest net.Destination, writer buf.Writer, transferType protocol.TransferType) *Writer {
@@ -45,9 +46,17 @@ func (w *Writer) getNextFrameMeta() FrameMetadata {
 
 	if w.followup {
 		meta.SessionStatus = SessionStatusKeep
+		// Frames sent after flow control is acknowledged are marked, so that the peer limits
+		// them by the window.
+		if w.window != nil && w.window.isEnabled() {
+			meta.Option.Set(OptionFlowControl)
+		}
 	} else {
 		w.followup = true
 		meta.SessionStatus = SessionStatusNew
+		if w.window != nil {
+			meta.Option.Set(OptionFlowControl)
+		}
 	}
 
 	return meta
@@ -102,11 +111,30 @@ func (w *Writer) WriteMultiBuffer(mb buf.MultiBuffer) error {
 	for !mb.IsEmpty() {
 		var chunk buf.MultiBuffer
 		if w.transferType == protocol.TransferTypeStream {
-			mb, chunk = buf.SplitSize(mb, 8*1024)
+			size := int32(8 * 1024)
+			if w.window != nil {
+				if mb.Len() < size {
+					size = mb.Len()
+				}
+				var err error
+				if size, err = w.window.acquire(size); err != nil {
+					return err
+				}
+			}
+			mb, chunk = buf.SplitSize(mb, size)
 		} else {
 			mb2, b := buf.SplitFirst(mb)
 			mb = mb2
 			chunk = buf.MultiBuffer{b}
+			if w.window != nil && b.Len() > 0 {
+				n, err := w.window.acquire(b.Len())
+				if err != nil {
+					b.Release()
+					return err
+				}
+				// Packets are never split.
+				w.window.consume(b.Len() - n)
+			}
 		}
 		if err := w.writeData(chunk); err != nil {
 			return err
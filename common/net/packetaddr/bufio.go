@@ -1,6 +1,7 @@
 package packetaddr
 
 import (
+	"github.com/v2fly/v2ray-core/v5/common"
 	"github.com/v2fly/v2ray-core/v5/common/buf"
 	"github.com/v2fly/v2ray-core/v5/common/net"
 )
@@ -50,6 +51,10 @@ func (r *BufReader) ReadMultiBuffer() (buf.MultiBuffer, error) {
 	return mb, nil
 }
 
+func (r *BufReader) Interrupt() {
+	common.Interrupt(r.Reader)
+}
+
 func NewPacketReader(reader buf.Reader) *BufReader {
 	return &BufReader{
 		Reader: reader,
@@ -105,6 +110,14 @@ func (w *BufWriter) WriteMultiBuffer(mb buf.MultiBuffer) error {
 	return w.Writer.WriteMultiBuffer(mb)
 }
 
+func (w *BufWriter) Close() error {
+	return common.Close(w.Writer)
+}
+
+func (w *BufWriter) Interrupt() {
+	common.Interrupt(w.Writer)
+}
+
 func NewPacketWriter(writer buf.Writer, dest net.Destination) *BufWriter {
 	return &BufWriter{
 		Writer: writer,
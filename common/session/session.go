@@ -24,12 +24,21 @@ func NewID() ID {
 	}
 }
 
-// ExportIDToError transfers session.ID into an error object, for logging purpose.
-// This can be used with error.WriteToLog().
+// ExportIDToError transfers session.ID and the tags of the handlers of the session into an error
+// object, for logging purpose. This can be used with error.WriteToLog().
 func ExportIDToError(ctx context.Context) errors.ExportOption {
 	id := IDFromContext(ctx)
+	var inboundTag, outboundTag string
+	if inbound := InboundFromContext(ctx); inbound != nil {
+		inboundTag = inbound.Tag
+	}
+	if outbound := OutboundFromContext(ctx); outbound != nil {
+		outboundTag = outbound.Tag
+	}
 	return func(h *errors.ExportOptionHolder) {
 		h.SessionID = uint32(id)
+		h.InboundTag = inboundTag
+		h.OutboundTag = outboundTag
 	}
 }
 
@@ -57,6 +66,8 @@ type Outbound struct {
 	RouteTarget net.Destination
 	// Gateway address
 	Gateway net.Address
+	// Tag of the outbound handler that handles the connection.
+	Tag string
 }
 
 // SniffingRequest controls the behavior of content sniffing.
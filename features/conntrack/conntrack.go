@@ -0,0 +1,70 @@
+package conntrack
+
+import (
+	"time"
+
+	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/features"
+	"github.com/v2fly/v2ray-core/v5/features/stats"
+)
+
+// Connection is a session dispatched to an outbound handler.
+type Connection struct {
+	// ID is assigned by the Tracker.
+	ID          uint64
+	InboundTag  string
+	User        string
+	Source      net.Destination
+	Destination net.Destination
+	// Domain is the sniffed domain of the connection, if any.
+	Domain      string
+	Protocol    string
+	OutboundTag string
+	Start       time.Time
+	// Uplink and Downlink count the bytes sent and received by the client.
+	Uplink   stats.Counter
+	Downlink stats.Counter
+	// Interrupt terminates the connection.
+	Interrupt func()
+}
+
+// EventType is the type of an Event.
+type EventType int
+
+const (
+	// EventOpen is published when a connection is tracked.
+	EventOpen EventType = iota
+	// EventClose is published when a connection ends.
+	EventClose
+)
+
+// Event is a change of the tracked connections.
+type Event struct {
+	Type       EventType
+	Connection *Connection
+}
+
+// Tracker records active connections, and terminates them on request.
+type Tracker interface {
+	features.Feature
+
+	// Track adds a connection to the tracker. It returns a function that removes the connection,
+	// which must be called when the connection ends.
+	Track(*Connection) (done func())
+	// List returns all active connections.
+	List() []*Connection
+	// CloseConnection terminates a connection by its ID, and returns false if the connection is not found.
+	CloseConnection(id uint64) bool
+	// CloseUser terminates all connections of a user, and returns the number of connections.
+	CloseUser(email string) int
+
+	// Subscribe registers for listening to connection events and returns a new listener channel.
+	Subscribe() (chan Event, error)
+	// Unsubscribe unregisters a listener channel.
+	Unsubscribe(chan Event) error
+}
+
+// TrackerType returns the type of Tracker interface. Can be used to implement common.HasType.
+func TrackerType() interface{} {
+	return (*Tracker)(nil)
+}
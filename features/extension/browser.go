@@ -7,6 +7,8 @@ import (
 
 type BrowserForwarder interface {
 	DialWebsocket(url string, header http.Header) (io.ReadWriteCloser, error)
+	// RoundTrip sends an HTTP request with the fetch API of the browser.
+	RoundTrip(request *http.Request) (*http.Response, error)
 }
 
 func BrowserForwarderType() interface{} {
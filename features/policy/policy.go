@@ -35,6 +35,16 @@ type Buffer struct {
 	PerConnection int32
 }
 
+// Limit contains limits of throughput and concurrent connections.
+type Limit struct {
+	// Rate of uplink traffic in bytes per second. 0 for unlimited.
+	Uplink uint64
+	// Rate of downlink traffic in bytes per second. 0 for unlimited.
+	Downlink uint64
+	// Maximum number of concurrent connections. 0 for unlimited.
+	Connections uint32
+}
+
 // SystemStats contains stat policy settings on system level.
 type SystemStats struct {
 	// Whether or not to enable stat counter for uplink traffic in inbound handlers.
@@ -58,6 +68,7 @@ type Session struct {
 	Timeouts Timeout // Timeout settings
 	Stats    Stats
 	Buffer   Buffer
+	Limit    Limit
 }
 
 // Manager is a feature that provides Policy for the given user by its id or level.
@@ -73,6 +84,21 @@ type Manager interface {
 	ForSystem() System
 }
 
+// RateLimiter limits the rate of traffic.
+type RateLimiter interface {
+	// Wait blocks until size bytes are allowed to pass, or the context is done.
+	Wait(ctx context.Context, size int64) error
+}
+
+// Limiter is implemented by Managers that enforce the limits of users and inbounds.
+type Limiter interface {
+	// AcquireLimit registers a new connection of an inbound and a user, and returns an error if
+	// there are too many connections. Otherwise, the uplink and downlink traffic of the connection
+	// must pass the returned rate limiters, which are nil if unlimited, and done must be called when
+	// the connection ends.
+	AcquireLimit(inboundTag string, email string, level uint32) (uplink RateLimiter, downlink RateLimiter, done func(), err error)
+}
+
 // ManagerType returns the type of Manager interface. Can be used to implement common.HasType.
 //
 // v2ray:api:stable
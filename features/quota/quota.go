@@ -0,0 +1,22 @@
+package quota
+
+import (
+	"github.com/v2fly/v2ray-core/v5/features"
+	"github.com/v2fly/v2ray-core/v5/features/stats"
+)
+
+// Manager enforces traffic quotas of users and inbounds.
+type Manager interface {
+	features.Feature
+
+	// Acquire registers a new connection of an inbound and a user, and returns an error if a quota
+	// of either is exceeded. Otherwise, the traffic of the connection must be added to the returned
+	// counter, which is nil if no quota applies. interrupt is called to terminate the connection
+	// when a quota is exceeded, and done must be called when the connection ends.
+	Acquire(inboundTag string, user string, interrupt func()) (counter stats.Counter, done func(), err error)
+}
+
+// ManagerType returns the type of Manager interface. Can be used to implement common.HasType.
+func ManagerType() interface{} {
+	return (*Manager)(nil)
+}
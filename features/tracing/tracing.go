@@ -0,0 +1,91 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/v2fly/v2ray-core/v5/features"
+)
+
+// Span is a timed operation of a connection, such as dialing or a DNS lookup.
+type Span interface {
+	// SetAttribute sets an attribute of the span. The value is a string, an integer, a bool or a
+	// float64. Other values are recorded as strings.
+	SetAttribute(key string, value interface{})
+	// RecordError marks the span as failed with the error. Nil errors are ignored.
+	RecordError(err error)
+	// End completes the span. Calls after the first are ignored.
+	End()
+	// IsRecording returns whether the span is recorded, which is false for spans of traces that
+	// are not sampled.
+	IsRecording() bool
+}
+
+// Tracer records spans of dispatched connections.
+type Tracer interface {
+	features.Feature
+
+	// StartSpan starts a span with the name, as a child of the span in the context if any. The
+	// returned context contains the new span. Spans of traces that are not sampled are not recorded.
+	StartSpan(ctx context.Context, name string) (context.Context, Span)
+}
+
+// TracerType returns the type of Tracer interface. Can be used to implement common.HasType.
+func TracerType() interface{} {
+	return (*Tracer)(nil)
+}
+
+type tracingKey int
+
+const (
+	tracerKey tracingKey = iota
+	spanKey
+)
+
+// ContextWithTracer returns a new context with the tracer, so that spans are started by Start.
+func ContextWithTracer(ctx context.Context, tracer Tracer) context.Context {
+	return context.WithValue(ctx, tracerKey, tracer)
+}
+
+// TracerFromContext returns the tracer in the context, or nil if not traced.
+func TracerFromContext(ctx context.Context) Tracer {
+	if tracer, ok := ctx.Value(tracerKey).(Tracer); ok {
+		return tracer
+	}
+	return nil
+}
+
+// ContextWithSpan returns a new context with the span as the current span. It is used by tracers.
+func ContextWithSpan(ctx context.Context, span Span) context.Context {
+	return context.WithValue(ctx, spanKey, span)
+}
+
+// SpanFromContext returns the current span in the context, or nil if none.
+func SpanFromContext(ctx context.Context) Span {
+	if span, ok := ctx.Value(spanKey).(Span); ok {
+		return span
+	}
+	return nil
+}
+
+// IsRecording returns whether the current span in the context is recorded, so that operations
+// only done for tracing, such as eager handshakes, are skipped for connections not sampled.
+func IsRecording(ctx context.Context) bool {
+	span := SpanFromContext(ctx)
+	return span != nil && span.IsRecording()
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttribute(string, interface{}) {}
+func (noopSpan) RecordError(error)                {}
+func (noopSpan) End()                             {}
+func (noopSpan) IsRecording() bool                { return false }
+
+// Start starts a span by the tracer in the context. If the context is not traced, the returned
+// span does nothing, so that operations are only traced as part of a traced connection.
+func Start(ctx context.Context, name string) (context.Context, Span) {
+	if tracer := TracerFromContext(ctx); tracer != nil {
+		return tracer.StartSpan(ctx, name)
+	}
+	return ctx, noopSpan{}
+}
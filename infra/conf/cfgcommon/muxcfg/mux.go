@@ -1,6 +1,10 @@
 package muxcfg
 
+//go:generate go run github.com/v2fly/v2ray-core/v5/common/errors/errorgen
+
 import (
+	"strings"
+
 	"github.com/v2fly/v2ray-core/v5/app/proxyman"
 	"github.com/v2fly/v2ray-core/v5/common/net/packetaddr"
 )
@@ -9,12 +13,15 @@ type MuxConfig struct {
 	Enabled        bool   `json:"enabled"`
 	Concurrency    int16  `json:"concurrency"`
 	PacketEncoding string `json:"packetEncoding"`
+	Protocol       string `json:"protocol"`
+	SmuxVersion    uint32 `json:"smuxVersion"`
+	Padding        bool   `json:"padding"`
 }
 
 // Build creates MultiplexingConfig, Concurrency < 0 completely disables mux.
-func (m *MuxConfig) Build() *proxyman.MultiplexingConfig {
+func (m *MuxConfig) Build() (*proxyman.MultiplexingConfig, error) {
 	if m.Concurrency < 0 {
-		return nil
+		return nil, nil
 	}
 
 	var con uint32 = 8
@@ -30,9 +37,26 @@ func (m *MuxConfig) Build() *proxyman.MultiplexingConfig {
 		packetEncoding = packetaddr.PacketAddrType_XUDP
 	}
 
+	var protocol proxyman.MultiplexingProtocol
+	switch strings.ToLower(m.Protocol) {
+	case "", "mux.cool", "muxcool":
+		protocol = proxyman.MultiplexingProtocol_MuxCool
+	case "h2mux":
+		protocol = proxyman.MultiplexingProtocol_H2Mux
+	case "smux":
+		protocol = proxyman.MultiplexingProtocol_Smux
+	case "yamux":
+		protocol = proxyman.MultiplexingProtocol_Yamux
+	default:
+		return nil, newError("unknown mux protocol: ", m.Protocol)
+	}
+
 	return &proxyman.MultiplexingConfig{
 		Enabled:        m.Enabled,
 		Concurrency:    con,
 		PacketEncoding: packetEncoding,
-	}
+		Protocol:       protocol,
+		SmuxVersion:    m.SmuxVersion,
+		Padding:        m.Padding,
+	}, nil
 }
@@ -3,17 +3,36 @@ package socketcfg
 import (
 	"strings"
 
+	"github.com/v2fly/v2ray-core/v5/infra/conf/cfgcommon"
 	"github.com/v2fly/v2ray-core/v5/transport/internet"
 )
 
+//go:generate go run github.com/v2fly/v2ray-core/v5/common/errors/errorgen
+
 type SocketConfig struct {
-	Mark                 uint32 `json:"mark"`
-	TFO                  *bool  `json:"tcpFastOpen"`
-	TProxy               string `json:"tproxy"`
-	AcceptProxyProtocol  bool   `json:"acceptProxyProtocol"`
-	TCPKeepAliveInterval int32  `json:"tcpKeepAliveInterval"`
-	TCPKeepAliveIdle     int32  `json:"tcpKeepAliveIdle"`
-	TFOQueueLength       uint32 `json:"tcpFastOpenQueueLength"`
+	Mark                    uint32               `json:"mark"`
+	TFO                     *bool                `json:"tcpFastOpen"`
+	TProxy                  string               `json:"tproxy"`
+	AcceptProxyProtocol     bool                 `json:"acceptProxyProtocol"`
+	TCPKeepAliveInterval    int32                `json:"tcpKeepAliveInterval"`
+	TCPKeepAliveIdle        int32                `json:"tcpKeepAliveIdle"`
+	TFOQueueLength          uint32               `json:"tcpFastOpenQueueLength"`
+	BindAddressPool         []*cfgcommon.Address `json:"bindAddressPool"`
+	BindAddressPoolStrategy string               `json:"bindAddressPoolStrategy"`
+}
+
+// ParseAddressPoolStrategy converts the name of an address pool strategy to its value.
+func ParseAddressPoolStrategy(strategy string) (internet.AddressPoolStrategy, error) {
+	switch strings.ToLower(strategy) {
+	case "", "random":
+		return internet.AddressPoolStrategy_Random, nil
+	case "hashuser", "hash_user", "user":
+		return internet.AddressPoolStrategy_HashUser, nil
+	case "hashdestination", "hash_destination", "destination":
+		return internet.AddressPoolStrategy_HashDestination, nil
+	default:
+		return internet.AddressPoolStrategy_Random, newError("unknown address pool strategy: ", strategy)
+	}
 }
 
 // Build implements Buildable.
@@ -42,13 +61,27 @@ func (c *SocketConfig) Build() (*internet.SocketConfig, error) {
 		tproxy = internet.SocketConfig_Off
 	}
 
+	var bindAddressPool [][]byte
+	for _, address := range c.BindAddressPool {
+		if !address.Family().IsIP() {
+			return nil, newError("invalid bind address: ", address.String())
+		}
+		bindAddressPool = append(bindAddressPool, address.IP())
+	}
+	bindAddressPoolStrategy, err := ParseAddressPoolStrategy(c.BindAddressPoolStrategy)
+	if err != nil {
+		return nil, err
+	}
+
 	return &internet.SocketConfig{
-		Mark:                 c.Mark,
-		Tfo:                  tfoSettings,
-		TfoQueueLength:       tfoQueueLength,
-		Tproxy:               tproxy,
-		AcceptProxyProtocol:  c.AcceptProxyProtocol,
-		TcpKeepAliveInterval: c.TCPKeepAliveInterval,
-		TcpKeepAliveIdle:     c.TCPKeepAliveIdle,
+		Mark:                    c.Mark,
+		Tfo:                     tfoSettings,
+		TfoQueueLength:          tfoQueueLength,
+		Tproxy:                  tproxy,
+		AcceptProxyProtocol:     c.AcceptProxyProtocol,
+		TcpKeepAliveInterval:    c.TCPKeepAliveInterval,
+		TcpKeepAliveIdle:        c.TCPKeepAliveIdle,
+		BindAddressPool:         bindAddressPool,
+		BindAddressPoolStrategy: bindAddressPoolStrategy,
 	}, nil
 }
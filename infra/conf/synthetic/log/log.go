@@ -4,6 +4,7 @@ import (
 	"strings"
 
 	"github.com/v2fly/v2ray-core/v5/app/log"
+	"github.com/v2fly/v2ray-core/v5/app/router/routercommon"
 	clog "github.com/v2fly/v2ray-core/v5/common/log"
 )
 
@@ -18,6 +19,154 @@ type LogConfig struct { // nolint: revive
 	AccessLog string `json:"access"`
 	ErrorLog  string `json:"error"`
 	LogLevel  string `json:"loglevel"`
+
+	// Format is "text", "json" or "logfmt", for both access and error logs.
+	Format      string   `json:"format"`
+	Fields      []string `json:"fields"`
+	MaskIP      string   `json:"maskIP"`
+	RecordClose bool     `json:"recordClose"`
+
+	Rotation *RotationConfig `json:"rotation"`
+	Syslog   *SyslogConfig   `json:"syslog"`
+
+	AccessFilters    []*AccessFilterConfig `json:"accessFilters"`
+	AccessSampleRate float32               `json:"accessSampleRate"`
+	// Levels of error logs by tags of inbounds and outbounds, such as "debug".
+	InboundLevels  map[string]string `json:"inboundLevels"`
+	OutboundLevels map[string]string `json:"outboundLevels"`
+}
+
+// AccessFilterConfig is a filter of access logs. Domains are in the form of routing rules, such as
+// "domain:v2fly.org", "full:www.v2fly.org", "regexp:.*\\.org$" or "keyword:v2fly".
+type AccessFilterConfig struct {
+	InboundTags  []string `json:"inboundTag"`
+	OutboundTags []string `json:"outboundTag"`
+	Users        []string `json:"user"`
+	Domains      []string `json:"domain"`
+	Statuses     []string `json:"status"`
+	Exclude      bool     `json:"exclude"`
+}
+
+func (c *AccessFilterConfig) Build() *log.AccessFilter {
+	filter := &log.AccessFilter{
+		InboundTag:  c.InboundTags,
+		OutboundTag: c.OutboundTags,
+		User:        c.Users,
+		Status:      c.Statuses,
+		Exclude:     c.Exclude,
+	}
+	for _, d := range c.Domains {
+		domain := &routercommon.Domain{Type: routercommon.Domain_Plain, Value: d}
+		switch {
+		case strings.HasPrefix(d, "domain:"):
+			domain.Type = routercommon.Domain_RootDomain
+			domain.Value = d[len("domain:"):]
+		case strings.HasPrefix(d, "full:"):
+			domain.Type = routercommon.Domain_Full
+			domain.Value = d[len("full:"):]
+		case strings.HasPrefix(d, "regexp:"):
+			domain.Type = routercommon.Domain_Regex
+			domain.Value = d[len("regexp:"):]
+		case strings.HasPrefix(d, "keyword:"):
+			domain.Value = d[len("keyword:"):]
+		}
+		filter.Domain = append(filter.Domain, domain)
+	}
+	return filter
+}
+
+func parseSeverity(level string) clog.Severity {
+	switch strings.ToLower(level) {
+	case "debug":
+		return clog.Severity_Debug
+	case "info":
+		return clog.Severity_Info
+	case "error":
+		return clog.Severity_Error
+	default:
+		return clog.Severity_Warning
+	}
+}
+
+// RotationConfig is the rotation of log files.
+type RotationConfig struct {
+	// MaxSize is in megabytes.
+	MaxSize uint64 `json:"maxSize"`
+	// Interval is "hourly", "daily" or "weekly".
+	Interval   string `json:"interval"`
+	MaxBackups uint32 `json:"maxBackups"`
+	// MaxAge is in days.
+	MaxAge   uint32 `json:"maxAge"`
+	Compress bool   `json:"compress"`
+}
+
+func (c *RotationConfig) Build() *log.LogSpecification_Rotation {
+	rotation := &log.LogSpecification_Rotation{
+		MaxSize:    c.MaxSize * 1024 * 1024,
+		MaxBackups: c.MaxBackups,
+		MaxAge:     c.MaxAge * 24 * 3600,
+		Compress:   c.Compress,
+	}
+	switch strings.ToLower(c.Interval) {
+	case "hourly":
+		rotation.Interval = 3600
+	case "daily":
+		rotation.Interval = 24 * 3600
+	case "weekly":
+		rotation.Interval = 7 * 24 * 3600
+	}
+	return rotation
+}
+
+// SyslogConfig is the syslog server of logs.
+type SyslogConfig struct {
+	Network string `json:"network"`
+	Address string `json:"address"`
+	Tag     string `json:"tag"`
+	// Facility is a name such as "daemon" or "local0".
+	Facility string `json:"facility"`
+}
+
+var syslogFacilities = map[string]uint32{
+	"kern": 0, "user": 1, "mail": 2, "daemon": 3, "auth": 4, "syslog": 5, "lpr": 6, "news": 7,
+	"uucp": 8, "cron": 9, "authpriv": 10, "ftp": 11,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19, "local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+func (c *SyslogConfig) Build() *log.LogSpecification_Syslog {
+	facility, found := syslogFacilities[strings.ToLower(c.Facility)]
+	if !found {
+		facility = syslogFacilities["daemon"]
+	}
+	return &log.LogSpecification_Syslog{
+		Network:  c.Network,
+		Address:  c.Address,
+		Tag:      c.Tag,
+		Facility: facility,
+	}
+}
+
+// buildSpecification sets the type of the log specification by the path, which is "none",
+// "syslog", "journald" or the path of a log file.
+func (v *LogConfig) buildSpecification(spec *log.LogSpecification, path string) {
+	switch path {
+	case "":
+	case "none":
+		spec.Type = log.LogType_None
+	case "syslog":
+		spec.Type = log.LogType_Syslog
+	case "journald":
+		spec.Type = log.LogType_Journald
+	default:
+		spec.Path = path
+		spec.Type = log.LogType_File
+		if v.Rotation != nil {
+			spec.Rotation = v.Rotation.Build()
+		}
+	}
+	if v.Syslog != nil {
+		spec.Syslog = v.Syslog.Build()
+	}
 }
 
 func (v *LogConfig) Build() *log.Config {
@@ -29,18 +178,8 @@ func (v *LogConfig) Build() *log.Config {
 		Error:  &log.LogSpecification{Type: log.LogType_Console},
 	}
 
-	if v.AccessLog == "none" {
-		config.Access.Type = log.LogType_None
-	} else if len(v.AccessLog) > 0 {
-		config.Access.Path = v.AccessLog
-		config.Access.Type = log.LogType_File
-	}
-	if v.ErrorLog == "none" {
-		config.Error.Type = log.LogType_None
-	} else if len(v.ErrorLog) > 0 {
-		config.Error.Path = v.ErrorLog
-		config.Error.Type = log.LogType_File
-	}
+	v.buildSpecification(config.Access, v.AccessLog)
+	v.buildSpecification(config.Error, v.ErrorLog)
 
 	level := strings.ToLower(v.LogLevel)
 	switch level {
@@ -56,5 +195,49 @@ func (v *LogConfig) Build() *log.Config {
 	default:
 		config.Error.Level = clog.Severity_Warning
 	}
+
+	var format log.LogFormat
+	switch strings.ToLower(v.Format) {
+	case "json":
+		format = log.LogFormat_JSON
+	case "logfmt":
+		format = log.LogFormat_Logfmt
+	default:
+		format = log.LogFormat_Text
+	}
+	var maskIP log.LogSpecification_IPMask
+	switch strings.ToLower(v.MaskIP) {
+	case "subnet":
+		maskIP = log.LogSpecification_Subnet
+	case "full":
+		maskIP = log.LogSpecification_Full
+	default:
+		maskIP = log.LogSpecification_NoMask
+	}
+	for _, spec := range []*log.LogSpecification{config.Access, config.Error} {
+		spec.Format = format
+		spec.Fields = v.Fields
+		spec.MaskIp = maskIP
+	}
+	config.Access.RecordClose = v.RecordClose
+
+	for _, f := range v.AccessFilters {
+		if f != nil {
+			config.AccessFilter = append(config.AccessFilter, f.Build())
+		}
+	}
+	config.AccessSampleRate = v.AccessSampleRate
+	if len(v.InboundLevels) > 0 {
+		config.InboundLevel = make(map[string]clog.Severity, len(v.InboundLevels))
+		for tag, level := range v.InboundLevels {
+			config.InboundLevel[tag] = parseSeverity(level)
+		}
+	}
+	if len(v.OutboundLevels) > 0 {
+		config.OutboundLevel = make(map[string]clog.Severity, len(v.OutboundLevels))
+		for tag, level := range v.OutboundLevels {
+			config.OutboundLevel[tag] = parseSeverity(level)
+		}
+	}
 	return config
 }
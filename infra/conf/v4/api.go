@@ -8,9 +8,11 @@ import (
 	"google.golang.org/protobuf/types/known/anypb"
 
 	"github.com/v2fly/v2ray-core/v5/app/commander"
+	conntrackservice "github.com/v2fly/v2ray-core/v5/app/conntrack/command"
 	loggerservice "github.com/v2fly/v2ray-core/v5/app/log/command"
 	observatoryservice "github.com/v2fly/v2ray-core/v5/app/observatory/command"
 	handlerservice "github.com/v2fly/v2ray-core/v5/app/proxyman/command"
+	quotaservice "github.com/v2fly/v2ray-core/v5/app/quota/command"
 	routerservice "github.com/v2fly/v2ray-core/v5/app/router/command"
 	statsservice "github.com/v2fly/v2ray-core/v5/app/stats/command"
 	"github.com/v2fly/v2ray-core/v5/common/serial"
@@ -41,6 +43,10 @@ func (c *APIConfig) Build() (*commander.Config, error) {
 			services = append(services, serial.ToTypedMessage(&observatoryservice.Config{}))
 		case "routingservice":
 			services = append(services, serial.ToTypedMessage(&routerservice.Config{}))
+		case "conntrackservice":
+			services = append(services, serial.ToTypedMessage(&conntrackservice.Config{}))
+		case "quotaservice":
+			services = append(services, serial.ToTypedMessage(&quotaservice.Config{}))
 		default:
 			if !strings.HasPrefix(s, "#") {
 				continue
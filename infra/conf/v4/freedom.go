@@ -1,7 +1,10 @@
 package v4
 
 import (
+	"encoding/base64"
+	"encoding/hex"
 	"net"
+	"strconv"
 	"strings"
 
 	"github.com/golang/protobuf/proto"
@@ -11,11 +14,112 @@ import (
 	"github.com/v2fly/v2ray-core/v5/proxy/freedom"
 )
 
+type FreedomFragmentConfig struct {
+	Packets         string `json:"packets"`
+	Length          string `json:"length"`
+	Interval        string `json:"interval"`
+	SplitServerName bool   `json:"splitServerName"`
+}
+
+// Build implements Buildable
+func (c *FreedomFragmentConfig) Build() (*freedom.Fragment, error) {
+	config := new(freedom.Fragment)
+	config.SplitServerName = c.SplitServerName
+
+	var err error
+	switch strings.ToLower(c.Packets) {
+	case "", "tlshello", "tls_hello", "tls-hello":
+		config.TlsHelloOnly = true
+	default:
+		if config.PacketsFrom, config.PacketsTo, err = parseUint32Range(c.Packets); err != nil {
+			return nil, newError("invalid fragment packets: ", c.Packets).Base(err)
+		}
+		if config.PacketsFrom == 0 {
+			return nil, newError("fragment packets start from 1")
+		}
+	}
+	if config.LengthMin, config.LengthMax, err = parseUint32Range(c.Length); err != nil {
+		return nil, newError("invalid fragment length: ", c.Length).Base(err)
+	}
+	if config.IntervalMin, config.IntervalMax, err = parseUint32Range(c.Interval); err != nil {
+		return nil, newError("invalid fragment interval: ", c.Interval).Base(err)
+	}
+	return config, nil
+}
+
+type FreedomNoiseConfig struct {
+	// Type is the encoding of Packet, which is "str" (default), "hex" or "base64".
+	Type   string `json:"type"`
+	Packet string `json:"packet"`
+	Length string `json:"length"`
+	Delay  string `json:"delay"`
+}
+
+// Build implements Buildable
+func (c *FreedomNoiseConfig) Build() (*freedom.Noise, error) {
+	config := new(freedom.Noise)
+
+	var err error
+	switch strings.ToLower(c.Type) {
+	case "", "str", "string":
+		config.Packet = []byte(c.Packet)
+	case "hex":
+		if config.Packet, err = hex.DecodeString(c.Packet); err != nil {
+			return nil, newError("invalid hex noise packet: ", c.Packet).Base(err)
+		}
+	case "base64":
+		if config.Packet, err = base64.StdEncoding.DecodeString(c.Packet); err != nil {
+			return nil, newError("invalid base64 noise packet: ", c.Packet).Base(err)
+		}
+	default:
+		return nil, newError("unknown noise type: ", c.Type)
+	}
+	if len(config.Packet) == 0 {
+		config.Packet = nil
+	}
+
+	if config.LengthMin, config.LengthMax, err = parseUint32Range(c.Length); err != nil {
+		return nil, newError("invalid noise length: ", c.Length).Base(err)
+	}
+	if config.DelayMin, config.DelayMax, err = parseUint32Range(c.Delay); err != nil {
+		return nil, newError("invalid noise delay: ", c.Delay).Base(err)
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// parseUint32Range parses "a" or "a-b" into a range. An empty string is an
+// empty range.
+func parseUint32Range(s string) (uint32, uint32, error) {
+	if len(s) == 0 {
+		return 0, 0, nil
+	}
+	pair := strings.SplitN(s, "-", 2)
+	from, err := strconv.ParseUint(strings.TrimSpace(pair[0]), 10, 32)
+	if err != nil {
+		return 0, 0, err
+	}
+	to := from
+	if len(pair) == 2 {
+		if to, err = strconv.ParseUint(strings.TrimSpace(pair[1]), 10, 32); err != nil {
+			return 0, 0, err
+		}
+	}
+	if from > to {
+		return 0, 0, newError("invalid range ", from, " -> ", to)
+	}
+	return uint32(from), uint32(to), nil
+}
+
 type FreedomConfig struct {
-	DomainStrategy string  `json:"domainStrategy"`
-	Timeout        *uint32 `json:"timeout"`
-	Redirect       string  `json:"redirect"`
-	UserLevel      uint32  `json:"userLevel"`
+	DomainStrategy string                 `json:"domainStrategy"`
+	Timeout        *uint32                `json:"timeout"`
+	Redirect       string                 `json:"redirect"`
+	UserLevel      uint32                 `json:"userLevel"`
+	Fragment       *FreedomFragmentConfig `json:"fragment"`
+	Noise          []*FreedomNoiseConfig  `json:"noise"`
 }
 
 // Build implements Buildable
@@ -54,5 +158,19 @@ func (c *FreedomConfig) Build() (proto.Message, error) {
 			config.DestinationOverride.Server.Address = v2net.NewIPOrDomain(v2net.ParseAddress(host))
 		}
 	}
+	if c.Fragment != nil {
+		fragment, err := c.Fragment.Build()
+		if err != nil {
+			return nil, err
+		}
+		config.Fragment = fragment
+	}
+	for _, n := range c.Noise {
+		noise, err := n.Build()
+		if err != nil {
+			return nil, err
+		}
+		config.Noise = append(config.Noise, noise)
+	}
 	return config, nil
 }
@@ -1,8 +1,11 @@
 package v4_test
 
 import (
+	"strconv"
+	"strings"
 	"testing"
 
+	"github.com/v2fly/v2ray-core/v5/common/buf"
 	"github.com/v2fly/v2ray-core/v5/common/net"
 	"github.com/v2fly/v2ray-core/v5/common/protocol"
 	"github.com/v2fly/v2ray-core/v5/infra/conf/cfgcommon"
@@ -41,5 +44,74 @@ func TestFreedomConfig(t *testing.T) {
 				UserLevel: 1,
 			},
 		},
+		{
+			Input: `{
+				"fragment": {
+					"packets": "1-3",
+					"length": "10-20",
+					"interval": "5"
+				},
+				"noise": [
+					{
+						"length": "20-40",
+						"delay": "1-5"
+					},
+					{
+						"packet": "ping"
+					},
+					{
+						"type": "hex",
+						"packet": "deadbeef"
+					},
+					{
+						"type": "base64",
+						"packet": "AAEC"
+					}
+				]
+			}`,
+			Parser: testassist.LoadJSON(creator),
+			Output: &freedom.Config{
+				DomainStrategy: freedom.Config_AS_IS,
+				Fragment: &freedom.Fragment{
+					PacketsFrom: 1,
+					PacketsTo:   3,
+					LengthMin:   10,
+					LengthMax:   20,
+					IntervalMin: 5,
+					IntervalMax: 5,
+				},
+				Noise: []*freedom.Noise{
+					{
+						LengthMin: 20,
+						LengthMax: 40,
+						DelayMin:  1,
+						DelayMax:  5,
+					},
+					{
+						Packet: []byte("ping"),
+					},
+					{
+						Packet: []byte{0xde, 0xad, 0xbe, 0xef},
+					},
+					{
+						Packet: []byte{0, 1, 2},
+					},
+				},
+			},
+		},
 	})
 }
+
+func TestFreedomInvalidNoise(t *testing.T) {
+	for _, noise := range []*v4.FreedomNoiseConfig{
+		{Type: "hex", Packet: "xyz"},
+		{Type: "base64", Packet: "!"},
+		{Type: "rand", Packet: "ping"},
+		{Packet: strings.Repeat("a", buf.Size+1)},
+		{Length: "100-" + strconv.Itoa(buf.Size+1)},
+	} {
+		if _, err := noise.Build(); err == nil {
+			t.Error("expected error of noise ", noise.Type, " ", noise.Packet, noise.Length)
+		}
+	}
+}
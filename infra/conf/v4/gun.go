@@ -7,9 +7,33 @@ import (
 )
 
 type GunConfig struct {
-	ServiceName string `json:"serviceName"`
+	ServiceName             string `json:"serviceName"`
+	Host                    string `json:"host"`
+	MultiMode               bool   `json:"multiMode"`
+	IdleTimeout             int32  `json:"idleTimeout"`
+	HealthCheckTimeout      int32  `json:"healthCheckTimeout"`
+	PermitWithoutStream     bool   `json:"permitWithoutStream"`
+	InitialWindowsSize      int32  `json:"initialWindowsSize"`
+	UserAgent               string `json:"userAgent"`
+	MaxStreamsPerConnection int32  `json:"maxStreamsPerConnection"`
+	HealthCheckInterval     int32  `json:"healthCheckInterval"`
 }
 
 func (g GunConfig) Build() (proto.Message, error) {
-	return &grpc.Config{ServiceName: g.ServiceName}, nil
+	if g.IdleTimeout < 0 || g.HealthCheckTimeout < 0 || g.InitialWindowsSize < 0 ||
+		g.MaxStreamsPerConnection < 0 || g.HealthCheckInterval < 0 {
+		return nil, newError("negative value in gRPC config")
+	}
+	return &grpc.Config{
+		ServiceName:             g.ServiceName,
+		Host:                    g.Host,
+		MultiMode:               g.MultiMode,
+		IdleTimeout:             g.IdleTimeout,
+		HealthCheckTimeout:      g.HealthCheckTimeout,
+		PermitWithoutStream:     g.PermitWithoutStream,
+		InitialWindowsSize:      g.InitialWindowsSize,
+		UserAgent:               g.UserAgent,
+		MaxStreamsPerConnection: g.MaxStreamsPerConnection,
+		HealthCheckInterval:     g.HealthCheckInterval,
+	}, nil
 }
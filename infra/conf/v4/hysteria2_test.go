@@ -0,0 +1,84 @@
+package v4_test
+
+import (
+	"testing"
+
+	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/common/protocol"
+	"github.com/v2fly/v2ray-core/v5/common/serial"
+	"github.com/v2fly/v2ray-core/v5/infra/conf/cfgcommon"
+	"github.com/v2fly/v2ray-core/v5/infra/conf/cfgcommon/testassist"
+	v4 "github.com/v2fly/v2ray-core/v5/infra/conf/v4"
+	"github.com/v2fly/v2ray-core/v5/proxy/hysteria2"
+)
+
+func TestHysteria2ServerConfig(t *testing.T) {
+	creator := func() cfgcommon.Buildable {
+		return new(v4.Hysteria2ServerConfig)
+	}
+
+	testassist.RunMultiTestCase(t, []testassist.TestCase{
+		{
+			Input: `{
+				"clients": [
+					{
+						"password": "password",
+						"level": 1,
+						"email": "love@v2fly.org"
+					}
+				]
+			}`,
+			Parser: testassist.LoadJSON(creator),
+			Output: &hysteria2.ServerConfig{
+				Users: []*protocol.User{
+					{
+						Level: 1,
+						Email: "love@v2fly.org",
+						Account: serial.ToTypedMessage(&hysteria2.Account{
+							Password: "password",
+						}),
+					},
+				},
+			},
+		},
+	})
+}
+
+func TestHysteria2ClientConfig(t *testing.T) {
+	creator := func() cfgcommon.Buildable {
+		return new(v4.Hysteria2ClientConfig)
+	}
+
+	testassist.RunMultiTestCase(t, []testassist.TestCase{
+		{
+			Input: `{
+				"servers": [
+					{
+						"address": "127.0.0.1",
+						"port": 443,
+						"email": "love@v2fly.org"
+					}
+				]
+			}`,
+			Parser: testassist.LoadJSON(creator),
+			Output: &hysteria2.ClientConfig{
+				Server: []*protocol.ServerEndpoint{
+					{
+						Address: &net.IPOrDomain{
+							Address: &net.IPOrDomain_Ip{
+								Ip: []byte{127, 0, 0, 1},
+							},
+						},
+						Port: 443,
+						User: []*protocol.User{
+							{
+								Email:   "love@v2fly.org",
+								Account: serial.ToTypedMessage(&hysteria2.Account{}),
+							},
+						},
+					},
+				},
+			},
+		},
+	})
+}
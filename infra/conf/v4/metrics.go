@@ -0,0 +1,35 @@
+package v4
+
+import (
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+
+	"github.com/v2fly/v2ray-core/v5/app/metrics"
+)
+
+type MetricsConfig struct {
+	ListenAddr string `json:"listenAddr"`
+	ListenPort int32  `json:"listenPort"`
+	AuthToken  string `json:"authToken"`
+	Path       string `json:"path"`
+}
+
+func (c *MetricsConfig) Build() (proto.Message, error) {
+	listenAddr := strings.TrimSpace(c.ListenAddr)
+	if listenAddr == "" {
+		listenAddr = "127.0.0.1"
+	}
+	if c.ListenPort == 0 {
+		return nil, newError("metrics listen port is not specified")
+	}
+	if c.Path != "" && !strings.HasPrefix(c.Path, "/") {
+		return nil, newError("metrics path must start with /: ", c.Path)
+	}
+	return &metrics.Config{
+		ListenAddr: listenAddr,
+		ListenPort: c.ListenPort,
+		AuthToken:  c.AuthToken,
+		Path:       c.Path,
+	}, nil
+}
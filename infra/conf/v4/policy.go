@@ -12,6 +12,9 @@ type Policy struct {
 	StatsUserUplink   bool    `json:"statsUserUplink"`
 	StatsUserDownlink bool    `json:"statsUserDownlink"`
 	BufferSize        *int32  `json:"bufferSize"`
+	UplinkRate        string  `json:"uplinkRate"`
+	DownlinkRate      string  `json:"downlinkRate"`
+	MaxConnections    uint32  `json:"maxConnections"`
 }
 
 func (t *Policy) Build() (*policy.Policy, error) {
@@ -47,9 +50,43 @@ func (t *Policy) Build() (*policy.Policy, error) {
 		}
 	}
 
+	limit, err := (&LimitConfig{
+		UplinkRate:     t.UplinkRate,
+		DownlinkRate:   t.DownlinkRate,
+		MaxConnections: t.MaxConnections,
+	}).Build()
+	if err != nil {
+		return nil, err
+	}
+	if limit.Uplink > 0 || limit.Downlink > 0 || limit.Connections > 0 {
+		p.Limit = limit
+	}
+
 	return p, nil
 }
 
+// LimitConfig is the limits of a user or an inbound. Rates are in bytes per second, such as
+// "1048576" or "1MB".
+type LimitConfig struct {
+	UplinkRate     string `json:"uplinkRate"`
+	DownlinkRate   string `json:"downlinkRate"`
+	MaxConnections uint32 `json:"maxConnections"`
+}
+
+func (c *LimitConfig) Build() (*policy.Policy_Limit, error) {
+	limit := &policy.Policy_Limit{
+		Connections: c.MaxConnections,
+	}
+	var err error
+	if limit.Uplink, err = parseQuotaSize(c.UplinkRate); err != nil {
+		return nil, newError("invalid uplink rate").Base(err)
+	}
+	if limit.Downlink, err = parseQuotaSize(c.DownlinkRate); err != nil {
+		return nil, newError("invalid downlink rate").Base(err)
+	}
+	return limit, nil
+}
+
 type SystemPolicy struct {
 	StatsInboundUplink    bool `json:"statsInboundUplink"`
 	StatsInboundDownlink  bool `json:"statsInboundDownlink"`
@@ -69,8 +106,10 @@ func (p *SystemPolicy) Build() (*policy.SystemPolicy, error) {
 }
 
 type PolicyConfig struct {
-	Levels map[uint32]*Policy `json:"levels"`
-	System *SystemPolicy      `json:"system"`
+	Levels   map[uint32]*Policy      `json:"levels"`
+	System   *SystemPolicy           `json:"system"`
+	Users    map[string]*LimitConfig `json:"users"`
+	Inbounds map[string]*LimitConfig `json:"inbounds"`
 }
 
 func (c *PolicyConfig) Build() (*policy.Config, error) {
@@ -96,5 +135,32 @@ func (c *PolicyConfig) Build() (*policy.Config, error) {
 		config.System = sc
 	}
 
+	if len(c.Users) > 0 {
+		config.User = make(map[string]*policy.Policy_Limit, len(c.Users))
+		for email, l := range c.Users {
+			if l == nil {
+				continue
+			}
+			limit, err := l.Build()
+			if err != nil {
+				return nil, newError("invalid limit of user ", email).Base(err)
+			}
+			config.User[email] = limit
+		}
+	}
+	if len(c.Inbounds) > 0 {
+		config.Inbound = make(map[string]*policy.Policy_Limit, len(c.Inbounds))
+		for tag, l := range c.Inbounds {
+			if l == nil {
+				continue
+			}
+			limit, err := l.Build()
+			if err != nil {
+				return nil, newError("invalid limit of inbound ", tag).Base(err)
+			}
+			config.Inbound[tag] = limit
+		}
+	}
+
 	return config, nil
 }
@@ -38,3 +38,42 @@ func TestBufferSize(t *testing.T) {
 		}
 	}
 }
+
+func TestPolicyLimit(t *testing.T) {
+	pConf := v4.PolicyConfig{
+		Levels: map[uint32]*v4.Policy{
+			0: {
+				UplinkRate:     "1MB",
+				DownlinkRate:   "2048",
+				MaxConnections: 8,
+			},
+			1: {},
+		},
+		Users: map[string]*v4.LimitConfig{
+			"love@v2fly.org": {
+				DownlinkRate: "512KB",
+			},
+		},
+	}
+	config, err := pConf.Build()
+	common.Must(err)
+
+	if limit := config.Level[0].Limit; limit.Uplink != 1024*1024 || limit.Downlink != 2048 || limit.Connections != 8 {
+		t.Error("unexpected limit of level 0: ", limit)
+	}
+	if limit := config.Level[1].Limit; limit != nil {
+		t.Error("expected no limit of level 1, but got ", limit)
+	}
+	if limit := config.User["love@v2fly.org"]; limit.Downlink != 512*1024 || limit.Uplink != 0 {
+		t.Error("unexpected limit of user: ", limit)
+	}
+
+	pConf.Inbounds = map[string]*v4.LimitConfig{
+		"in": {
+			UplinkRate: "fast",
+		},
+	}
+	if _, err := pConf.Build(); err == nil {
+		t.Error("expected error of invalid rate")
+	}
+}
@@ -0,0 +1,107 @@
+package v4
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+
+	"github.com/v2fly/v2ray-core/v5/app/persistentstorage/filesystemstorage"
+	"github.com/v2fly/v2ray-core/v5/app/quota"
+	"github.com/v2fly/v2ray-core/v5/common/units"
+)
+
+type StorageConfig struct {
+	Directory string `json:"directory"`
+}
+
+func (c *StorageConfig) Build() (proto.Message, error) {
+	if c.Directory == "" {
+		return nil, newError("persistent storage directory is not specified")
+	}
+	return &filesystemstorage.Config{
+		Directory: c.Directory,
+	}, nil
+}
+
+type QuotaRuleConfig struct {
+	Tag         string   `json:"tag"`
+	Users       []string `json:"users"`
+	InboundTags []string `json:"inboundTags"`
+	Limit       string   `json:"limit"`
+	Period      string   `json:"period"`
+	ResetDay    uint32   `json:"resetDay"`
+	Grace       string   `json:"grace"`
+}
+
+// parseQuotaSize parses a size in bytes, with an optional unit like "10GB".
+func parseQuotaSize(s string) (uint64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	if v, err := strconv.ParseUint(s, 10, 64); err == nil {
+		return v, nil
+	}
+	var size units.ByteSize
+	if err := size.Parse(s); err != nil {
+		return 0, newError("invalid size: ", s).Base(err)
+	}
+	return uint64(size), nil
+}
+
+func (c *QuotaRuleConfig) Build() (*quota.Rule, error) {
+	rule := &quota.Rule{
+		Tag:         c.Tag,
+		Users:       c.Users,
+		InboundTags: c.InboundTags,
+		ResetDay:    c.ResetDay,
+	}
+	var err error
+	if rule.Limit, err = parseQuotaSize(c.Limit); err != nil {
+		return nil, err
+	}
+	if rule.Limit == 0 {
+		return nil, newError("quota limit is not specified")
+	}
+	if rule.Grace, err = parseQuotaSize(c.Grace); err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(c.Period) {
+	case "total", "":
+		rule.Period = quota.Period_Total
+	case "daily":
+		rule.Period = quota.Period_Daily
+	case "weekly":
+		rule.Period = quota.Period_Weekly
+		if c.ResetDay > 6 {
+			return nil, newError("invalid reset day of weekly quota: ", c.ResetDay)
+		}
+	case "monthly":
+		rule.Period = quota.Period_Monthly
+		if c.ResetDay > 28 {
+			return nil, newError("invalid reset day of monthly quota: ", c.ResetDay)
+		}
+	default:
+		return nil, newError("unknown quota period: ", c.Period)
+	}
+	return rule, nil
+}
+
+type QuotaConfig struct {
+	Rules        []*QuotaRuleConfig `json:"rules"`
+	SaveInterval uint32             `json:"saveInterval"`
+}
+
+func (c *QuotaConfig) Build() (proto.Message, error) {
+	config := &quota.Config{
+		SaveInterval: c.SaveInterval,
+	}
+	for _, r := range c.Rules {
+		rule, err := r.Build()
+		if err != nil {
+			return nil, err
+		}
+		config.Rules = append(config.Rules, rule)
+	}
+	return config, nil
+}
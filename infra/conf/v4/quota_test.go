@@ -0,0 +1,68 @@
+package v4_test
+
+import (
+	"testing"
+
+	"github.com/v2fly/v2ray-core/v5/app/quota"
+	"github.com/v2fly/v2ray-core/v5/infra/conf/cfgcommon"
+	"github.com/v2fly/v2ray-core/v5/infra/conf/cfgcommon/testassist"
+	v4 "github.com/v2fly/v2ray-core/v5/infra/conf/v4"
+)
+
+func TestQuotaConfig(t *testing.T) {
+	creator := func() cfgcommon.Buildable {
+		return new(v4.QuotaConfig)
+	}
+
+	testassist.RunMultiTestCase(t, []testassist.TestCase{
+		{
+			Input: `{
+				"rules": [
+					{
+						"tag": "users",
+						"users": ["love@v2fly.org"],
+						"limit": "10GB",
+						"grace": "100MB",
+						"period": "monthly",
+						"resetDay": 15
+					},
+					{
+						"inboundTags": ["socks"],
+						"limit": "1024"
+					}
+				],
+				"saveInterval": 30
+			}`,
+			Parser: testassist.LoadJSON(creator),
+			Output: &quota.Config{
+				Rules: []*quota.Rule{
+					{
+						Tag:      "users",
+						Users:    []string{"love@v2fly.org"},
+						Limit:    10 << 30,
+						Grace:    100 << 20,
+						Period:   quota.Period_Monthly,
+						ResetDay: 15,
+					},
+					{
+						InboundTags: []string{"socks"},
+						Limit:       1024,
+						Period:      quota.Period_Total,
+					},
+				},
+				SaveInterval: 30,
+			},
+		},
+	})
+}
+
+func TestQuotaConfigInvalidPeriod(t *testing.T) {
+	config := &v4.QuotaConfig{
+		Rules: []*v4.QuotaRuleConfig{
+			{Users: []string{"love@v2fly.org"}, Limit: "1GB", Period: "hourly"},
+		},
+	}
+	if _, err := config.Build(); err == nil {
+		t.Error("expected failure for unknown period")
+	}
+}
@@ -9,24 +9,30 @@ import (
 type BridgeConfig struct {
 	Tag    string `json:"tag"`
 	Domain string `json:"domain"`
+	Name   string `json:"name"`
+	Secret string `json:"secret"`
 }
 
 func (c *BridgeConfig) Build() (*reverse.BridgeConfig, error) {
 	return &reverse.BridgeConfig{
 		Tag:    c.Tag,
 		Domain: c.Domain,
+		Name:   c.Name,
+		Secret: c.Secret,
 	}, nil
 }
 
 type PortalConfig struct {
 	Tag    string `json:"tag"`
 	Domain string `json:"domain"`
+	Secret string `json:"secret"`
 }
 
 func (c *PortalConfig) Build() (*reverse.PortalConfig, error) {
 	return &reverse.PortalConfig{
 		Tag:    c.Tag,
 		Domain: c.Domain,
+		Secret: c.Secret,
 	}, nil
 }
 
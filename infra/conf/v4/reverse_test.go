@@ -43,5 +43,29 @@ func TestReverseConfig(t *testing.T) {
 				},
 			},
 		},
+		{
+			Input: `{
+				"bridges": [{
+					"tag": "bridge",
+					"domain": "test.v2fly.org",
+					"name": "office",
+					"secret": "secret"
+				}],
+				"portals": [{
+					"tag": "portal",
+					"domain": "test.v2fly.org",
+					"secret": "secret"
+				}]
+			}`,
+			Parser: testassist.LoadJSON(creator),
+			Output: &reverse.Config{
+				BridgeConfig: []*reverse.BridgeConfig{
+					{Tag: "bridge", Domain: "test.v2fly.org", Name: "office", Secret: "secret"},
+				},
+				PortalConfig: []*reverse.PortalConfig{
+					{Tag: "portal", Domain: "test.v2fly.org", Secret: "secret"},
+				},
+			},
+		},
 	})
 }
@@ -0,0 +1,34 @@
+package v4
+
+import (
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+
+	"github.com/v2fly/v2ray-core/v5/app/tracing"
+)
+
+type TracingConfig struct {
+	Endpoint    string            `json:"endpoint"`
+	Insecure    bool              `json:"insecure"`
+	Headers     map[string]string `json:"headers"`
+	ServiceName string            `json:"serviceName"`
+	SampleRatio float32           `json:"sampleRatio"`
+}
+
+func (c *TracingConfig) Build() (proto.Message, error) {
+	endpoint := strings.TrimSpace(c.Endpoint)
+	if endpoint == "" {
+		return nil, newError("tracing endpoint is not specified")
+	}
+	if c.SampleRatio < 0 || c.SampleRatio > 1 {
+		return nil, newError("tracing sample ratio must be between 0 and 1: ", c.SampleRatio)
+	}
+	return &tracing.Config{
+		Endpoint:    endpoint,
+		Insecure:    c.Insecure,
+		Headers:     c.Headers,
+		ServiceName: c.ServiceName,
+		SampleRatio: c.SampleRatio,
+	}, nil
+}
@@ -11,8 +11,13 @@ type TransportConfig struct {
 	KCPConfig  *KCPConfig          `json:"kcpSettings"`
 	WSConfig   *WebSocketConfig    `json:"wsSettings"`
 	HTTPConfig *HTTPConfig         `json:"httpSettings"`
+	HUConfig   *HTTPUpgradeConfig  `json:"httpupgradeSettings"`
+	SHConfig   *SplitHTTPConfig    `json:"splithttpSettings"`
+	MeekConfig *MeekConfig         `json:"meekSettings"`
 	DSConfig   *DomainSocketConfig `json:"dsSettings"`
 	QUICConfig *QUICConfig         `json:"quicSettings"`
+	Hy2Config  *Hysteria2Config    `json:"hysteria2Settings"`
+	TUICConfig *TUICConfig         `json:"tuicSettings"`
 	GunConfig  *GunConfig          `json:"gunSettings"`
 	GRPCConfig *GunConfig          `json:"grpcSettings"`
 }
@@ -65,6 +70,39 @@ func (c *TransportConfig) Build() (*transport.Config, error) {
 		})
 	}
 
+	if c.HUConfig != nil {
+		hs, err := c.HUConfig.Build()
+		if err != nil {
+			return nil, newError("Failed to build HTTP upgrade config.").Base(err)
+		}
+		config.TransportSettings = append(config.TransportSettings, &internet.TransportConfig{
+			ProtocolName: "httpupgrade",
+			Settings:     serial.ToTypedMessage(hs),
+		})
+	}
+
+	if c.SHConfig != nil {
+		ss, err := c.SHConfig.Build()
+		if err != nil {
+			return nil, newError("Failed to build split HTTP config.").Base(err)
+		}
+		config.TransportSettings = append(config.TransportSettings, &internet.TransportConfig{
+			ProtocolName: "splithttp",
+			Settings:     serial.ToTypedMessage(ss),
+		})
+	}
+
+	if c.MeekConfig != nil {
+		ms, err := c.MeekConfig.Build()
+		if err != nil {
+			return nil, newError("Failed to build meek config.").Base(err)
+		}
+		config.TransportSettings = append(config.TransportSettings, &internet.TransportConfig{
+			ProtocolName: "meek",
+			Settings:     serial.ToTypedMessage(ms),
+		})
+	}
+
 	if c.DSConfig != nil {
 		ds, err := c.DSConfig.Build()
 		if err != nil {
@@ -87,6 +125,28 @@ func (c *TransportConfig) Build() (*transport.Config, error) {
 		})
 	}
 
+	if c.Hy2Config != nil {
+		hs, err := c.Hy2Config.Build()
+		if err != nil {
+			return nil, newError("Failed to build Hysteria2 config.").Base(err)
+		}
+		config.TransportSettings = append(config.TransportSettings, &internet.TransportConfig{
+			ProtocolName: "hysteria2",
+			Settings:     serial.ToTypedMessage(hs),
+		})
+	}
+
+	if c.TUICConfig != nil {
+		ts, err := c.TUICConfig.Build()
+		if err != nil {
+			return nil, newError("Failed to build TUIC config.").Base(err)
+		}
+		config.TransportSettings = append(config.TransportSettings, &internet.TransportConfig{
+			ProtocolName: "tuic",
+			Settings:     serial.ToTypedMessage(ts),
+		})
+	}
+
 	if c.GunConfig == nil {
 		c.GunConfig = c.GRPCConfig
 	}
@@ -1,6 +1,8 @@
 package v4
 
 import (
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"strings"
 
@@ -8,6 +10,7 @@ import (
 
 	"github.com/v2fly/v2ray-core/v5/common/protocol"
 	"github.com/v2fly/v2ray-core/v5/common/serial"
+	"github.com/v2fly/v2ray-core/v5/common/uuid"
 	"github.com/v2fly/v2ray-core/v5/infra/conf/cfgcommon"
 	"github.com/v2fly/v2ray-core/v5/infra/conf/cfgcommon/loader"
 	"github.com/v2fly/v2ray-core/v5/infra/conf/cfgcommon/socketcfg"
@@ -16,9 +19,16 @@ import (
 	"github.com/v2fly/v2ray-core/v5/transport/internet/domainsocket"
 	httpheader "github.com/v2fly/v2ray-core/v5/transport/internet/headers/http"
 	"github.com/v2fly/v2ray-core/v5/transport/internet/http"
+	"github.com/v2fly/v2ray-core/v5/transport/internet/httpupgrade"
+	"github.com/v2fly/v2ray-core/v5/transport/internet/hysteria2"
 	"github.com/v2fly/v2ray-core/v5/transport/internet/kcp"
+	"github.com/v2fly/v2ray-core/v5/transport/internet/meek"
 	"github.com/v2fly/v2ray-core/v5/transport/internet/quic"
+	"github.com/v2fly/v2ray-core/v5/transport/internet/reality"
+	"github.com/v2fly/v2ray-core/v5/transport/internet/shadowtls"
+	"github.com/v2fly/v2ray-core/v5/transport/internet/splithttp"
 	"github.com/v2fly/v2ray-core/v5/transport/internet/tcp"
+	"github.com/v2fly/v2ray-core/v5/transport/internet/tuic"
 	"github.com/v2fly/v2ray-core/v5/transport/internet/websocket"
 )
 
@@ -38,16 +48,24 @@ var (
 	}, "type", "")
 )
 
+type KCPFECConfig struct {
+	DataShards   uint32 `json:"dataShards"`
+	ParityShards uint32 `json:"parityShards"`
+}
+
 type KCPConfig struct {
-	Mtu             *uint32         `json:"mtu"`
-	Tti             *uint32         `json:"tti"`
-	UpCap           *uint32         `json:"uplinkCapacity"`
-	DownCap         *uint32         `json:"downlinkCapacity"`
-	Congestion      *bool           `json:"congestion"`
-	ReadBufferSize  *uint32         `json:"readBufferSize"`
-	WriteBufferSize *uint32         `json:"writeBufferSize"`
-	HeaderConfig    json.RawMessage `json:"header"`
-	Seed            *string         `json:"seed"`
+	Mtu               *uint32         `json:"mtu"`
+	Tti               *uint32         `json:"tti"`
+	UpCap             *uint32         `json:"uplinkCapacity"`
+	DownCap           *uint32         `json:"downlinkCapacity"`
+	Congestion        *bool           `json:"congestion"`
+	ReadBufferSize    *uint32         `json:"readBufferSize"`
+	WriteBufferSize   *uint32         `json:"writeBufferSize"`
+	HeaderConfig      json.RawMessage `json:"header"`
+	Seed              *string         `json:"seed"`
+	FEC               *KCPFECConfig   `json:"fec"`
+	CongestionControl string          `json:"congestionControl"`
+	Stats             bool            `json:"stats"`
 }
 
 // Build implements Buildable.
@@ -109,6 +127,26 @@ func (c *KCPConfig) Build() (proto.Message, error) {
 		config.Seed = &kcp.EncryptionSeed{Seed: *c.Seed}
 	}
 
+	if c.FEC != nil && c.FEC.DataShards > 0 {
+		if c.FEC.ParityShards == 0 || c.FEC.DataShards+c.FEC.ParityShards > 256 {
+			return nil, newError("invalid mKCP FEC shards: ", c.FEC.DataShards, "+", c.FEC.ParityShards).AtError()
+		}
+		config.Fec = &kcp.FEC{
+			DataShards:   c.FEC.DataShards,
+			ParityShards: c.FEC.ParityShards,
+		}
+	}
+
+	switch strings.ToLower(c.CongestionControl) {
+	case "", "loss":
+		config.CongestionControl = kcp.CongestionControl_LOSS
+	case "bbr":
+		config.CongestionControl = kcp.CongestionControl_BBR
+	default:
+		return nil, newError("unknown mKCP congestion control: ", c.CongestionControl).AtError()
+	}
+	config.Stats = c.Stats
+
 	return config, nil
 }
 
@@ -169,6 +207,96 @@ func (c *WebSocketConfig) Build() (proto.Message, error) {
 	return config, nil
 }
 
+type HTTPUpgradeConfig struct {
+	Path                string            `json:"path"`
+	Host                string            `json:"host"`
+	Headers             map[string]string `json:"headers"`
+	AcceptProxyProtocol bool              `json:"acceptProxyProtocol"`
+	MaxEarlyData        int32             `json:"maxEarlyData"`
+	EarlyDataHeaderName string            `json:"earlyDataHeaderName"`
+}
+
+// Build implements Buildable.
+func (c *HTTPUpgradeConfig) Build() (proto.Message, error) {
+	header := make([]*httpupgrade.Header, 0, len(c.Headers))
+	for key, value := range c.Headers {
+		header = append(header, &httpupgrade.Header{
+			Key:   key,
+			Value: value,
+		})
+	}
+	return &httpupgrade.Config{
+		Path:                c.Path,
+		Host:                c.Host,
+		Header:              header,
+		AcceptProxyProtocol: c.AcceptProxyProtocol,
+		MaxEarlyData:        c.MaxEarlyData,
+		EarlyDataHeaderName: c.EarlyDataHeaderName,
+	}, nil
+}
+
+type SplitHTTPConfig struct {
+	Path                 string            `json:"path"`
+	Host                 string            `json:"host"`
+	Headers              map[string]string `json:"headers"`
+	MaxUploadSize        int32             `json:"maxUploadSize"`
+	MaxConcurrentUploads int32             `json:"maxConcurrentUploads"`
+	Mode                 string            `json:"mode"`
+}
+
+// Build implements Buildable.
+func (c *SplitHTTPConfig) Build() (proto.Message, error) {
+	header := make([]*splithttp.Header, 0, len(c.Headers))
+	for key, value := range c.Headers {
+		header = append(header, &splithttp.Header{
+			Key:   key,
+			Value: value,
+		})
+	}
+	config := &splithttp.Config{
+		Path:                 c.Path,
+		Host:                 c.Host,
+		Header:               header,
+		MaxUploadSize:        c.MaxUploadSize,
+		MaxConcurrentUploads: c.MaxConcurrentUploads,
+	}
+	switch strings.ToLower(c.Mode) {
+	case "", "auto":
+		config.Mode = splithttp.Mode_Auto
+	case "http1", "http/1.1":
+		config.Mode = splithttp.Mode_HTTP1
+	case "h2", "http2":
+		config.Mode = splithttp.Mode_H2
+	default:
+		return nil, newError("unknown split HTTP mode: ", c.Mode)
+	}
+	return config, nil
+}
+
+type MeekConfig struct {
+	Path                 string `json:"path"`
+	Host                 string `json:"host"`
+	MaxPayloadSize       int32  `json:"maxPayloadSize"`
+	MinPollInterval      uint32 `json:"minPollInterval"`
+	MaxPollInterval      uint32 `json:"maxPollInterval"`
+	UseBrowserForwarding bool   `json:"useBrowserForwarding"`
+}
+
+// Build implements Buildable.
+func (c *MeekConfig) Build() (proto.Message, error) {
+	if c.MaxPollInterval != 0 && c.MaxPollInterval < c.MinPollInterval {
+		return nil, newError("meek maxPollInterval is less than minPollInterval")
+	}
+	return &meek.Config{
+		Path:                 c.Path,
+		Host:                 c.Host,
+		MaxPayloadSize:       c.MaxPayloadSize,
+		MinPollInterval:      c.MinPollInterval,
+		MaxPollInterval:      c.MaxPollInterval,
+		UseBrowserForwarding: c.UseBrowserForwarding,
+	}, nil
+}
+
 type HTTPConfig struct {
 	Host    *cfgcommon.StringList            `json:"host"`
 	Path    string                           `json:"path"`
@@ -205,15 +333,28 @@ func (c *HTTPConfig) Build() (proto.Message, error) {
 }
 
 type QUICConfig struct {
-	Header   json.RawMessage `json:"header"`
-	Security string          `json:"security"`
-	Key      string          `json:"key"`
+	Header           json.RawMessage `json:"header"`
+	Security         string          `json:"security"`
+	Key              string          `json:"key"`
+	HandshakeTimeout int32           `json:"handshakeTimeout"`
+	IdleTimeout      int32           `json:"idleTimeout"`
+	DisableKeepAlive bool            `json:"disableKeepAlive"`
+	ZeroRTTHandshake bool            `json:"zeroRttHandshake"`
+	EnableDatagrams  bool            `json:"enableDatagrams"`
 }
 
 // Build implements Buildable.
 func (c *QUICConfig) Build() (proto.Message, error) {
+	if c.HandshakeTimeout < 0 || c.IdleTimeout < 0 {
+		return nil, newError("QUIC timeouts must not be negative")
+	}
 	config := &quic.Config{
-		Key: c.Key,
+		Key:              c.Key,
+		HandshakeTimeout: c.HandshakeTimeout,
+		IdleTimeout:      c.IdleTimeout,
+		DisableKeepAlive: c.DisableKeepAlive,
+		ZeroRttHandshake: c.ZeroRTTHandshake,
+		EnableDatagrams:  c.EnableDatagrams,
 	}
 
 	if len(c.Header) > 0 {
@@ -245,6 +386,85 @@ func (c *QUICConfig) Build() (proto.Message, error) {
 	return config, nil
 }
 
+type Hysteria2ObfsConfig struct {
+	Type     string `json:"type"`
+	Password string `json:"password"`
+}
+
+type Hysteria2Config struct {
+	Password              string               `json:"password"`
+	UpMbps                uint64               `json:"upMbps"`
+	DownMbps              uint64               `json:"downMbps"`
+	IgnoreClientBandwidth bool                 `json:"ignoreClientBandwidth"`
+	Obfs                  *Hysteria2ObfsConfig `json:"obfs"`
+	IdleTimeout           int32                `json:"idleTimeout"`
+}
+
+// Build implements Buildable.
+func (c *Hysteria2Config) Build() (proto.Message, error) {
+	if c.IdleTimeout < 0 {
+		return nil, newError("Hysteria2 idle timeout must not be negative")
+	}
+	config := &hysteria2.Config{
+		Password:              c.Password,
+		UpMbps:                c.UpMbps,
+		DownMbps:              c.DownMbps,
+		IgnoreClientBandwidth: c.IgnoreClientBandwidth,
+		IdleTimeout:           c.IdleTimeout,
+	}
+	if c.Obfs != nil {
+		switch strings.ToLower(c.Obfs.Type) {
+		case "", "salamander":
+			if c.Obfs.Password == "" {
+				return nil, newError("Hysteria2 obfs password is not specified")
+			}
+			config.ObfsPassword = c.Obfs.Password
+		default:
+			return nil, newError("unknown Hysteria2 obfs type: ", c.Obfs.Type)
+		}
+	}
+	return config, nil
+}
+
+type TUICConfig struct {
+	UUID             string `json:"uuid"`
+	Password         string `json:"password"`
+	UDPRelayMode     string `json:"udpRelayMode"`
+	ZeroRTTHandshake bool   `json:"zeroRttHandshake"`
+	Heartbeat        int32  `json:"heartbeat"`
+	IdleTimeout      int32  `json:"idleTimeout"`
+	AuthTimeout      int32  `json:"authTimeout"`
+}
+
+// Build implements Buildable.
+func (c *TUICConfig) Build() (proto.Message, error) {
+	if c.Heartbeat < 0 || c.IdleTimeout < 0 || c.AuthTimeout < 0 {
+		return nil, newError("TUIC timeouts must not be negative")
+	}
+	if c.UUID != "" {
+		if _, err := uuid.ParseString(c.UUID); err != nil {
+			return nil, newError("invalid TUIC uuid: ", c.UUID).Base(err)
+		}
+	}
+	config := &tuic.Config{
+		Uuid:             c.UUID,
+		Password:         c.Password,
+		ZeroRttHandshake: c.ZeroRTTHandshake,
+		Heartbeat:        c.Heartbeat,
+		IdleTimeout:      c.IdleTimeout,
+		AuthTimeout:      c.AuthTimeout,
+	}
+	switch strings.ToLower(c.UDPRelayMode) {
+	case "", "native":
+		config.UdpRelayMode = tuic.UDPRelayMode_NATIVE
+	case "quic":
+		config.UdpRelayMode = tuic.UDPRelayMode_QUIC
+	default:
+		return nil, newError("unknown TUIC UDP relay mode: ", c.UDPRelayMode)
+	}
+	return config, nil
+}
+
 type DomainSocketConfig struct {
 	Path     string `json:"path"`
 	Abstract bool   `json:"abstract"`
@@ -273,10 +493,20 @@ func (p TransportProtocol) Build() (string, error) {
 		return "websocket", nil
 	case "h2", "http":
 		return "http", nil
+	case "httpupgrade":
+		return "httpupgrade", nil
+	case "splithttp":
+		return "splithttp", nil
+	case "meek":
+		return "meek", nil
 	case "ds", "domainsocket":
 		return "domainsocket", nil
 	case "quic":
 		return "quic", nil
+	case "hysteria2":
+		return "hysteria2", nil
+	case "tuic":
+		return "tuic", nil
 	case "gun", "grpc":
 		return "gun", nil
 	default:
@@ -284,16 +514,129 @@ func (p TransportProtocol) Build() (string, error) {
 	}
 }
 
+type ShadowTLSConfig struct {
+	Password         string                `json:"password"`
+	ServerName       string                `json:"serverName"`
+	ALPN             *cfgcommon.StringList `json:"alpn"`
+	HandshakeAddress *cfgcommon.Address    `json:"handshakeAddress"`
+	HandshakePort    uint16                `json:"handshakePort"`
+}
+
+// Build implements Buildable.
+func (c *ShadowTLSConfig) Build() (proto.Message, error) {
+	if c.Password == "" {
+		return nil, newError("ShadowTLS password is not specified")
+	}
+	config := &shadowtls.Config{
+		Password:      c.Password,
+		ServerName:    c.ServerName,
+		HandshakePort: uint32(c.HandshakePort),
+	}
+	if c.ALPN != nil && len(*c.ALPN) > 0 {
+		config.NextProtocol = []string(*c.ALPN)
+	}
+	if c.HandshakeAddress != nil {
+		config.HandshakeAddress = c.HandshakeAddress.Build()
+	}
+	return config, nil
+}
+
+type RealityConfig struct {
+	DestAddress       *cfgcommon.Address    `json:"destAddress"`
+	DestPort          uint16                `json:"destPort"`
+	ServerNames       *cfgcommon.StringList `json:"serverNames"`
+	PrivateKey        string                `json:"privateKey"`
+	ShortIDs          *cfgcommon.StringList `json:"shortIds"`
+	MaxTimeDifference uint32                `json:"maxTimeDiff"`
+	ServerName        string                `json:"serverName"`
+	PublicKey         string                `json:"publicKey"`
+	ShortID           string                `json:"shortId"`
+	ALPN              *cfgcommon.StringList `json:"alpn"`
+}
+
+func parseRealityKey(s string) ([]byte, error) {
+	key, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil || len(key) != 32 {
+		return nil, newError("invalid REALITY key: ", s)
+	}
+	return key, nil
+}
+
+func parseRealityShortID(s string) ([]byte, error) {
+	id, err := hex.DecodeString(s)
+	if err != nil || len(id) > 8 {
+		return nil, newError("invalid REALITY short ID: ", s)
+	}
+	return id, nil
+}
+
+// Build implements Buildable.
+func (c *RealityConfig) Build() (proto.Message, error) {
+	config := &reality.Config{
+		DestPort:          uint32(c.DestPort),
+		MaxTimeDifference: c.MaxTimeDifference,
+		ServerName:        c.ServerName,
+	}
+	switch {
+	case c.PrivateKey != "":
+		if c.DestAddress == nil || c.DestPort == 0 {
+			return nil, newError("REALITY destination is not specified")
+		}
+		config.DestAddress = c.DestAddress.Build()
+		key, err := parseRealityKey(c.PrivateKey)
+		if err != nil {
+			return nil, err
+		}
+		config.PrivateKey = key
+		if c.ServerNames != nil {
+			config.ServerNames = []string(*c.ServerNames)
+		}
+		if c.ShortIDs != nil {
+			for _, s := range *c.ShortIDs {
+				id, err := parseRealityShortID(s)
+				if err != nil {
+					return nil, err
+				}
+				config.ShortIds = append(config.ShortIds, id)
+			}
+		}
+	case c.PublicKey != "":
+		key, err := parseRealityKey(c.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+		config.PublicKey = key
+		id, err := parseRealityShortID(c.ShortID)
+		if err != nil {
+			return nil, err
+		}
+		config.ShortId = id
+		if c.ALPN != nil && len(*c.ALPN) > 0 {
+			config.NextProtocol = []string(*c.ALPN)
+		}
+	default:
+		return nil, newError("neither REALITY private key nor public key is specified")
+	}
+	return config, nil
+}
+
 type StreamConfig struct {
 	Network        *TransportProtocol      `json:"network"`
 	Security       string                  `json:"security"`
 	TLSSettings    *tlscfg.TLSConfig       `json:"tlsSettings"`
+	ShadowTLS      *ShadowTLSConfig        `json:"shadowtlsSettings"`
+	Reality        *RealityConfig          `json:"realitySettings"`
 	TCPSettings    *TCPConfig              `json:"tcpSettings"`
 	KCPSettings    *KCPConfig              `json:"kcpSettings"`
 	WSSettings     *WebSocketConfig        `json:"wsSettings"`
 	HTTPSettings   *HTTPConfig             `json:"httpSettings"`
+	HUSettings     *HTTPUpgradeConfig      `json:"httpupgradeSettings"`
+	SplitSettings  *SplitHTTPConfig        `json:"splithttpSettings"`
+	MeekSettings   *MeekConfig             `json:"meekSettings"`
 	DSSettings     *DomainSocketConfig     `json:"dsSettings"`
 	QUICSettings   *QUICConfig             `json:"quicSettings"`
+	Hy2Settings    *Hysteria2Config        `json:"hysteria2Settings"`
+	TUICSettings   *TUICConfig             `json:"tuicSettings"`
 	GunSettings    *GunConfig              `json:"gunSettings"`
 	GRPCSettings   *GunConfig              `json:"grpcSettings"`
 	SocketSettings *socketcfg.SocketConfig `json:"sockopt"`
@@ -324,6 +667,30 @@ func (c *StreamConfig) Build() (*internet.StreamConfig, error) {
 		config.SecuritySettings = append(config.SecuritySettings, tm)
 		config.SecurityType = serial.V2Type(tm)
 	}
+	if strings.EqualFold(c.Security, "shadowtls") {
+		if c.ShadowTLS == nil {
+			return nil, newError("ShadowTLS settings are not specified")
+		}
+		ts, err := c.ShadowTLS.Build()
+		if err != nil {
+			return nil, newError("Failed to build ShadowTLS config.").Base(err)
+		}
+		tm := serial.ToTypedMessage(ts)
+		config.SecuritySettings = append(config.SecuritySettings, tm)
+		config.SecurityType = serial.V2Type(tm)
+	}
+	if strings.EqualFold(c.Security, "reality") {
+		if c.Reality == nil {
+			return nil, newError("REALITY settings are not specified")
+		}
+		ts, err := c.Reality.Build()
+		if err != nil {
+			return nil, newError("Failed to build REALITY config.").Base(err)
+		}
+		tm := serial.ToTypedMessage(ts)
+		config.SecuritySettings = append(config.SecuritySettings, tm)
+		config.SecurityType = serial.V2Type(tm)
+	}
 	if c.TCPSettings != nil {
 		ts, err := c.TCPSettings.Build()
 		if err != nil {
@@ -364,6 +731,36 @@ func (c *StreamConfig) Build() (*internet.StreamConfig, error) {
 			Settings:     serial.ToTypedMessage(ts),
 		})
 	}
+	if c.HUSettings != nil {
+		hs, err := c.HUSettings.Build()
+		if err != nil {
+			return nil, newError("Failed to build HTTP upgrade config.").Base(err)
+		}
+		config.TransportSettings = append(config.TransportSettings, &internet.TransportConfig{
+			ProtocolName: "httpupgrade",
+			Settings:     serial.ToTypedMessage(hs),
+		})
+	}
+	if c.SplitSettings != nil {
+		ss, err := c.SplitSettings.Build()
+		if err != nil {
+			return nil, newError("Failed to build split HTTP config.").Base(err)
+		}
+		config.TransportSettings = append(config.TransportSettings, &internet.TransportConfig{
+			ProtocolName: "splithttp",
+			Settings:     serial.ToTypedMessage(ss),
+		})
+	}
+	if c.MeekSettings != nil {
+		ms, err := c.MeekSettings.Build()
+		if err != nil {
+			return nil, newError("Failed to build meek config.").Base(err)
+		}
+		config.TransportSettings = append(config.TransportSettings, &internet.TransportConfig{
+			ProtocolName: "meek",
+			Settings:     serial.ToTypedMessage(ms),
+		})
+	}
 	if c.DSSettings != nil {
 		ds, err := c.DSSettings.Build()
 		if err != nil {
@@ -384,6 +781,26 @@ func (c *StreamConfig) Build() (*internet.StreamConfig, error) {
 			Settings:     serial.ToTypedMessage(qs),
 		})
 	}
+	if c.Hy2Settings != nil {
+		hs, err := c.Hy2Settings.Build()
+		if err != nil {
+			return nil, newError("Failed to build Hysteria2 config.").Base(err)
+		}
+		config.TransportSettings = append(config.TransportSettings, &internet.TransportConfig{
+			ProtocolName: "hysteria2",
+			Settings:     serial.ToTypedMessage(hs),
+		})
+	}
+	if c.TUICSettings != nil {
+		ts, err := c.TUICSettings.Build()
+		if err != nil {
+			return nil, newError("Failed to build TUIC config.").Base(err)
+		}
+		config.TransportSettings = append(config.TransportSettings, &internet.TransportConfig{
+			ProtocolName: "tuic",
+			Settings:     serial.ToTypedMessage(ts),
+		})
+	}
 	if c.GunSettings == nil {
 		c.GunSettings = c.GRPCSettings
 	}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/golang/protobuf/proto"
 
+	"github.com/v2fly/v2ray-core/v5/common/net"
 	"github.com/v2fly/v2ray-core/v5/common/protocol"
 	"github.com/v2fly/v2ray-core/v5/common/serial"
 	"github.com/v2fly/v2ray-core/v5/infra/conf/cfgcommon/socketcfg"
@@ -13,12 +14,20 @@ import (
 	v4 "github.com/v2fly/v2ray-core/v5/infra/conf/v4"
 	"github.com/v2fly/v2ray-core/v5/transport"
 	"github.com/v2fly/v2ray-core/v5/transport/internet"
+	"github.com/v2fly/v2ray-core/v5/transport/internet/grpc"
 	"github.com/v2fly/v2ray-core/v5/transport/internet/headers/http"
 	"github.com/v2fly/v2ray-core/v5/transport/internet/headers/noop"
 	"github.com/v2fly/v2ray-core/v5/transport/internet/headers/tls"
+	"github.com/v2fly/v2ray-core/v5/transport/internet/httpupgrade"
+	"github.com/v2fly/v2ray-core/v5/transport/internet/hysteria2"
 	"github.com/v2fly/v2ray-core/v5/transport/internet/kcp"
+	"github.com/v2fly/v2ray-core/v5/transport/internet/meek"
 	"github.com/v2fly/v2ray-core/v5/transport/internet/quic"
+	"github.com/v2fly/v2ray-core/v5/transport/internet/reality"
+	"github.com/v2fly/v2ray-core/v5/transport/internet/shadowtls"
+	"github.com/v2fly/v2ray-core/v5/transport/internet/splithttp"
 	"github.com/v2fly/v2ray-core/v5/transport/internet/tcp"
+	"github.com/v2fly/v2ray-core/v5/transport/internet/tuic"
 	"github.com/v2fly/v2ray-core/v5/transport/internet/websocket"
 )
 
@@ -87,16 +96,64 @@ func TestTransportConfig(t *testing.T) {
 					"mtu": 1200,
 					"header": {
 						"type": "none"
-					}
+					},
+					"fec": {
+						"dataShards": 10,
+						"parityShards": 3
+					},
+					"congestionControl": "bbr",
+					"stats": true
 				},
 				"wsSettings": {
 					"path": "/t"
 				},
+				"httpupgradeSettings": {
+					"path": "/u",
+					"host": "www.v2fly.org",
+					"maxEarlyData": 2048,
+					"earlyDataHeaderName": "Sec-WebSocket-Protocol"
+				},
+				"splithttpSettings": {
+					"path": "/s",
+					"maxUploadSize": 65536,
+					"mode": "h2"
+				},
+				"meekSettings": {
+					"path": "/m",
+					"maxPayloadSize": 32768,
+					"maxPollInterval": 2000,
+					"useBrowserForwarding": true
+				},
 				"quicSettings": {
 					"key": "abcd",
 					"header": {
 						"type": "dtls"
+					},
+					"idleTimeout": 60,
+					"zeroRttHandshake": true,
+					"enableDatagrams": true
+				},
+				"hysteria2Settings": {
+					"password": "pass",
+					"upMbps": 50,
+					"downMbps": 100,
+					"obfs": {
+						"type": "salamander",
+						"password": "obfs"
 					}
+				},
+				"tuicSettings": {
+					"uuid": "27848739-7e62-4138-9fd3-098a63964b6b",
+					"password": "pass",
+					"udpRelayMode": "quic",
+					"zeroRttHandshake": true,
+					"heartbeat": 5
+				},
+				"grpcSettings": {
+					"serviceName": "tun",
+					"multiMode": true,
+					"idleTimeout": 60,
+					"maxStreamsPerConnection": 8
 				}
 			}`,
 			Parser: createParser(),
@@ -149,6 +206,12 @@ func TestTransportConfig(t *testing.T) {
 						Settings: serial.ToTypedMessage(&kcp.Config{
 							Mtu:          &kcp.MTU{Value: 1200},
 							HeaderConfig: serial.ToTypedMessage(&noop.Config{}),
+							Fec: &kcp.FEC{
+								DataShards:   10,
+								ParityShards: 3,
+							},
+							CongestionControl: kcp.CongestionControl_BBR,
+							Stats:             true,
 						}),
 					},
 					{
@@ -157,6 +220,32 @@ func TestTransportConfig(t *testing.T) {
 							Path: "/t",
 						}),
 					},
+					{
+						ProtocolName: "httpupgrade",
+						Settings: serial.ToTypedMessage(&httpupgrade.Config{
+							Path:                "/u",
+							Host:                "www.v2fly.org",
+							MaxEarlyData:        2048,
+							EarlyDataHeaderName: "Sec-WebSocket-Protocol",
+						}),
+					},
+					{
+						ProtocolName: "splithttp",
+						Settings: serial.ToTypedMessage(&splithttp.Config{
+							Path:          "/s",
+							MaxUploadSize: 65536,
+							Mode:          splithttp.Mode_H2,
+						}),
+					},
+					{
+						ProtocolName: "meek",
+						Settings: serial.ToTypedMessage(&meek.Config{
+							Path:                 "/m",
+							MaxPayloadSize:       32768,
+							MaxPollInterval:      2000,
+							UseBrowserForwarding: true,
+						}),
+					},
 					{
 						ProtocolName: "quic",
 						Settings: serial.ToTypedMessage(&quic.Config{
@@ -164,7 +253,38 @@ func TestTransportConfig(t *testing.T) {
 							Security: &protocol.SecurityConfig{
 								Type: protocol.SecurityType_NONE,
 							},
-							Header: serial.ToTypedMessage(&tls.PacketConfig{}),
+							Header:           serial.ToTypedMessage(&tls.PacketConfig{}),
+							IdleTimeout:      60,
+							ZeroRttHandshake: true,
+							EnableDatagrams:  true,
+						}),
+					},
+					{
+						ProtocolName: "hysteria2",
+						Settings: serial.ToTypedMessage(&hysteria2.Config{
+							Password:     "pass",
+							UpMbps:       50,
+							DownMbps:     100,
+							ObfsPassword: "obfs",
+						}),
+					},
+					{
+						ProtocolName: "tuic",
+						Settings: serial.ToTypedMessage(&tuic.Config{
+							Uuid:             "27848739-7e62-4138-9fd3-098a63964b6b",
+							Password:         "pass",
+							UdpRelayMode:     tuic.UDPRelayMode_QUIC,
+							ZeroRttHandshake: true,
+							Heartbeat:        5,
+						}),
+					},
+					{
+						ProtocolName: "gun",
+						Settings: serial.ToTypedMessage(&grpc.Config{
+							ServiceName:             "tun",
+							MultiMode:               true,
+							IdleTimeout:             60,
+							MaxStreamsPerConnection: 8,
 						}),
 					},
 				},
@@ -172,3 +292,95 @@ func TestTransportConfig(t *testing.T) {
 		},
 	})
 }
+
+func TestShadowTLSConfig(t *testing.T) {
+	createParser := func() func(string) (proto.Message, error) {
+		return func(s string) (proto.Message, error) {
+			config := new(v4.ShadowTLSConfig)
+			if err := json.Unmarshal([]byte(s), config); err != nil {
+				return nil, err
+			}
+			return config.Build()
+		}
+	}
+
+	testassist.RunMultiTestCase(t, []testassist.TestCase{
+		{
+			Input: `{
+				"password": "password",
+				"serverName": "www.v2fly.org",
+				"alpn": ["h2", "http/1.1"]
+			}`,
+			Parser: createParser(),
+			Output: &shadowtls.Config{
+				Password:     "password",
+				ServerName:   "www.v2fly.org",
+				NextProtocol: []string{"h2", "http/1.1"},
+			},
+		},
+		{
+			Input: `{
+				"password": "password",
+				"handshakeAddress": "www.v2fly.org",
+				"handshakePort": 443
+			}`,
+			Parser: createParser(),
+			Output: &shadowtls.Config{
+				Password:         "password",
+				HandshakeAddress: net.NewIPOrDomain(net.DomainAddress("www.v2fly.org")),
+				HandshakePort:    443,
+			},
+		},
+	})
+}
+
+func TestRealityConfig(t *testing.T) {
+	createParser := func() func(string) (proto.Message, error) {
+		return func(s string) (proto.Message, error) {
+			config := new(v4.RealityConfig)
+			if err := json.Unmarshal([]byte(s), config); err != nil {
+				return nil, err
+			}
+			return config.Build()
+		}
+	}
+
+	key := make([]byte, 32)
+	key[0] = 1
+	testassist.RunMultiTestCase(t, []testassist.TestCase{
+		{
+			Input: `{
+				"destAddress": "www.v2fly.org",
+				"destPort": 443,
+				"serverNames": ["www.v2fly.org"],
+				"privateKey": "AQAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA",
+				"shortIds": ["", "0bad"],
+				"maxTimeDiff": 60
+			}`,
+			Parser: createParser(),
+			Output: &reality.Config{
+				DestAddress:       net.NewIPOrDomain(net.DomainAddress("www.v2fly.org")),
+				DestPort:          443,
+				ServerNames:       []string{"www.v2fly.org"},
+				PrivateKey:        key,
+				ShortIds:          [][]byte{{}, {0x0b, 0xad}},
+				MaxTimeDifference: 60,
+			},
+		},
+		{
+			Input: `{
+				"serverName": "www.v2fly.org",
+				"publicKey": "AQAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA",
+				"shortId": "0bad",
+				"alpn": ["h2"]
+			}`,
+			Parser: createParser(),
+			Output: &reality.Config{
+				ServerName:   "www.v2fly.org",
+				PublicKey:    key,
+				ShortId:      []byte{0x0b, 0xad},
+				NextProtocol: []string{"h2"},
+			},
+		},
+	})
+}
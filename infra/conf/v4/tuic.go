@@ -0,0 +1,84 @@
+package v4
+
+import (
+	"github.com/golang/protobuf/proto"
+
+	"github.com/v2fly/v2ray-core/v5/common/protocol"
+	"github.com/v2fly/v2ray-core/v5/common/serial"
+	"github.com/v2fly/v2ray-core/v5/common/uuid"
+	"github.com/v2fly/v2ray-core/v5/infra/conf/cfgcommon"
+	"github.com/v2fly/v2ray-core/v5/proxy/tuic"
+)
+
+// TUICServerTarget is configuration of a single tuic server. Clients authenticate with the
+// uuid and password in tuicSettings.
+type TUICServerTarget struct {
+	Address *cfgcommon.Address `json:"address"`
+	Port    uint16             `json:"port"`
+}
+
+// TUICClientConfig is configuration of tuic servers
+type TUICClientConfig struct {
+	Servers []*TUICServerTarget `json:"servers"`
+}
+
+// Build implements Buildable
+func (c *TUICClientConfig) Build() (proto.Message, error) {
+	config := new(tuic.ClientConfig)
+
+	if len(c.Servers) == 0 {
+		return nil, newError("0 TUIC server configured.")
+	}
+
+	serverSpecs := make([]*protocol.ServerEndpoint, len(c.Servers))
+	for idx, rec := range c.Servers {
+		if rec.Address == nil {
+			return nil, newError("TUIC server address is not set.")
+		}
+		if rec.Port == 0 {
+			return nil, newError("Invalid TUIC port.")
+		}
+		serverSpecs[idx] = &protocol.ServerEndpoint{
+			Address: rec.Address.Build(),
+			Port:    uint32(rec.Port),
+		}
+	}
+
+	config.Server = serverSpecs
+
+	return config, nil
+}
+
+// TUICUserConfig is user configuration
+type TUICUserConfig struct {
+	UUID     string `json:"uuid"`
+	Password string `json:"password"`
+	Level    byte   `json:"level"`
+	Email    string `json:"email"`
+}
+
+// TUICServerConfig is Inbound configuration
+type TUICServerConfig struct {
+	Clients []*TUICUserConfig `json:"clients"`
+}
+
+// Build implements Buildable
+func (c *TUICServerConfig) Build() (proto.Message, error) {
+	config := new(tuic.ServerConfig)
+	config.Users = make([]*protocol.User, len(c.Clients))
+	for idx, rawUser := range c.Clients {
+		if _, err := uuid.ParseString(rawUser.UUID); err != nil {
+			return nil, newError("invalid TUIC uuid: ", rawUser.UUID).Base(err)
+		}
+		config.Users[idx] = &protocol.User{
+			Email: rawUser.Email,
+			Level: uint32(rawUser.Level),
+			Account: serial.ToTypedMessage(&tuic.Account{
+				Uuid:     rawUser.UUID,
+				Password: rawUser.Password,
+			}),
+		}
+	}
+
+	return config, nil
+}
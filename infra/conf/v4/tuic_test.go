@@ -0,0 +1,79 @@
+package v4_test
+
+import (
+	"testing"
+
+	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/common/protocol"
+	"github.com/v2fly/v2ray-core/v5/common/serial"
+	"github.com/v2fly/v2ray-core/v5/infra/conf/cfgcommon"
+	"github.com/v2fly/v2ray-core/v5/infra/conf/cfgcommon/testassist"
+	v4 "github.com/v2fly/v2ray-core/v5/infra/conf/v4"
+	"github.com/v2fly/v2ray-core/v5/proxy/tuic"
+)
+
+func TestTUICServerConfig(t *testing.T) {
+	creator := func() cfgcommon.Buildable {
+		return new(v4.TUICServerConfig)
+	}
+
+	testassist.RunMultiTestCase(t, []testassist.TestCase{
+		{
+			Input: `{
+				"clients": [
+					{
+						"uuid": "27848739-7e62-4138-9fd3-098a63964b6b",
+						"password": "password",
+						"level": 1,
+						"email": "love@v2fly.org"
+					}
+				]
+			}`,
+			Parser: testassist.LoadJSON(creator),
+			Output: &tuic.ServerConfig{
+				Users: []*protocol.User{
+					{
+						Level: 1,
+						Email: "love@v2fly.org",
+						Account: serial.ToTypedMessage(&tuic.Account{
+							Uuid:     "27848739-7e62-4138-9fd3-098a63964b6b",
+							Password: "password",
+						}),
+					},
+				},
+			},
+		},
+	})
+}
+
+func TestTUICClientConfig(t *testing.T) {
+	creator := func() cfgcommon.Buildable {
+		return new(v4.TUICClientConfig)
+	}
+
+	testassist.RunMultiTestCase(t, []testassist.TestCase{
+		{
+			Input: `{
+				"servers": [
+					{
+						"address": "127.0.0.1",
+						"port": 443
+					}
+				]
+			}`,
+			Parser: testassist.LoadJSON(creator),
+			Output: &tuic.ClientConfig{
+				Server: []*protocol.ServerEndpoint{
+					{
+						Address: &net.IPOrDomain{
+							Address: &net.IPOrDomain_Ip{
+								Ip: []byte{127, 0, 0, 1},
+							},
+						},
+						Port: 443,
+					},
+				},
+			},
+		},
+	})
+}
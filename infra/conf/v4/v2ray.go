@@ -7,6 +7,7 @@ import (
 	"google.golang.org/protobuf/types/known/anypb"
 
 	core "github.com/v2fly/v2ray-core/v5"
+	"github.com/v2fly/v2ray-core/v5/app/conntrack"
 	"github.com/v2fly/v2ray-core/v5/app/dispatcher"
 	"github.com/v2fly/v2ray-core/v5/app/proxyman"
 	"github.com/v2fly/v2ray-core/v5/app/stats"
@@ -16,6 +17,7 @@ import (
 	"github.com/v2fly/v2ray-core/v5/infra/conf/cfgcommon/muxcfg"
 	"github.com/v2fly/v2ray-core/v5/infra/conf/cfgcommon/proxycfg"
 	"github.com/v2fly/v2ray-core/v5/infra/conf/cfgcommon/sniffer"
+	"github.com/v2fly/v2ray-core/v5/infra/conf/cfgcommon/socketcfg"
 	"github.com/v2fly/v2ray-core/v5/infra/conf/synthetic/dns"
 	"github.com/v2fly/v2ray-core/v5/infra/conf/synthetic/log"
 	"github.com/v2fly/v2ray-core/v5/infra/conf/synthetic/router"
@@ -30,6 +32,8 @@ var (
 		"vless":         func() interface{} { return new(VLessInboundConfig) },
 		"vmess":         func() interface{} { return new(VMessInboundConfig) },
 		"trojan":        func() interface{} { return new(TrojanServerConfig) },
+		"hysteria2":     func() interface{} { return new(Hysteria2ServerConfig) },
+		"tuic":          func() interface{} { return new(TUICServerConfig) },
 	}, "protocol", "settings")
 
 	outboundConfigLoader = loader.NewJSONConfigLoader(loader.ConfigCreatorCache{
@@ -41,6 +45,8 @@ var (
 		"vless":       func() interface{} { return new(VLessOutboundConfig) },
 		"vmess":       func() interface{} { return new(VMessOutboundConfig) },
 		"trojan":      func() interface{} { return new(TrojanClientConfig) },
+		"hysteria2":   func() interface{} { return new(Hysteria2ClientConfig) },
+		"tuic":        func() interface{} { return new(TUICClientConfig) },
 		"dns":         func() interface{} { return new(DNSOutboundConfig) },
 		"loopback":    func() interface{} { return new(LoopbackConfig) },
 		"wireguard":   func() interface{} { return new(WireGuardClientConfig) },
@@ -204,14 +210,16 @@ func (c *InboundDetourConfig) Build() (*core.InboundHandlerConfig, error) {
 }
 
 type OutboundDetourConfig struct {
-	Protocol       string                `json:"protocol"`
-	SendThrough    *cfgcommon.Address    `json:"sendThrough"`
-	Tag            string                `json:"tag"`
-	Settings       *json.RawMessage      `json:"settings"`
-	StreamSetting  *StreamConfig         `json:"streamSettings"`
-	ProxySettings  *proxycfg.ProxyConfig `json:"proxySettings"`
-	MuxSettings    *muxcfg.MuxConfig     `json:"mux"`
-	DomainStrategy string                `json:"domainStrategy"`
+	Protocol            string                `json:"protocol"`
+	SendThrough         *cfgcommon.Address    `json:"sendThrough"`
+	SendThroughPool     []*cfgcommon.Address  `json:"sendThroughPool"`
+	SendThroughStrategy string                `json:"sendThroughStrategy"`
+	Tag                 string                `json:"tag"`
+	Settings            *json.RawMessage      `json:"settings"`
+	StreamSetting       *StreamConfig         `json:"streamSettings"`
+	ProxySettings       *proxycfg.ProxyConfig `json:"proxySettings"`
+	MuxSettings         *muxcfg.MuxConfig     `json:"mux"`
+	DomainStrategy      string                `json:"domainStrategy"`
 }
 
 // Build implements Buildable.
@@ -241,6 +249,20 @@ func (c *OutboundDetourConfig) Build() (*core.OutboundHandlerConfig, error) {
 		senderSettings.Via = address.Build()
 	}
 
+	for _, address := range c.SendThroughPool {
+		if address.Family().IsDomain() {
+			return nil, newError("unable to send through: " + address.String())
+		}
+		senderSettings.ViaPool = append(senderSettings.ViaPool, address.Build())
+	}
+	if len(c.SendThroughPool) > 0 {
+		strategy, err := socketcfg.ParseAddressPoolStrategy(c.SendThroughStrategy)
+		if err != nil {
+			return nil, err
+		}
+		senderSettings.ViaPoolStrategy = strategy
+	}
+
 	if c.StreamSetting != nil {
 		ss, err := c.StreamSetting.Build()
 		if err != nil {
@@ -258,7 +280,11 @@ func (c *OutboundDetourConfig) Build() (*core.OutboundHandlerConfig, error) {
 	}
 
 	if c.MuxSettings != nil {
-		senderSettings.MultiplexSettings = c.MuxSettings.Build()
+		ms, err := c.MuxSettings.Build()
+		if err != nil {
+			return nil, newError("invalid mux settings").Base(err)
+		}
+		senderSettings.MultiplexSettings = ms
 	}
 
 	settings := []byte("{}")
@@ -281,11 +307,38 @@ func (c *OutboundDetourConfig) Build() (*core.OutboundHandlerConfig, error) {
 	}, nil
 }
 
-type StatsConfig struct{}
+type StatsHistoryConfig struct {
+	Patterns []string `json:"patterns"`
+	Regexp   bool     `json:"regexp"`
+	Minutes  uint32   `json:"minutes"`
+	Hours    uint32   `json:"hours"`
+	Days     uint32   `json:"days"`
+}
+
+type StatsConfig struct {
+	History *StatsHistoryConfig `json:"history"`
+}
 
 // Build implements Buildable.
 func (c *StatsConfig) Build() (*stats.Config, error) {
-	return &stats.Config{}, nil
+	config := &stats.Config{}
+	if c.History != nil {
+		config.History = &stats.HistoryConfig{
+			Patterns: c.History.Patterns,
+			Regexp:   c.History.Regexp,
+			Minutes:  c.History.Minutes,
+			Hours:    c.History.Hours,
+			Days:     c.History.Days,
+		}
+	}
+	return config, nil
+}
+
+type ConntrackConfig struct{}
+
+// Build implements Buildable.
+func (c *ConntrackConfig) Build() (*conntrack.Config, error) {
+	return &conntrack.Config{}, nil
 }
 
 type Config struct {
@@ -325,6 +378,11 @@ type Config struct {
 	BurstObservatory *BurstObservatoryConfig `json:"burstObservatory"`
 	MultiObservatory *MultiObservatoryConfig `json:"multiObservatory"`
 	Ping             *PingConfig             `json:"ping"`
+	Metrics          *MetricsConfig          `json:"metrics"`
+	Conntrack        *ConntrackConfig        `json:"conntrack"`
+	Storage          *StorageConfig          `json:"persistentStorage"`
+	Quota            *QuotaConfig            `json:"quota"`
+	Tracing          *TracingConfig          `json:"tracing"`
 
 	Services map[string]*json.RawMessage `json:"services"`
 }
@@ -481,6 +539,46 @@ func (c *Config) Build() (*core.Config, error) {
 		config.App = append(config.App, serial.ToTypedMessage(r))
 	}
 
+	if c.Metrics != nil {
+		r, err := c.Metrics.Build()
+		if err != nil {
+			return nil, err
+		}
+		config.App = append(config.App, serial.ToTypedMessage(r))
+	}
+
+	if c.Conntrack != nil {
+		r, err := c.Conntrack.Build()
+		if err != nil {
+			return nil, err
+		}
+		config.App = append(config.App, serial.ToTypedMessage(r))
+	}
+
+	if c.Storage != nil {
+		r, err := c.Storage.Build()
+		if err != nil {
+			return nil, err
+		}
+		config.App = append(config.App, serial.ToTypedMessage(r))
+	}
+
+	if c.Quota != nil {
+		r, err := c.Quota.Build()
+		if err != nil {
+			return nil, err
+		}
+		config.App = append(config.App, serial.ToTypedMessage(r))
+	}
+
+	if c.Tracing != nil {
+		r, err := c.Tracing.Build()
+		if err != nil {
+			return nil, err
+		}
+		config.App = append(config.App, serial.ToTypedMessage(r))
+	}
+
 	// Load Additional Services that do not have a json translator
 
 	if msg, err := c.BuildServices(c.Services); err != nil {
@@ -371,12 +371,21 @@ func TestMuxConfig_Build(t *testing.T) {
 			Concurrency: 4,
 		}},
 		{"forbidden", `{"enabled": false, "concurrency": -1}`, nil},
+		{"smux", `{"enabled": true, "protocol": "smux", "smuxVersion": 2, "padding": true}`, &proxyman.MultiplexingConfig{
+			Enabled:     true,
+			Concurrency: 8,
+			Protocol:    proxyman.MultiplexingProtocol_Smux,
+			SmuxVersion: 2,
+			Padding:     true,
+		}},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			m := &muxcfg.MuxConfig{}
 			common.Must(json.Unmarshal([]byte(tt.fields), m))
-			if got := m.Build(); !reflect.DeepEqual(got, tt.want) {
+			got, err := m.Build()
+			common.Must(err)
+			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("MuxConfig.Build() = %v, want %v", got, tt.want)
 			}
 		})
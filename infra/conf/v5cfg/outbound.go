@@ -54,7 +54,11 @@ func (c OutboundConfig) BuildV5(ctx context.Context) (proto.Message, error) {
 	}
 
 	if c.MuxSettings != nil {
-		senderSettings.MultiplexSettings = c.MuxSettings.Build()
+		ms, err := c.MuxSettings.Build()
+		if err != nil {
+			return nil, newError("invalid mux settings").Base(err)
+		}
+		senderSettings.MultiplexSettings = ms
 	}
 
 	if c.Settings == nil {
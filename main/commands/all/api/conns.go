@@ -0,0 +1,200 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	conntrackService "github.com/v2fly/v2ray-core/v5/app/conntrack/command"
+	"github.com/v2fly/v2ray-core/v5/common/units"
+	"github.com/v2fly/v2ray-core/v5/main/commands/base"
+)
+
+var cmdConns = &base.Command{
+	CustomFlags: true,
+	UsageLine:   "{{.Exec}} api conns [--server=127.0.0.1:8080] [-close] [id]...",
+	Short:       "list or close connections",
+	Long: `
+List active connections of V2Ray, or close them.
+
+> Make sure you have "ConntrackService" set in "config.api.services"
+of server config, and "conntrack" enabled.
+
+> It ignores -timeout flag while watching connections
+
+Arguments:
+
+	-user <email>
+		Only connections of the user. With -close, close all
+		connections of the user.
+
+	-inbound <tag>
+		Only connections of the inbound.
+
+	-close
+		Close connections by their IDs.
+
+	-watch
+		Print connections as they are opened and closed.
+
+	-json
+		Use json output.
+
+	-s, -server <server:port>
+		The API server address. Default 127.0.0.1:8080
+
+	-t, -timeout <seconds>
+		Timeout seconds to call API. Default 3
+
+Example:
+
+	{{.Exec}} {{.LongName}}
+	{{.Exec}} {{.LongName}} -user love@v2fly.org
+	{{.Exec}} {{.LongName}} -close 12 13
+	{{.Exec}} {{.LongName}} -close -user love@v2fly.org
+	{{.Exec}} {{.LongName}} -watch
+`,
+	Run: executeConns,
+}
+
+func executeConns(cmd *base.Command, args []string) {
+	setSharedFlags(cmd)
+	var (
+		user    string
+		inbound string
+		doClose bool
+		watch   bool
+	)
+	cmd.Flag.StringVar(&user, "user", "", "")
+	cmd.Flag.StringVar(&inbound, "inbound", "", "")
+	cmd.Flag.BoolVar(&doClose, "close", false, "")
+	cmd.Flag.BoolVar(&watch, "watch", false, "")
+	cmd.Flag.Parse(args)
+
+	switch {
+	case doClose:
+		closeConns(cmd.Flag.Args(), user)
+	case watch:
+		watchConns(apiJSON)
+	default:
+		listConns(user, inbound, apiJSON)
+	}
+}
+
+func listConns(user, inbound string, jsonOutput bool) {
+	conn, ctx, close := dialAPIServer()
+	defer close()
+
+	client := conntrackService.NewConnectionTrackerServiceClient(conn)
+	r := &conntrackService.ListConnectionsRequest{
+		User:       user,
+		InboundTag: inbound,
+	}
+	resp, err := client.ListConnections(ctx, r)
+	if err != nil {
+		base.Fatalf("failed to list connections: %s", err)
+	}
+	if jsonOutput {
+		showJSONResponse(resp)
+		return
+	}
+	showConns(resp.Connections)
+}
+
+func closeConns(ids []string, user string) {
+	r := &conntrackService.CloseConnectionsRequest{
+		User: user,
+	}
+	for _, id := range ids {
+		v, err := strconv.ParseUint(id, 10, 64)
+		if err != nil {
+			base.Fatalf("invalid connection ID: %s", id)
+		}
+		r.Ids = append(r.Ids, v)
+	}
+	if len(r.Ids) == 0 && user == "" {
+		base.Fatalf("no connection specified")
+	}
+
+	conn, ctx, close := dialAPIServer()
+	defer close()
+
+	client := conntrackService.NewConnectionTrackerServiceClient(conn)
+	resp, err := client.CloseConnections(ctx, r)
+	if err != nil {
+		base.Fatalf("failed to close connections: %s", err)
+	}
+	fmt.Printf("%d connections closed\n", resp.Closed)
+}
+
+func watchConns(jsonOutput bool) {
+	conn, ctx, close := dialAPIServerWithoutTimeout()
+	defer close()
+
+	client := conntrackService.NewConnectionTrackerServiceClient(conn)
+	stream, err := client.SubscribeConnections(ctx, &conntrackService.SubscribeConnectionsRequest{})
+	if err != nil {
+		base.Fatalf("failed to watch connections: %s", err)
+	}
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			base.Fatalf("failed to fetch connection event: %s", err)
+		}
+		if jsonOutput {
+			showJSONResponse(event)
+			continue
+		}
+		c := event.Connection
+		switch event.Type {
+		case conntrackService.ConnectionEvent_Open:
+			fmt.Printf("open  %d %s [%s -> %s] %s -> %s\n", c.Id, c.User, c.InboundTag, c.OutboundTag, c.Source, connTarget(c))
+		case conntrackService.ConnectionEvent_Close:
+			fmt.Printf("close %d up %s down %s\n", c.Id, units.ByteSize(c.Uplink), units.ByteSize(c.Downlink))
+		}
+	}
+}
+
+func connTarget(c *conntrackService.Connection) string {
+	if c.Domain != "" && !strings.Contains(c.Destination, c.Domain) {
+		return c.Destination + " (" + c.Domain + ")"
+	}
+	return c.Destination
+}
+
+func showConns(conns []*conntrackService.Connection) {
+	if len(conns) == 0 {
+		return
+	}
+	formats := []string{"%-8s", "%-20s", "%-24s", "%-12s", "%-12s", "%-10s", "%-10s", "%-10s", "%s"}
+	sb := new(strings.Builder)
+	writeRow(sb, 0, 0,
+		[]string{"ID", "User", "Source", "Inbound", "Outbound", "Up", "Down", "Duration", "Destination"},
+		formats,
+	)
+	now := time.Now()
+	for i, c := range conns {
+		duration := now.Sub(time.Unix(c.StartTime, 0)).Truncate(time.Second)
+		writeRow(sb, 0, i+1,
+			[]string{
+				strconv.FormatUint(c.Id, 10),
+				c.User,
+				c.Source,
+				c.InboundTag,
+				c.OutboundTag,
+				units.ByteSize(c.Uplink).String(),
+				units.ByteSize(c.Downlink).String(),
+				duration.String(),
+				connTarget(c),
+			},
+			formats,
+		)
+	}
+	os.Stdout.WriteString(sb.String())
+}
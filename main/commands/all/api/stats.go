@@ -2,6 +2,7 @@ package api
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"sort"
 	"strings"
@@ -33,6 +34,17 @@ Arguments:
 	-runtime
 		Get runtime statistics.
 
+	-watch
+		Print traffic of counters in each interval, until interrupted.
+		It ignores -timeout flag.
+
+	-interval <seconds>
+		Interval of -watch. Default 1
+
+	-history <minute|hour|day>
+		Get histories of counters in buckets of the resolution.
+		Histories must be enabled in "config.stats.history".
+
 	-json
 		Use json output.
 
@@ -48,6 +60,8 @@ Example:
 	{{.Exec}} {{.LongName}} node1
 	{{.Exec}} {{.LongName}} -json node1 node2
 	{{.Exec}} {{.LongName}} -regexp 'node1.+downlink'
+	{{.Exec}} {{.LongName}} -history hour 'user>>>love@v2fly.org'
+	{{.Exec}} {{.LongName}} -watch -interval 5 'user>>>'
 `,
 	Run: executeStats,
 }
@@ -55,19 +69,33 @@ Example:
 func executeStats(cmd *base.Command, args []string) {
 	setSharedFlags(cmd)
 	var (
-		runtime bool
-		regexp  bool
-		reset   bool
+		runtime  bool
+		regexp   bool
+		reset    bool
+		history  string
+		watch    bool
+		interval uint
 	)
 	cmd.Flag.BoolVar(&runtime, "runtime", false, "")
 	cmd.Flag.BoolVar(&regexp, "regexp", false, "")
 	cmd.Flag.BoolVar(&reset, "reset", false, "")
+	cmd.Flag.StringVar(&history, "history", "", "")
+	cmd.Flag.BoolVar(&watch, "watch", false, "")
+	cmd.Flag.UintVar(&interval, "interval", 1, "")
 	cmd.Flag.Parse(args)
 	unnamed := cmd.Flag.Args()
 	if runtime {
 		getRuntimeStats(apiJSON)
 		return
 	}
+	if history != "" {
+		getHistory(unnamed, regexp, history, apiJSON)
+		return
+	}
+	if watch {
+		watchStats(unnamed, regexp, uint32(interval), apiJSON)
+		return
+	}
 	getStats(unnamed, regexp, reset, apiJSON)
 }
 
@@ -166,3 +194,94 @@ func showStats(stats []*statsService.Stat) {
 	)
 	os.Stdout.WriteString(sb.String())
 }
+
+func getHistory(patterns []string, regexp bool, resolution string, jsonOutput bool) {
+	r := &statsService.QueryHistoryRequest{
+		Patterns: patterns,
+		Regexp:   regexp,
+	}
+	switch strings.ToLower(resolution) {
+	case "minute":
+		r.Resolution = statsService.QueryHistoryRequest_Minute
+	case "hour":
+		r.Resolution = statsService.QueryHistoryRequest_Hour
+	case "day":
+		r.Resolution = statsService.QueryHistoryRequest_Day
+	default:
+		base.Fatalf("unknown resolution: %s", resolution)
+	}
+
+	conn, ctx, close := dialAPIServer()
+	defer close()
+
+	client := statsService.NewStatsServiceClient(conn)
+	resp, err := client.QueryHistory(ctx, r)
+	if err != nil {
+		base.Fatalf("failed to query history: %s", err)
+	}
+	if jsonOutput {
+		showJSONResponse(resp)
+		return
+	}
+	showHistory(resp.History)
+}
+
+func showHistory(histories []*statsService.History) {
+	formats := []string{"%-20s", "%-12s", "%s"}
+	sb := new(strings.Builder)
+	writeRow(sb, 0, 0,
+		[]string{"Time", "Value", "Name"},
+		formats,
+	)
+	idx := 0
+	for _, h := range histories {
+		for _, p := range h.Points {
+			idx++
+			writeRow(
+				sb, 0, idx,
+				[]string{time.Unix(p.Time, 0).Format("2006-01-02 15:04"), units.ByteSize(p.Value).String(), h.Name},
+				formats,
+			)
+		}
+	}
+	os.Stdout.WriteString(sb.String())
+}
+
+func watchStats(patterns []string, regexp bool, interval uint32, jsonOutput bool) {
+	conn, ctx, close := dialAPIServerWithoutTimeout()
+	defer close()
+
+	client := statsService.NewStatsServiceClient(conn)
+	stream, err := client.SubscribeStats(ctx, &statsService.SubscribeStatsRequest{
+		Patterns: patterns,
+		Regexp:   regexp,
+		Interval: interval,
+	})
+	if err != nil {
+		base.Fatalf("failed to watch stats: %s", err)
+	}
+	if interval == 0 {
+		interval = 1
+	}
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			base.Fatalf("failed to fetch stats: %s", err)
+		}
+		if jsonOutput {
+			showJSONResponse(resp)
+			continue
+		}
+		t := time.Unix(resp.Time, 0).Format("15:04:05")
+		for _, stat := range resp.Stat {
+			fmt.Printf("%s %-12s %-12s %s\n", t,
+				units.ByteSize(stat.Value).String(),
+				units.ByteSize(stat.Value/int64(interval)).String()+"/s",
+				stat.Name,
+			)
+		}
+	}
+}
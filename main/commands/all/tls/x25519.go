@@ -0,0 +1,51 @@
+package tls
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+
+	"golang.org/x/crypto/curve25519"
+
+	"github.com/v2fly/v2ray-core/v5/main/commands/base"
+)
+
+// cmdX25519 is the tls x25519 command
+var cmdX25519 = &base.Command{
+	UsageLine: "{{.Exec}} tls x25519 [-i <private_key>]",
+	Short:     "Generate X25519 key pairs for REALITY",
+	Long: `
+Generate X25519 key pairs for REALITY.
+
+Arguments:
+
+	-i <private_key>
+		Print the public key of the private key, instead of generating one.
+`,
+}
+
+func init() {
+	cmdX25519.Run = executeX25519 // break init loop
+}
+
+var x25519Input = cmdX25519.Flag.String("i", "", "")
+
+func executeX25519(cmd *base.Command, args []string) {
+	privateKey := make([]byte, curve25519.ScalarSize)
+	if *x25519Input != "" {
+		key, err := base64.RawURLEncoding.DecodeString(*x25519Input)
+		if err != nil || len(key) != curve25519.ScalarSize {
+			base.Fatalf("invalid private key: %s", *x25519Input)
+		}
+		privateKey = key
+	} else if _, err := rand.Read(privateKey); err != nil {
+		base.Fatalf("failed to generate private key: %s", err)
+	}
+
+	publicKey, err := curve25519.X25519(privateKey, curve25519.Basepoint)
+	if err != nil {
+		base.Fatalf("failed to compute public key: %s", err)
+	}
+	fmt.Println("Private key:", base64.RawURLEncoding.EncodeToString(privateKey))
+	fmt.Println("Public key:", base64.RawURLEncoding.EncodeToString(publicKey))
+}
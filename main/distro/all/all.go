@@ -15,8 +15,10 @@ import (
 	_ "github.com/v2fly/v2ray-core/v5/app/stats/command"
 
 	// Developer preview services
+	_ "github.com/v2fly/v2ray-core/v5/app/conntrack/command"
 	_ "github.com/v2fly/v2ray-core/v5/app/instman/command"
 	_ "github.com/v2fly/v2ray-core/v5/app/observatory/command"
+	_ "github.com/v2fly/v2ray-core/v5/app/quota/command"
 
 	// Other optional features.
 	_ "github.com/v2fly/v2ray-core/v5/app/dns"
@@ -30,9 +32,14 @@ import (
 	_ "github.com/v2fly/v2ray-core/v5/transport/internet/tagged/taggedimpl"
 
 	// Developer preview features
+	_ "github.com/v2fly/v2ray-core/v5/app/conntrack"
 	_ "github.com/v2fly/v2ray-core/v5/app/instman"
+	_ "github.com/v2fly/v2ray-core/v5/app/metrics"
 	_ "github.com/v2fly/v2ray-core/v5/app/observatory"
+	_ "github.com/v2fly/v2ray-core/v5/app/persistentstorage/filesystemstorage"
+	_ "github.com/v2fly/v2ray-core/v5/app/quota"
 	_ "github.com/v2fly/v2ray-core/v5/app/restfulapi"
+	_ "github.com/v2fly/v2ray-core/v5/app/tracing"
 
 	// Inbound and outbound proxies.
 	_ "github.com/v2fly/v2ray-core/v5/proxy/blackhole"
@@ -40,11 +47,13 @@ import (
 	_ "github.com/v2fly/v2ray-core/v5/proxy/dokodemo"
 	_ "github.com/v2fly/v2ray-core/v5/proxy/freedom"
 	_ "github.com/v2fly/v2ray-core/v5/proxy/http"
+	_ "github.com/v2fly/v2ray-core/v5/proxy/hysteria2"
 	_ "github.com/v2fly/v2ray-core/v5/proxy/shadowsocks"
 	_ "github.com/v2fly/v2ray-core/v5/proxy/shadowsocks/plugin/external"
 	_ "github.com/v2fly/v2ray-core/v5/proxy/shadowsocks/plugin/self"
 	_ "github.com/v2fly/v2ray-core/v5/proxy/socks"
 	_ "github.com/v2fly/v2ray-core/v5/proxy/trojan"
+	_ "github.com/v2fly/v2ray-core/v5/proxy/tuic"
 	_ "github.com/v2fly/v2ray-core/v5/proxy/vless/inbound"
 	_ "github.com/v2fly/v2ray-core/v5/proxy/vless/outbound"
 	_ "github.com/v2fly/v2ray-core/v5/proxy/vmess/inbound"
@@ -54,10 +63,15 @@ import (
 	_ "github.com/v2fly/v2ray-core/v5/transport/internet/domainsocket"
 	_ "github.com/v2fly/v2ray-core/v5/transport/internet/grpc"
 	_ "github.com/v2fly/v2ray-core/v5/transport/internet/http"
+	_ "github.com/v2fly/v2ray-core/v5/transport/internet/httpupgrade"
+	_ "github.com/v2fly/v2ray-core/v5/transport/internet/hysteria2"
 	_ "github.com/v2fly/v2ray-core/v5/transport/internet/kcp"
+	_ "github.com/v2fly/v2ray-core/v5/transport/internet/meek"
 	_ "github.com/v2fly/v2ray-core/v5/transport/internet/quic"
+	_ "github.com/v2fly/v2ray-core/v5/transport/internet/splithttp"
 	_ "github.com/v2fly/v2ray-core/v5/transport/internet/tcp"
 	_ "github.com/v2fly/v2ray-core/v5/transport/internet/tls"
+	_ "github.com/v2fly/v2ray-core/v5/transport/internet/tuic"
 	_ "github.com/v2fly/v2ray-core/v5/transport/internet/udp"
 	_ "github.com/v2fly/v2ray-core/v5/transport/internet/websocket"
 
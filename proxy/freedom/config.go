@@ -1,5 +1,20 @@
 package freedom
 
+import (
+	"github.com/v2fly/v2ray-core/v5/common/buf"
+)
+
 func (c *Config) useIP() bool {
 	return c.DomainStrategy == Config_USE_IP || c.DomainStrategy == Config_USE_IP4 || c.DomainStrategy == Config_USE_IP6
 }
+
+// Validate returns an error if the noise packet does not fit in a buffer.
+func (n *Noise) Validate() error {
+	if len(n.Packet) > buf.Size {
+		return newError("noise packet of ", len(n.Packet), " bytes is larger than ", buf.Size, " bytes")
+	}
+	if n.LengthMin > buf.Size || n.LengthMax > buf.Size {
+		return newError("noise length ", n.LengthMin, "-", n.LengthMax, " is larger than ", buf.Size, " bytes")
+	}
+	return nil
+}
@@ -0,0 +1,199 @@
+package freedom
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+	"math"
+	"time"
+
+	"github.com/v2fly/v2ray-core/v5/common/buf"
+	"github.com/v2fly/v2ray-core/v5/common/dice"
+)
+
+func randomBetween(min, max uint32) int {
+	if max <= min {
+		return int(min)
+	}
+	return int(min) + dice.Roll(int(max-min)+1)
+}
+
+// fragmentWriter splits selected writes into several TCP segments with small
+// delays in between.
+type fragmentWriter struct {
+	writer io.Writer
+	config *Fragment
+	count  uint32
+}
+
+func newFragmentWriter(writer io.Writer, config *Fragment) buf.Writer {
+	return &fragmentWriter{
+		writer: writer,
+		config: config,
+	}
+}
+
+func (w *fragmentWriter) shouldFragment() bool {
+	if w.config.TlsHelloOnly {
+		return w.count == 1
+	}
+	from := w.config.PacketsFrom
+	if from == 0 {
+		from = 1
+	}
+	to := w.config.PacketsTo
+	if to < from {
+		to = from
+	}
+	return w.count >= from && w.count <= to
+}
+
+// WriteMultiBuffer implements buf.Writer.
+func (w *fragmentWriter) WriteMultiBuffer(mb buf.MultiBuffer) error {
+	if w.count < math.MaxUint32 {
+		w.count++
+	}
+	if !w.shouldFragment() {
+		mb, err := buf.WriteMultiBuffer(w.writer, mb)
+		buf.ReleaseMulti(mb)
+		return err
+	}
+
+	payload := make([]byte, mb.Len())
+	mb.Copy(payload)
+	buf.ReleaseMulti(mb)
+
+	if w.config.TlsHelloOnly && !isTLSClientHello(payload) {
+		return buf.WriteAllBytes(w.writer, payload)
+	}
+	return w.writeFragments(payload)
+}
+
+func (w *fragmentWriter) writeFragments(payload []byte) error {
+	cut := -1
+	if w.config.SplitServerName {
+		if offset, length := findServerName(payload); length > 1 {
+			cut = offset + length/2
+		}
+	}
+
+	for start := 0; start < len(payload); {
+		end := len(payload)
+		if w.config.LengthMax > 0 || w.config.LengthMin > 0 {
+			if length := randomBetween(w.config.LengthMin, w.config.LengthMax); length > 0 && start+length < end {
+				end = start + length
+			}
+		}
+		if cut > start && cut < end {
+			end = cut
+		}
+		if err := buf.WriteAllBytes(w.writer, payload[start:end]); err != nil {
+			return err
+		}
+		start = end
+		if start < len(payload) {
+			if delay := randomBetween(w.config.IntervalMin, w.config.IntervalMax); delay > 0 {
+				time.Sleep(time.Duration(delay) * time.Millisecond)
+			}
+		}
+	}
+	return nil
+}
+
+func isTLSClientHello(b []byte) bool {
+	return len(b) > 5 && b[0] == 0x16 && b[5] == 0x01
+}
+
+// findServerName returns the offset and the length of the server name in a
+// TLS ClientHello record, or a zero length if it cannot be found.
+func findServerName(b []byte) (int, int) {
+	if !isTLSClientHello(b) {
+		return 0, 0
+	}
+	// record header (5), handshake header (4), client version (2), random (32)
+	offset := 5 + 4 + 2 + 32
+	if len(b) < offset+1 {
+		return 0, 0
+	}
+	offset += 1 + int(b[offset]) // session id
+	if len(b) < offset+2 {
+		return 0, 0
+	}
+	offset += 2 + int(binary.BigEndian.Uint16(b[offset:])) // cipher suites
+	if len(b) < offset+1 {
+		return 0, 0
+	}
+	offset += 1 + int(b[offset]) // compression methods
+	if len(b) < offset+2 {
+		return 0, 0
+	}
+	end := offset + 2 + int(binary.BigEndian.Uint16(b[offset:]))
+	offset += 2
+	if end > len(b) {
+		end = len(b)
+	}
+	for offset+4 <= end {
+		extensionType := binary.BigEndian.Uint16(b[offset:])
+		extensionLength := int(binary.BigEndian.Uint16(b[offset+2:]))
+		offset += 4
+		if extensionType != 0x0000 {
+			offset += extensionLength
+			continue
+		}
+		// server name list length (2), name type (1), name length (2)
+		if offset+5 > end {
+			return 0, 0
+		}
+		length := int(binary.BigEndian.Uint16(b[offset+3:]))
+		offset += 5
+		if offset+length > end {
+			return 0, 0
+		}
+		return offset, length
+	}
+	return 0, 0
+}
+
+// noiseWriter sends the configured noise packets before the first packet to
+// the destination.
+type noiseWriter struct {
+	buf.Writer
+	noise []*Noise
+	sent  bool
+}
+
+func newNoiseWriter(writer buf.Writer, noise []*Noise) buf.Writer {
+	return &noiseWriter{
+		Writer: writer,
+		noise:  noise,
+	}
+}
+
+// WriteMultiBuffer implements buf.Writer.
+func (w *noiseWriter) WriteMultiBuffer(mb buf.MultiBuffer) error {
+	if !w.sent && !mb.IsEmpty() {
+		w.sent = true
+		for _, noise := range w.noise {
+			b := buf.New()
+			if len(noise.Packet) > 0 {
+				b.Write(noise.Packet)
+			} else {
+				length := randomBetween(noise.LengthMin, noise.LengthMax)
+				if _, err := b.ReadFullFrom(rand.Reader, int32(length)); err != nil {
+					b.Release()
+					buf.ReleaseMulti(mb)
+					return err
+				}
+			}
+			b.Endpoint = mb[0].Endpoint
+			if err := w.Writer.WriteMultiBuffer(buf.MultiBuffer{b}); err != nil {
+				buf.ReleaseMulti(mb)
+				return err
+			}
+			if delay := randomBetween(noise.DelayMin, noise.DelayMax); delay > 0 {
+				time.Sleep(time.Duration(delay) * time.Millisecond)
+			}
+		}
+	}
+	return w.Writer.WriteMultiBuffer(mb)
+}
@@ -0,0 +1,94 @@
+package freedom
+
+import (
+	"bytes"
+	"crypto/tls"
+	"net"
+	"testing"
+
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/buf"
+)
+
+func clientHello(t *testing.T, serverName string) []byte {
+	client, server := net.Pipe()
+	go func() {
+		tls.Client(client, &tls.Config{ServerName: serverName}).Handshake()
+	}()
+	defer client.Close()
+	defer server.Close()
+
+	b := buf.New()
+	defer b.Release()
+	if _, err := b.ReadFrom(server); err != nil {
+		t.Fatal(err)
+	}
+	return append([]byte(nil), b.Bytes()...)
+}
+
+type segmentRecorder struct {
+	segments [][]byte
+}
+
+func (r *segmentRecorder) Write(b []byte) (int, error) {
+	r.segments = append(r.segments, append([]byte(nil), b...))
+	return len(b), nil
+}
+
+func TestFindServerName(t *testing.T) {
+	hello := clientHello(t, "www.example.com")
+	offset, length := findServerName(hello)
+	if string(hello[offset:offset+length]) != "www.example.com" {
+		t.Error("unexpected server name: ", string(hello[offset:offset+length]))
+	}
+
+	if _, length := findServerName([]byte("GET / HTTP/1.1\r\n")); length != 0 {
+		t.Error("expected no server name")
+	}
+}
+
+func TestFragmentWriter(t *testing.T) {
+	hello := clientHello(t, "www.example.com")
+	recorder := &segmentRecorder{}
+	writer := newFragmentWriter(recorder, &Fragment{
+		TlsHelloOnly: true,
+		LengthMin:    10,
+		LengthMax:    20,
+	})
+
+	common.Must(writer.WriteMultiBuffer(buf.MergeBytes(nil, hello)))
+	if len(recorder.segments) < len(hello)/20 {
+		t.Error("expected at least ", len(hello)/20, " segments, but got ", len(recorder.segments))
+	}
+	for _, segment := range recorder.segments[:len(recorder.segments)-1] {
+		if len(segment) < 10 || len(segment) > 20 {
+			t.Error("unexpected segment length: ", len(segment))
+		}
+	}
+	if !bytes.Equal(bytes.Join(recorder.segments, nil), hello) {
+		t.Error("fragmented payload does not match")
+	}
+
+	count := len(recorder.segments)
+	common.Must(writer.WriteMultiBuffer(buf.MergeBytes(nil, hello)))
+	if len(recorder.segments) != count+1 {
+		t.Error("expected the second write to be sent as a whole")
+	}
+}
+
+func TestFragmentWriterSplitServerName(t *testing.T) {
+	hello := clientHello(t, "www.example.com")
+	recorder := &segmentRecorder{}
+	writer := newFragmentWriter(recorder, &Fragment{
+		TlsHelloOnly:    true,
+		SplitServerName: true,
+	})
+
+	common.Must(writer.WriteMultiBuffer(buf.MergeBytes(nil, hello)))
+	if len(recorder.segments) != 2 {
+		t.Fatal("expected 2 segments, but got ", len(recorder.segments))
+	}
+	if bytes.Contains(recorder.segments[0], []byte("www.example.com")) || bytes.Contains(recorder.segments[1], []byte("www.example.com")) {
+		t.Error("server name is not split")
+	}
+}
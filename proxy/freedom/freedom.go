@@ -50,6 +50,11 @@ type Handler struct {
 
 // Init initializes the Handler with necessary parameters.
 func (h *Handler) Init(config *Config, pm policy.Manager, d dns.Client) error {
+	for _, noise := range config.Noise {
+		if err := noise.Validate(); err != nil {
+			return err
+		}
+	}
 	h.config = config
 	h.policyManager = pm
 	h.dns = d
@@ -153,9 +158,16 @@ func (h *Handler) Process(ctx context.Context, link *transport.Link, dialer inte
 
 		var writer buf.Writer
 		if destination.Network == net.Network_TCP {
-			writer = buf.NewWriter(conn)
+			if h.config.Fragment != nil {
+				writer = newFragmentWriter(conn, h.config.Fragment)
+			} else {
+				writer = buf.NewWriter(conn)
+			}
 		} else {
 			writer = newPacketWriter(conn)
+			if len(h.config.Noise) > 0 {
+				writer = newNoiseWriter(writer, h.config.Noise)
+			}
 		}
 
 		if err := buf.Copy(input, writer, buf.UpdateActivity(timer)); err != nil {
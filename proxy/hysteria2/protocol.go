@@ -0,0 +1,312 @@
+package hysteria2
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+	"sync"
+
+	"github.com/lucas-clemente/quic-go/quicvarint"
+
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/buf"
+	"github.com/v2fly/v2ray-core/v5/common/dice"
+	"github.com/v2fly/v2ray-core/v5/common/net"
+)
+
+const (
+	frameTypeTCPRequest = 0x401
+
+	tcpResponseOK    = 0x00
+	tcpResponseError = 0x01
+
+	maxAddressLength = 2048
+	maxMessageLength = 2048
+	maxPaddingLength = 4096
+
+	// maxUDPMessageSize keeps each UDP message in one QUIC packet with the session ID added by the
+	// transport. Larger packets are fragmented.
+	maxUDPMessageSize = 1150
+	udpHeaderSize     = 4
+)
+
+func writeVarBytes(w *bytes.Buffer, b []byte) {
+	quicvarint.Write(w, uint64(len(b)))
+	w.Write(b)
+}
+
+func readVarBytes(r io.Reader, limit uint64) ([]byte, error) {
+	length, err := quicvarint.Read(quicvarint.NewReader(r))
+	if err != nil {
+		return nil, err
+	}
+	if length > limit {
+		return nil, newError("field too long: ", length)
+	}
+	b := make([]byte, length)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func randomPadding() []byte {
+	padding := make([]byte, 64+dice.Roll(448))
+	common.Must2(rand.Read(padding))
+	return padding
+}
+
+func parseAddress(addr string) (net.Destination, error) {
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		return net.Destination{}, newError("invalid address: ", addr).Base(err)
+	}
+	port, err := net.PortFromString(portStr)
+	if err != nil {
+		return net.Destination{}, newError("invalid port: ", addr).Base(err)
+	}
+	return net.Destination{
+		Address: net.ParseAddress(host),
+		Port:    port,
+	}, nil
+}
+
+// WriteTCPRequest writes the request of a connection to dest.
+func WriteTCPRequest(w io.Writer, dest net.Destination) error {
+	var request bytes.Buffer
+	quicvarint.Write(&request, frameTypeTCPRequest)
+	writeVarBytes(&request, []byte(dest.NetAddr()))
+	writeVarBytes(&request, randomPadding())
+	_, err := w.Write(request.Bytes())
+	return err
+}
+
+// ReadTCPRequest reads the request of a connection and returns its destination.
+func ReadTCPRequest(r io.Reader) (net.Destination, error) {
+	frameType, err := quicvarint.Read(quicvarint.NewReader(r))
+	if err != nil {
+		return net.Destination{}, newError("failed to read frame type").Base(err)
+	}
+	if frameType != frameTypeTCPRequest {
+		return net.Destination{}, newError("unexpected frame type: ", frameType)
+	}
+	addr, err := readVarBytes(r, maxAddressLength)
+	if err != nil {
+		return net.Destination{}, newError("failed to read address").Base(err)
+	}
+	if _, err := readVarBytes(r, maxPaddingLength); err != nil {
+		return net.Destination{}, newError("failed to read padding").Base(err)
+	}
+	dest, err := parseAddress(string(addr))
+	if err != nil {
+		return net.Destination{}, err
+	}
+	dest.Network = net.Network_TCP
+	return dest, nil
+}
+
+// WriteTCPResponse writes the response to the request of a connection. An empty message means
+// success.
+func WriteTCPResponse(w io.Writer, message string) error {
+	var response bytes.Buffer
+	if message == "" {
+		response.WriteByte(tcpResponseOK)
+	} else {
+		response.WriteByte(tcpResponseError)
+	}
+	writeVarBytes(&response, []byte(message))
+	writeVarBytes(&response, randomPadding())
+	_, err := w.Write(response.Bytes())
+	return err
+}
+
+// ReadTCPResponse reads the response to the request of a connection, and returns an error if the
+// request was rejected.
+func ReadTCPResponse(r io.Reader) error {
+	var status [1]byte
+	if _, err := io.ReadFull(r, status[:]); err != nil {
+		return newError("failed to read status").Base(err)
+	}
+	message, err := readVarBytes(r, maxMessageLength)
+	if err != nil {
+		return newError("failed to read message").Base(err)
+	}
+	if _, err := readVarBytes(r, maxPaddingLength); err != nil {
+		return newError("failed to read padding").Base(err)
+	}
+	if status[0] != tcpResponseOK {
+		return newError("request rejected: ", string(message))
+	}
+	return nil
+}
+
+// PacketWriter writes UDP packets as UDP messages. Each Write of the underlying writer must send a
+// single datagram.
+type PacketWriter struct {
+	io.Writer
+	Target net.Destination
+
+	access   sync.Mutex
+	packetID uint16
+}
+
+// WriteMultiBuffer implements buf.Writer.
+func (w *PacketWriter) WriteMultiBuffer(mb buf.MultiBuffer) error {
+	defer buf.ReleaseMulti(mb)
+
+	for _, buffer := range mb {
+		target := w.Target
+		if buffer.Endpoint != nil {
+			target = *buffer.Endpoint
+		}
+		if err := w.writePacket(buffer.Bytes(), target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteMultiBufferWithMetadata writes UDP packets from dest.
+func (w *PacketWriter) WriteMultiBufferWithMetadata(mb buf.MultiBuffer, dest net.Destination) error {
+	defer buf.ReleaseMulti(mb)
+
+	for _, buffer := range mb {
+		if err := w.writePacket(buffer.Bytes(), dest); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *PacketWriter) writePacket(payload []byte, dest net.Destination) error {
+	addr := []byte(dest.NetAddr())
+	headerSize := udpHeaderSize + int(quicvarint.Len(uint64(len(addr)))) + len(addr)
+	fragmentSize := maxUDPMessageSize - headerSize
+	if fragmentSize <= 0 {
+		return newError("address too long: ", dest)
+	}
+	fragments := (len(payload) + fragmentSize - 1) / fragmentSize
+	if fragments == 0 {
+		fragments = 1
+	}
+	if fragments > 255 {
+		return newError("packet too large: ", len(payload))
+	}
+
+	w.access.Lock()
+	defer w.access.Unlock()
+
+	w.packetID++
+	for i := 0; i < fragments; i++ {
+		end := (i + 1) * fragmentSize
+		if end > len(payload) {
+			end = len(payload)
+		}
+
+		var message bytes.Buffer
+		var header [udpHeaderSize]byte
+		binary.BigEndian.PutUint16(header[:], w.packetID)
+		header[2] = byte(i)
+		header[3] = byte(fragments)
+		message.Write(header[:])
+		writeVarBytes(&message, addr)
+		message.Write(payload[i*fragmentSize : end])
+		if _, err := w.Write(message.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PacketPayload combines UDP payload and its source or destination.
+type PacketPayload struct {
+	Target net.Destination
+	Buffer buf.MultiBuffer
+}
+
+// PacketReader reads UDP packets from UDP messages. Each Read of the underlying reader must
+// return a single datagram.
+type PacketReader struct {
+	io.Reader
+
+	packetID  uint16
+	fragments [][]byte
+	received  int
+}
+
+// ReadMultiBuffer implements buf.Reader.
+func (r *PacketReader) ReadMultiBuffer() (buf.MultiBuffer, error) {
+	p, err := r.ReadMultiBufferWithMetadata()
+	if p != nil {
+		return p.Buffer, err
+	}
+	return nil, err
+}
+
+// ReadMultiBufferWithMetadata reads a UDP packet with its source or destination.
+func (r *PacketReader) ReadMultiBufferWithMetadata() (*PacketPayload, error) {
+	message := make([]byte, buf.Size)
+	for {
+		n, err := r.Read(message)
+		if err != nil {
+			return nil, err
+		}
+		if p := r.handleMessage(message[:n]); p != nil {
+			return p, nil
+		}
+	}
+}
+
+// handleMessage returns the packet completed by message, or nil if more fragments are expected
+// or the message is invalid.
+func (r *PacketReader) handleMessage(message []byte) *PacketPayload {
+	if len(message) < udpHeaderSize {
+		return nil
+	}
+	packetID := binary.BigEndian.Uint16(message)
+	fragmentID := int(message[2])
+	fragments := int(message[3])
+	reader := bytes.NewReader(message[udpHeaderSize:])
+	addr, err := readVarBytes(reader, maxAddressLength)
+	if err != nil || fragments == 0 || fragmentID >= fragments {
+		return nil
+	}
+	dest, err := parseAddress(string(addr))
+	if err != nil {
+		return nil
+	}
+	dest.Network = net.Network_UDP
+	payload := message[len(message)-reader.Len():]
+
+	if fragments == 1 {
+		return newPacketPayload(dest, payload)
+	}
+
+	// Only the latest fragmented packet is kept, and a new one drops the incomplete one.
+	if r.fragments == nil || r.packetID != packetID || len(r.fragments) != fragments {
+		r.packetID = packetID
+		r.fragments = make([][]byte, fragments)
+		r.received = 0
+	}
+	if r.fragments[fragmentID] != nil {
+		return nil
+	}
+	r.fragments[fragmentID] = append([]byte(nil), payload...)
+	r.received++
+	if r.received < fragments {
+		return nil
+	}
+
+	packet := bytes.Join(r.fragments, nil)
+	r.fragments = nil
+	return newPacketPayload(dest, packet)
+}
+
+func newPacketPayload(dest net.Destination, payload []byte) *PacketPayload {
+	mb := buf.MergeBytes(nil, payload)
+	for _, b := range mb {
+		b.Endpoint = &dest
+	}
+	return &PacketPayload{Target: dest, Buffer: mb}
+}
@@ -0,0 +1,87 @@
+package hysteria2_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/buf"
+	"github.com/v2fly/v2ray-core/v5/common/net"
+	. "github.com/v2fly/v2ray-core/v5/proxy/hysteria2"
+)
+
+func TestTCPRequest(t *testing.T) {
+	dest := net.TCPDestination(net.DomainAddress("www.v2fly.org"), 443)
+
+	var buffer bytes.Buffer
+	common.Must(WriteTCPRequest(&buffer, dest))
+	common.Must(WriteTCPResponse(&buffer, ""))
+	common.Must(WriteTCPResponse(&buffer, "connection refused"))
+
+	actualDest, err := ReadTCPRequest(&buffer)
+	common.Must(err)
+	if r := cmp.Diff(actualDest, dest); r != "" {
+		t.Error(r)
+	}
+	if err := ReadTCPResponse(&buffer); err != nil {
+		t.Error("expected success, but got ", err)
+	}
+	if err := ReadTCPResponse(&buffer); err == nil {
+		t.Error("expected rejection")
+	}
+}
+
+// datagrams keeps each Write as a message, like the datagram connections of the transport.
+type datagrams struct {
+	messages [][]byte
+}
+
+func (d *datagrams) Write(b []byte) (int, error) {
+	d.messages = append(d.messages, append([]byte(nil), b...))
+	return len(b), nil
+}
+
+func (d *datagrams) Read(b []byte) (int, error) {
+	if len(d.messages) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(b, d.messages[0])
+	d.messages = d.messages[1:]
+	return n, nil
+}
+
+func TestUDPPacket(t *testing.T) {
+	dest := net.UDPDestination(net.IPAddress([]byte{1, 2, 3, 4}), 53)
+
+	small := make([]byte, 64)
+	common.Must2(rand.Read(small))
+	large := make([]byte, 4000)
+	common.Must2(rand.Read(large))
+
+	conn := new(datagrams)
+	writer := &PacketWriter{Writer: conn, Target: dest}
+	common.Must(writer.WriteMultiBuffer(buf.MergeBytes(nil, small)))
+	common.Must(writer.WriteMultiBuffer(buf.MergeBytes(nil, large)))
+	if len(conn.messages) < 5 {
+		t.Error("expected fragmented packet, but got ", len(conn.messages), " messages")
+	}
+
+	reader := &PacketReader{Reader: conn}
+	for _, expected := range [][]byte{small, large} {
+		p, err := reader.ReadMultiBufferWithMetadata()
+		common.Must(err)
+		if r := cmp.Diff(p.Target, dest); r != "" {
+			t.Error(r)
+		}
+		actual := make([]byte, p.Buffer.Len())
+		p.Buffer.Copy(actual)
+		buf.ReleaseMulti(p.Buffer)
+		if r := cmp.Diff(actual, expected); r != "" {
+			t.Error(r)
+		}
+	}
+}
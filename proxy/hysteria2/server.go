@@ -0,0 +1,244 @@
+package hysteria2
+
+import (
+	"context"
+	"io"
+	"time"
+
+	core "github.com/v2fly/v2ray-core/v5"
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/buf"
+	"github.com/v2fly/v2ray-core/v5/common/errors"
+	"github.com/v2fly/v2ray-core/v5/common/log"
+	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/common/protocol"
+	udp_proto "github.com/v2fly/v2ray-core/v5/common/protocol/udp"
+	"github.com/v2fly/v2ray-core/v5/common/session"
+	"github.com/v2fly/v2ray-core/v5/common/signal"
+	"github.com/v2fly/v2ray-core/v5/common/task"
+	"github.com/v2fly/v2ray-core/v5/features/policy"
+	"github.com/v2fly/v2ray-core/v5/features/routing"
+	"github.com/v2fly/v2ray-core/v5/transport/internet"
+	hyTransport "github.com/v2fly/v2ray-core/v5/transport/internet/hysteria2"
+	"github.com/v2fly/v2ray-core/v5/transport/internet/udp"
+)
+
+func init() {
+	common.Must(common.RegisterConfig((*ServerConfig)(nil), func(ctx context.Context, config interface{}) (interface{}, error) {
+		return NewServer(ctx, config.(*ServerConfig))
+	}))
+}
+
+// Server is an inbound connection handler that handles messages in hysteria2 protocol.
+type Server struct {
+	policyManager policy.Manager
+	validator     *Validator
+}
+
+// NewServer creates a new hysteria2 inbound handler.
+func NewServer(ctx context.Context, config *ServerConfig) (*Server, error) {
+	validator := new(Validator)
+	for _, user := range config.Users {
+		u, err := user.ToMemoryUser()
+		if err != nil {
+			return nil, newError("failed to get hysteria2 user").Base(err).AtError()
+		}
+
+		if err := validator.Add(u); err != nil {
+			return nil, newError("failed to add user").Base(err).AtError()
+		}
+	}
+
+	v := core.MustFromContext(ctx)
+	server := &Server{
+		policyManager: v.GetFeature(policy.ManagerType()).(policy.Manager),
+		validator:     validator,
+	}
+	return server, nil
+}
+
+// AddUser implements proxy.UserManager.AddUser().
+func (s *Server) AddUser(ctx context.Context, u *protocol.MemoryUser) error {
+	return s.validator.Add(u)
+}
+
+// RemoveUser implements proxy.UserManager.RemoveUser().
+func (s *Server) RemoveUser(ctx context.Context, e string) error {
+	return s.validator.Del(e)
+}
+
+// Network implements proxy.Inbound.Network().
+func (s *Server) Network() []net.Network {
+	return []net.Network{net.Network_TCP}
+}
+
+// Process implements proxy.Inbound.Process().
+func (s *Server) Process(ctx context.Context, network net.Network, conn internet.Connection, dispatcher routing.Dispatcher) error {
+	iConn := conn
+	if statConn, ok := iConn.(*internet.StatCouterConnection); ok {
+		iConn = statConn.Connection
+	}
+
+	switch c := iConn.(type) {
+	case hyTransport.AuthConnection:
+		return s.authenticate(c)
+	case hyTransport.PacketConnection:
+		return s.handleUDPPayload(ctx, c.User(), conn, dispatcher)
+	case hyTransport.Connection:
+		return s.handleConnection(ctx, c.User(), conn, dispatcher)
+	default:
+		return newError("hysteria2 inbound requires hysteria2 transport")
+	}
+}
+
+func (s *Server) authenticate(conn hyTransport.AuthConnection) error {
+	user := s.validator.Get(conn.Auth())
+	conn.Authenticate(user)
+	if user == nil {
+		err := newError("not a valid user")
+		log.Record(&log.AccessMessage{
+			From:   conn.RemoteAddr(),
+			To:     "",
+			Status: log.AccessRejected,
+			Reason: err,
+		})
+		return err
+	}
+	newError("accepted user ", user.Email, " from ", conn.RemoteAddr()).AtInfo().WriteToLog()
+	return nil
+}
+
+func (s *Server) sessionPolicy(ctx context.Context, user *protocol.MemoryUser) policy.Session {
+	inbound := session.InboundFromContext(ctx)
+	if inbound == nil {
+		panic("no inbound metadata")
+	}
+	if user == nil {
+		return s.policyManager.ForLevel(0)
+	}
+	inbound.User = user
+	return s.policyManager.ForLevel(user.Level)
+}
+
+func (s *Server) handleUDPPayload(ctx context.Context, user *protocol.MemoryUser, conn internet.Connection, dispatcher routing.Dispatcher) error {
+	sessionPolicy := s.sessionPolicy(ctx, user)
+	inbound := session.InboundFromContext(ctx)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	timer := signal.CancelAfterInactivity(ctx, cancel, sessionPolicy.Timeouts.ConnectionIdle)
+	go func() {
+		// UDP sessions have no end, so close the connection when it has been idle.
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	clientWriter := &PacketWriter{Writer: conn}
+	udpServer := udp.NewSplitDispatcher(dispatcher, func(ctx context.Context, packet *udp_proto.Packet) {
+		timer.Update()
+		if err := clientWriter.WriteMultiBufferWithMetadata(buf.MultiBuffer{packet.Payload}, packet.Source); err != nil {
+			newError("failed to write response").Base(err).AtWarning().WriteToLog(session.ExportIDToError(ctx))
+		}
+	})
+
+	clientReader := &PacketReader{Reader: conn}
+	for {
+		p, err := clientReader.ReadMultiBufferWithMetadata()
+		if err != nil {
+			if errors.Cause(err) != io.EOF {
+				return newError("unexpected EOF").Base(err)
+			}
+			return nil
+		}
+		timer.Update()
+
+		accessMessage := &log.AccessMessage{
+			From:   inbound.Source,
+			To:     p.Target,
+			Status: log.AccessAccepted,
+			Reason: "",
+		}
+		if user != nil {
+			accessMessage.Email = user.Email
+		}
+		ctx := log.ContextWithAccessMessage(ctx, accessMessage)
+		newError("tunnelling request to ", p.Target).WriteToLog(session.ExportIDToError(ctx))
+
+		for _, b := range p.Buffer {
+			udpServer.Dispatch(ctx, p.Target, b)
+		}
+	}
+}
+
+func (s *Server) handleConnection(ctx context.Context, user *protocol.MemoryUser, conn internet.Connection, dispatcher routing.Dispatcher) error {
+	sessionPolicy := s.sessionPolicy(ctx, user)
+
+	if err := conn.SetReadDeadline(time.Now().Add(sessionPolicy.Timeouts.Handshake)); err != nil {
+		return newError("unable to set read deadline").Base(err).AtWarning()
+	}
+	destination, err := ReadTCPRequest(conn)
+	if err != nil {
+		log.Record(&log.AccessMessage{
+			From:   conn.RemoteAddr(),
+			To:     "",
+			Status: log.AccessRejected,
+			Reason: err,
+		})
+		return newError("failed to read request from: ", conn.RemoteAddr()).Base(err)
+	}
+	if err := conn.SetReadDeadline(time.Time{}); err != nil {
+		return newError("unable to set read deadline").Base(err).AtWarning()
+	}
+
+	accessMessage := &log.AccessMessage{
+		From:   conn.RemoteAddr(),
+		To:     destination,
+		Status: log.AccessAccepted,
+		Reason: "",
+	}
+	if user != nil {
+		accessMessage.Email = user.Email
+	}
+	ctx = log.ContextWithAccessMessage(ctx, accessMessage)
+	newError("received request for ", destination).WriteToLog(session.ExportIDToError(ctx))
+
+	ctx, cancel := context.WithCancel(ctx)
+	timer := signal.CancelAfterInactivity(ctx, cancel, sessionPolicy.Timeouts.ConnectionIdle)
+	ctx = policy.ContextWithBufferPolicy(ctx, sessionPolicy.Buffer)
+
+	link, err := dispatcher.Dispatch(ctx, destination)
+	if err != nil {
+		WriteTCPResponse(conn, err.Error())
+		return newError("failed to dispatch request to ", destination).Base(err)
+	}
+
+	requestDone := func() error {
+		defer timer.SetTimeout(sessionPolicy.Timeouts.DownlinkOnly)
+
+		if err := buf.Copy(buf.NewReader(conn), link.Writer, buf.UpdateActivity(timer)); err != nil {
+			return newError("failed to transfer request").Base(err)
+		}
+		return nil
+	}
+
+	responseDone := func() error {
+		defer timer.SetTimeout(sessionPolicy.Timeouts.UplinkOnly)
+
+		if err := WriteTCPResponse(conn, ""); err != nil {
+			return newError("failed to write response header").Base(err)
+		}
+		if err := buf.Copy(link.Reader, buf.NewWriter(conn), buf.UpdateActivity(timer)); err != nil {
+			return newError("failed to write response").Base(err)
+		}
+		return nil
+	}
+
+	requestDonePost := task.OnSuccess(requestDone, task.Close(link.Writer))
+	if err := task.Run(ctx, requestDonePost, responseDone); err != nil {
+		common.Must(common.Interrupt(link.Reader))
+		common.Must(common.Interrupt(link.Writer))
+		return newError("connection ends").Base(err)
+	}
+
+	return nil
+}
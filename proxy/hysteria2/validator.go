@@ -0,0 +1,50 @@
+package hysteria2
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/v2fly/v2ray-core/v5/common/protocol"
+)
+
+// Validator stores valid hysteria2 users.
+type Validator struct {
+	email sync.Map
+	users sync.Map
+}
+
+// Add a hysteria2 user, Email must be empty or unique.
+func (v *Validator) Add(u *protocol.MemoryUser) error {
+	if u.Email != "" {
+		_, loaded := v.email.LoadOrStore(strings.ToLower(u.Email), u)
+		if loaded {
+			return newError("User ", u.Email, " already exists.")
+		}
+	}
+	v.users.Store(u.Account.(*MemoryAccount).Password, u)
+	return nil
+}
+
+// Del a hysteria2 user with a non-empty Email.
+func (v *Validator) Del(e string) error {
+	if e == "" {
+		return newError("Email must not be empty.")
+	}
+	le := strings.ToLower(e)
+	u, _ := v.email.Load(le)
+	if u == nil {
+		return newError("User ", e, " not found.")
+	}
+	v.email.Delete(le)
+	v.users.Delete(u.(*protocol.MemoryUser).Account.(*MemoryAccount).Password)
+	return nil
+}
+
+// Get a hysteria2 user with password, nil if user doesn't exist.
+func (v *Validator) Get(password string) *protocol.MemoryUser {
+	u, _ := v.users.Load(password)
+	if u != nil {
+		return u.(*protocol.MemoryUser)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package tuic
+
+import (
+	"github.com/v2fly/v2ray-core/v5/common/protocol"
+	"github.com/v2fly/v2ray-core/v5/common/uuid"
+)
+
+// MemoryAccount is an account type converted from Account.
+type MemoryAccount struct {
+	ID       uuid.UUID
+	Password string
+}
+
+// AsAccount implements protocol.AsAccount.
+func (a *Account) AsAccount() (protocol.Account, error) {
+	id, err := uuid.ParseString(a.Uuid)
+	if err != nil {
+		return nil, newError("failed to parse ID").Base(err).AtError()
+	}
+	return &MemoryAccount{
+		ID:       id,
+		Password: a.GetPassword(),
+	}, nil
+}
+
+// Equals implements protocol.Account.Equals().
+func (a *MemoryAccount) Equals(another protocol.Account) bool {
+	if account, ok := another.(*MemoryAccount); ok {
+		return a.ID == account.ID && a.Password == account.Password
+	}
+	return false
+}
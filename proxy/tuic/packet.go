@@ -0,0 +1,48 @@
+package tuic
+
+import (
+	"github.com/v2fly/v2ray-core/v5/common/buf"
+	"github.com/v2fly/v2ray-core/v5/features/stats"
+	"github.com/v2fly/v2ray-core/v5/transport/internet"
+	tuicTransport "github.com/v2fly/v2ray-core/v5/transport/internet/tuic"
+)
+
+// packetConn reads and writes UDP packets with their addresses on a UDP session. The traffic is
+// counted here as internet.StatCouterConnection only counts Read and Write, which drop the
+// addresses.
+type packetConn struct {
+	tuicTransport.PacketConnection
+	readCounter  stats.Counter
+	writeCounter stats.Counter
+}
+
+// newPacketConn returns the UDP session of conn, or false if conn is not a UDP session.
+func newPacketConn(conn internet.Connection) (*packetConn, bool) {
+	c := new(packetConn)
+	if statConn, ok := conn.(*internet.StatCouterConnection); ok {
+		conn = statConn.Connection
+		c.readCounter = statConn.ReadCounter
+		c.writeCounter = statConn.WriteCounter
+	}
+	pc, ok := conn.(tuicTransport.PacketConnection)
+	if !ok {
+		return nil, false
+	}
+	c.PacketConnection = pc
+	return c, true
+}
+
+func (c *packetConn) ReadMultiBuffer() (buf.MultiBuffer, error) {
+	mb, err := c.PacketConnection.ReadMultiBuffer()
+	if c.readCounter != nil {
+		c.readCounter.Add(int64(mb.Len()))
+	}
+	return mb, err
+}
+
+func (c *packetConn) WriteMultiBuffer(mb buf.MultiBuffer) error {
+	if c.writeCounter != nil {
+		c.writeCounter.Add(int64(mb.Len()))
+	}
+	return c.PacketConnection.WriteMultiBuffer(mb)
+}
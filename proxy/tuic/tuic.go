@@ -0,0 +1,3 @@
+package tuic
+
+//go:generate go run github.com/v2fly/v2ray-core/v5/common/errors/errorgen
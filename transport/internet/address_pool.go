@@ -0,0 +1,100 @@
+package internet
+
+import (
+	"context"
+	"hash/fnv"
+
+	"github.com/v2fly/v2ray-core/v5/common/dice"
+	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/common/session"
+)
+
+// SelectAddressFromPool picks a local address from the pool for a connection to the given destination.
+// Only addresses in the same family as an IP destination are considered. A domain destination is
+// resolved if the pool has addresses of both families, and only the families of its IPs are
+// considered. It returns nil if no address fits.
+func SelectAddressFromPool(ctx context.Context, pool []net.Address, strategy AddressPoolStrategy, dest net.Destination) net.Address {
+	candidates := pool
+	if dest.Address != nil {
+		var ipv4, ipv6 bool
+		switch {
+		case dest.Address.Family().IsIP():
+			ipv4 = dest.Address.Family().IsIPv4()
+			ipv6 = !ipv4
+		case dest.Address.Family().IsDomain() && hasMixedFamilies(pool):
+			ipv4, ipv6 = lookupFamilies(dest.Address.Domain())
+		default:
+			ipv4, ipv6 = true, true
+		}
+		candidates = make([]net.Address, 0, len(pool))
+		for _, address := range pool {
+			if (ipv4 && address.Family().IsIPv4()) || (ipv6 && address.Family().IsIPv6()) {
+				candidates = append(candidates, address)
+			}
+		}
+	}
+
+	switch len(candidates) {
+	case 0:
+		return nil
+	case 1:
+		return candidates[0]
+	}
+
+	var key string
+	switch strategy {
+	case AddressPoolStrategy_HashUser:
+		if inbound := session.InboundFromContext(ctx); inbound != nil {
+			if inbound.User != nil && len(inbound.User.Email) > 0 {
+				key = inbound.User.Email
+			} else if inbound.Source.IsValid() {
+				key = inbound.Source.Address.String()
+			}
+		}
+	case AddressPoolStrategy_HashDestination:
+		if dest.Address != nil {
+			key = dest.Address.String()
+		}
+	}
+	if len(key) == 0 {
+		return candidates[dice.Roll(len(candidates))]
+	}
+
+	hash := fnv.New32a()
+	hash.Write([]byte(key))
+	return candidates[hash.Sum32()%uint32(len(candidates))]
+}
+
+func hasMixedFamilies(pool []net.Address) bool {
+	for _, address := range pool {
+		if address.Family() != pool[0].Family() {
+			return true
+		}
+	}
+	return false
+}
+
+// lookupFamilies returns the families of the IPs of the domain. Both are returned if the lookup
+// fails, so that dialing reports the error.
+func lookupFamilies(domain string) (ipv4 bool, ipv6 bool) {
+	ips, err := net.LookupIP(domain)
+	if err != nil || len(ips) == 0 {
+		return true, true
+	}
+	for _, ip := range ips {
+		if ip.To4() != nil {
+			ipv4 = true
+		} else {
+			ipv6 = true
+		}
+	}
+	return ipv4, ipv6
+}
+
+func (c *SocketConfig) getBindAddressPool() []net.Address {
+	pool := make([]net.Address, 0, len(c.BindAddressPool))
+	for _, ip := range c.BindAddressPool {
+		pool = append(pool, net.IPAddress(ip))
+	}
+	return pool
+}
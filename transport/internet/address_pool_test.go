@@ -0,0 +1,61 @@
+package internet_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/common/protocol"
+	"github.com/v2fly/v2ray-core/v5/common/session"
+	. "github.com/v2fly/v2ray-core/v5/transport/internet"
+)
+
+func TestSelectAddressFromPool(t *testing.T) {
+	pool := []net.Address{
+		net.ParseAddress("192.0.2.1"),
+		net.ParseAddress("192.0.2.2"),
+		net.ParseAddress("192.0.2.3"),
+		net.ParseAddress("2001:db8::1"),
+	}
+
+	ctx := context.Background()
+	if address := SelectAddressFromPool(ctx, pool, AddressPoolStrategy_Random, net.TCPDestination(net.ParseAddress("2001:db8::2"), 443)); address != pool[3] {
+		t.Error("expected IPv6 address, but got ", address)
+	}
+	for i := 0; i < 16; i++ {
+		if address := SelectAddressFromPool(ctx, pool, AddressPoolStrategy_Random, net.TCPDestination(net.ParseAddress("198.51.100.1"), 443)); address.Family() != net.AddressFamilyIPv4 {
+			t.Error("expected IPv4 address, but got ", address)
+		}
+	}
+	if address := SelectAddressFromPool(ctx, pool[:3], AddressPoolStrategy_Random, net.TCPDestination(net.ParseAddress("2001:db8::2"), 443)); address != nil {
+		t.Error("expected no address, but got ", address)
+	}
+	// Domains are resolved to pick the family of the pool.
+	if address := SelectAddressFromPool(ctx, pool, AddressPoolStrategy_Random, net.TCPDestination(net.DomainAddress("2001:db8::2"), 443)); address != pool[3] {
+		t.Error("expected IPv6 address for domain, but got ", address)
+	}
+	for i := 0; i < 16; i++ {
+		if address := SelectAddressFromPool(ctx, pool, AddressPoolStrategy_Random, net.TCPDestination(net.DomainAddress("198.51.100.1"), 443)); address.Family() != net.AddressFamilyIPv4 {
+			t.Error("expected IPv4 address for domain, but got ", address)
+		}
+	}
+
+	ctx = session.ContextWithInbound(ctx, &session.Inbound{
+		User: &protocol.MemoryUser{Email: "love@v2fly.org"},
+	})
+	first := SelectAddressFromPool(ctx, pool[:3], AddressPoolStrategy_HashUser, net.TCPDestination(net.DomainAddress("v2fly.org"), 443))
+	for i := 0; i < 16; i++ {
+		dest := net.TCPDestination(net.ParseAddress("198.51.100.1"), net.Port(1000+i))
+		if address := SelectAddressFromPool(ctx, pool[:3], AddressPoolStrategy_HashUser, dest); address != first {
+			t.Error("expected ", first, " for the same user, but got ", address)
+		}
+	}
+
+	first = SelectAddressFromPool(ctx, pool, AddressPoolStrategy_HashDestination, net.TCPDestination(net.ParseAddress("198.51.100.1"), 443))
+	for i := 0; i < 16; i++ {
+		dest := net.TCPDestination(net.ParseAddress("198.51.100.1"), net.Port(1000+i))
+		if address := SelectAddressFromPool(ctx, pool, AddressPoolStrategy_HashDestination, dest); address != first {
+			t.Error("expected ", first, " for the same destination, but got ", address)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/v2fly/v2ray-core/v5/common/net"
 	"github.com/v2fly/v2ray-core/v5/common/session"
+	"github.com/v2fly/v2ray-core/v5/features/tracing"
 	"github.com/v2fly/v2ray-core/v5/transport/internet/tagged"
 )
 
@@ -32,7 +33,14 @@ func RegisterTransportDialer(protocol string, dialer dialFunc) error {
 }
 
 // Dial dials a internet connection towards the given destination.
-func Dial(ctx context.Context, dest net.Destination, streamSettings *MemoryStreamConfig) (Connection, error) {
+func Dial(ctx context.Context, dest net.Destination, streamSettings *MemoryStreamConfig) (conn Connection, err error) {
+	ctx, span := tracing.Start(ctx, "transport.dial")
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+	span.SetAttribute("destination", dest.String())
+
 	if dest.Network == net.Network_TCP {
 		if streamSettings == nil {
 			s, err := ToMemoryStreamConfig(nil)
@@ -48,6 +56,7 @@ func Dial(ctx context.Context, dest net.Destination, streamSettings *MemoryStrea
 			protocol = originalProtocolName
 		}
 
+		span.SetAttribute("transport", protocol)
 		dialer := transportDialerCache[protocol]
 		if dialer == nil {
 			return nil, newError(protocol, " dialer not registered").AtError()
@@ -77,7 +86,18 @@ func DialSystem(ctx context.Context, dest net.Destination, sockopt *SocketConfig
 		return DialTaggedOutbound(ctx, dest, transportLayerOutgoingTag)
 	}
 
-	return effectiveSystemDialer.Dial(ctx, src, dest, sockopt)
+	_, span := tracing.Start(ctx, "system.dial")
+	defer span.End()
+	span.SetAttribute("destination", dest.String())
+	conn, err := effectiveSystemDialer.Dial(ctx, src, dest, sockopt)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	if addr := conn.LocalAddr(); addr != nil {
+		span.SetAttribute("local", addr.String())
+	}
+	return conn, nil
 }
 
 // SagerNet: private
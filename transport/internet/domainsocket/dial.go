@@ -25,7 +25,12 @@ func Dial(ctx context.Context, dest net.Destination, streamSettings *internet.Me
 	}
 
 	if config := tls.ConfigFromStreamSettings(streamSettings); config != nil {
-		return tls.Client(conn, config.GetTLSConfig(tls.WithDestination(dest))), nil
+		tlsConn := tls.Client(conn, config.GetTLSConfig(tls.WithDestination(dest)))
+		if err := tls.TraceHandshake(ctx, tlsConn); err != nil {
+			tlsConn.Close()
+			return nil, err
+		}
+		return tlsConn, nil
 	}
 
 	return conn, nil
@@ -1,12 +1,21 @@
 package grpc
 
 import (
+	"time"
+
 	"github.com/v2fly/v2ray-core/v5/common"
 	"github.com/v2fly/v2ray-core/v5/transport/internet"
 )
 
 const protocolName = "gun"
 
+func (c *Config) getHealthCheckTimeout() time.Duration {
+	if c.HealthCheckTimeout <= 0 {
+		return time.Second * 20
+	}
+	return time.Second * time.Duration(c.HealthCheckTimeout)
+}
+
 func init() {
 	common.Must(internet.RegisterProtocolConfigCreator(protocolName, func() interface{} {
 		return new(Config)
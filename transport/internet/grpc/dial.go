@@ -7,22 +7,56 @@ import (
 	"context"
 	gonet "net"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/connectivity"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/status"
 
 	core "github.com/v2fly/v2ray-core/v5"
 	"github.com/v2fly/v2ray-core/v5/common"
 	"github.com/v2fly/v2ray-core/v5/common/net"
 	"github.com/v2fly/v2ray-core/v5/common/session"
+	"github.com/v2fly/v2ray-core/v5/features/tracing"
 	"github.com/v2fly/v2ray-core/v5/transport/internet"
 	"github.com/v2fly/v2ray-core/v5/transport/internet/grpc/encoding"
 	"github.com/v2fly/v2ray-core/v5/transport/internet/tls"
 )
 
+// tracedCredentials traces TLS handshakes in the session that creates the connection, as gRPC
+// connections are shared by sessions.
+type tracedCredentials struct {
+	credentials.TransportCredentials
+	ctx context.Context
+}
+
+func (c *tracedCredentials) ClientHandshake(ctx context.Context, authority string, rawConn gonet.Conn) (gonet.Conn, credentials.AuthInfo, error) {
+	_, span := tracing.Start(c.ctx, "tls.handshake")
+	defer span.End()
+
+	conn, authInfo, err := c.TransportCredentials.ClientHandshake(ctx, authority, rawConn)
+	if err != nil {
+		span.RecordError(err)
+		return nil, nil, err
+	}
+	if info, ok := authInfo.(credentials.TLSInfo); ok {
+		tls.RecordHandshake(span, info.State)
+	}
+	return conn, authInfo, nil
+}
+
+func (c *tracedCredentials) Clone() credentials.TransportCredentials {
+	return &tracedCredentials{
+		TransportCredentials: c.TransportCredentials.Clone(),
+		ctx:                  c.ctx,
+	}
+}
+
 func Dial(ctx context.Context, dest net.Destination, streamSettings *internet.MemoryStreamConfig) (internet.Connection, error) {
 	newError("creating connection to ", dest).WriteToLog(session.ExportIDToError(ctx))
 
@@ -37,58 +71,81 @@ func init() {
 	common.Must(internet.RegisterTransportDialer(protocolName, Dial))
 }
 
-type dialerCanceller func()
+type dialerConf struct {
+	dest           net.Destination
+	streamSettings *internet.MemoryStreamConfig
+}
+
+// clientConn is an underlying connection in the pool, with the number of tunnels on it.
+type clientConn struct {
+	*grpc.ClientConn
+	streams int32
+}
 
 var (
-	globalDialerMap    map[net.Destination]*grpc.ClientConn
+	globalDialerMap    map[dialerConf][]*clientConn
 	globalDialerAccess sync.Mutex
 )
 
 func dialgRPC(ctx context.Context, dest net.Destination, streamSettings *internet.MemoryStreamConfig) (net.Conn, error) {
 	grpcSettings := streamSettings.ProtocolSettings.(*Config)
 
-	config := tls.ConfigFromStreamSettings(streamSettings)
-	dialOption := grpc.WithInsecure()
-
-	if config != nil {
-		dialOption = grpc.WithTransportCredentials(credentials.NewTLS(config.GetTLSConfig()))
-	}
-
-	conn, canceller, err := getGrpcClient(ctx, dest, dialOption)
+	conn, err := getGrpcClient(ctx, dest, streamSettings)
 	if err != nil {
 		return nil, newError("Cannot dial grpc").Base(err)
 	}
-	client := encoding.NewGunServiceClient(conn)
-	gunService, err := client.(encoding.GunServiceClientX).TunCustomName(ctx, grpcSettings.ServiceName)
+
+	tunCtx, cancel := context.WithCancel(ctx)
+	go func() {
+		<-tunCtx.Done()
+		atomic.AddInt32(&conn.streams, -1)
+	}()
+
+	client := encoding.NewGunServiceClient(conn).(encoding.GunServiceClientX)
+	if grpcSettings.MultiMode {
+		gunService, err := client.TunMultiCustomName(tunCtx, grpcSettings.ServiceName)
+		if err != nil {
+			cancel()
+			return nil, newError("Cannot dial grpc").Base(err)
+		}
+		return encoding.NewMultiHunkConn(gunService, cancel), nil
+	}
+	gunService, err := client.TunCustomName(tunCtx, grpcSettings.ServiceName)
 	if err != nil {
-		canceller()
+		cancel()
 		return nil, newError("Cannot dial grpc").Base(err)
 	}
-	return encoding.NewGunConn(gunService, nil), nil
+	return encoding.NewGunConn(gunService, cancel), nil
 }
 
-func getGrpcClient(ctx context.Context, dest net.Destination, dialOption grpc.DialOption) (*grpc.ClientConn, dialerCanceller, error) {
+// getGrpcClient picks a connection from the pool with room for another tunnel, or dials a new
+// one. The number of tunnels of the returned connection is already increased.
+func getGrpcClient(ctx context.Context, dest net.Destination, streamSettings *internet.MemoryStreamConfig) (*clientConn, error) {
 	globalDialerAccess.Lock()
 	defer globalDialerAccess.Unlock()
 
 	if globalDialerMap == nil {
-		globalDialerMap = make(map[net.Destination]*grpc.ClientConn)
+		globalDialerMap = make(map[dialerConf][]*clientConn)
 	}
 
-	canceller := func() {
-		globalDialerAccess.Lock()
-		defer globalDialerAccess.Unlock()
-		delete(globalDialerMap, dest)
-	}
+	grpcSettings := streamSettings.ProtocolSettings.(*Config)
+	key := dialerConf{dest: dest, streamSettings: streamSettings}
 
-	// TODO Should support chain proxy to the same destination
-	if client, found := globalDialerMap[dest]; found && client.GetState() != connectivity.Shutdown {
-		return client, canceller, nil
+	conns := globalDialerMap[key][:0]
+	for _, conn := range globalDialerMap[key] {
+		if conn.GetState() != connectivity.Shutdown {
+			conns = append(conns, conn)
+		}
+	}
+	globalDialerMap[key] = conns
+	for _, conn := range conns {
+		if grpcSettings.MaxStreamsPerConnection <= 0 || atomic.LoadInt32(&conn.streams) < grpcSettings.MaxStreamsPerConnection {
+			atomic.AddInt32(&conn.streams, 1)
+			return conn, nil
+		}
 	}
 
-	conn, err := grpc.Dial(
-		dest.Address.String()+":"+dest.Port.String(),
-		dialOption,
+	dialOptions := []grpc.DialOption{
 		grpc.WithConnectParams(grpc.ConnectParams{
 			Backoff: backoff.Config{
 				BaseDelay:  500 * time.Millisecond,
@@ -114,7 +171,67 @@ func getGrpcClient(ctx context.Context, dest net.Destination, dialOption grpc.Di
 			detachedContext := core.ToBackgroundDetachedContext(ctx)
 			return internet.DialSystem(detachedContext, net.TCPDestination(address, port), nil)
 		}),
-	)
-	globalDialerMap[dest] = conn
-	return conn, canceller, err
+	}
+
+	if config := tls.ConfigFromStreamSettings(streamSettings); config != nil {
+		dialOptions = append(dialOptions, grpc.WithTransportCredentials(&tracedCredentials{
+			TransportCredentials: credentials.NewTLS(config.GetTLSConfig()),
+			ctx:                  core.ToBackgroundDetachedContext(ctx),
+		}))
+	} else {
+		dialOptions = append(dialOptions, grpc.WithInsecure())
+	}
+	if grpcSettings.Host != "" {
+		dialOptions = append(dialOptions, grpc.WithAuthority(grpcSettings.Host))
+	}
+	if grpcSettings.UserAgent != "" {
+		dialOptions = append(dialOptions, grpc.WithUserAgent(grpcSettings.UserAgent))
+	}
+	if grpcSettings.IdleTimeout > 0 {
+		dialOptions = append(dialOptions, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                time.Second * time.Duration(grpcSettings.IdleTimeout),
+			Timeout:             grpcSettings.getHealthCheckTimeout(),
+			PermitWithoutStream: grpcSettings.PermitWithoutStream,
+		}))
+	}
+	if grpcSettings.InitialWindowsSize > 0 {
+		dialOptions = append(dialOptions,
+			grpc.WithInitialWindowSize(grpcSettings.InitialWindowsSize),
+			grpc.WithInitialConnWindowSize(grpcSettings.InitialWindowsSize))
+	}
+
+	grpcConn, err := grpc.Dial(dest.Address.String()+":"+dest.Port.String(), dialOptions...)
+	if err != nil {
+		return nil, err
+	}
+	conn := &clientConn{ClientConn: grpcConn, streams: 1}
+	globalDialerMap[key] = append(conns, conn)
+	if grpcSettings.HealthCheckInterval > 0 {
+		go checkHealth(conn, grpcSettings)
+	}
+	return conn, nil
+}
+
+// checkHealth calls the health check RPC periodically, and closes the connection with all its
+// tunnels if it fails. HTTP/2 pings may be answered by a reverse proxy before the server, but the
+// RPC reaches the server.
+func checkHealth(conn *clientConn, config *Config) {
+	client := encoding.NewGunServiceClient(conn).(encoding.GunServiceClientX)
+	ticker := time.NewTicker(time.Second * time.Duration(config.HealthCheckInterval))
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if conn.GetState() == connectivity.Shutdown {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), config.getHealthCheckTimeout())
+		_, err := client.HealthCustomName(ctx, config.ServiceName, &encoding.HealthRequest{})
+		cancel()
+		// Servers without the health check RPC are alive if they respond.
+		if err != nil && status.Code(err) != codes.Unimplemented {
+			newError("health check failed, closing connection").Base(err).AtWarning().WriteToLog()
+			conn.Close()
+			return
+		}
+	}
 }
@@ -13,7 +13,12 @@ func ServerDesc(name string) grpc.ServiceDesc {
 	return grpc.ServiceDesc{
 		ServiceName: name,
 		HandlerType: (*GunServiceServer)(nil),
-		Methods:     []grpc.MethodDesc{},
+		Methods: []grpc.MethodDesc{
+			{
+				MethodName: "Health",
+				Handler:    _GunService_Health_Handler,
+			},
+		},
 		Streams: []grpc.StreamDesc{
 			{
 				StreamName:    "Tun",
@@ -21,6 +26,12 @@ func ServerDesc(name string) grpc.ServiceDesc {
 				ServerStreams: true,
 				ClientStreams: true,
 			},
+			{
+				StreamName:    "TunMulti",
+				Handler:       _GunService_TunMulti_Handler,
+				ServerStreams: true,
+				ClientStreams: true,
+			},
 		},
 		Metadata: "gun.proto",
 	}
@@ -35,9 +46,31 @@ func (c *gunServiceClient) TunCustomName(ctx context.Context, name string, opts
 	return x, nil
 }
 
+func (c *gunServiceClient) TunMultiCustomName(ctx context.Context, name string, opts ...grpc.CallOption) (GunService_TunMultiClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ServerDesc(name).Streams[1], "/"+name+"/TunMulti", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &gunServiceTunMultiClient{stream}
+	return x, nil
+}
+
+func (c *gunServiceClient) HealthCustomName(ctx context.Context, name string, in *HealthRequest, opts ...grpc.CallOption) (*HealthResponse, error) {
+	out := new(HealthResponse)
+	err := c.cc.Invoke(ctx, "/"+name+"/Health", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 type GunServiceClientX interface {
 	TunCustomName(ctx context.Context, name string, opts ...grpc.CallOption) (GunService_TunClient, error)
+	TunMultiCustomName(ctx context.Context, name string, opts ...grpc.CallOption) (GunService_TunMultiClient, error)
+	HealthCustomName(ctx context.Context, name string, in *HealthRequest, opts ...grpc.CallOption) (*HealthResponse, error)
 	Tun(ctx context.Context, opts ...grpc.CallOption) (GunService_TunClient, error)
+	TunMulti(ctx context.Context, opts ...grpc.CallOption) (GunService_TunMultiClient, error)
+	Health(ctx context.Context, in *HealthRequest, opts ...grpc.CallOption) (*HealthResponse, error)
 }
 
 func RegisterGunServiceServerX(s *grpc.Server, srv GunServiceServer, name string) {
@@ -0,0 +1,148 @@
+//go:build !confonly
+// +build !confonly
+
+package encoding
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"google.golang.org/grpc/peer"
+
+	"github.com/v2fly/v2ray-core/v5/common/buf"
+)
+
+// MultiHunkService is the abstract interface of GunService_TunMultiClient and GunService_TunMultiServer
+type MultiHunkService interface {
+	Context() context.Context
+	Send(*MultiHunk) error
+	Recv() (*MultiHunk, error)
+}
+
+// MultiHunkConn implements net.Conn for gun tunnel in multi mode, where a message carries
+// multiple buffers.
+type MultiHunkConn struct {
+	service MultiHunkService
+	pending buf.MultiBuffer
+	over    context.CancelFunc
+	local   net.Addr
+	remote  net.Addr
+}
+
+// ReadMultiBuffer implements buf.Reader.
+func (c *MultiHunkConn) ReadMultiBuffer() (buf.MultiBuffer, error) {
+	if !c.pending.IsEmpty() {
+		mb := c.pending
+		c.pending = nil
+		return mb, nil
+	}
+	for {
+		h, err := c.service.Recv()
+		if err != nil {
+			return nil, newError("unable to read from gun tunnel").Base(err)
+		}
+		var mb buf.MultiBuffer
+		for _, data := range h.Data {
+			mb = buf.MergeBytes(mb, data)
+		}
+		if !mb.IsEmpty() {
+			return mb, nil
+		}
+	}
+}
+
+// Read implements net.Conn.Read()
+func (c *MultiHunkConn) Read(b []byte) (int, error) {
+	if c.pending.IsEmpty() {
+		mb, err := c.ReadMultiBuffer()
+		if err != nil {
+			return 0, err
+		}
+		c.pending = mb
+	}
+	var n int
+	c.pending, n = buf.SplitBytes(c.pending, b)
+	return n, nil
+}
+
+// WriteMultiBuffer implements buf.Writer.
+func (c *MultiHunkConn) WriteMultiBuffer(mb buf.MultiBuffer) error {
+	defer buf.ReleaseMulti(mb)
+
+	data := make([][]byte, 0, len(mb))
+	for _, b := range mb {
+		if !b.IsEmpty() {
+			data = append(data, b.Bytes())
+		}
+	}
+	if err := c.service.Send(&MultiHunk{Data: data}); err != nil {
+		return newError("Unable to send data over gun").Base(err)
+	}
+	return nil
+}
+
+// Write implements net.Conn.Write()
+func (c *MultiHunkConn) Write(b []byte) (int, error) {
+	if err := c.service.Send(&MultiHunk{Data: [][]byte{b}}); err != nil {
+		return 0, newError("Unable to send data over gun").Base(err)
+	}
+	return len(b), nil
+}
+
+// Close implements net.Conn.Close()
+func (c *MultiHunkConn) Close() error {
+	if c.over != nil {
+		c.over()
+	}
+	return nil
+}
+
+// LocalAddr implements net.Conn.LocalAddr()
+func (c *MultiHunkConn) LocalAddr() net.Addr {
+	return c.local
+}
+
+// RemoteAddr implements net.Conn.RemoteAddr()
+func (c *MultiHunkConn) RemoteAddr() net.Addr {
+	return c.remote
+}
+
+// SetDeadline implements net.Conn.SetDeadline()
+func (*MultiHunkConn) SetDeadline(time.Time) error {
+	return nil
+}
+
+// SetReadDeadline implements net.Conn.SetReadDeadline()
+func (*MultiHunkConn) SetReadDeadline(time.Time) error {
+	return nil
+}
+
+// SetWriteDeadline implements net.Conn.SetWriteDeadline()
+func (*MultiHunkConn) SetWriteDeadline(time.Time) error {
+	return nil
+}
+
+// NewMultiHunkConn creates MultiHunkConn which handles gun tunnel in multi mode
+func NewMultiHunkConn(service MultiHunkService, over context.CancelFunc) *MultiHunkConn {
+	conn := &MultiHunkConn{
+		service: service,
+		over:    over,
+	}
+
+	conn.local = &net.TCPAddr{
+		IP:   []byte{0, 0, 0, 0},
+		Port: 0,
+	}
+	pr, ok := peer.FromContext(service.Context())
+	if ok {
+		conn.remote = pr.Addr
+	} else {
+		conn.remote = &net.TCPAddr{
+			IP:   []byte{0, 0, 0, 0},
+			Port: 0,
+		}
+	}
+
+	return conn
+}
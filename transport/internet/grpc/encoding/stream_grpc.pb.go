@@ -23,6 +23,8 @@ const _ = grpc.SupportPackageIsVersion7
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type GunServiceClient interface {
 	Tun(ctx context.Context, opts ...grpc.CallOption) (GunService_TunClient, error)
+	TunMulti(ctx context.Context, opts ...grpc.CallOption) (GunService_TunMultiClient, error)
+	Health(ctx context.Context, in *HealthRequest, opts ...grpc.CallOption) (*HealthResponse, error)
 }
 
 type gunServiceClient struct {
@@ -64,11 +66,53 @@ func (x *gunServiceTunClient) Recv() (*Hunk, error) {
 	return m, nil
 }
 
+func (c *gunServiceClient) TunMulti(ctx context.Context, opts ...grpc.CallOption) (GunService_TunMultiClient, error) {
+	stream, err := c.cc.NewStream(ctx, &GunService_ServiceDesc.Streams[1], "/v2ray.core.transport.internet.grpc.encoding.GunService/TunMulti", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &gunServiceTunMultiClient{stream}
+	return x, nil
+}
+
+type GunService_TunMultiClient interface {
+	Send(*MultiHunk) error
+	Recv() (*MultiHunk, error)
+	grpc.ClientStream
+}
+
+type gunServiceTunMultiClient struct {
+	grpc.ClientStream
+}
+
+func (x *gunServiceTunMultiClient) Send(m *MultiHunk) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *gunServiceTunMultiClient) Recv() (*MultiHunk, error) {
+	m := new(MultiHunk)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *gunServiceClient) Health(ctx context.Context, in *HealthRequest, opts ...grpc.CallOption) (*HealthResponse, error) {
+	out := new(HealthResponse)
+	err := c.cc.Invoke(ctx, "/v2ray.core.transport.internet.grpc.encoding.GunService/Health", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // GunServiceServer is the server API for GunService service.
 // All implementations must embed UnimplementedGunServiceServer
 // for forward compatibility
 type GunServiceServer interface {
 	Tun(GunService_TunServer) error
+	TunMulti(GunService_TunMultiServer) error
+	Health(context.Context, *HealthRequest) (*HealthResponse, error)
 	mustEmbedUnimplementedGunServiceServer()
 }
 
@@ -79,6 +123,12 @@ type UnimplementedGunServiceServer struct {
 func (UnimplementedGunServiceServer) Tun(GunService_TunServer) error {
 	return status.Errorf(codes.Unimplemented, "method Tun not implemented")
 }
+func (UnimplementedGunServiceServer) TunMulti(GunService_TunMultiServer) error {
+	return status.Errorf(codes.Unimplemented, "method TunMulti not implemented")
+}
+func (UnimplementedGunServiceServer) Health(context.Context, *HealthRequest) (*HealthResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Health not implemented")
+}
 func (UnimplementedGunServiceServer) mustEmbedUnimplementedGunServiceServer() {}
 
 // UnsafeGunServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -118,13 +168,62 @@ func (x *gunServiceTunServer) Recv() (*Hunk, error) {
 	return m, nil
 }
 
+func _GunService_TunMulti_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(GunServiceServer).TunMulti(&gunServiceTunMultiServer{stream})
+}
+
+type GunService_TunMultiServer interface {
+	Send(*MultiHunk) error
+	Recv() (*MultiHunk, error)
+	grpc.ServerStream
+}
+
+type gunServiceTunMultiServer struct {
+	grpc.ServerStream
+}
+
+func (x *gunServiceTunMultiServer) Send(m *MultiHunk) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *gunServiceTunMultiServer) Recv() (*MultiHunk, error) {
+	m := new(MultiHunk)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _GunService_Health_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HealthRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GunServiceServer).Health(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v2ray.core.transport.internet.grpc.encoding.GunService/Health",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GunServiceServer).Health(ctx, req.(*HealthRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // GunService_ServiceDesc is the grpc.ServiceDesc for GunService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
 var GunService_ServiceDesc = grpc.ServiceDesc{
 	ServiceName: "v2ray.core.transport.internet.grpc.encoding.GunService",
 	HandlerType: (*GunServiceServer)(nil),
-	Methods:     []grpc.MethodDesc{},
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Health",
+			Handler:    _GunService_Health_Handler,
+		},
+	},
 	Streams: []grpc.StreamDesc{
 		{
 			StreamName:    "Tun",
@@ -132,6 +231,12 @@ var GunService_ServiceDesc = grpc.ServiceDesc{
 			ServerStreams: true,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "TunMulti",
+			Handler:       _GunService_TunMulti_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
 	},
 	Metadata: "transport/internet/grpc/encoding/stream.proto",
 }
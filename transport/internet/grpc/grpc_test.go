@@ -0,0 +1,76 @@
+package grpc_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"io"
+	"testing"
+
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/buf"
+	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/testing/servers/tcp"
+	"github.com/v2fly/v2ray-core/v5/transport/internet"
+	. "github.com/v2fly/v2ray-core/v5/transport/internet/grpc"
+)
+
+func testEcho(t *testing.T, config *Config) {
+	port := tcp.PickPort()
+	streamSettings := &internet.MemoryStreamConfig{
+		ProtocolName:     "gun",
+		ProtocolSettings: config,
+	}
+	listener, err := Listen(context.Background(), net.LocalHostIP, port, streamSettings, func(conn internet.Connection) {
+		go func() {
+			defer conn.Close()
+			buf.Copy(buf.NewReader(conn), buf.NewWriter(conn))
+		}()
+	})
+	common.Must(err)
+	defer listener.Close()
+
+	for i := 0; i < 3; i++ {
+		ctx, cancel := context.WithCancel(context.Background())
+		conn, err := Dial(ctx, net.TCPDestination(net.LocalHostIP, port), streamSettings)
+		common.Must(err)
+
+		payload := make([]byte, 256*1024)
+		common.Must2(rand.Read(payload))
+		go func() {
+			for i := 0; i < len(payload); i += 4096 {
+				if err := buf.NewWriter(conn).WriteMultiBuffer(buf.MergeBytes(nil, payload[i:i+4096])); err != nil {
+					return
+				}
+			}
+		}()
+
+		response := make([]byte, len(payload))
+		common.Must2(io.ReadFull(conn, response))
+		if !bytes.Equal(payload, response) {
+			t.Error("response mismatch")
+		}
+		common.Must(conn.Close())
+		cancel()
+	}
+}
+
+func TestTun(t *testing.T) {
+	testEcho(t, &Config{
+		ServiceName: "tun",
+	})
+}
+
+func TestTunMulti(t *testing.T) {
+	testEcho(t, &Config{
+		ServiceName:             "tun",
+		Host:                    "www.v2fly.org",
+		MultiMode:               true,
+		IdleTimeout:             10,
+		PermitWithoutStream:     true,
+		InitialWindowsSize:      512 * 1024,
+		UserAgent:               "v2fly",
+		MaxStreamsPerConnection: 1,
+		HealthCheckInterval:     1,
+	})
+}
@@ -5,9 +5,11 @@ package grpc
 
 import (
 	"context"
+	"time"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
 
 	"github.com/v2fly/v2ray-core/v5/common"
 	"github.com/v2fly/v2ray-core/v5/common/net"
@@ -35,6 +37,17 @@ func (l Listener) Tun(server encoding.GunService_TunServer) error {
 	return nil
 }
 
+func (l Listener) TunMulti(server encoding.GunService_TunMultiServer) error {
+	tunCtx, cancel := context.WithCancel(l.ctx)
+	l.handler(encoding.NewMultiHunkConn(server, cancel))
+	<-tunCtx.Done()
+	return nil
+}
+
+func (l Listener) Health(context.Context, *encoding.HealthRequest) (*encoding.HealthResponse, error) {
+	return &encoding.HealthResponse{}, nil
+}
+
 func (l Listener) Close() error {
 	l.s.Stop()
 	return nil
@@ -71,13 +84,29 @@ func Listen(ctx context.Context, address net.Address, port net.Port, settings *i
 
 	config := tls.ConfigFromStreamSettings(settings)
 
-	var s *grpc.Server
-	if config == nil {
-		s = grpc.NewServer()
-	} else {
+	options := []grpc.ServerOption{
+		// Allow pings from clients with idle timeout.
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             time.Second * 5,
+			PermitWithoutStream: true,
+		}),
+	}
+	if grpcSettings.IdleTimeout > 0 {
+		options = append(options, grpc.KeepaliveParams(keepalive.ServerParameters{
+			Time:    time.Second * time.Duration(grpcSettings.IdleTimeout),
+			Timeout: grpcSettings.getHealthCheckTimeout(),
+		}))
+	}
+	if grpcSettings.InitialWindowsSize > 0 {
+		options = append(options,
+			grpc.InitialWindowSize(grpcSettings.InitialWindowsSize),
+			grpc.InitialConnWindowSize(grpcSettings.InitialWindowsSize))
+	}
+	if config != nil {
 		// gRPC server may silently ignore TLS errors
-		s = grpc.NewServer(grpc.Creds(credentials.NewTLS(config.GetTLSConfig(tls.WithNextProto("h2")))))
+		options = append(options, grpc.Creds(credentials.NewTLS(config.GetTLSConfig(tls.WithNextProto("h2")))))
 	}
+	s := grpc.NewServer(options...)
 	listener.s = s
 
 	if settings.SocketSettings != nil && settings.SocketSettings.AcceptProxyProtocol {
@@ -64,8 +64,11 @@ func getHTTPClient(ctx context.Context, dest net.Destination, tlsSettings *tls.C
 				return nil, err
 			}
 
+			// DialTLS has no context of requests, so handshakes are traced in the session that
+			// creates the client.
 			cn := gotls.Client(pconn, tlsConfig)
-			if err := cn.Handshake(); err != nil {
+			if err := tls.Handshake(detachedContext, cn); err != nil {
+				pconn.Close()
 				return nil, err
 			}
 			if !tlsConfig.InsecureSkipVerify {
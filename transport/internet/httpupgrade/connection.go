@@ -0,0 +1,175 @@
+package httpupgrade
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/v2fly/v2ray-core/v5/common/signal/done"
+)
+
+// connection is a net.Conn after the upgrade handshake. Data read from the connection during
+// the handshake, such as early data, is returned first.
+type connection struct {
+	net.Conn
+	reader     io.Reader
+	remoteAddr net.Addr
+}
+
+func newConnection(conn net.Conn, reader io.Reader, remoteAddr net.Addr) *connection {
+	if reader == nil {
+		reader = conn
+	}
+	if remoteAddr == nil {
+		remoteAddr = conn.RemoteAddr()
+	}
+	return &connection{
+		Conn:       conn,
+		reader:     reader,
+		remoteAddr: remoteAddr,
+	}
+}
+
+func (c *connection) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
+
+func (c *connection) RemoteAddr() net.Addr {
+	return c.remoteAddr
+}
+
+// handshakeReader returns a reader of the connection that starts with the data buffered by reader.
+func handshakeReader(conn net.Conn, reader *bufio.Reader) io.Reader {
+	if n := reader.Buffered(); n > 0 {
+		return io.MultiReader(io.LimitReader(reader, int64(n)), conn)
+	}
+	return conn
+}
+
+// delayedDialer performs the upgrade handshake with the early data in the request.
+type delayedDialer interface {
+	Dial(earlyData []byte) (net.Conn, error)
+}
+
+// delayedConnection postpones the upgrade handshake until the first write.
+type delayedConnection struct {
+	access sync.Mutex
+	conn   net.Conn
+	err    error
+	dialer delayedDialer
+	dialed *done.Instance
+	closed *done.Instance
+}
+
+func newDelayedConnection(dialer delayedDialer) *delayedConnection {
+	return &delayedConnection{
+		dialer: dialer,
+		dialed: done.New(),
+		closed: done.New(),
+	}
+}
+
+// getConn blocks until the handshake is finished.
+func (c *delayedConnection) getConn() (net.Conn, error) {
+	select {
+	case <-c.dialed.Wait():
+	case <-c.closed.Wait():
+		if !c.dialed.Done() {
+			return nil, io.ErrClosedPipe
+		}
+	}
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.conn, nil
+}
+
+func (c *delayedConnection) Read(b []byte) (int, error) {
+	conn, err := c.getConn()
+	if err != nil {
+		return 0, err
+	}
+	return conn.Read(b)
+}
+
+func (c *delayedConnection) Write(b []byte) (int, error) {
+	c.access.Lock()
+	if c.dialed.Done() {
+		c.access.Unlock()
+		if c.err != nil {
+			return 0, c.err
+		}
+		return c.conn.Write(b)
+	}
+	defer c.access.Unlock()
+
+	if c.closed.Done() {
+		return 0, io.ErrClosedPipe
+	}
+	conn, err := c.dialer.Dial(b)
+	if err != nil {
+		c.err = newError("failed to dial with early data").Base(err)
+	}
+	c.conn = conn
+	c.dialed.Close()
+	if c.err != nil {
+		return 0, c.err
+	}
+	return len(b), nil
+}
+
+func (c *delayedConnection) Close() error {
+	c.closed.Close()
+	c.access.Lock()
+	defer c.access.Unlock()
+
+	if c.dialed.Done() && c.conn != nil {
+		return c.conn.Close()
+	}
+	return nil
+}
+
+// currentConn returns the underlying connection without waiting for the handshake.
+func (c *delayedConnection) currentConn() net.Conn {
+	if !c.dialed.Done() {
+		return nil
+	}
+	return c.conn
+}
+
+func (c *delayedConnection) LocalAddr() net.Addr {
+	if conn := c.currentConn(); conn != nil {
+		return conn.LocalAddr()
+	}
+	return &net.TCPAddr{}
+}
+
+func (c *delayedConnection) RemoteAddr() net.Addr {
+	if conn := c.currentConn(); conn != nil {
+		return conn.RemoteAddr()
+	}
+	return &net.TCPAddr{}
+}
+
+func (c *delayedConnection) SetDeadline(t time.Time) error {
+	if conn := c.currentConn(); conn != nil {
+		return conn.SetDeadline(t)
+	}
+	return nil
+}
+
+func (c *delayedConnection) SetReadDeadline(t time.Time) error {
+	if conn := c.currentConn(); conn != nil {
+		return conn.SetReadDeadline(t)
+	}
+	return nil
+}
+
+func (c *delayedConnection) SetWriteDeadline(t time.Time) error {
+	if conn := c.currentConn(); conn != nil {
+		return conn.SetWriteDeadline(t)
+	}
+	return nil
+}
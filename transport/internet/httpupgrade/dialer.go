@@ -0,0 +1,138 @@
+package httpupgrade
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/common/session"
+	"github.com/v2fly/v2ray-core/v5/transport/internet"
+	"github.com/v2fly/v2ray-core/v5/transport/internet/tls"
+)
+
+// Dial dials an HTTP upgrade connection to the given destination.
+func Dial(ctx context.Context, dest net.Destination, streamSettings *internet.MemoryStreamConfig) (internet.Connection, error) {
+	newError("creating connection to ", dest).WriteToLog(session.ExportIDToError(ctx))
+
+	config := streamSettings.ProtocolSettings.(*Config)
+	d := &upgradeDialer{
+		ctx:            ctx,
+		dest:           dest,
+		streamSettings: streamSettings,
+		config:         config,
+	}
+	if config.MaxEarlyData != 0 {
+		return newDelayedConnection(d), nil
+	}
+
+	conn, err := d.Dial(nil)
+	if err != nil {
+		return nil, newError("failed to dial HTTP upgrade").Base(err)
+	}
+	return internet.Connection(conn), nil
+}
+
+func init() {
+	common.Must(internet.RegisterTransportDialer(protocolName, Dial))
+}
+
+type upgradeDialer struct {
+	ctx            context.Context
+	dest           net.Destination
+	streamSettings *internet.MemoryStreamConfig
+	config         *Config
+}
+
+// Dial implements delayedDialer. At most MaxEarlyData bytes of earlyData are sent in the
+// upgrade request, and the rest right after it.
+func (d *upgradeDialer) Dial(earlyData []byte) (net.Conn, error) {
+	conn, err := internet.DialSystem(d.ctx, d.dest, d.streamSettings.SocketSettings)
+	if err != nil {
+		return nil, err
+	}
+
+	secure := false
+	if config := tls.ConfigFromStreamSettings(d.streamSettings); config != nil {
+		secure = true
+		conn = tls.Client(conn, config.GetTLSConfig(tls.WithDestination(d.dest), tls.WithNextProto("http/1.1")))
+		if err := tls.TraceHandshake(d.ctx, conn); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	host := d.config.Host
+	if host == "" {
+		host = d.dest.NetAddr()
+		if (!secure && d.dest.Port == 80) || (secure && d.dest.Port == 443) {
+			host = d.dest.Address.String()
+		}
+	}
+
+	path := d.config.GetNormalizedPath()
+	header := d.config.GetRequestHeader()
+	var remainder []byte
+	if len(earlyData) > 0 {
+		n := len(earlyData)
+		if n > int(d.config.MaxEarlyData) {
+			n = int(d.config.MaxEarlyData)
+		}
+		encoded := base64.RawURLEncoding.EncodeToString(earlyData[:n])
+		if d.config.EarlyDataHeaderName != "" {
+			header.Set(d.config.EarlyDataHeaderName, encoded)
+		} else {
+			path += encoded
+		}
+		remainder = earlyData[n:]
+	}
+	header.Set("Connection", "Upgrade")
+	header.Set("Upgrade", "websocket")
+
+	requestURL, err := url.Parse(path)
+	if err != nil {
+		conn.Close()
+		return nil, newError("invalid path: ", path).Base(err)
+	}
+	request := &http.Request{
+		Method:     http.MethodGet,
+		URL:        requestURL,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     header,
+		Host:       host,
+	}
+
+	conn.SetDeadline(time.Now().Add(time.Second * 8))
+	if err := request.Write(conn); err != nil {
+		conn.Close()
+		return nil, newError("failed to send upgrade request").Base(err)
+	}
+	reader := bufio.NewReader(conn)
+	response, err := http.ReadResponse(reader, request) // nolint: bodyclose
+	if err != nil {
+		conn.Close()
+		return nil, newError("failed to read upgrade response").Base(err)
+	}
+	if response.StatusCode != http.StatusSwitchingProtocols ||
+		!strings.EqualFold(response.Header.Get("Upgrade"), "websocket") ||
+		!strings.EqualFold(response.Header.Get("Connection"), "upgrade") {
+		conn.Close()
+		return nil, newError("unexpected upgrade response: ", response.Status)
+	}
+	conn.SetDeadline(time.Time{})
+
+	if len(remainder) > 0 {
+		if _, err := conn.Write(remainder); err != nil {
+			conn.Close()
+			return nil, newError("failed to write remainder of early data").Base(err)
+		}
+	}
+	return newConnection(conn, handshakeReader(conn, reader), nil), nil
+}
@@ -0,0 +1,9 @@
+/*
+Package httpupgrade implements HTTP upgrade transport
+
+HTTP upgrade transport performs the HTTP/1.1 Upgrade handshake of WebSocket, and then carries raw bytes
+without WebSocket framing.
+*/
+package httpupgrade
+
+//go:generate go run github.com/v2fly/v2ray-core/v5/common/errors/errorgen
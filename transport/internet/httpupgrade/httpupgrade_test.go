@@ -0,0 +1,90 @@
+package httpupgrade_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"io"
+	"testing"
+
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/testing/servers/tcp"
+	"github.com/v2fly/v2ray-core/v5/transport/internet"
+	. "github.com/v2fly/v2ray-core/v5/transport/internet/httpupgrade"
+)
+
+func echoHandler(conn internet.Connection) {
+	go func(c internet.Connection) {
+		defer c.Close()
+		io.Copy(c, c)
+	}(conn)
+}
+
+func testEcho(t *testing.T, config *Config) {
+	port := tcp.PickPort()
+	listen, err := ListenHTTPUpgrade(context.Background(), net.LocalHostIP, port, &internet.MemoryStreamConfig{
+		ProtocolName:     "httpupgrade",
+		ProtocolSettings: config,
+	}, echoHandler)
+	common.Must(err)
+	defer listen.Close()
+
+	conn, err := Dial(context.Background(), net.TCPDestination(net.LocalHostIP, port), &internet.MemoryStreamConfig{
+		ProtocolName:     "httpupgrade",
+		ProtocolSettings: config,
+	})
+	common.Must(err)
+	defer conn.Close()
+
+	payload := make([]byte, 64*1024)
+	common.Must2(rand.Read(payload))
+	common.Must2(conn.Write(payload[:100]))
+	common.Must2(conn.Write(payload[100:]))
+
+	response := make([]byte, len(payload))
+	common.Must2(io.ReadFull(conn, response))
+	if !bytes.Equal(payload, response) {
+		t.Error("response mismatch")
+	}
+}
+
+func TestHTTPUpgrade(t *testing.T) {
+	testEcho(t, &Config{
+		Path: "upgrade",
+		Host: "www.v2fly.org",
+	})
+}
+
+func TestHTTPUpgradeWithEarlyData(t *testing.T) {
+	testEcho(t, &Config{
+		Path:         "/upgrade",
+		MaxEarlyData: 32,
+	})
+}
+
+func TestHTTPUpgradeWithEarlyDataHeader(t *testing.T) {
+	testEcho(t, &Config{
+		Path:                "/upgrade",
+		MaxEarlyData:        2048,
+		EarlyDataHeaderName: "Sec-WebSocket-Protocol",
+	})
+}
+
+func TestHTTPUpgradeWrongPath(t *testing.T) {
+	port := tcp.PickPort()
+	listen, err := ListenHTTPUpgrade(context.Background(), net.LocalHostIP, port, &internet.MemoryStreamConfig{
+		ProtocolName:     "httpupgrade",
+		ProtocolSettings: &Config{Path: "/upgrade"},
+	}, echoHandler)
+	common.Must(err)
+	defer listen.Close()
+
+	_, err = Dial(context.Background(), net.TCPDestination(net.LocalHostIP, port), &internet.MemoryStreamConfig{
+		ProtocolName:     "httpupgrade",
+		ProtocolSettings: &Config{Path: "/other"},
+	})
+	if err == nil {
+		t.Error("expected error, but got nil")
+	}
+}
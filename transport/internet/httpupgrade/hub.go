@@ -0,0 +1,187 @@
+package httpupgrade
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/net"
+	http_proto "github.com/v2fly/v2ray-core/v5/common/protocol/http"
+	"github.com/v2fly/v2ray-core/v5/common/session"
+	"github.com/v2fly/v2ray-core/v5/transport/internet"
+	v2tls "github.com/v2fly/v2ray-core/v5/transport/internet/tls"
+)
+
+const upgradeResponse = "HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: websocket\r\n\r\n"
+
+type requestHandler struct {
+	host                string
+	path                string
+	ln                  *Listener
+	earlyDataEnabled    bool
+	earlyDataHeaderName string
+}
+
+func (h *requestHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	if h.host != "" && !strings.EqualFold(request.Host, h.host) {
+		writer.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	var earlyData []byte
+	if !h.earlyDataEnabled || h.earlyDataHeaderName != "" {
+		if request.URL.Path != h.path {
+			writer.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if h.earlyDataEnabled {
+			earlyData = []byte(request.Header.Get(h.earlyDataHeaderName))
+		}
+	} else {
+		if !strings.HasPrefix(request.URL.RequestURI(), h.path) {
+			writer.WriteHeader(http.StatusNotFound)
+			return
+		}
+		earlyData = []byte(request.URL.RequestURI()[len(h.path):])
+	}
+
+	if !strings.EqualFold(request.Header.Get("Upgrade"), "websocket") ||
+		!strings.Contains(strings.ToLower(request.Header.Get("Connection")), "upgrade") {
+		writer.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	hijacker, ok := writer.(http.Hijacker)
+	if !ok {
+		writer.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		newError("failed to hijack HTTP connection").Base(err).WriteToLog()
+		return
+	}
+	conn.SetDeadline(time.Time{})
+	if _, err := io.WriteString(conn, upgradeResponse); err != nil {
+		newError("failed to send upgrade response").Base(err).WriteToLog()
+		conn.Close()
+		return
+	}
+
+	forwardedAddrs := http_proto.ParseXForwardedFor(request.Header)
+	remoteAddr := conn.RemoteAddr()
+	if len(forwardedAddrs) > 0 && forwardedAddrs[0].Family().IsIP() {
+		remoteAddr = &net.TCPAddr{
+			IP:   forwardedAddrs[0].IP(),
+			Port: int(0),
+		}
+	}
+
+	reader := handshakeReader(conn, rw.Reader)
+	if len(earlyData) > 0 {
+		reader = io.MultiReader(base64.NewDecoder(base64.RawURLEncoding, bytes.NewReader(earlyData)), reader)
+	}
+	h.ln.addConn(newConnection(conn, reader, remoteAddr))
+}
+
+type Listener struct {
+	sync.Mutex
+	server   http.Server
+	listener net.Listener
+	config   *Config
+	addConn  internet.ConnHandler
+	locker   *internet.FileLocker // for unix domain socket
+}
+
+func ListenHTTPUpgrade(ctx context.Context, address net.Address, port net.Port, streamSettings *internet.MemoryStreamConfig, addConn internet.ConnHandler) (internet.Listener, error) {
+	l := &Listener{
+		addConn: addConn,
+	}
+	config := streamSettings.ProtocolSettings.(*Config)
+	l.config = config
+	if streamSettings.SocketSettings == nil {
+		streamSettings.SocketSettings = &internet.SocketConfig{}
+	}
+	streamSettings.SocketSettings.AcceptProxyProtocol = config.AcceptProxyProtocol
+
+	var listener net.Listener
+	var err error
+	if port == net.Port(0) { // unix
+		listener, err = internet.ListenSystem(ctx, &net.UnixAddr{
+			Name: address.Domain(),
+			Net:  "unix",
+		}, streamSettings.SocketSettings)
+		if err != nil {
+			return nil, newError("failed to listen unix domain socket(for HTTP upgrade) on ", address).Base(err)
+		}
+		newError("listening unix domain socket(for HTTP upgrade) on ", address).WriteToLog(session.ExportIDToError(ctx))
+		locker := ctx.Value(address.Domain())
+		if locker != nil {
+			l.locker = locker.(*internet.FileLocker)
+		}
+	} else { // tcp
+		listener, err = internet.ListenSystem(ctx, &net.TCPAddr{
+			IP:   address.IP(),
+			Port: int(port),
+		}, streamSettings.SocketSettings)
+		if err != nil {
+			return nil, newError("failed to listen TCP(for HTTP upgrade) on ", address, ":", port).Base(err)
+		}
+		newError("listening TCP(for HTTP upgrade) on ", address, ":", port).WriteToLog(session.ExportIDToError(ctx))
+	}
+
+	if streamSettings.SocketSettings.AcceptProxyProtocol {
+		newError("accepting PROXY protocol").AtWarning().WriteToLog(session.ExportIDToError(ctx))
+	}
+
+	if config := v2tls.ConfigFromStreamSettings(streamSettings); config != nil {
+		if tlsConfig := config.GetTLSConfig(); tlsConfig != nil {
+			listener = tls.NewListener(listener, tlsConfig)
+		}
+	}
+
+	l.listener = listener
+	l.server = http.Server{
+		Handler: &requestHandler{
+			host:                config.Host,
+			path:                config.GetNormalizedPath(),
+			ln:                  l,
+			earlyDataEnabled:    config.MaxEarlyData != 0,
+			earlyDataHeaderName: config.EarlyDataHeaderName,
+		},
+		ReadHeaderTimeout: time.Second * 4,
+		MaxHeaderBytes:    http.DefaultMaxHeaderBytes,
+	}
+
+	go func() {
+		if err := l.server.Serve(l.listener); err != nil {
+			newError("failed to serve http for HTTP upgrade").Base(err).AtWarning().WriteToLog(session.ExportIDToError(ctx))
+		}
+	}()
+
+	return l, nil
+}
+
+// Addr implements net.Listener.Addr().
+func (ln *Listener) Addr() net.Addr {
+	return ln.listener.Addr()
+}
+
+// Close implements net.Listener.Close().
+func (ln *Listener) Close() error {
+	if ln.locker != nil {
+		ln.locker.Release()
+	}
+	return ln.listener.Close()
+}
+
+func init() {
+	common.Must(internet.RegisterTransportListener(protocolName, ListenHTTPUpgrade))
+}
@@ -0,0 +1,51 @@
+package hysteria2
+
+import (
+	"time"
+
+	"github.com/lucas-clemente/quic-go"
+
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/transport/internet"
+)
+
+const (
+	protocolName   = "hysteria2"
+	internalDomain = "hysteria2.internal.v2fly.org"
+)
+
+// bytes per second in 1 Mbps
+const mbps = 125000
+
+func (c *Config) getSendBPS() uint64 {
+	return c.UpMbps * mbps
+}
+
+func (c *Config) getReceiveBPS() uint64 {
+	return c.DownMbps * mbps
+}
+
+func getQuicConfig(config *Config) *quic.Config {
+	quicConfig := &quic.Config{
+		HandshakeIdleTimeout:           time.Second * 8,
+		MaxIdleTimeout:                 time.Second * 30,
+		InitialStreamReceiveWindow:     8 * 1024 * 1024,
+		MaxStreamReceiveWindow:         8 * 1024 * 1024,
+		InitialConnectionReceiveWindow: 20 * 1024 * 1024,
+		MaxConnectionReceiveWindow:     20 * 1024 * 1024,
+		MaxIncomingStreams:             1024,
+		MaxIncomingUniStreams:          -1,
+		KeepAlive:                      true,
+		EnableDatagrams:                true,
+	}
+	if config.IdleTimeout > 0 {
+		quicConfig.MaxIdleTimeout = time.Second * time.Duration(config.IdleTimeout)
+	}
+	return quicConfig
+}
+
+func init() {
+	common.Must(internet.RegisterProtocolConfigCreator(protocolName, func() interface{} {
+		return new(Config)
+	}))
+}
@@ -0,0 +1,156 @@
+package hysteria2
+
+import (
+	"io"
+	"sync"
+	"time"
+
+	"github.com/lucas-clemente/quic-go"
+
+	"github.com/v2fly/v2ray-core/v5/common/buf"
+	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/common/protocol"
+	"github.com/v2fly/v2ray-core/v5/transport/internet"
+)
+
+// Connection is a connection carried in a Hysteria2 session.
+type Connection interface {
+	internet.Connection
+	// User returns the user accepted by AuthConnection.Authenticate, or nil if the client was
+	// authenticated by the transport.
+	User() *protocol.MemoryUser
+}
+
+// PacketConnection is a UDP session carried in QUIC datagrams. Each Read and Write carries one
+// datagram, which may be lost.
+type PacketConnection interface {
+	Connection
+	SessionID() uint32
+}
+
+// AuthConnection is handed to the connection handler for each new client if the server has no
+// password configured, so that the inbound proxy can authenticate the client. The connection
+// carries no data.
+type AuthConnection interface {
+	internet.Connection
+	// Auth returns the credential sent by the client.
+	Auth() string
+	// Authenticate accepts the client as user, or rejects it if user is nil. Closing the
+	// connection without calling Authenticate rejects the client.
+	Authenticate(user *protocol.MemoryUser)
+}
+
+// streamConn is a connection carried in a QUIC stream.
+type streamConn struct {
+	stream  quic.Stream
+	session *sessionState
+	local   net.Addr
+	remote  net.Addr
+}
+
+func (c *streamConn) Read(b []byte) (int, error) {
+	return c.stream.Read(b)
+}
+
+func (c *streamConn) WriteMultiBuffer(mb buf.MultiBuffer) error {
+	mb = buf.Compact(mb)
+	mb, err := buf.WriteMultiBuffer(c, mb)
+	buf.ReleaseMulti(mb)
+	return err
+}
+
+func (c *streamConn) Write(b []byte) (int, error) {
+	if err := c.session.limiter.wait(c.stream.Context(), len(b)); err != nil {
+		return 0, err
+	}
+	return c.stream.Write(b)
+}
+
+func (c *streamConn) Close() error {
+	c.stream.CancelRead(0)
+	return c.stream.Close()
+}
+
+func (c *streamConn) User() *protocol.MemoryUser {
+	return c.session.user
+}
+
+func (c *streamConn) LocalAddr() net.Addr {
+	return c.local
+}
+
+func (c *streamConn) RemoteAddr() net.Addr {
+	return c.remote
+}
+
+func (c *streamConn) SetDeadline(t time.Time) error {
+	return c.stream.SetDeadline(t)
+}
+
+func (c *streamConn) SetReadDeadline(t time.Time) error {
+	return c.stream.SetReadDeadline(t)
+}
+
+func (c *streamConn) SetWriteDeadline(t time.Time) error {
+	return c.stream.SetWriteDeadline(t)
+}
+
+type authConn struct {
+	auth   string
+	local  net.Addr
+	remote net.Addr
+	once   sync.Once
+	result chan *protocol.MemoryUser
+}
+
+func newAuthConn(auth string, local, remote net.Addr) *authConn {
+	return &authConn{
+		auth:   auth,
+		local:  local,
+		remote: remote,
+		result: make(chan *protocol.MemoryUser, 1),
+	}
+}
+
+func (c *authConn) Auth() string {
+	return c.auth
+}
+
+func (c *authConn) Authenticate(user *protocol.MemoryUser) {
+	c.once.Do(func() {
+		c.result <- user
+	})
+}
+
+func (c *authConn) Read([]byte) (int, error) {
+	return 0, io.EOF
+}
+
+func (c *authConn) Write([]byte) (int, error) {
+	return 0, io.ErrClosedPipe
+}
+
+func (c *authConn) Close() error {
+	c.Authenticate(nil)
+	return nil
+}
+
+func (c *authConn) LocalAddr() net.Addr {
+	return c.local
+}
+
+func (c *authConn) RemoteAddr() net.Addr {
+	return c.remote
+}
+
+func (c *authConn) SetDeadline(time.Time) error {
+	return nil
+}
+
+func (c *authConn) SetReadDeadline(time.Time) error {
+	return nil
+}
+
+func (c *authConn) SetWriteDeadline(time.Time) error {
+	return nil
+}
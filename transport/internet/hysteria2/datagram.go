@@ -0,0 +1,229 @@
+package hysteria2
+
+import (
+	"encoding/binary"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/buf"
+	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/common/protocol"
+	"github.com/v2fly/v2ray-core/v5/common/signal/done"
+)
+
+const (
+	sessionIDSize     = 4
+	datagramQueueSize = 64
+)
+
+// datagramMux carries UDP sessions in QUIC DATAGRAM frames. Each datagram starts with the ID of its
+// UDP session. UDP sessions are opened by the client, and end when either side is idle.
+type datagramMux struct {
+	access sync.Mutex
+	state  *sessionState
+	conns  map[uint32]*datagramConn
+	nextID uint32
+	// onConn handles UDP sessions opened by the peer. It is nil on clients.
+	onConn func(*datagramConn)
+}
+
+func newDatagramMux(state *sessionState, onConn func(*datagramConn)) *datagramMux {
+	m := &datagramMux{
+		state:  state,
+		conns:  make(map[uint32]*datagramConn),
+		onConn: onConn,
+	}
+	go m.receive()
+	return m
+}
+
+func (m *datagramMux) newConn(id uint32) *datagramConn {
+	c := &datagramConn{
+		mux:    m,
+		id:     id,
+		queue:  make(chan []byte, datagramQueueSize),
+		done:   done.New(),
+		local:  m.state.session.LocalAddr(),
+		remote: m.state.session.RemoteAddr(),
+	}
+	m.conns[id] = c
+	return c
+}
+
+func (m *datagramMux) open() (*datagramConn, error) {
+	if !m.state.isActive() {
+		return nil, errSessionClosed
+	}
+
+	m.access.Lock()
+	defer m.access.Unlock()
+
+	m.nextID++
+	return m.newConn(m.nextID), nil
+}
+
+func (m *datagramMux) remove(id uint32) {
+	m.access.Lock()
+	delete(m.conns, id)
+	m.access.Unlock()
+}
+
+func (m *datagramMux) receive() {
+	defer m.closeAll()
+
+	for {
+		data, err := m.state.session.ReceiveMessage()
+		if err != nil {
+			return
+		}
+		if len(data) < sessionIDSize {
+			continue
+		}
+		id := binary.BigEndian.Uint32(data)
+
+		m.access.Lock()
+		c, found := m.conns[id]
+		if !found && m.onConn != nil {
+			c = m.newConn(id)
+			m.access.Unlock()
+			m.onConn(c)
+		} else {
+			m.access.Unlock()
+		}
+		if c != nil {
+			c.push(data[sessionIDSize:])
+		}
+	}
+}
+
+func (m *datagramMux) closeAll() {
+	m.access.Lock()
+	conns := make([]*datagramConn, 0, len(m.conns))
+	for _, c := range m.conns {
+		conns = append(conns, c)
+	}
+	m.access.Unlock()
+
+	for _, c := range conns {
+		common.Close(c)
+	}
+}
+
+// datagramConn is a UDP session whose writes are carried in individual datagrams.
+type datagramConn struct {
+	mux    *datagramMux
+	id     uint32
+	queue  chan []byte
+	done   *done.Instance
+	local  net.Addr
+	remote net.Addr
+}
+
+func (c *datagramConn) push(payload []byte) {
+	if c.done.Done() {
+		return
+	}
+	select {
+	case c.queue <- payload:
+	default:
+		// Drop the datagram if the reader is too slow, like a UDP socket.
+	}
+}
+
+func (c *datagramConn) SessionID() uint32 {
+	return c.id
+}
+
+func (c *datagramConn) User() *protocol.MemoryUser {
+	return c.mux.state.user
+}
+
+// ReadMultiBuffer implements buf.Reader. Each buffer holds one datagram.
+func (c *datagramConn) ReadMultiBuffer() (buf.MultiBuffer, error) {
+	select {
+	case payload := <-c.queue:
+		b := buf.New()
+		if _, err := b.Write(payload); err != nil {
+			b.Release()
+			return nil, err
+		}
+		return buf.MultiBuffer{b}, nil
+	case <-c.done.Wait():
+		return nil, io.EOF
+	}
+}
+
+func (c *datagramConn) Read(b []byte) (int, error) {
+	select {
+	case payload := <-c.queue:
+		return copy(b, payload), nil
+	case <-c.done.Wait():
+		return 0, io.EOF
+	}
+}
+
+// WriteMultiBuffer implements buf.Writer. Each buffer is sent in one datagram.
+func (c *datagramConn) WriteMultiBuffer(mb buf.MultiBuffer) error {
+	defer buf.ReleaseMulti(mb)
+
+	for _, b := range mb {
+		if _, err := c.Write(b.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *datagramConn) Write(b []byte) (int, error) {
+	if c.done.Done() {
+		return 0, io.ErrClosedPipe
+	}
+
+	datagram := make([]byte, sessionIDSize+len(b))
+	binary.BigEndian.PutUint32(datagram, c.id)
+	copy(datagram[sessionIDSize:], b)
+
+	session := c.mux.state.session
+	if err := c.mux.state.limiter.wait(session.Context(), len(datagram)); err != nil {
+		return 0, err
+	}
+	if err := session.SendMessage(datagram); err != nil {
+		if !c.mux.state.isActive() {
+			return 0, err
+		}
+		// The datagram is too large for the path. Drop it like an oversized UDP packet.
+		newError("dropping datagram of ", len(b), " bytes").Base(err).AtDebug().WriteToLog()
+	}
+	return len(b), nil
+}
+
+func (c *datagramConn) Close() error {
+	if c.done.Done() {
+		return nil
+	}
+	c.done.Close()
+	c.mux.remove(c.id)
+	return nil
+}
+
+func (c *datagramConn) LocalAddr() net.Addr {
+	return c.local
+}
+
+func (c *datagramConn) RemoteAddr() net.Addr {
+	return c.remote
+}
+
+func (c *datagramConn) SetDeadline(time.Time) error {
+	return nil
+}
+
+func (c *datagramConn) SetReadDeadline(time.Time) error {
+	return nil
+}
+
+func (c *datagramConn) SetWriteDeadline(time.Time) error {
+	return nil
+}
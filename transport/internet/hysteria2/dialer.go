@@ -0,0 +1,181 @@
+package hysteria2
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/lucas-clemente/quic-go"
+
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/common/session"
+	"github.com/v2fly/v2ray-core/v5/transport/internet"
+	"github.com/v2fly/v2ray-core/v5/transport/internet/tls"
+)
+
+const authTimeout = time.Second * 8
+
+type dialerKey struct {
+	dest           net.Destination
+	streamSettings *internet.MemoryStreamConfig
+}
+
+// clientSession is an authenticated session to a server.
+type clientSession struct {
+	*sessionState
+	rawConn net.PacketConn
+}
+
+func (s *clientSession) close() {
+	if err := s.session.CloseWithError(0, ""); err != nil {
+		newError("failed to close session").Base(err).WriteToLog()
+	}
+	if err := s.rawConn.Close(); err != nil {
+		newError("failed to close raw connection").Base(err).WriteToLog()
+	}
+}
+
+var (
+	globalDialerMap    map[dialerKey]*clientSession
+	globalDialerAccess sync.Mutex
+)
+
+func getSession(dest net.Destination, streamSettings *internet.MemoryStreamConfig) (*clientSession, error) {
+	globalDialerAccess.Lock()
+	defer globalDialerAccess.Unlock()
+
+	if globalDialerMap == nil {
+		globalDialerMap = make(map[dialerKey]*clientSession)
+	}
+
+	key := dialerKey{dest, streamSettings}
+	if s, found := globalDialerMap[key]; found && s.isActive() {
+		return s, nil
+	}
+
+	s, err := dialSession(dest, streamSettings)
+	if err != nil {
+		return nil, err
+	}
+	globalDialerMap[key] = s
+
+	go func() {
+		<-s.session.Context().Done()
+		globalDialerAccess.Lock()
+		if globalDialerMap[key] == s {
+			delete(globalDialerMap, key)
+		}
+		globalDialerAccess.Unlock()
+		s.rawConn.Close()
+	}()
+
+	return s, nil
+}
+
+func dialSession(dest net.Destination, streamSettings *internet.MemoryStreamConfig) (*clientSession, error) {
+	config := streamSettings.ProtocolSettings.(*Config)
+	tlsConfig := tls.ConfigFromStreamSettings(streamSettings)
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{
+			ServerName:    internalDomain,
+			AllowInsecure: true,
+		}
+	}
+
+	destAddr, err := net.ResolveUDPAddr("udp", dest.NetAddr())
+	if err != nil {
+		return nil, err
+	}
+
+	rawConn, err := internet.ListenSystemPacket(context.Background(), &net.UDPAddr{
+		IP:   []byte{0, 0, 0, 0},
+		Port: 0,
+	}, streamSettings.SocketSettings)
+	if err != nil {
+		return nil, err
+	}
+	if config.ObfsPassword != "" {
+		rawConn = newSalamanderConn(rawConn.(*net.UDPConn), config.ObfsPassword)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), authTimeout)
+	defer cancel()
+
+	qSession, err := quic.DialContext(ctx, rawConn, destAddr, "", tlsConfig.GetTLSConfig(tls.WithDestination(dest), tls.WithNextProto("h3")), getQuicConfig(config))
+	if err != nil {
+		rawConn.Close()
+		return nil, err
+	}
+	s := &clientSession{
+		sessionState: &sessionState{session: qSession},
+		rawConn:      rawConn,
+	}
+
+	response, err := authenticate(ctx, qSession, config)
+	if err != nil {
+		s.close()
+		return nil, err
+	}
+	if response.Status != statusAuthOK {
+		s.close()
+		return nil, newError("authentication failed with status ", response.Status)
+	}
+
+	s.limiter = newSendLimiter(negotiateSendBPS(config.getSendBPS(), response.RX))
+	if response.UDP {
+		s.datagrams = newDatagramMux(s.sessionState, nil)
+	}
+	return s, nil
+}
+
+func authenticate(ctx context.Context, qSession quic.Session, config *Config) (*authResponse, error) {
+	stream, err := qSession.OpenStreamSync(ctx)
+	if err != nil {
+		return nil, newError("failed to open authentication stream").Base(err)
+	}
+	defer stream.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		common.Must(stream.SetDeadline(deadline))
+	}
+	if err := writeAuthRequest(stream, &authRequest{
+		Auth: config.Password,
+		RX:   config.getReceiveBPS(),
+	}); err != nil {
+		return nil, newError("failed to send authentication request").Base(err)
+	}
+	response, err := readAuthResponse(stream)
+	if err != nil {
+		return nil, newError("failed to read authentication response").Base(err)
+	}
+	return response, nil
+}
+
+// Dial opens a stream, or a UDP session if the target of the outbound is UDP, in the session to
+// dest.
+func Dial(ctx context.Context, dest net.Destination, streamSettings *internet.MemoryStreamConfig) (internet.Connection, error) {
+	newError("creating connection to ", dest).WriteToLog(session.ExportIDToError(ctx))
+
+	s, err := getSession(dest, streamSettings)
+	if err != nil {
+		return nil, newError("failed to dial session to ", dest).Base(err)
+	}
+
+	if outbound := session.OutboundFromContext(ctx); outbound != nil && outbound.Target.Network == net.Network_UDP {
+		if s.datagrams == nil {
+			return nil, newError("server does not support UDP")
+		}
+		return s.datagrams.open()
+	}
+
+	stream, err := s.session.OpenStreamSync(ctx)
+	if err != nil {
+		return nil, newError("failed to open stream").Base(err)
+	}
+	return s.newStreamConn(stream), nil
+}
+
+func init() {
+	common.Must(internet.RegisterTransportDialer(protocolName, Dial))
+}
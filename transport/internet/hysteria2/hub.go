@@ -0,0 +1,197 @@
+package hysteria2
+
+import (
+	"context"
+	"time"
+
+	"github.com/lucas-clemente/quic-go"
+
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/common/protocol"
+	"github.com/v2fly/v2ray-core/v5/common/protocol/tls/cert"
+	"github.com/v2fly/v2ray-core/v5/common/signal/done"
+	"github.com/v2fly/v2ray-core/v5/transport/internet"
+	"github.com/v2fly/v2ray-core/v5/transport/internet/tls"
+)
+
+// Listener is an internet.Listener that accepts Hysteria2 sessions.
+type Listener struct {
+	rawConn  net.PacketConn
+	listener quic.Listener
+	config   *Config
+	done     *done.Instance
+	addConn  internet.ConnHandler
+}
+
+// authenticate handles the authentication request of a session. It returns the state of the
+// session if the client is accepted.
+func (l *Listener) authenticate(qSession quic.Session) (*sessionState, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), authTimeout)
+	defer cancel()
+
+	stream, err := qSession.AcceptStream(ctx)
+	if err != nil {
+		return nil, newError("failed to accept authentication stream").Base(err)
+	}
+	defer stream.Close()
+
+	common.Must(stream.SetDeadline(time.Now().Add(authTimeout)))
+	request, err := readAuthRequest(stream)
+	if err != nil {
+		writeAuthResponse(stream, &authResponse{Status: statusAuthRejected})
+		return nil, newError("invalid authentication request").Base(err)
+	}
+
+	var user *protocol.MemoryUser
+	if l.config.Password != "" {
+		if request.Auth != l.config.Password {
+			writeAuthResponse(stream, &authResponse{Status: statusAuthRejected})
+			return nil, newError("invalid password")
+		}
+	} else {
+		conn := newAuthConn(request.Auth, qSession.LocalAddr(), qSession.RemoteAddr())
+		l.addConn(conn)
+		select {
+		case user = <-conn.result:
+		case <-ctx.Done():
+		}
+		if user == nil {
+			writeAuthResponse(stream, &authResponse{Status: statusAuthRejected})
+			return nil, newError("client rejected by the inbound")
+		}
+	}
+
+	peerRX := request.RX
+	if l.config.IgnoreClientBandwidth {
+		peerRX = 0
+	}
+	state := &sessionState{
+		session: qSession,
+		limiter: newSendLimiter(negotiateSendBPS(l.config.getSendBPS(), peerRX)),
+		user:    user,
+	}
+	if err := writeAuthResponse(stream, &authResponse{
+		Status: statusAuthOK,
+		UDP:    true,
+		RX:     l.config.getReceiveBPS(),
+	}); err != nil {
+		return nil, newError("failed to send authentication response").Base(err)
+	}
+	return state, nil
+}
+
+func (l *Listener) handleSession(qSession quic.Session) {
+	state, err := l.authenticate(qSession)
+	if err != nil {
+		newError("failed to authenticate client from ", qSession.RemoteAddr()).Base(err).WriteToLog()
+		// Give the client some time to receive the response before closing the session.
+		select {
+		case <-qSession.Context().Done():
+		case <-time.After(time.Second):
+		}
+		qSession.CloseWithError(0, "")
+		return
+	}
+
+	state.datagrams = newDatagramMux(state, func(conn *datagramConn) {
+		l.addConn(conn)
+	})
+
+	for {
+		stream, err := qSession.AcceptStream(context.Background())
+		if err != nil {
+			newError("failed to accept stream").Base(err).WriteToLog()
+			select {
+			case <-qSession.Context().Done():
+				return
+			case <-l.done.Wait():
+				if err := qSession.CloseWithError(0, ""); err != nil {
+					newError("failed to close session").Base(err).WriteToLog()
+				}
+				return
+			default:
+				time.Sleep(time.Second)
+				continue
+			}
+		}
+
+		l.addConn(state.newStreamConn(stream))
+	}
+}
+
+func (l *Listener) keepAccepting() {
+	for {
+		qSession, err := l.listener.Accept(context.Background())
+		if err != nil {
+			newError("failed to accept QUIC sessions").Base(err).WriteToLog()
+			if l.done.Done() {
+				break
+			}
+			time.Sleep(time.Second)
+			continue
+		}
+		go l.handleSession(qSession)
+	}
+}
+
+// Addr implements internet.Listener.Addr.
+func (l *Listener) Addr() net.Addr {
+	return l.listener.Addr()
+}
+
+// Close implements internet.Listener.Close.
+func (l *Listener) Close() error {
+	l.done.Close()
+	l.listener.Close()
+	l.rawConn.Close()
+	return nil
+}
+
+// Listen creates a new Listener based on configurations.
+func Listen(ctx context.Context, address net.Address, port net.Port, streamSettings *internet.MemoryStreamConfig, handler internet.ConnHandler) (internet.Listener, error) {
+	if address.Family().IsDomain() {
+		return nil, newError("domain address is not allows for listening hysteria2")
+	}
+
+	tlsConfig := tls.ConfigFromStreamSettings(streamSettings)
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{
+			Certificate: []*tls.Certificate{tls.ParseCertificate(cert.MustGenerate(nil, cert.DNSNames(internalDomain), cert.CommonName(internalDomain)))},
+		}
+	}
+
+	config := streamSettings.ProtocolSettings.(*Config)
+	rawConn, err := internet.ListenSystemPacket(context.Background(), &net.UDPAddr{
+		IP:   address.IP(),
+		Port: int(port),
+	}, streamSettings.SocketSettings)
+	if err != nil {
+		return nil, err
+	}
+	if config.ObfsPassword != "" {
+		rawConn = newSalamanderConn(rawConn.(*net.UDPConn), config.ObfsPassword)
+	}
+
+	qListener, err := quic.Listen(rawConn, tlsConfig.GetTLSConfig(tls.WithNextProto("h3")), getQuicConfig(config))
+	if err != nil {
+		rawConn.Close()
+		return nil, err
+	}
+
+	listener := &Listener{
+		rawConn:  rawConn,
+		listener: qListener,
+		config:   config,
+		done:     done.New(),
+		addConn:  handler,
+	}
+
+	go listener.keepAccepting()
+
+	return listener, nil
+}
+
+func init() {
+	common.Must(internet.RegisterTransportListener(protocolName, Listen))
+}
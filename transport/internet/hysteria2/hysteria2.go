@@ -0,0 +1,10 @@
+/*
+Package hysteria2 implements the Hysteria2 transport
+
+Hysteria2 transport authenticates clients with an HTTP/3 request on a QUIC connection, and then
+carries connections in QUIC streams and UDP sessions in QUIC DATAGRAM frames. The sending rate of
+each side is limited to the bandwidth negotiated in the authentication.
+*/
+package hysteria2
+
+//go:generate go run github.com/v2fly/v2ray-core/v5/common/errors/errorgen
@@ -0,0 +1,185 @@
+package hysteria2_test
+
+import (
+	"context"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/buf"
+	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/common/protocol"
+	"github.com/v2fly/v2ray-core/v5/common/protocol/tls/cert"
+	"github.com/v2fly/v2ray-core/v5/common/session"
+	"github.com/v2fly/v2ray-core/v5/testing/servers/udp"
+	"github.com/v2fly/v2ray-core/v5/transport/internet"
+	"github.com/v2fly/v2ray-core/v5/transport/internet/hysteria2"
+	"github.com/v2fly/v2ray-core/v5/transport/internet/tls"
+)
+
+func listen(t *testing.T, port net.Port, config *hysteria2.Config, handler internet.ConnHandler) internet.Listener {
+	listener, err := hysteria2.Listen(context.Background(), net.LocalHostIP, port, &internet.MemoryStreamConfig{
+		ProtocolName:     "hysteria2",
+		ProtocolSettings: config,
+		SecurityType:     "tls",
+		SecuritySettings: &tls.Config{
+			Certificate: []*tls.Certificate{
+				tls.ParseCertificate(
+					cert.MustGenerate(nil,
+						cert.DNSNames("www.v2fly.org"),
+					),
+				),
+			},
+		},
+	}, handler)
+	common.Must(err)
+	return listener
+}
+
+func dial(ctx context.Context, port net.Port, config *hysteria2.Config) (internet.Connection, error) {
+	return hysteria2.Dial(ctx, net.UDPDestination(net.LocalHostIP, port), &internet.MemoryStreamConfig{
+		ProtocolName:     "hysteria2",
+		ProtocolSettings: config,
+		SecurityType:     "tls",
+		SecuritySettings: &tls.Config{
+			ServerName:    "www.v2fly.org",
+			AllowInsecure: true,
+		},
+	})
+}
+
+func echo(conn internet.Connection) {
+	go func() {
+		defer conn.Close()
+
+		b := make([]byte, 2048)
+		for {
+			n, err := conn.Read(b)
+			if err != nil {
+				return
+			}
+			if _, err := conn.Write(b[:n]); err != nil {
+				return
+			}
+		}
+	}()
+}
+
+func TestHysteria2Connection(t *testing.T) {
+	port := udp.PickPort()
+	listener := listen(t, port, &hysteria2.Config{
+		Password:     "password",
+		UpMbps:       100,
+		DownMbps:     100,
+		ObfsPassword: "obfs",
+	}, echo)
+	defer listener.Close()
+
+	conn, err := dial(context.Background(), port, &hysteria2.Config{
+		Password:     "password",
+		UpMbps:       100,
+		DownMbps:     100,
+		ObfsPassword: "obfs",
+	})
+	common.Must(err)
+	defer conn.Close()
+
+	const N = 1024 * 64
+	b1 := make([]byte, N)
+	common.Must2(rand.Read(b1))
+	b2 := buf.New()
+
+	for i := 0; i < 2; i++ {
+		common.Must2(conn.Write(b1))
+
+		var received []byte
+		for len(received) < N {
+			b2.Clear()
+			common.Must2(b2.ReadFrom(conn))
+			received = append(received, b2.Bytes()...)
+		}
+		if r := cmp.Diff(received, b1); r != "" {
+			t.Error(r)
+		}
+	}
+}
+
+func TestHysteria2PacketConnection(t *testing.T) {
+	port := udp.PickPort()
+	listener := listen(t, port, &hysteria2.Config{
+		Password: "password",
+	}, func(conn internet.Connection) {
+		if _, ok := conn.(hysteria2.PacketConnection); !ok {
+			t.Error("not a packet connection")
+		}
+		echo(conn)
+	})
+	defer listener.Close()
+
+	ctx := session.ContextWithOutbound(context.Background(), &session.Outbound{
+		Target: net.UDPDestination(net.LocalHostIP, net.Port(53)),
+	})
+	conn, err := dial(ctx, port, &hysteria2.Config{
+		Password: "password",
+	})
+	common.Must(err)
+	defer conn.Close()
+
+	b1 := make([]byte, 512)
+	common.Must2(rand.Read(b1))
+	b2 := make([]byte, 2048)
+
+	for i := 0; i < 3; i++ {
+		common.Must2(conn.Write(b1))
+		n, err := conn.Read(b2)
+		common.Must(err)
+		if r := cmp.Diff(b2[:n], b1); r != "" {
+			t.Error(r)
+		}
+	}
+}
+
+func TestHysteria2AuthConnection(t *testing.T) {
+	user := &protocol.MemoryUser{Email: "love@v2fly.org"}
+
+	port := udp.PickPort()
+	listener := listen(t, port, &hysteria2.Config{}, func(conn internet.Connection) {
+		switch c := conn.(type) {
+		case hysteria2.AuthConnection:
+			if c.Auth() == "user" {
+				c.Authenticate(user)
+			}
+			c.Close()
+		case hysteria2.Connection:
+			if c.User() != user {
+				t.Error("unexpected user: ", c.User())
+			}
+			echo(conn)
+		}
+	})
+	defer listener.Close()
+
+	if _, err := dial(context.Background(), port, &hysteria2.Config{
+		Password: "invalid",
+	}); err == nil {
+		t.Error("expected authentication failure")
+	}
+
+	conn, err := dial(context.Background(), port, &hysteria2.Config{
+		Password: "user",
+	})
+	common.Must(err)
+	defer conn.Close()
+
+	common.Must2(conn.Write([]byte("test")))
+	common.Must(conn.SetReadDeadline(time.Now().Add(time.Second * 5)))
+	b := make([]byte, 16)
+	n, err := conn.Read(b)
+	common.Must(err)
+	if string(b[:n]) != "test" {
+		t.Error("unexpected response: ", string(b[:n]))
+	}
+}
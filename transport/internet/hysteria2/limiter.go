@@ -0,0 +1,54 @@
+package hysteria2
+
+import (
+	"context"
+
+	"golang.org/x/time/rate"
+)
+
+const sendBurst = 64 * 1024
+
+// negotiateSendBPS returns the sending bandwidth of a session: the configured sending bandwidth,
+// limited by the receiving bandwidth announced by the peer. 0 means unlimited.
+func negotiateSendBPS(sendBPS, peerRX uint64) uint64 {
+	if sendBPS == 0 || (peerRX > 0 && peerRX < sendBPS) {
+		return peerRX
+	}
+	return sendBPS
+}
+
+// sendLimiter caps the data sent on a session at the negotiated bandwidth, by limiting the writes
+// of streams and datagrams. It is not the Brutal congestion control of Hysteria: the congestion
+// controller of quic-go still backs off on packet loss, and quic-go v0.25 doesn't allow replacing
+// it.
+type sendLimiter struct {
+	limiter *rate.Limiter
+}
+
+// newSendLimiter returns a sendLimiter of bps bytes per second, or nil if bps is 0.
+func newSendLimiter(bps uint64) *sendLimiter {
+	if bps == 0 {
+		return nil
+	}
+	return &sendLimiter{
+		limiter: rate.NewLimiter(rate.Limit(bps), sendBurst),
+	}
+}
+
+// wait blocks until n bytes may be sent.
+func (l *sendLimiter) wait(ctx context.Context, n int) error {
+	if l == nil {
+		return nil
+	}
+	for n > 0 {
+		size := n
+		if size > sendBurst {
+			size = sendBurst
+		}
+		if err := l.limiter.WaitN(ctx, size); err != nil {
+			return err
+		}
+		n -= size
+	}
+	return nil
+}
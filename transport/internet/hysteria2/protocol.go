@@ -0,0 +1,179 @@
+package hysteria2
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+
+	"github.com/lucas-clemente/quic-go/quicvarint"
+	"github.com/marten-seemann/qpack"
+
+	"github.com/v2fly/v2ray-core/v5/common/dice"
+)
+
+const (
+	frameTypeHeaders = 0x1
+	maxHeadersSize   = 8192
+
+	authPath      = "/auth"
+	authAuthority = "hysteria"
+
+	// statusAuthOK is the status of a successful authentication. Servers reply to failed
+	// authentications as an ordinary web server without the resource.
+	statusAuthOK       = 233
+	statusAuthRejected = 404
+
+	headerAuth    = "hysteria-auth"
+	headerUDP     = "hysteria-udp"
+	headerCCRX    = "hysteria-cc-rx"
+	headerPadding = "hysteria-padding"
+
+	// ccRXAuto means the sender does not know its receiving bandwidth.
+	ccRXAuto = "auto"
+
+	paddingChars = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+)
+
+func randomPadding() string {
+	padding := make([]byte, 64+dice.Roll(448))
+	for i := range padding {
+		padding[i] = paddingChars[dice.Roll(len(paddingChars))]
+	}
+	return string(padding)
+}
+
+func formatRX(rx uint64) string {
+	if rx == 0 {
+		return ccRXAuto
+	}
+	return strconv.FormatUint(rx, 10)
+}
+
+func parseRX(value string) uint64 {
+	rx, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return rx
+}
+
+// writeHeaders writes an HTTP/3 HEADERS frame.
+func writeHeaders(w io.Writer, fields ...qpack.HeaderField) error {
+	var block bytes.Buffer
+	encoder := qpack.NewEncoder(&block)
+	for _, field := range fields {
+		if err := encoder.WriteField(field); err != nil {
+			return err
+		}
+	}
+
+	var frame bytes.Buffer
+	quicvarint.Write(&frame, frameTypeHeaders)
+	quicvarint.Write(&frame, uint64(block.Len()))
+	frame.Write(block.Bytes())
+	_, err := w.Write(frame.Bytes())
+	return err
+}
+
+// readHeaders reads an HTTP/3 HEADERS frame.
+func readHeaders(r io.Reader) (map[string]string, error) {
+	reader := quicvarint.NewReader(r)
+	frameType, err := quicvarint.Read(reader)
+	if err != nil {
+		return nil, err
+	}
+	if frameType != frameTypeHeaders {
+		return nil, newError("unexpected frame type: ", frameType)
+	}
+	length, err := quicvarint.Read(reader)
+	if err != nil {
+		return nil, err
+	}
+	if length > maxHeadersSize {
+		return nil, newError("headers too large: ", length)
+	}
+	block := make([]byte, length)
+	if _, err := io.ReadFull(r, block); err != nil {
+		return nil, err
+	}
+	fields, err := qpack.NewDecoder(nil).DecodeFull(block)
+	if err != nil {
+		return nil, newError("failed to decode headers").Base(err)
+	}
+
+	headers := make(map[string]string, len(fields))
+	for _, field := range fields {
+		headers[field.Name] = field.Value
+	}
+	return headers, nil
+}
+
+type authRequest struct {
+	Auth string
+	// RX is the receiving bandwidth of the client in bytes per second. 0 means unknown.
+	RX uint64
+}
+
+func writeAuthRequest(w io.Writer, request *authRequest) error {
+	return writeHeaders(w,
+		qpack.HeaderField{Name: ":method", Value: "POST"},
+		qpack.HeaderField{Name: ":scheme", Value: "https"},
+		qpack.HeaderField{Name: ":authority", Value: authAuthority},
+		qpack.HeaderField{Name: ":path", Value: authPath},
+		qpack.HeaderField{Name: headerAuth, Value: request.Auth},
+		qpack.HeaderField{Name: headerCCRX, Value: formatRX(request.RX)},
+		qpack.HeaderField{Name: headerPadding, Value: randomPadding()},
+	)
+}
+
+func readAuthRequest(r io.Reader) (*authRequest, error) {
+	headers, err := readHeaders(r)
+	if err != nil {
+		return nil, err
+	}
+	if headers[":method"] != "POST" || headers[":path"] != authPath || headers[":authority"] != authAuthority {
+		return nil, newError("not an authentication request")
+	}
+	return &authRequest{
+		Auth: headers[headerAuth],
+		RX:   parseRX(headers[headerCCRX]),
+	}, nil
+}
+
+type authResponse struct {
+	Status int
+	UDP    bool
+	// RX is the receiving bandwidth of the server in bytes per second. 0 means unknown.
+	RX uint64
+}
+
+func writeAuthResponse(w io.Writer, response *authResponse) error {
+	fields := []qpack.HeaderField{
+		{Name: ":status", Value: strconv.Itoa(response.Status)},
+	}
+	if response.Status == statusAuthOK {
+		fields = append(fields,
+			qpack.HeaderField{Name: headerUDP, Value: strconv.FormatBool(response.UDP)},
+			qpack.HeaderField{Name: headerCCRX, Value: formatRX(response.RX)},
+		)
+	}
+	fields = append(fields, qpack.HeaderField{Name: headerPadding, Value: randomPadding()})
+	return writeHeaders(w, fields...)
+}
+
+func readAuthResponse(r io.Reader) (*authResponse, error) {
+	headers, err := readHeaders(r)
+	if err != nil {
+		return nil, err
+	}
+	status, err := strconv.Atoi(headers[":status"])
+	if err != nil {
+		return nil, newError("invalid status: ", headers[":status"])
+	}
+	udp, _ := strconv.ParseBool(headers[headerUDP])
+	return &authResponse{
+		Status: status,
+		UDP:    udp,
+		RX:     parseRX(headers[headerCCRX]),
+	}, nil
+}
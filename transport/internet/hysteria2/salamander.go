@@ -0,0 +1,111 @@
+package hysteria2
+
+import (
+	"crypto/rand"
+	"syscall"
+	"time"
+
+	"golang.org/x/crypto/blake2b"
+
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/bytespool"
+	"github.com/v2fly/v2ray-core/v5/common/net"
+)
+
+const (
+	salamanderSaltSize = 8
+	salamanderBufSize  = 2048
+)
+
+// salamanderConn obfuscates packets with Salamander. Each packet is prefixed with a random salt,
+// and the payload is XORed with BLAKE2b-256(password + salt).
+type salamanderConn struct {
+	conn     *net.UDPConn
+	password []byte
+}
+
+func newSalamanderConn(conn *net.UDPConn, password string) *salamanderConn {
+	return &salamanderConn{
+		conn:     conn,
+		password: []byte(password),
+	}
+}
+
+func (c *salamanderConn) key(salt []byte) [blake2b.Size256]byte {
+	material := make([]byte, 0, len(c.password)+len(salt))
+	material = append(material, c.password...)
+	material = append(material, salt...)
+	return blake2b.Sum256(material)
+}
+
+func (c *salamanderConn) ReadFrom(p []byte) (int, net.Addr, error) {
+	buffer := bytespool.Alloc(salamanderBufSize)
+	defer bytespool.Free(buffer)
+
+	for {
+		n, addr, err := c.conn.ReadFrom(buffer)
+		if err != nil {
+			return 0, nil, err
+		}
+		if n <= salamanderSaltSize {
+			continue
+		}
+
+		key := c.key(buffer[:salamanderSaltSize])
+		payload := buffer[salamanderSaltSize:n]
+		for i := range payload {
+			payload[i] ^= key[i%blake2b.Size256]
+		}
+		return copy(p, payload), addr, nil
+	}
+}
+
+func (c *salamanderConn) WriteTo(p []byte, addr net.Addr) (int, error) {
+	buffer := bytespool.Alloc(int32(salamanderSaltSize + len(p)))
+	defer bytespool.Free(buffer)
+
+	salt := buffer[:salamanderSaltSize]
+	common.Must2(rand.Read(salt))
+	key := c.key(salt)
+	payload := buffer[salamanderSaltSize : salamanderSaltSize+len(p)]
+	for i := range p {
+		payload[i] = p[i] ^ key[i%blake2b.Size256]
+	}
+
+	if _, err := c.conn.WriteTo(buffer[:salamanderSaltSize+len(p)], addr); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (c *salamanderConn) Close() error {
+	return c.conn.Close()
+}
+
+func (c *salamanderConn) LocalAddr() net.Addr {
+	return c.conn.LocalAddr()
+}
+
+func (c *salamanderConn) SetReadBuffer(bytes int) error {
+	return c.conn.SetReadBuffer(bytes)
+}
+
+func (c *salamanderConn) SetWriteBuffer(bytes int) error {
+	return c.conn.SetWriteBuffer(bytes)
+}
+
+func (c *salamanderConn) SetDeadline(t time.Time) error {
+	return c.conn.SetDeadline(t)
+}
+
+func (c *salamanderConn) SetReadDeadline(t time.Time) error {
+	return c.conn.SetReadDeadline(t)
+}
+
+func (c *salamanderConn) SetWriteDeadline(t time.Time) error {
+	return c.conn.SetWriteDeadline(t)
+}
+
+func (c *salamanderConn) SyscallConn() (syscall.RawConn, error) {
+	return c.conn.SyscallConn()
+}
@@ -0,0 +1,35 @@
+package hysteria2
+
+import (
+	"github.com/lucas-clemente/quic-go"
+
+	"github.com/v2fly/v2ray-core/v5/common/protocol"
+)
+
+var errSessionClosed = newError("session closed")
+
+// sessionState is shared by the connections of an authenticated session.
+type sessionState struct {
+	session   quic.Session
+	limiter   *sendLimiter
+	user      *protocol.MemoryUser
+	datagrams *datagramMux
+}
+
+func (s *sessionState) isActive() bool {
+	select {
+	case <-s.session.Context().Done():
+		return false
+	default:
+		return true
+	}
+}
+
+func (s *sessionState) newStreamConn(stream quic.Stream) *streamConn {
+	return &streamConn{
+		stream:  stream,
+		session: s,
+		local:   s.session.LocalAddr(),
+		remote:  s.session.RemoteAddr(),
+	}
+}
@@ -0,0 +1,206 @@
+package kcp
+
+const (
+	bbrStartup = iota
+	bbrDrain
+	bbrProbeBandwidth
+	bbrProbeRTT
+)
+
+const (
+	bbrHighGain = 2.885
+	// bbrBandwidthRounds is the number of rounds of the bottleneck bandwidth filter.
+	bbrBandwidthRounds = 10
+	// bbrMinRTTWindow is the time in milliseconds before the minimum round trip time is probed again.
+	bbrMinRTTWindow = 10000
+	// bbrProbeRTTTime is the time in milliseconds of a ProbeRTT phase.
+	bbrProbeRTTTime = 200
+	// bbrProbeRTTSegments is the number of segments in flight during a ProbeRTT phase.
+	bbrProbeRTTSegments = 4
+)
+
+var bbrPacingGainCycle = [...]float64{1.25, 0.75, 1, 1, 1, 1, 1, 1}
+
+// bbrPacer paces data segments by the bottleneck bandwidth and the minimum round trip time, like
+// BBR (https://datatracker.ietf.org/doc/html/draft-cardwell-iccrg-bbr-congestion-control).
+// Bandwidth is measured in segments per second, from the segments acknowledged in each round,
+// which lasts a minimum round trip time.
+type bbrPacer struct {
+	mode        int
+	initialRate float64
+	interval    uint32
+
+	delivered      uint32
+	sent           uint32
+	roundStart     uint32
+	roundDelivered uint32
+	roundSent      uint32
+	round          int
+	bandwidth      [bbrBandwidthRounds]float64
+
+	minRTT        uint32
+	minRTTStamp   uint32
+	minRTTExpired bool
+	probeRTTDone  uint32
+
+	fullBandwidth      float64
+	fullBandwidthCount int
+	cycleIndex         int
+
+	budget      float64
+	lastPacing  uint32
+	pacingStart bool
+}
+
+// newBBRPacer creates a pacer that sends at initialRate segments per second until the bandwidth
+// is estimated. interval is the time between flushes in milliseconds.
+func newBBRPacer(initialRate float64, interval uint32) *bbrPacer {
+	return &bbrPacer{
+		initialRate: initialRate,
+		interval:    interval,
+	}
+}
+
+func (b *bbrPacer) bottleneckBandwidth() float64 {
+	var max float64
+	for _, v := range b.bandwidth {
+		if v > max {
+			max = v
+		}
+	}
+	return max
+}
+
+// OnAck records segments acknowledged by the peer.
+func (b *bbrPacer) OnAck(segments uint32) {
+	b.delivered += segments
+}
+
+// OnRTT records a round trip time sample.
+func (b *bbrPacer) OnRTT(rtt uint32, current uint32) {
+	if b.minRTT == 0 || rtt < b.minRTT || b.minRTTExpired {
+		b.minRTT = rtt
+		b.minRTTStamp = current
+		b.minRTTExpired = false
+	}
+}
+
+// OnSent records segments sent, including retransmissions.
+func (b *bbrPacer) OnSent(segments uint32) {
+	b.sent += segments
+	b.budget -= float64(segments)
+}
+
+func (b *bbrPacer) roundTime() uint32 {
+	if b.minRTT > b.interval {
+		return b.minRTT
+	}
+	return b.interval
+}
+
+func (b *bbrPacer) endRound(current uint32) {
+	elapsed := current - b.roundStart
+	// Rounds without sending are limited by the application, and do not tell the bandwidth.
+	if b.sent != b.roundSent {
+		b.bandwidth[b.round%bbrBandwidthRounds] = float64(b.delivered-b.roundDelivered) * 1000 / float64(elapsed)
+		b.round++
+	}
+	b.roundStart = current
+	b.roundDelivered = b.delivered
+	b.roundSent = b.sent
+
+	switch b.mode {
+	case bbrStartup:
+		// Startup ends when the bandwidth stops growing by 25% in three rounds.
+		if bw := b.bottleneckBandwidth(); bw >= b.fullBandwidth*1.25 {
+			b.fullBandwidth = bw
+			b.fullBandwidthCount = 0
+		} else {
+			b.fullBandwidthCount++
+		}
+		if b.fullBandwidthCount >= 3 {
+			b.mode = bbrDrain
+		}
+	case bbrDrain:
+		b.mode = bbrProbeBandwidth
+		b.cycleIndex = 0
+	case bbrProbeBandwidth:
+		b.cycleIndex = (b.cycleIndex + 1) % len(bbrPacingGainCycle)
+	}
+}
+
+func (b *bbrPacer) update(current uint32) {
+	if current-b.roundStart >= b.roundTime() {
+		b.endRound(current)
+	}
+
+	switch {
+	case b.mode != bbrProbeRTT && b.minRTT > 0 && current-b.minRTTStamp > bbrMinRTTWindow:
+		b.mode = bbrProbeRTT
+		b.probeRTTDone = current + bbrProbeRTTTime
+		b.minRTTExpired = true
+	case b.mode == bbrProbeRTT && current-b.probeRTTDone < 0x7FFFFFFF:
+		b.minRTTStamp = current
+		b.minRTTExpired = false
+		if b.fullBandwidthCount >= 3 {
+			b.mode = bbrProbeBandwidth
+		} else {
+			b.mode = bbrStartup
+		}
+	}
+}
+
+// PacingRate returns the current sending rate in segments per second.
+func (b *bbrPacer) PacingRate() float64 {
+	// Keep a few segments in each round, so that the bandwidth can still be measured.
+	minRate := bbrProbeRTTSegments * 1000 / float64(b.roundTime())
+	if b.mode == bbrProbeRTT {
+		return minRate
+	}
+
+	bw := b.bottleneckBandwidth()
+	if bw == 0 {
+		return b.initialRate
+	}
+	var rate float64
+	switch b.mode {
+	case bbrStartup:
+		rate = bw * bbrHighGain
+	case bbrDrain:
+		rate = bw / bbrHighGain
+	default:
+		rate = bw * bbrPacingGainCycle[b.cycleIndex]
+	}
+	if rate < minRate {
+		return minRate
+	}
+	return rate
+}
+
+// Budget returns the number of segments that can be sent now.
+func (b *bbrPacer) Budget(current uint32) uint32 {
+	b.update(current)
+
+	rate := b.PacingRate()
+	if !b.pacingStart {
+		b.pacingStart = true
+		b.roundStart = current
+		b.budget = rate * float64(b.interval) / 1000
+	} else {
+		b.budget += rate * float64(current-b.lastPacing) / 1000
+	}
+	b.lastPacing = current
+
+	// Bursts are limited to two flush intervals.
+	limit := rate * float64(2*b.interval) / 1000
+	if limit < 1 {
+		limit = 1
+	}
+	if b.budget > limit {
+		b.budget = limit
+	}
+	if b.budget < 1 {
+		return 0
+	}
+	return uint32(b.budget)
+}
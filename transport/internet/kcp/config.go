@@ -78,6 +78,22 @@ func (c *Config) GetPackerHeader() (internet.PacketHeader, error) {
 	return nil, nil
 }
 
+// newFECEncoder returns an encoder for outgoing packets, or nil if FEC is disabled.
+func (c *Config) newFECEncoder() (*FECEncoder, error) {
+	if c.GetFec().GetDataShards() == 0 {
+		return nil, nil
+	}
+	return NewFECEncoder(int(c.Fec.DataShards), int(c.Fec.ParityShards))
+}
+
+// newFECDecoder returns a decoder for incoming packets, or nil if FEC is disabled.
+func (c *Config) newFECDecoder() (*FECDecoder, error) {
+	if c.GetFec().GetDataShards() == 0 {
+		return nil, nil
+	}
+	return NewFECDecoder(int(c.Fec.DataShards), int(c.Fec.ParityShards))
+}
+
 func (c *Config) GetSendingInFlightSize() uint32 {
 	size := c.GetUplinkCapacityValue() * 1024 * 1024 / c.GetMTUValue() / (1000 / c.GetTTIValue())
 	if size < 8 {
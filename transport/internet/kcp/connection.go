@@ -12,6 +12,7 @@ import (
 	"github.com/v2fly/v2ray-core/v5/common/buf"
 	"github.com/v2fly/v2ray-core/v5/common/signal"
 	"github.com/v2fly/v2ray-core/v5/common/signal/semaphore"
+	"github.com/v2fly/v2ray-core/v5/features/stats"
 )
 
 var (
@@ -171,6 +172,8 @@ type ConnMetadata struct {
 	LocalAddr    net.Addr
 	RemoteAddr   net.Addr
 	Conversation uint16
+	// StatsManager registers the counters of the connection, if it is not nil.
+	StatsManager stats.Manager
 }
 
 // Connection is a KCP connection over UDP.
@@ -196,6 +199,7 @@ type Connection struct {
 	sendingWorker   *SendingWorker
 
 	output SegmentWriter
+	stats  *connectionStats
 
 	dataUpdater *Updater
 	pingUpdater *Updater
@@ -218,6 +222,7 @@ func NewConnection(meta ConnMetadata, writer PacketWriter, closer io.Closer, con
 			rto:    100,
 			minRtt: config.GetTTIValue(),
 		},
+		stats: newConnectionStats(meta.StatsManager, meta),
 	}
 
 	conn.receivingWorker = NewReceivingWorker(conn)
@@ -537,6 +542,7 @@ func (c *Connection) Terminate() {
 	c.closer.Close()
 	c.sendingWorker.Release()
 	c.receivingWorker.Release()
+	c.stats.release()
 }
 
 func (c *Connection) HandleOption(opt SegmentOption) {
@@ -1,11 +1,15 @@
 package kcp_test
 
 import (
+	"context"
 	"io"
 	"testing"
 	"time"
 
+	"github.com/v2fly/v2ray-core/v5/app/stats"
+	"github.com/v2fly/v2ray-core/v5/common"
 	"github.com/v2fly/v2ray-core/v5/common/buf"
+	"github.com/v2fly/v2ray-core/v5/common/net"
 	. "github.com/v2fly/v2ray-core/v5/transport/internet/kcp"
 )
 
@@ -36,3 +40,34 @@ func TestConnectionInterface(t *testing.T) {
 	_ = (buf.Reader)(new(Connection))
 	_ = (buf.Writer)(new(Connection))
 }
+
+func TestConnectionStats(t *testing.T) {
+	manager, err := stats.NewManager(context.Background(), &stats.Config{})
+	common.Must(err)
+
+	conn := NewConnection(ConnMetadata{
+		RemoteAddr:   &net.UDPAddr{IP: net.IP{127, 0, 0, 1}, Port: 1234},
+		Conversation: 5,
+		StatsManager: manager,
+	}, &KCPPacketWriter{
+		Writer: buf.DiscardBytes,
+	}, NoOpCloser(0), &Config{})
+
+	names := []string{
+		"mkcp>>>127.0.0.1:1234/5>>>retransmit",
+		"mkcp>>>127.0.0.1:1234/5>>>rtt",
+		"mkcp>>>127.0.0.1:1234/5>>>loss",
+	}
+	for _, name := range names {
+		if manager.GetCounter(name) == nil {
+			t.Error("counter is not registered: ", name)
+		}
+	}
+
+	conn.Terminate()
+	for _, name := range names {
+		if manager.GetCounter(name) != nil {
+			t.Error("counter is not removed: ", name)
+		}
+	}
+}
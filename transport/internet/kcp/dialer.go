@@ -62,13 +62,23 @@ func DialKCP(ctx context.Context, dest net.Destination, streamSettings *internet
 	if err != nil {
 		return nil, newError("failed to create security").Base(err)
 	}
+	decoder, err := kcpSettings.newFECDecoder()
+	if err != nil {
+		return nil, newError("failed to create FEC decoder").Base(err)
+	}
+	encoder, err := kcpSettings.newFECEncoder()
+	if err != nil {
+		return nil, newError("failed to create FEC encoder").Base(err)
+	}
 	reader := &KCPPacketReader{
 		Header:   header,
 		Security: security,
+		FEC:      decoder,
 	}
 	writer := &KCPPacketWriter{
 		Header:   header,
 		Security: security,
+		FEC:      encoder,
 		Writer:   rawConn,
 	}
 
@@ -77,6 +87,7 @@ func DialKCP(ctx context.Context, dest net.Destination, streamSettings *internet
 		LocalAddr:    rawConn.LocalAddr(),
 		RemoteAddr:   rawConn.RemoteAddr(),
 		Conversation: conv,
+		StatsManager: statsManager(ctx, kcpSettings),
 	}, writer, rawConn, kcpSettings)
 
 	go fetchInput(ctx, rawConn, reader, session)
@@ -85,6 +96,10 @@ func DialKCP(ctx context.Context, dest net.Destination, streamSettings *internet
 
 	if config := tls.ConfigFromStreamSettings(streamSettings); config != nil {
 		iConn = tls.Client(iConn, config.GetTLSConfig(tls.WithDestination(dest)))
+		if err := tls.TraceHandshake(ctx, iConn); err != nil {
+			iConn.Close()
+			return nil, err
+		}
 	}
 
 	return iConn, nil
@@ -0,0 +1,238 @@
+package kcp
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+const (
+	fecHeaderSize = 6
+	// fecOverhead is the overhead of FEC in a data packet, which is the header and the size of the
+	// payload.
+	fecOverhead = fecHeaderSize + 2
+
+	fecTypeData   = 0xf1
+	fecTypeParity = 0xf2
+
+	// maxFECGroups is the number of incomplete groups kept by a decoder.
+	maxFECGroups = 64
+)
+
+// FECEncoder adds Reed-Solomon parity packets to outgoing packets. Each packet is prefixed with
+// its sequence number and type, and data packets also carry the size of their payload, since
+// shards of a group are padded to the same size.
+type FECEncoder struct {
+	codec   *reedSolomon
+	shards  [][]byte
+	packets [][]byte
+	next    uint32
+	count   int
+	maxSize int
+}
+
+func NewFECEncoder(dataShards, parityShards int) (*FECEncoder, error) {
+	codec, err := newReedSolomon(dataShards, parityShards)
+	if err != nil {
+		return nil, err
+	}
+	return &FECEncoder{
+		codec:  codec,
+		shards: make([][]byte, dataShards+parityShards),
+	}, nil
+}
+
+func (e *FECEncoder) shard(index int, size int) []byte {
+	shard := e.shards[index]
+	if cap(shard) < size {
+		grown := make([]byte, len(shard), size+64)
+		copy(grown, shard)
+		shard = grown
+	}
+	e.shards[index] = shard[:size]
+	return e.shards[index]
+}
+
+func (e *FECEncoder) header(b []byte, packetType uint16) {
+	binary.BigEndian.PutUint32(b, e.next)
+	binary.BigEndian.PutUint16(b[4:], packetType)
+	e.next++
+}
+
+// Encode returns the packets to send for payload, which are the data packet, followed by the
+// parity packets if payload completes a group. The packets are valid until the next call.
+func (e *FECEncoder) Encode(payload []byte) [][]byte {
+	total := e.codec.dataShards + e.codec.parityShards
+	if e.count == 0 && e.next > math.MaxUint32-uint32(total) {
+		// Keep groups aligned when the sequence number wraps.
+		e.next = 0
+	}
+
+	data := e.shard(e.count, fecOverhead+len(payload))
+	e.header(data, fecTypeData)
+	binary.BigEndian.PutUint16(data[fecHeaderSize:], uint16(2+len(payload)))
+	copy(data[fecOverhead:], payload)
+	e.packets = append(e.packets[:0], data)
+
+	e.count++
+	if size := len(data) - fecHeaderSize; size > e.maxSize {
+		e.maxSize = size
+	}
+	if e.count < e.codec.dataShards {
+		return e.packets
+	}
+
+	bodies := make([][]byte, total)
+	for i := 0; i < e.codec.dataShards; i++ {
+		shard := e.shards[i]
+		padded := e.shard(i, fecHeaderSize+e.maxSize)
+		for j := len(shard); j < len(padded); j++ {
+			padded[j] = 0
+		}
+		bodies[i] = padded[fecHeaderSize:]
+	}
+	for i := e.codec.dataShards; i < total; i++ {
+		parity := e.shard(i, fecHeaderSize+e.maxSize)
+		e.header(parity, fecTypeParity)
+		bodies[i] = parity[fecHeaderSize:]
+		e.packets = append(e.packets, parity)
+	}
+	e.codec.encode(bodies)
+
+	e.count = 0
+	e.maxSize = 0
+	return e.packets
+}
+
+type fecGroup struct {
+	shards   [][]byte
+	received int
+	done     bool
+}
+
+// FECDecoder extracts payloads from packets encoded by FECEncoder, and recovers lost data packets
+// of a group once enough packets of the group are received.
+type FECDecoder struct {
+	codec  *reedSolomon
+	groups map[uint32]*fecGroup
+	order  []uint32
+}
+
+func NewFECDecoder(dataShards, parityShards int) (*FECDecoder, error) {
+	codec, err := newReedSolomon(dataShards, parityShards)
+	if err != nil {
+		return nil, err
+	}
+	return &FECDecoder{
+		codec:  codec,
+		groups: make(map[uint32]*fecGroup),
+	}, nil
+}
+
+func (d *FECDecoder) group(id uint32) *fecGroup {
+	if g, found := d.groups[id]; found {
+		return g
+	}
+
+	g := &fecGroup{
+		shards: make([][]byte, d.codec.dataShards+d.codec.parityShards),
+	}
+	d.groups[id] = g
+	d.order = append(d.order, id)
+	if len(d.order) > maxFECGroups {
+		delete(d.groups, d.order[0])
+		d.order = d.order[1:]
+	}
+	return g
+}
+
+// fecPayload returns the payload in the body of a data packet.
+func fecPayload(body []byte) ([]byte, bool) {
+	size := int(binary.BigEndian.Uint16(body))
+	if size < 2 || size > len(body) {
+		return nil, false
+	}
+	return body[2:size], true
+}
+
+// Decode returns the payload in packet, and the payloads recovered with it. It returns false if
+// packet is invalid. Parity packets, and data packets without payload, return no payloads unless
+// they complete a recovery.
+func (d *FECDecoder) Decode(packet []byte) ([][]byte, bool) {
+	if len(packet) < fecOverhead {
+		return nil, false
+	}
+	seq := binary.BigEndian.Uint32(packet)
+	packetType := binary.BigEndian.Uint16(packet[4:])
+	body := packet[fecHeaderSize:]
+
+	var result [][]byte
+	switch packetType {
+	case fecTypeData:
+		payload, ok := fecPayload(body)
+		if !ok {
+			return nil, false
+		}
+		result = append(result, payload)
+	case fecTypeParity:
+	default:
+		return nil, false
+	}
+
+	total := uint32(len(d.codec.matrix))
+	g := d.group(seq / total)
+	index := seq % total
+	if g.done || g.shards[index] != nil {
+		return result, true
+	}
+	g.shards[index] = append([]byte(nil), body...)
+	g.received++
+	if g.received < d.codec.dataShards {
+		return result, true
+	}
+
+	g.done = true
+	shards := g.shards
+	g.shards = nil
+	return append(result, d.recover(shards)...), true
+}
+
+// recover returns the payloads of missing data shards in a group.
+func (d *FECDecoder) recover(shards [][]byte) [][]byte {
+	var missing []int
+	for i := 0; i < d.codec.dataShards; i++ {
+		if shards[i] == nil {
+			missing = append(missing, i)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	size := 0
+	for _, shard := range shards[d.codec.dataShards:] {
+		if shard != nil {
+			size = len(shard)
+			break
+		}
+	}
+	for i, shard := range shards {
+		switch {
+		case shard == nil:
+		case len(shard) > size || (i >= d.codec.dataShards && len(shard) != size):
+			return nil
+		case len(shard) < size:
+			shards[i] = append(shard, make([]byte, size-len(shard))...)
+		}
+	}
+	if err := d.codec.reconstruct(shards); err != nil {
+		return nil
+	}
+
+	var result [][]byte
+	for _, i := range missing {
+		if payload, ok := fecPayload(shards[i]); ok {
+			result = append(result, payload)
+		}
+	}
+	return result
+}
@@ -0,0 +1,53 @@
+package kcp_test
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/v2fly/v2ray-core/v5/common"
+	. "github.com/v2fly/v2ray-core/v5/transport/internet/kcp"
+)
+
+func TestFECRecovery(t *testing.T) {
+	encoder, err := NewFECEncoder(4, 2)
+	common.Must(err)
+	decoder, err := NewFECDecoder(4, 2)
+	common.Must(err)
+
+	var expected []string
+	var received []string
+	for i := 0; i < 12; i++ {
+		payload := make([]byte, 100+i*37)
+		common.Must2(rand.Read(payload))
+		expected = append(expected, string(payload))
+
+		for j, packet := range encoder.Encode(payload) {
+			// Lose two data packets of each group, which are recovered by the parity packets.
+			if j == 0 && (i%4 == 0 || i%4 == 2) {
+				continue
+			}
+			payloads, ok := decoder.Decode(append([]byte(nil), packet...))
+			if !ok {
+				t.Fatal("failed to decode packet ", j, " of payload ", i)
+			}
+			for _, p := range payloads {
+				received = append(received, string(p))
+			}
+		}
+	}
+
+	if r := cmp.Diff(received, expected, cmpopts.SortSlices(func(a, b string) bool { return a < b })); r != "" {
+		t.Error(r)
+	}
+}
+
+func TestFECInvalidShards(t *testing.T) {
+	for _, shards := range [][2]int{{0, 1}, {1, 0}, {200, 57}} {
+		if _, err := NewFECEncoder(shards[0], shards[1]); err == nil {
+			t.Error("expected error for shards ", shards)
+		}
+	}
+}
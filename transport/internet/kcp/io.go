@@ -22,8 +22,10 @@ type PacketWriter interface {
 type KCPPacketReader struct { // nolint: revive
 	Security cipher.AEAD
 	Header   internet.PacketHeader
+	FEC      *FECDecoder
 }
 
+// Read returns the segments in b, or nil if b is not a valid packet.
 func (r *KCPPacketReader) Read(b []byte) []Segment {
 	if r.Header != nil {
 		if int32(len(b)) <= r.Header.Size() {
@@ -43,6 +45,22 @@ func (r *KCPPacketReader) Read(b []byte) []Segment {
 		}
 		b = out
 	}
+	if r.FEC != nil {
+		payloads, ok := r.FEC.Decode(b)
+		if !ok {
+			return nil
+		}
+		// Packets kept by the decoder for recovery are valid without segments.
+		result := []Segment{}
+		for _, payload := range payloads {
+			result = append(result, readSegments(payload)...)
+		}
+		return result
+	}
+	return readSegments(b)
+}
+
+func readSegments(b []byte) []Segment {
 	var result []Segment
 	for len(b) > 0 {
 		seg, x := ReadSegment(b)
@@ -58,6 +76,7 @@ func (r *KCPPacketReader) Read(b []byte) []Segment {
 type KCPPacketWriter struct { // nolint: revive
 	Header   internet.PacketHeader
 	Security cipher.AEAD
+	FEC      *FECEncoder
 	Writer   io.Writer
 }
 
@@ -69,10 +88,25 @@ func (w *KCPPacketWriter) Overhead() int {
 	if w.Security != nil {
 		overhead += w.Security.Overhead()
 	}
+	if w.FEC != nil {
+		overhead += fecOverhead
+	}
 	return overhead
 }
 
 func (w *KCPPacketWriter) Write(b []byte) (int, error) {
+	if w.FEC == nil {
+		return w.write(b)
+	}
+	for _, packet := range w.FEC.Encode(b) {
+		if _, err := w.write(packet); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+func (w *KCPPacketWriter) write(b []byte) (int, error) {
 	bb := buf.StackNew()
 	defer bb.Release()
 
@@ -3,6 +3,7 @@ package kcp_test
 import (
 	"testing"
 
+	"github.com/v2fly/v2ray-core/v5/common"
 	. "github.com/v2fly/v2ray-core/v5/transport/internet/kcp"
 )
 
@@ -34,3 +35,23 @@ func TestKCPPacketReader(t *testing.T) {
 		}
 	}
 }
+
+func TestKCPPacketReaderWithFEC(t *testing.T) {
+	encoder, err := NewFECEncoder(2, 1)
+	common.Must(err)
+	decoder, err := NewFECDecoder(2, 1)
+	common.Must(err)
+	reader := KCPPacketReader{
+		FEC: decoder,
+	}
+
+	encoder.Encode([]byte{})
+	packets := encoder.Encode([]byte{})
+	// The parity packet is kept for recovery, which is valid without segments.
+	if seg := reader.Read(packets[len(packets)-1]); seg == nil || len(seg) != 0 {
+		t.Error("unexpected segments of parity packet: ", seg)
+	}
+	if seg := reader.Read([]byte{1, 2, 3}); seg != nil {
+		t.Error("unexpected segments of invalid packet: ", seg)
+	}
+}
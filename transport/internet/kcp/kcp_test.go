@@ -18,9 +18,23 @@ import (
 )
 
 func TestDialAndListen(t *testing.T) {
+	testDialAndListen(t, &Config{})
+}
+
+func TestDialAndListenWithFECAndBBR(t *testing.T) {
+	testDialAndListen(t, &Config{
+		Fec: &FEC{
+			DataShards:   10,
+			ParityShards: 3,
+		},
+		CongestionControl: CongestionControl_BBR,
+	})
+}
+
+func testDialAndListen(t *testing.T, config *Config) {
 	listener, err := NewListener(context.Background(), net.LocalHostIP, net.Port(0), &internet.MemoryStreamConfig{
 		ProtocolName:     "mkcp",
-		ProtocolSettings: &Config{},
+		ProtocolSettings: config,
 	}, func(conn internet.Connection) {
 		go func(c internet.Connection) {
 			payload := make([]byte, 4096)
@@ -47,7 +61,7 @@ func TestDialAndListen(t *testing.T) {
 		errg.Go(func() error {
 			clientConn, err := DialKCP(context.Background(), net.UDPDestination(net.LocalHostIP, port), &internet.MemoryStreamConfig{
 				ProtocolName:     "mkcp",
-				ProtocolSettings: &Config{},
+				ProtocolSettings: config,
 			})
 			if err != nil {
 				return err
@@ -9,6 +9,7 @@ import (
 	"github.com/v2fly/v2ray-core/v5/common"
 	"github.com/v2fly/v2ray-core/v5/common/buf"
 	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/features/stats"
 	"github.com/v2fly/v2ray-core/v5/transport/internet"
 	"github.com/v2fly/v2ray-core/v5/transport/internet/tls"
 	"github.com/v2fly/v2ray-core/v5/transport/internet/udp"
@@ -31,6 +32,9 @@ type Listener struct {
 	header    internet.PacketHeader
 	security  cipher.AEAD
 	addConn   internet.ConnHandler
+	stats     stats.Manager
+	// readers are the readers of each source with FEC, keyed with zero Conv.
+	readers map[ConnectionID]*KCPPacketReader
 }
 
 func NewListener(ctx context.Context, address net.Address, port net.Port, streamSettings *internet.MemoryStreamConfig, addConn internet.ConnHandler) (*Listener, error) {
@@ -43,6 +47,9 @@ func NewListener(ctx context.Context, address net.Address, port net.Port, stream
 	if err != nil {
 		return nil, newError("failed to create security").Base(err).AtError()
 	}
+	if _, err := kcpSettings.newFECDecoder(); err != nil {
+		return nil, newError("failed to create FEC decoder").Base(err).AtError()
+	}
 	l := &Listener{
 		header:   header,
 		security: security,
@@ -51,8 +58,10 @@ func NewListener(ctx context.Context, address net.Address, port net.Port, stream
 			Security: security,
 		},
 		sessions: make(map[ConnectionID]*Connection),
+		readers:  make(map[ConnectionID]*KCPPacketReader),
 		config:   kcpSettings,
 		addConn:  addConn,
+		stats:    statsManager(ctx, kcpSettings),
 	}
 
 	if config := tls.ConfigFromStreamSettings(streamSettings); config != nil {
@@ -80,14 +89,56 @@ func (l *Listener) handlePackets() {
 	}
 }
 
+// readerOf returns the reader of packets from src. With FEC, each source has its own reader, as
+// the decoder keeps packet groups of the source.
+func (l *Listener) readerOf(src net.Destination) PacketReader {
+	if l.config.GetFec().GetDataShards() == 0 {
+		return l.reader
+	}
+
+	l.Lock()
+	defer l.Unlock()
+
+	key := ConnectionID{Remote: src.Address, Port: src.Port}
+	reader, found := l.readers[key]
+	if !found {
+		decoder, err := l.config.newFECDecoder()
+		common.Must(err)
+		reader = &KCPPacketReader{
+			Header:   l.header,
+			Security: l.security,
+			FEC:      decoder,
+		}
+		l.readers[key] = reader
+	}
+	return reader
+}
+
+// removeReaderWithoutLock removes the reader of a source without connections.
+func (l *Listener) removeReaderWithoutLock(remote net.Address, port net.Port) {
+	for id := range l.sessions {
+		if id.Remote == remote && id.Port == port {
+			return
+		}
+	}
+	delete(l.readers, ConnectionID{Remote: remote, Port: port})
+}
+
 func (l *Listener) OnReceive(payload *buf.Buffer, src net.Destination) {
-	segments := l.reader.Read(payload.Bytes())
+	segments := l.readerOf(src).Read(payload.Bytes())
 	payload.Release()
 
-	if len(segments) == 0 {
+	if segments == nil {
+		l.Lock()
+		l.removeReaderWithoutLock(src.Address, src.Port)
+		l.Unlock()
 		newError("discarding invalid payload from ", src).WriteToLog()
 		return
 	}
+	if len(segments) == 0 {
+		// The packet is kept by the FEC decoder for recovery.
+		return
+	}
 
 	conv := segments[0].Conversation()
 	cmd := segments[0].Command()
@@ -105,6 +156,7 @@ func (l *Listener) OnReceive(payload *buf.Buffer, src net.Destination) {
 
 	if !found {
 		if cmd == CommandTerminate {
+			l.removeReaderWithoutLock(src.Address, src.Port)
 			return
 		}
 		writer := &Writer{
@@ -118,13 +170,17 @@ func (l *Listener) OnReceive(payload *buf.Buffer, src net.Destination) {
 			Port: int(src.Port),
 		}
 		localAddr := l.hub.Addr()
+		encoder, err := l.config.newFECEncoder()
+		common.Must(err)
 		conn = NewConnection(ConnMetadata{
 			LocalAddr:    localAddr,
 			RemoteAddr:   remoteAddr,
 			Conversation: conv,
+			StatsManager: l.stats,
 		}, &KCPPacketWriter{
 			Header:   l.header,
 			Security: l.security,
+			FEC:      encoder,
 			Writer:   writer,
 		}, writer, l.config)
 		var netConn internet.Connection = conn
@@ -141,6 +197,7 @@ func (l *Listener) OnReceive(payload *buf.Buffer, src net.Destination) {
 func (l *Listener) Remove(id ConnectionID) {
 	l.Lock()
 	delete(l.sessions, id)
+	l.removeReaderWithoutLock(id.Remote, id.Port)
 	l.Unlock()
 }
 
@@ -0,0 +1,196 @@
+package kcp
+
+// Reed-Solomon erasure codes over GF(2^8), with the systematic encoding matrix derived from a
+// Vandermonde matrix, so that data shards are sent as is.
+
+const gfPolynomial = 0x11d
+
+var (
+	gfExp [510]byte
+	gfLog [256]byte
+	gfMul [256][256]byte
+)
+
+func init() {
+	x := 1
+	for i := 0; i < 255; i++ {
+		gfExp[i] = byte(x)
+		gfExp[i+255] = byte(x)
+		gfLog[x] = byte(i)
+		x <<= 1
+		if x&0x100 != 0 {
+			x ^= gfPolynomial
+		}
+	}
+	for a := 1; a < 256; a++ {
+		for b := 1; b < 256; b++ {
+			gfMul[a][b] = gfExp[int(gfLog[a])+int(gfLog[b])]
+		}
+	}
+}
+
+// gfInverse returns the multiplicative inverse of a, which must not be 0.
+func gfInverse(a byte) byte {
+	return gfExp[255-int(gfLog[a])]
+}
+
+func gfPow(a byte, n int) byte {
+	if n == 0 {
+		return 1
+	}
+	if a == 0 {
+		return 0
+	}
+	return gfExp[int(gfLog[a])*n%255]
+}
+
+type gfMatrix [][]byte
+
+func newGFMatrix(rows, cols int) gfMatrix {
+	m := make(gfMatrix, rows)
+	for r := range m {
+		m[r] = make([]byte, cols)
+	}
+	return m
+}
+
+func (m gfMatrix) multiply(o gfMatrix) gfMatrix {
+	result := newGFMatrix(len(m), len(o[0]))
+	for r := range result {
+		for c := range result[r] {
+			var v byte
+			for i := range o {
+				v ^= gfMul[m[r][i]][o[i][c]]
+			}
+			result[r][c] = v
+		}
+	}
+	return result
+}
+
+// invert returns the inverse of a square matrix by Gauss-Jordan elimination.
+func (m gfMatrix) invert() (gfMatrix, error) {
+	n := len(m)
+	work := newGFMatrix(n, 2*n)
+	for r := range m {
+		copy(work[r], m[r])
+		work[r][n+r] = 1
+	}
+
+	for c := 0; c < n; c++ {
+		p := c
+		for p < n && work[p][c] == 0 {
+			p++
+		}
+		if p == n {
+			return nil, newError("singular matrix")
+		}
+		work[c], work[p] = work[p], work[c]
+
+		inv := gfInverse(work[c][c])
+		for i := range work[c] {
+			work[c][i] = gfMul[inv][work[c][i]]
+		}
+		for r := 0; r < n; r++ {
+			if r == c || work[r][c] == 0 {
+				continue
+			}
+			f := work[r][c]
+			for i := range work[r] {
+				work[r][i] ^= gfMul[f][work[c][i]]
+			}
+		}
+	}
+
+	result := newGFMatrix(n, n)
+	for r := range result {
+		copy(result[r], work[r][n:])
+	}
+	return result, nil
+}
+
+// combine sets out to the linear combination of inputs with coefficients.
+func combine(out []byte, coefficients []byte, inputs [][]byte) {
+	for i := range out {
+		out[i] = 0
+	}
+	for j, in := range inputs {
+		table := &gfMul[coefficients[j]]
+		for i := range out {
+			out[i] ^= table[in[i]]
+		}
+	}
+}
+
+type reedSolomon struct {
+	dataShards   int
+	parityShards int
+	// matrix encodes data shards into all shards. Its top rows are an identity matrix.
+	matrix gfMatrix
+}
+
+func newReedSolomon(dataShards, parityShards int) (*reedSolomon, error) {
+	if dataShards <= 0 || parityShards <= 0 {
+		return nil, newError("invalid number of shards: ", dataShards, "+", parityShards)
+	}
+	total := dataShards + parityShards
+	if total > 256 {
+		return nil, newError("too many shards: ", total)
+	}
+
+	vandermonde := newGFMatrix(total, dataShards)
+	for r := range vandermonde {
+		for c := range vandermonde[r] {
+			vandermonde[r][c] = gfPow(byte(r), c)
+		}
+	}
+	top, err := vandermonde[:dataShards].invert()
+	if err != nil {
+		return nil, err
+	}
+	return &reedSolomon{
+		dataShards:   dataShards,
+		parityShards: parityShards,
+		matrix:       vandermonde.multiply(top),
+	}, nil
+}
+
+// encode computes the parity shards from the data shards. All shards must be of the same size.
+func (rs *reedSolomon) encode(shards [][]byte) {
+	for p := 0; p < rs.parityShards; p++ {
+		combine(shards[rs.dataShards+p], rs.matrix[rs.dataShards+p], shards[:rs.dataShards])
+	}
+}
+
+// reconstruct recovers missing data shards, which are nil, from any dataShards present shards of
+// the same size. Missing parity shards are not recovered.
+func (rs *reedSolomon) reconstruct(shards [][]byte) error {
+	rows := make(gfMatrix, 0, rs.dataShards)
+	inputs := make([][]byte, 0, rs.dataShards)
+	for i, shard := range shards {
+		if shard == nil {
+			continue
+		}
+		rows = append(rows, rs.matrix[i])
+		inputs = append(inputs, shard)
+		if len(inputs) == rs.dataShards {
+			break
+		}
+	}
+	if len(inputs) < rs.dataShards {
+		return newError("too few shards to reconstruct: ", len(inputs))
+	}
+
+	decode, err := rows.invert()
+	if err != nil {
+		return err
+	}
+	for i := 0; i < rs.dataShards; i++ {
+		if shards[i] != nil {
+			continue
+		}
+		shards[i] = make([]byte, len(inputs[0]))
+		combine(shards[i], decode[i], inputs)
+	}
+	return nil
+}
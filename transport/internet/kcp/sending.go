@@ -95,9 +95,11 @@ func (sw *SendingWindow) Visit(visitor func(seg *DataSegment) bool) {
 	}
 }
 
-func (sw *SendingWindow) Flush(current uint32, rto uint32, maxInFlightSize uint32) {
+// Flush sends segments that are due, and returns the number of segments sent and the number of
+// them retransmitted.
+func (sw *SendingWindow) Flush(current uint32, rto uint32, maxInFlightSize uint32) (uint32, uint32) {
 	if sw.IsEmpty() {
-		return
+		return 0, 0
 	}
 
 	var lost uint32
@@ -126,6 +128,7 @@ func (sw *SendingWindow) Flush(current uint32, rto uint32, maxInFlightSize uint3
 		rate := lost * 100 / sw.totalInFlightSize
 		sw.onPacketLoss(rate)
 	}
+	return inFlightSize, lost
 }
 
 func (sw *SendingWindow) Remove(number uint32) bool {
@@ -162,6 +165,7 @@ type SendingWorker struct {
 	windowSize                 uint32
 	firstUnacknowledgedUpdated bool
 	closed                     bool
+	bbr                        *bbrPacer
 }
 
 func NewSendingWorker(kcp *Connection) *SendingWorker {
@@ -173,6 +177,10 @@ func NewSendingWorker(kcp *Connection) *SendingWorker {
 		windowSize:       kcp.Config.GetSendingBufferSize(),
 	}
 	worker.window = NewSendingWindow(worker, worker.OnPacketLoss)
+	if kcp.Config.CongestionControl == CongestionControl_BBR {
+		tti := kcp.Config.GetTTIValue()
+		worker.bbr = newBBRPacer(float64(kcp.Config.GetSendingInFlightSize())*1000/float64(tti), tti)
+	}
 	return worker
 }
 
@@ -191,7 +199,11 @@ func (w *SendingWorker) ProcessReceivingNext(nextNumber uint32) {
 }
 
 func (w *SendingWorker) ProcessReceivingNextWithoutLock(nextNumber uint32) {
+	size := w.window.Len()
 	w.window.Clear(nextNumber)
+	if w.bbr != nil {
+		w.bbr.OnAck(size - w.window.Len())
+	}
 	w.FindFirstUnacknowledged()
 }
 
@@ -215,6 +227,9 @@ func (w *SendingWorker) processAck(number uint32) bool {
 
 	removed := w.window.Remove(number)
 	if removed {
+		if w.bbr != nil {
+			w.bbr.OnAck(1)
+		}
 		w.FindFirstUnacknowledged()
 	}
 	return removed
@@ -251,8 +266,12 @@ func (w *SendingWorker) ProcessSegment(current uint32, seg *AckSegment, rto uint
 
 	if maxackRemoved {
 		w.window.HandleFastAck(maxack, rto)
-		if current-seg.Timestamp < 10000 {
-			w.conn.roundTrip.Update(current-seg.Timestamp, current)
+		if rtt := current - seg.Timestamp; rtt < 10000 {
+			w.conn.roundTrip.Update(rtt, current)
+			w.conn.stats.setRTT(w.conn.roundTrip.SmoothedTime())
+			if w.bbr != nil {
+				w.bbr.OnRTT(rtt, current)
+			}
 		}
 	}
 }
@@ -288,6 +307,7 @@ func (w *SendingWorker) Write(seg Segment) error {
 }
 
 func (w *SendingWorker) OnPacketLoss(lossRate uint32) {
+	w.conn.stats.setLoss(lossRate)
 	if !w.conn.Config.Congestion || w.conn.roundTrip.Timeout() == 0 {
 		return
 	}
@@ -305,14 +325,7 @@ func (w *SendingWorker) OnPacketLoss(lossRate uint32) {
 	}
 }
 
-func (w *SendingWorker) Flush(current uint32) {
-	w.Lock()
-
-	if w.closed {
-		w.Unlock()
-		return
-	}
-
+func (w *SendingWorker) congestionWindow() uint32 {
 	cwnd := w.conn.Config.GetSendingInFlightSize()
 	if cwnd > w.remoteNextNumber-w.firstUnacknowledged {
 		cwnd = w.remoteNextNumber - w.firstUnacknowledged
@@ -321,10 +334,28 @@ func (w *SendingWorker) Flush(current uint32) {
 		cwnd = w.controlWindow
 	}
 
-	cwnd *= 20 // magic
+	return cwnd * 20 // magic
+}
+
+func (w *SendingWorker) Flush(current uint32) {
+	w.Lock()
 
+	if w.closed {
+		w.Unlock()
+		return
+	}
+
+	var retransmitted uint32
 	if !w.window.IsEmpty() {
-		w.window.Flush(current, w.conn.roundTrip.Timeout(), cwnd)
+		if w.bbr != nil {
+			if budget := w.bbr.Budget(current); budget > 0 {
+				var sent uint32
+				sent, retransmitted = w.window.Flush(current, w.conn.roundTrip.Timeout(), budget)
+				w.bbr.OnSent(sent)
+			}
+		} else {
+			_, retransmitted = w.window.Flush(current, w.conn.roundTrip.Timeout(), w.congestionWindow())
+		}
 		w.firstUnacknowledgedUpdated = false
 	}
 
@@ -333,6 +364,8 @@ func (w *SendingWorker) Flush(current uint32) {
 
 	w.Unlock()
 
+	w.conn.stats.addRetransmit(retransmitted)
+
 	if updated {
 		w.conn.Ping(current, CommandPing)
 	}
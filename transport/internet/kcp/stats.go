@@ -0,0 +1,89 @@
+package kcp
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	core "github.com/v2fly/v2ray-core/v5"
+	"github.com/v2fly/v2ray-core/v5/features/stats"
+)
+
+// statsManager returns the stats manager of the instance in ctx, or nil if stats are disabled in
+// config.
+func statsManager(ctx context.Context, config *Config) stats.Manager {
+	if !config.Stats {
+		return nil
+	}
+	if v := core.FromContext(ctx); v != nil {
+		if manager, ok := v.GetFeature(stats.ManagerType()).(stats.Manager); ok {
+			return manager
+		}
+	}
+	return nil
+}
+
+// connectionStats holds the counters of a connection in the stats manager, named
+// "mkcp>>>[remote address]/[conversation]>>>[retransmit|rtt|loss]". rtt is the smoothed round
+// trip time in milliseconds, and loss is the loss rate in percentage.
+type connectionStats struct {
+	manager    stats.Manager
+	names      []string
+	released   sync.Once
+	retransmit stats.Counter
+	rtt        stats.Counter
+	loss       stats.Counter
+}
+
+func newConnectionStats(manager stats.Manager, meta ConnMetadata) *connectionStats {
+	if manager == nil {
+		return nil
+	}
+
+	s := &connectionStats{
+		manager: manager,
+	}
+	register := func(name string) stats.Counter {
+		name = fmt.Sprintf("mkcp>>>%s/%d>>>%s", meta.RemoteAddr, meta.Conversation, name)
+		counter, err := manager.RegisterCounter(name)
+		if err != nil {
+			newError("failed to register counter ", name).Base(err).AtDebug().WriteToLog()
+			return nil
+		}
+		s.names = append(s.names, name)
+		return counter
+	}
+	s.retransmit = register("retransmit")
+	s.rtt = register("rtt")
+	s.loss = register("loss")
+	return s
+}
+
+func (s *connectionStats) addRetransmit(n uint32) {
+	if s != nil && s.retransmit != nil && n > 0 {
+		s.retransmit.Add(int64(n))
+	}
+}
+
+func (s *connectionStats) setRTT(rtt uint32) {
+	if s != nil && s.rtt != nil {
+		s.rtt.Set(int64(rtt))
+	}
+}
+
+func (s *connectionStats) setLoss(rate uint32) {
+	if s != nil && s.loss != nil {
+		s.loss.Set(int64(rate))
+	}
+}
+
+func (s *connectionStats) release() {
+	if s == nil {
+		return
+	}
+	s.released.Do(func() {
+		for _, name := range s.names {
+			s.manager.UnregisterCounter(name)
+		}
+	})
+}
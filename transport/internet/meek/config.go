@@ -0,0 +1,50 @@
+package meek
+
+import (
+	"time"
+
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/transport/internet"
+)
+
+const protocolName = "meek"
+
+// GetNormalizedPath returns the path of requests, which starts with "/".
+func (c *Config) GetNormalizedPath() string {
+	path := c.Path
+	if path == "" || path[0] != '/' {
+		path = "/" + path
+	}
+	return path
+}
+
+func (c *Config) GetNormalizedMaxPayloadSize() int32 {
+	if c.MaxPayloadSize <= 0 {
+		return 64 * 1024
+	}
+	return c.MaxPayloadSize
+}
+
+func (c *Config) GetNormalizedMinPollInterval() time.Duration {
+	if c.MinPollInterval == 0 {
+		return time.Millisecond * 50
+	}
+	return time.Duration(c.MinPollInterval) * time.Millisecond
+}
+
+func (c *Config) GetNormalizedMaxPollInterval() time.Duration {
+	interval := time.Second * 5
+	if c.MaxPollInterval != 0 {
+		interval = time.Duration(c.MaxPollInterval) * time.Millisecond
+	}
+	if min := c.GetNormalizedMinPollInterval(); interval < min {
+		return min
+	}
+	return interval
+}
+
+func init() {
+	common.Must(internet.RegisterProtocolConfigCreator(protocolName, func() interface{} {
+		return new(Config)
+	}))
+}
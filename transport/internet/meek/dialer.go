@@ -0,0 +1,249 @@
+package meek
+
+import (
+	"bytes"
+	"context"
+	gotls "crypto/tls"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	core "github.com/v2fly/v2ray-core/v5"
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/buf"
+	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/common/session"
+	"github.com/v2fly/v2ray-core/v5/common/uuid"
+	"github.com/v2fly/v2ray-core/v5/features/extension"
+	"github.com/v2fly/v2ray-core/v5/transport/internet"
+	"github.com/v2fly/v2ray-core/v5/transport/internet/tls"
+	"github.com/v2fly/v2ray-core/v5/transport/pipe"
+)
+
+type dialerKey struct {
+	dest           net.Destination
+	streamSettings *internet.MemoryStreamConfig
+}
+
+var (
+	globalDialerMap    map[dialerKey]*http.Client
+	globalDialerAccess sync.Mutex
+)
+
+func getHTTPClient(ctx context.Context, dest net.Destination, streamSettings *internet.MemoryStreamConfig) *http.Client {
+	globalDialerAccess.Lock()
+	defer globalDialerAccess.Unlock()
+
+	if globalDialerMap == nil {
+		globalDialerMap = make(map[dialerKey]*http.Client)
+	}
+	key := dialerKey{dest: dest, streamSettings: streamSettings}
+	if client, found := globalDialerMap[key]; found {
+		return client
+	}
+
+	tlsConfig := tls.ConfigFromStreamSettings(streamSettings)
+	detachedContext := core.ToBackgroundDetachedContext(ctx)
+	dial := func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := internet.DialSystem(detachedContext, dest, streamSettings.SocketSettings)
+		if err != nil {
+			return nil, err
+		}
+		if tlsConfig == nil {
+			return conn, nil
+		}
+		tlsConn := gotls.Client(conn, tlsConfig.GetTLSConfig(tls.WithDestination(dest), tls.WithNextProto("http/1.1")))
+		if err := tls.Handshake(ctx, tlsConn); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		return tlsConn, nil
+	}
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			DialContext:        dial,
+			DialTLSContext:     dial,
+			DisableCompression: true,
+		},
+	}
+	globalDialerMap[key] = client
+	return client
+}
+
+// Dial dials a new meek connection to the given destination.
+func Dial(ctx context.Context, dest net.Destination, streamSettings *internet.MemoryStreamConfig) (internet.Connection, error) {
+	newError("creating connection to ", dest).WriteToLog(session.ExportIDToError(ctx))
+
+	config := streamSettings.ProtocolSettings.(*Config)
+	scheme := "http"
+	if tls.ConfigFromStreamSettings(streamSettings) != nil {
+		scheme = "https"
+	}
+	host := config.Host
+	if host == "" {
+		host = dest.NetAddr()
+	}
+	sessionID := uuid.New()
+	requestURL := url.URL{
+		Scheme:   scheme,
+		Host:     dest.NetAddr(),
+		Path:     config.GetNormalizedPath(),
+		RawQuery: url.Values{"session": {sessionID.String()}}.Encode(),
+	}
+
+	var client *http.Client
+	if config.UseBrowserForwarding {
+		var forwarder extension.BrowserForwarder
+		err := core.RequireFeatures(ctx, func(Forwarder extension.BrowserForwarder) {
+			forwarder = Forwarder
+		})
+		if err != nil {
+			return nil, newError("cannot find browser forwarder service").Base(err)
+		}
+		client = &http.Client{Transport: forwarder}
+		// Browsers send requests to the host of the URL, and do not allow to change the Host header.
+		requestURL.Host = host
+	} else {
+		client = getHTTPClient(ctx, dest, streamSettings)
+	}
+
+	maxPayloadSize := config.GetNormalizedMaxPayloadSize()
+	uplinkReader, uplinkWriter := pipe.New(pipe.WithSizeLimit(maxPayloadSize))
+	downlinkReader, downlinkWriter := pipe.New(pipe.WithSizeLimit(maxPayloadSize))
+	p := &poller{
+		client:      client,
+		url:         requestURL.String(),
+		host:        host,
+		maxSize:     maxPayloadSize,
+		minInterval: config.GetNormalizedMinPollInterval(),
+		maxInterval: config.GetNormalizedMaxPollInterval(),
+		uplink:      uplinkReader,
+		downlink:    downlinkWriter,
+	}
+	go func() {
+		connCtx, cancel := context.WithCancel(core.ToBackgroundDetachedContext(ctx))
+		defer cancel()
+		if err := p.run(connCtx); err != nil {
+			newError("failed to poll ", dest).Base(err).WriteToLog(session.ExportIDToError(ctx))
+			uplinkReader.Interrupt()
+			downlinkWriter.Interrupt()
+			return
+		}
+		downlinkWriter.Close()
+	}()
+
+	return buf.NewConnection(
+		buf.ConnectionInputMulti(uplinkWriter),
+		buf.ConnectionOutputMulti(downlinkReader),
+		buf.ConnectionOnClose(common.ChainedClosable{uplinkWriter, closerFunc(downlinkReader.Interrupt)}),
+	), nil
+}
+
+func init() {
+	common.Must(internet.RegisterTransportDialer(protocolName, Dial))
+}
+
+type closerFunc func()
+
+func (f closerFunc) Close() error {
+	f()
+	return nil
+}
+
+// poller sends the uplink of a connection in sequential requests, and writes the responses to the
+// downlink.
+type poller struct {
+	client      *http.Client
+	url         string
+	host        string
+	maxSize     int32
+	minInterval time.Duration
+	maxInterval time.Duration
+	uplink      *pipe.Reader
+	downlink    *pipe.Writer
+}
+
+// run polls until either side of the connection closes. Idle connections are polled at intervals
+// growing from minInterval to maxInterval, which are reset by any data.
+func (p *poller) run(ctx context.Context) error {
+	interval := p.minInterval
+	var pending buf.MultiBuffer
+	defer func() {
+		buf.ReleaseMulti(pending)
+	}()
+
+	for {
+		closing := false
+		if pending.IsEmpty() {
+			mb, err := p.uplink.ReadMultiBufferTimeout(interval)
+			switch {
+			case err == buf.ErrReadTimeout:
+			case err != nil:
+				closing = true
+			default:
+				pending = mb
+			}
+		}
+
+		var chunk buf.MultiBuffer
+		pending, chunk = buf.SplitSize(pending, p.maxSize)
+		payload := make([]byte, chunk.Len())
+		chunk.Copy(payload)
+		buf.ReleaseMulti(chunk)
+
+		data, closed, err := p.poll(ctx, payload, closing)
+		if err != nil || closing || closed {
+			return err
+		}
+		if len(data) > 0 {
+			if err := p.downlink.WriteMultiBuffer(buf.MergeBytes(nil, data)); err != nil {
+				_, _, err := p.poll(ctx, nil, true)
+				return err
+			}
+		}
+
+		if len(payload) > 0 || len(data) > 0 {
+			interval = p.minInterval
+		} else if interval = interval * 3 / 2; interval > p.maxInterval {
+			interval = p.maxInterval
+		}
+	}
+}
+
+// poll sends a request with the payload, and returns the downlink data in the response, or
+// whether the server has closed the connection.
+func (p *poller) poll(ctx context.Context, payload []byte, closing bool) ([]byte, bool, error) {
+	requestURL := p.url
+	if closing {
+		requestURL += "&close=1"
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, requestURL, bytes.NewReader(payload))
+	common.Must(err)
+	request.Host = p.host
+	request.ContentLength = int64(len(payload))
+
+	response, err := p.client.Do(request)
+	if err != nil {
+		return nil, false, err
+	}
+	defer response.Body.Close()
+
+	switch response.StatusCode {
+	case http.StatusOK:
+	case http.StatusGone:
+		return nil, true, nil
+	default:
+		return nil, false, newError("unexpected status: ", response.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(response.Body, int64(p.maxSize)+1))
+	if err != nil {
+		return nil, false, err
+	}
+	if len(data) > int(p.maxSize) {
+		return nil, false, newError("response too large")
+	}
+	return data, false, nil
+}
@@ -0,0 +1,340 @@
+package meek
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/buf"
+	"github.com/v2fly/v2ray-core/v5/common/net"
+	http_proto "github.com/v2fly/v2ray-core/v5/common/protocol/http"
+	"github.com/v2fly/v2ray-core/v5/common/session"
+	"github.com/v2fly/v2ray-core/v5/common/signal"
+	"github.com/v2fly/v2ray-core/v5/transport/internet"
+	"github.com/v2fly/v2ray-core/v5/transport/internet/tls"
+	"github.com/v2fly/v2ray-core/v5/transport/pipe"
+)
+
+const (
+	// sessionTimeout is how long a session waits for its next request.
+	sessionTimeout = time.Second * 30
+	// responseWait is how long a request waits for downlink data, so that responses to uplink
+	// data can carry the reply.
+	responseWait = time.Millisecond * 50
+	// maxPendingSessions is the number of sessions without any downlink data. Requests of further
+	// new sessions are rejected.
+	maxPendingSessions = 1024
+	// maxBufferedBytes is the size of request payloads being handled by all sessions of a
+	// listener. Requests are rejected while the buffers are full.
+	maxBufferedBytes = 128 * 1024 * 1024
+)
+
+type meekSession struct {
+	// access serializes the requests of the session.
+	access   sync.Mutex
+	uplink   *pipe.Writer
+	downlink *pipe.Reader
+	pending  buf.MultiBuffer
+	activity *signal.ActivityTimer
+	// answered is whether the session has sent downlink data, guarded by the access of the listener.
+	answered bool
+}
+
+// read returns the downlink data of the next response, or whether the connection is closed.
+func (s *meekSession) read(maxSize int32) ([]byte, bool) {
+	if s.pending.IsEmpty() {
+		mb, err := s.downlink.ReadMultiBufferTimeout(responseWait)
+		if err != nil && err != buf.ErrReadTimeout {
+			return nil, true
+		}
+		s.pending = mb
+	}
+
+	var chunk buf.MultiBuffer
+	s.pending, chunk = buf.SplitSize(s.pending, maxSize)
+	data := make([]byte, chunk.Len())
+	chunk.Copy(data)
+	buf.ReleaseMulti(chunk)
+	return data, false
+}
+
+type Listener struct {
+	access   sync.Mutex
+	sessions map[string]*meekSession
+	// pending is the number of sessions without any downlink data.
+	pending int
+	// buffered is the size of request payloads being handled.
+	buffered int64
+
+	server   *http.Server
+	listener net.Listener
+	config   *Config
+	addConn  internet.ConnHandler
+	locker   *internet.FileLocker // for unix domain socket
+}
+
+// getOrCreateSession returns the session of the ID, or creates one. It returns nil if the
+// session is new, and too many sessions are without downlink data.
+func (l *Listener) getOrCreateSession(id string, request *http.Request) *meekSession {
+	l.access.Lock()
+	if s, found := l.sessions[id]; found {
+		l.access.Unlock()
+		return s
+	}
+	if l.pending >= maxPendingSessions {
+		l.access.Unlock()
+		return nil
+	}
+
+	maxPayloadSize := l.config.GetNormalizedMaxPayloadSize()
+	uplinkReader, uplinkWriter := pipe.New(pipe.WithSizeLimit(maxPayloadSize))
+	downlinkReader, downlinkWriter := pipe.New(pipe.WithSizeLimit(maxPayloadSize))
+	s := &meekSession{
+		uplink:   uplinkWriter,
+		downlink: downlinkReader,
+	}
+	s.activity = signal.CancelAfterInactivity(context.Background(), func() {
+		l.removeSession(id, s)
+	}, sessionTimeout)
+	l.sessions[id] = s
+	l.pending++
+	l.access.Unlock()
+
+	l.addConn(buf.NewConnection(
+		buf.ConnectionOutputMulti(uplinkReader),
+		buf.ConnectionInputMulti(downlinkWriter),
+		buf.ConnectionOnClose(common.ChainedClosable{downlinkWriter, closerFunc(uplinkReader.Interrupt)}),
+		buf.ConnectionLocalAddr(l.Addr()),
+		buf.ConnectionRemoteAddr(l.remoteAddr(request)),
+	))
+	return s
+}
+
+func (l *Listener) removeSession(id string, s *meekSession) {
+	l.access.Lock()
+	if l.sessions[id] == s {
+		delete(l.sessions, id)
+		if !s.answered {
+			l.pending--
+		}
+	}
+	l.access.Unlock()
+	s.uplink.Close()
+	s.downlink.Interrupt()
+}
+
+// answerSession marks the session as having sent downlink data.
+func (l *Listener) answerSession(id string, s *meekSession) {
+	l.access.Lock()
+	defer l.access.Unlock()
+
+	if !s.answered && l.sessions[id] == s {
+		s.answered = true
+		l.pending--
+	}
+}
+
+// reserveBuffer reserves the size of a payload in the buffers, and returns false if they are full.
+func (l *Listener) reserveBuffer(size int64) bool {
+	l.access.Lock()
+	defer l.access.Unlock()
+
+	if l.buffered+size > maxBufferedBytes {
+		return false
+	}
+	l.buffered += size
+	return true
+}
+
+func (l *Listener) releaseBuffer(size int64) {
+	l.access.Lock()
+	l.buffered -= size
+	l.access.Unlock()
+}
+
+func (l *Listener) remoteAddr(request *http.Request) net.Addr {
+	remoteAddr := l.Addr()
+	if dest, err := net.ParseDestination(request.RemoteAddr); err == nil {
+		remoteAddr = &net.TCPAddr{
+			IP:   dest.Address.IP(),
+			Port: int(dest.Port),
+		}
+	}
+	forwardedAddrs := http_proto.ParseXForwardedFor(request.Header)
+	if len(forwardedAddrs) > 0 && forwardedAddrs[0].Family().IsIP() {
+		remoteAddr = &net.TCPAddr{
+			IP:   forwardedAddrs[0].IP(),
+			Port: 0,
+		}
+	}
+	return remoteAddr
+}
+
+func (l *Listener) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	if l.config.Host != "" && !strings.EqualFold(request.Host, l.config.Host) {
+		writer.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if request.URL.Path != l.config.GetNormalizedPath() {
+		writer.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	// Browsers only expose responses of cross-origin requests allowed by CORS.
+	writer.Header().Set("Access-Control-Allow-Origin", "*")
+	switch request.Method {
+	case http.MethodOptions:
+		writer.Header().Set("Access-Control-Allow-Methods", http.MethodPost)
+		writer.WriteHeader(http.StatusNoContent)
+		return
+	case http.MethodPost:
+	default:
+		writer.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	query := request.URL.Query()
+	sessionID := query.Get("session")
+	if sessionID == "" {
+		writer.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	// The maximum size is reserved before reading, and released when the request ends. Payloads
+	// in the uplinks are limited by each session.
+	maxPayloadSize := l.config.GetNormalizedMaxPayloadSize()
+	if !l.reserveBuffer(int64(maxPayloadSize)) {
+		newError("request buffers are full, rejecting request of session ", sessionID).AtWarning().WriteToLog()
+		writer.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	defer l.releaseBuffer(int64(maxPayloadSize))
+	payload, err := io.ReadAll(io.LimitReader(request.Body, int64(maxPayloadSize)+1))
+	if err != nil {
+		newError("failed to read request of session ", sessionID).Base(err).WriteToLog()
+		writer.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if len(payload) > int(maxPayloadSize) {
+		writer.WriteHeader(http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	s := l.getOrCreateSession(sessionID, request)
+	if s == nil {
+		newError("too many pending sessions, rejecting request of session ", sessionID).AtWarning().WriteToLog()
+		writer.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	s.access.Lock()
+	defer s.access.Unlock()
+	s.activity.Update()
+
+	writer.Header().Set("Cache-Control", "no-store")
+	if len(payload) > 0 {
+		if err := s.uplink.WriteMultiBuffer(buf.MergeBytes(nil, payload)); err != nil {
+			l.removeSession(sessionID, s)
+			writer.WriteHeader(http.StatusGone)
+			return
+		}
+	}
+	if query.Get("close") != "" {
+		l.removeSession(sessionID, s)
+		writer.WriteHeader(http.StatusOK)
+		return
+	}
+
+	data, closed := s.read(maxPayloadSize)
+	if closed {
+		l.removeSession(sessionID, s)
+		writer.WriteHeader(http.StatusGone)
+		return
+	}
+	if len(data) > 0 {
+		l.answerSession(sessionID, s)
+	}
+	writer.WriteHeader(http.StatusOK)
+	writer.Write(data)
+}
+
+func ListenMeek(ctx context.Context, address net.Address, port net.Port, streamSettings *internet.MemoryStreamConfig, addConn internet.ConnHandler) (internet.Listener, error) {
+	l := &Listener{
+		sessions: make(map[string]*meekSession),
+		config:   streamSettings.ProtocolSettings.(*Config),
+		addConn:  addConn,
+	}
+
+	var listener net.Listener
+	var err error
+	if port == net.Port(0) { // unix
+		listener, err = internet.ListenSystem(ctx, &net.UnixAddr{
+			Name: address.Domain(),
+			Net:  "unix",
+		}, streamSettings.SocketSettings)
+		if err != nil {
+			return nil, newError("failed to listen unix domain socket(for meek) on ", address).Base(err)
+		}
+		newError("listening unix domain socket(for meek) on ", address).WriteToLog(session.ExportIDToError(ctx))
+		locker := ctx.Value(address.Domain())
+		if locker != nil {
+			l.locker = locker.(*internet.FileLocker)
+		}
+	} else { // tcp
+		listener, err = internet.ListenSystem(ctx, &net.TCPAddr{
+			IP:   address.IP(),
+			Port: int(port),
+		}, streamSettings.SocketSettings)
+		if err != nil {
+			return nil, newError("failed to listen TCP(for meek) on ", address, ":", port).Base(err)
+		}
+		newError("listening TCP(for meek) on ", address, ":", port).WriteToLog(session.ExportIDToError(ctx))
+	}
+
+	if streamSettings.SocketSettings != nil && streamSettings.SocketSettings.AcceptProxyProtocol {
+		newError("accepting PROXY protocol").AtWarning().WriteToLog(session.ExportIDToError(ctx))
+	}
+
+	l.listener = listener
+	l.server = &http.Server{
+		Handler:           l,
+		ReadHeaderTimeout: time.Second * 4,
+	}
+	config := tls.ConfigFromStreamSettings(streamSettings)
+	if config != nil {
+		l.server.TLSConfig = config.GetTLSConfig(tls.WithNextProto("h2", "http/1.1"))
+	}
+
+	go func() {
+		var err error
+		if config == nil {
+			err = l.server.Serve(listener)
+		} else {
+			err = l.server.ServeTLS(listener, "", "")
+		}
+		if err != nil {
+			newError("failed to serve http for meek").Base(err).AtWarning().WriteToLog(session.ExportIDToError(ctx))
+		}
+	}()
+
+	return l, nil
+}
+
+// Addr implements net.Listener.Addr().
+func (l *Listener) Addr() net.Addr {
+	return l.listener.Addr()
+}
+
+// Close implements net.Listener.Close().
+func (l *Listener) Close() error {
+	if l.locker != nil {
+		l.locker.Release()
+	}
+	return l.server.Close()
+}
+
+func init() {
+	common.Must(internet.RegisterTransportListener(protocolName, ListenMeek))
+}
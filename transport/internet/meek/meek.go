@@ -0,0 +1,11 @@
+/*
+Package meek implements meek transport
+
+Meek transport carries each connection as a sequence of plain HTTP POST requests. Each request
+carries a chunk of the uplink in its body, and receives the downlink available on the server in
+its response. Clients poll idle connections at increasing intervals, so that the transport works
+where only plain HTTPS fetches are allowed, including through the browser forwarder.
+*/
+package meek
+
+//go:generate go run github.com/v2fly/v2ray-core/v5/common/errors/errorgen
@@ -0,0 +1,160 @@
+package meek_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/common/protocol/tls/cert"
+	"github.com/v2fly/v2ray-core/v5/testing/servers/tcp"
+	"github.com/v2fly/v2ray-core/v5/transport/internet"
+	. "github.com/v2fly/v2ray-core/v5/transport/internet/meek"
+	"github.com/v2fly/v2ray-core/v5/transport/internet/tls"
+)
+
+func testEcho(t *testing.T, streamSettings *internet.MemoryStreamConfig) {
+	port := tcp.PickPort()
+	listen, err := ListenMeek(context.Background(), net.LocalHostIP, port, streamSettings, func(conn internet.Connection) {
+		go func(c internet.Connection) {
+			defer c.Close()
+			io.Copy(c, c)
+		}(conn)
+	})
+	common.Must(err)
+	defer listen.Close()
+
+	conn, err := Dial(context.Background(), net.TCPDestination(net.DomainAddress("localhost"), port), streamSettings)
+	common.Must(err)
+	defer conn.Close()
+
+	payload := make([]byte, 256*1024)
+	common.Must2(rand.Read(payload))
+	go func() {
+		for i := 0; i < len(payload); i += 8192 {
+			if _, err := conn.Write(payload[i : i+8192]); err != nil {
+				return
+			}
+		}
+	}()
+
+	response := make([]byte, len(payload))
+	common.Must2(io.ReadFull(conn, response))
+	if !bytes.Equal(payload, response) {
+		t.Error("response mismatch")
+	}
+}
+
+func TestMeek(t *testing.T) {
+	testEcho(t, &internet.MemoryStreamConfig{
+		ProtocolName: "meek",
+		ProtocolSettings: &Config{
+			Path:           "meek",
+			MaxPayloadSize: 16 * 1024,
+		},
+	})
+}
+
+func TestMeekWithTLS(t *testing.T) {
+	testEcho(t, &internet.MemoryStreamConfig{
+		ProtocolName: "meek",
+		ProtocolSettings: &Config{
+			Host: "www.v2fly.org",
+		},
+		SecurityType: "tls",
+		SecuritySettings: &tls.Config{
+			AllowInsecure: true,
+			ServerName:    "www.v2fly.org",
+			Certificate:   []*tls.Certificate{tls.ParseCertificate(cert.MustGenerate(nil, cert.DNSNames("www.v2fly.org")))},
+		},
+	})
+}
+
+func TestMeekIdle(t *testing.T) {
+	streamSettings := &internet.MemoryStreamConfig{
+		ProtocolName: "meek",
+		ProtocolSettings: &Config{
+			MinPollInterval: 10,
+			MaxPollInterval: 100,
+		},
+	}
+	port := tcp.PickPort()
+	listen, err := ListenMeek(context.Background(), net.LocalHostIP, port, streamSettings, func(conn internet.Connection) {
+		go func(c internet.Connection) {
+			defer c.Close()
+			// Reply after the client has backed off to the maximum interval.
+			time.Sleep(time.Millisecond * 500)
+			common.Must2(c.Write([]byte("late reply")))
+		}(conn)
+	})
+	common.Must(err)
+	defer listen.Close()
+
+	conn, err := Dial(context.Background(), net.TCPDestination(net.LocalHostIP, port), streamSettings)
+	common.Must(err)
+	defer conn.Close()
+	common.Must2(conn.Write([]byte("hello")))
+
+	b := make([]byte, 16)
+	n, err := io.ReadFull(conn, b[:10])
+	common.Must(err)
+	if string(b[:n]) != "late reply" {
+		t.Error("unexpected response: ", string(b[:n]))
+	}
+	// The connection is closed by the server after the reply.
+	if _, err := conn.Read(b); err != io.EOF {
+		t.Error("expected EOF, got ", err)
+	}
+}
+
+func TestPendingSessionLimit(t *testing.T) {
+	port := tcp.PickPort()
+	listen, err := ListenMeek(context.Background(), net.LocalHostIP, port, &internet.MemoryStreamConfig{
+		ProtocolName:     "meek",
+		ProtocolSettings: &Config{Path: "/meek"},
+	}, func(conn internet.Connection) {
+		// Connections never reply, so their sessions stay pending.
+	})
+	common.Must(err)
+	defer listen.Close()
+
+	request := func(session int) int {
+		url := "http://127.0.0.1:" + port.String() + "/meek?session=" + strconv.Itoa(session)
+		response, err := http.Post(url, "application/octet-stream", strings.NewReader("payload"))
+		common.Must(err)
+		response.Body.Close()
+		return response.StatusCode
+	}
+
+	// Sessions without downlink data are kept until the limit.
+	var errg errgroup.Group
+	for i := 0; i < 1024; i += 64 {
+		i := i
+		errg.Go(func() error {
+			for j := i; j < i+64; j++ {
+				if code := request(j); code != http.StatusOK {
+					return fmt.Errorf("unexpected status of session %d: %d", j, code)
+				}
+			}
+			return nil
+		})
+	}
+	common.Must(errg.Wait())
+	if code := request(1024); code != http.StatusServiceUnavailable {
+		t.Error("expected the session to be rejected, but got ", code)
+	}
+	// Requests of existing sessions are still accepted.
+	if code := request(0); code != http.StatusOK {
+		t.Error("unexpected status of existing session: ", code)
+	}
+}
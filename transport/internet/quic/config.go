@@ -4,7 +4,9 @@ import (
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/sha256"
+	"time"
 
+	"github.com/lucas-clemente/quic-go"
 	"golang.org/x/crypto/chacha20poly1305"
 
 	"github.com/v2fly/v2ray-core/v5/common"
@@ -35,6 +37,23 @@ func getAuth(config *Config) (cipher.AEAD, error) {
 	return nil, newError("unsupported security type")
 }
 
+func getQuicConfig(config *Config, defaultIdleTimeout time.Duration) *quic.Config {
+	quicConfig := &quic.Config{
+		ConnectionIDLength:   12,
+		HandshakeIdleTimeout: time.Second * 8,
+		MaxIdleTimeout:       defaultIdleTimeout,
+		KeepAlive:            !config.DisableKeepAlive,
+		EnableDatagrams:      config.EnableDatagrams,
+	}
+	if config.HandshakeTimeout > 0 {
+		quicConfig.HandshakeIdleTimeout = time.Second * time.Duration(config.HandshakeTimeout)
+	}
+	if config.IdleTimeout > 0 {
+		quicConfig.MaxIdleTimeout = time.Second * time.Duration(config.IdleTimeout)
+	}
+	return quicConfig
+}
+
 func getHeader(config *Config) (internet.PacketHeader, error) {
 	if config.Header == nil {
 		return nil, nil
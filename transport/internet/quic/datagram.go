@@ -0,0 +1,252 @@
+package quic
+
+import (
+	"encoding/binary"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/lucas-clemente/quic-go"
+
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/buf"
+	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/common/signal/done"
+)
+
+const (
+	datagramTypeData  byte = 0
+	datagramTypeClose byte = 1
+
+	datagramQueueSize = 64
+)
+
+// datagramMux carries connections in QUIC DATAGRAM frames on a session. Each datagram starts
+// with its type and the varint ID of its connection. Connections are opened by the client.
+type datagramMux struct {
+	access  sync.Mutex
+	session quic.Session
+	conns   map[uint64]*datagramConn
+	nextID  uint64
+	// onConn handles connections opened by the peer. It is nil on clients.
+	onConn func(*datagramConn)
+}
+
+func newDatagramMux(session quic.Session, onConn func(*datagramConn)) *datagramMux {
+	m := &datagramMux{
+		session: session,
+		conns:   make(map[uint64]*datagramConn),
+		onConn:  onConn,
+	}
+	go m.receive()
+	return m
+}
+
+func (m *datagramMux) newConn(id uint64) *datagramConn {
+	c := &datagramConn{
+		mux:    m,
+		id:     id,
+		queue:  make(chan []byte, datagramQueueSize),
+		done:   done.New(),
+		local:  m.session.LocalAddr(),
+		remote: m.session.RemoteAddr(),
+	}
+	m.conns[id] = c
+	return c
+}
+
+func (m *datagramMux) open() (*datagramConn, error) {
+	if !isActive(m.session) {
+		return nil, errSessionClosed
+	}
+
+	m.access.Lock()
+	defer m.access.Unlock()
+
+	c := m.newConn(m.nextID)
+	m.nextID++
+	return c, nil
+}
+
+func (m *datagramMux) remove(id uint64) {
+	m.access.Lock()
+	delete(m.conns, id)
+	m.access.Unlock()
+}
+
+func (m *datagramMux) send(t byte, id uint64, payload []byte) error {
+	b := buf.New()
+	defer b.Release()
+
+	common.Must(b.WriteByte(t))
+	n := binary.PutUvarint(b.Extend(binary.MaxVarintLen64), id)
+	b.Resize(0, int32(1+n))
+	if _, err := b.Write(payload); err != nil {
+		return err
+	}
+	return m.session.SendMessage(b.Bytes())
+}
+
+func (m *datagramMux) receive() {
+	defer m.closeAll()
+
+	for {
+		data, err := m.session.ReceiveMessage()
+		if err != nil {
+			return
+		}
+		if len(data) < 2 {
+			continue
+		}
+		id, n := binary.Uvarint(data[1:])
+		if n <= 0 {
+			continue
+		}
+		payload := data[1+n:]
+
+		m.access.Lock()
+		c, found := m.conns[id]
+		if !found && data[0] == datagramTypeData && m.onConn != nil {
+			c = m.newConn(id)
+			m.access.Unlock()
+			m.onConn(c)
+		} else {
+			m.access.Unlock()
+		}
+		if c == nil {
+			continue
+		}
+
+		switch data[0] {
+		case datagramTypeData:
+			c.push(payload)
+		case datagramTypeClose:
+			c.closeLocal()
+		}
+	}
+}
+
+func (m *datagramMux) closeAll() {
+	m.access.Lock()
+	conns := make([]*datagramConn, 0, len(m.conns))
+	for _, c := range m.conns {
+		conns = append(conns, c)
+	}
+	m.access.Unlock()
+
+	for _, c := range conns {
+		c.closeLocal()
+	}
+}
+
+// datagramConn is a connection whose writes are carried in individual datagrams. Datagrams
+// may be lost or reordered.
+type datagramConn struct {
+	mux    *datagramMux
+	id     uint64
+	queue  chan []byte
+	done   *done.Instance
+	local  net.Addr
+	remote net.Addr
+}
+
+func (c *datagramConn) push(payload []byte) {
+	if c.done.Done() {
+		return
+	}
+	select {
+	case c.queue <- payload:
+	default:
+		// Drop the datagram if the reader is too slow, like a UDP socket.
+	}
+}
+
+// ReadMultiBuffer implements buf.Reader. Each buffer holds one datagram.
+func (c *datagramConn) ReadMultiBuffer() (buf.MultiBuffer, error) {
+	select {
+	case payload := <-c.queue:
+		b := buf.New()
+		if _, err := b.Write(payload); err != nil {
+			b.Release()
+			return nil, err
+		}
+		return buf.MultiBuffer{b}, nil
+	case <-c.done.Wait():
+		return nil, io.EOF
+	}
+}
+
+func (c *datagramConn) Read(b []byte) (int, error) {
+	select {
+	case payload := <-c.queue:
+		return copy(b, payload), nil
+	case <-c.done.Wait():
+		return 0, io.EOF
+	}
+}
+
+// WriteMultiBuffer implements buf.Writer. Each buffer is sent in one datagram.
+func (c *datagramConn) WriteMultiBuffer(mb buf.MultiBuffer) error {
+	defer buf.ReleaseMulti(mb)
+
+	for _, b := range mb {
+		if _, err := c.Write(b.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *datagramConn) Write(b []byte) (int, error) {
+	if c.done.Done() {
+		return 0, io.ErrClosedPipe
+	}
+	if err := c.mux.send(datagramTypeData, c.id, b); err != nil {
+		if !isActive(c.mux.session) {
+			return 0, err
+		}
+		// The datagram is too large for the path. Drop it like an oversized UDP packet.
+		newError("dropping datagram of ", len(b), " bytes").Base(err).AtDebug().WriteToLog()
+	}
+	return len(b), nil
+}
+
+// closeLocal closes the connection without notifying the peer.
+func (c *datagramConn) closeLocal() {
+	if c.done.Done() {
+		return
+	}
+	c.done.Close()
+	c.mux.remove(c.id)
+}
+
+func (c *datagramConn) Close() error {
+	if c.done.Done() {
+		return nil
+	}
+	c.closeLocal()
+	if isActive(c.mux.session) {
+		return c.mux.send(datagramTypeClose, c.id, nil)
+	}
+	return nil
+}
+
+func (c *datagramConn) LocalAddr() net.Addr {
+	return c.local
+}
+
+func (c *datagramConn) RemoteAddr() net.Addr {
+	return c.remote
+}
+
+func (c *datagramConn) SetDeadline(time.Time) error {
+	return nil
+}
+
+func (c *datagramConn) SetReadDeadline(time.Time) error {
+	return nil
+}
+
+func (c *datagramConn) SetWriteDeadline(time.Time) error {
+	return nil
+}
@@ -2,6 +2,7 @@ package quic
 
 import (
 	"context"
+	gotls "crypto/tls"
 	"sync"
 	"time"
 
@@ -9,14 +10,16 @@ import (
 
 	"github.com/v2fly/v2ray-core/v5/common"
 	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/common/session"
 	"github.com/v2fly/v2ray-core/v5/common/task"
 	"github.com/v2fly/v2ray-core/v5/transport/internet"
 	"github.com/v2fly/v2ray-core/v5/transport/internet/tls"
 )
 
 type sessionContext struct {
-	rawConn *sysConn
-	session quic.Session
+	rawConn   *sysConn
+	session   quic.Session
+	datagrams *datagramMux
 }
 
 var errSessionClosed = newError("session closed")
@@ -40,6 +43,15 @@ func (c *sessionContext) openStream(destAddr net.Addr) (*interConn, error) {
 	return conn, nil
 }
 
+// openDatagramConn opens a connection in datagrams, or returns nil if the peer does not
+// support datagrams.
+func (c *sessionContext) openDatagramConn() (*datagramConn, error) {
+	if c.datagrams == nil || !c.session.ConnectionState().SupportsDatagrams {
+		return nil, nil
+	}
+	return c.datagrams.open()
+}
+
 type clientSessions struct {
 	access   sync.Mutex
 	sessions map[net.Destination][]*sessionContext
@@ -77,6 +89,23 @@ func removeInactiveSessions(sessions []*sessionContext) []*sessionContext {
 	return sessions
 }
 
+func openDatagramConn(sessions []*sessionContext) *datagramConn {
+	for _, s := range sessions {
+		if !isActive(s.session) {
+			continue
+		}
+
+		conn, err := s.openDatagramConn()
+		if err != nil || conn == nil {
+			continue
+		}
+
+		return conn
+	}
+
+	return nil
+}
+
 func openStream(sessions []*sessionContext, destAddr net.Addr) *interConn {
 	for _, s := range sessions {
 		if !isActive(s.session) {
@@ -115,7 +144,7 @@ func (s *clientSessions) cleanSessions() error {
 	return nil
 }
 
-func (s *clientSessions) openConnection(destAddr net.Addr, config *Config, tlsConfig *tls.Config, sockopt *internet.SocketConfig) (internet.Connection, error) {
+func (s *clientSessions) openConnection(destAddr net.Addr, config *Config, tlsConfig *tls.Config, sockopt *internet.SocketConfig, udp bool) (internet.Connection, error) {
 	s.access.Lock()
 	defer s.access.Unlock()
 
@@ -130,12 +159,14 @@ func (s *clientSessions) openConnection(destAddr net.Addr, config *Config, tlsCo
 		sessions = s
 	}
 
-	if true {
-		conn := openStream(sessions, destAddr)
-		if conn != nil {
+	if udp && config.EnableDatagrams {
+		if conn := openDatagramConn(sessions); conn != nil {
 			return conn, nil
 		}
 	}
+	if conn := openStream(sessions, destAddr); conn != nil {
+		return conn, nil
+	}
 
 	sessions = removeInactiveSessions(sessions)
 
@@ -147,12 +178,7 @@ func (s *clientSessions) openConnection(destAddr net.Addr, config *Config, tlsCo
 		return nil, err
 	}
 
-	quicConfig := &quic.Config{
-		ConnectionIDLength:   12,
-		HandshakeIdleTimeout: time.Second * 8,
-		MaxIdleTimeout:       time.Second * 30,
-		KeepAlive:            true,
-	}
+	quicConfig := getQuicConfig(config, time.Second*30)
 
 	conn, err := wrapSysConn(rawConn.(*net.UDPConn), config)
 	if err != nil {
@@ -160,7 +186,14 @@ func (s *clientSessions) openConnection(destAddr net.Addr, config *Config, tlsCo
 		return nil, err
 	}
 
-	session, err := quic.DialContext(context.Background(), conn, destAddr, "", tlsConfig.GetTLSConfig(tls.WithDestination(dest)), quicConfig)
+	var session quic.Session
+	if config.ZeroRttHandshake {
+		tlsConfig := tlsConfig.GetTLSConfig(tls.WithDestination(dest))
+		tlsConfig.ClientSessionCache = clientSessionCache
+		session, err = quic.DialEarlyContext(context.Background(), conn, destAddr, "", tlsConfig, quicConfig)
+	} else {
+		session, err = quic.DialContext(context.Background(), conn, destAddr, "", tlsConfig.GetTLSConfig(tls.WithDestination(dest)), quicConfig)
+	}
 	if err != nil {
 		conn.Close()
 		return nil, err
@@ -170,11 +203,25 @@ func (s *clientSessions) openConnection(destAddr net.Addr, config *Config, tlsCo
 		session: session,
 		rawConn: conn,
 	}
+	if config.EnableDatagrams {
+		context.datagrams = newDatagramMux(session, nil)
+	}
 	s.sessions[dest] = append(sessions, context)
+
+	if udp && config.EnableDatagrams {
+		conn, err := context.openDatagramConn()
+		if err != nil || conn != nil {
+			return conn, err
+		}
+	}
 	return context.openStream(destAddr)
 }
 
-var client clientSessions
+var (
+	client clientSessions
+	// clientSessionCache keeps session tickets for 0-RTT handshakes.
+	clientSessionCache = gotls.NewLRUClientSessionCache(128)
+)
 
 func init() {
 	client.sessions = make(map[net.Destination][]*sessionContext)
@@ -209,8 +256,12 @@ func Dial(ctx context.Context, dest net.Destination, streamSettings *internet.Me
 	}
 
 	config := streamSettings.ProtocolSettings.(*Config)
+	udp := false
+	if outbound := session.OutboundFromContext(ctx); outbound != nil {
+		udp = outbound.Target.Network == net.Network_UDP
+	}
 
-	return client.openConnection(destAddr, config, tlsConfig, streamSettings.SocketSettings)
+	return client.openConnection(destAddr, config, tlsConfig, streamSettings.SocketSettings, udp)
 }
 
 func init() {
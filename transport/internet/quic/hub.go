@@ -14,15 +14,37 @@ import (
 	"github.com/v2fly/v2ray-core/v5/transport/internet/tls"
 )
 
+// sessionListener is either a quic.Listener or a quic.EarlyListener.
+type sessionListener interface {
+	Accept(context.Context) (quic.Session, error)
+	Addr() net.Addr
+	Close() error
+}
+
+type earlyListener struct {
+	quic.EarlyListener
+}
+
+func (l earlyListener) Accept(ctx context.Context) (quic.Session, error) {
+	return l.EarlyListener.Accept(ctx)
+}
+
 // Listener is an internet.Listener that listens for TCP connections.
 type Listener struct {
-	rawConn  *sysConn
-	listener quic.Listener
-	done     *done.Instance
-	addConn  internet.ConnHandler
+	rawConn   *sysConn
+	listener  sessionListener
+	done      *done.Instance
+	addConn   internet.ConnHandler
+	datagrams bool
 }
 
 func (l *Listener) acceptStreams(session quic.Session) {
+	if l.datagrams && session.ConnectionState().SupportsDatagrams {
+		newDatagramMux(session, func(conn *datagramConn) {
+			l.addConn(conn)
+		})
+	}
+
 	for {
 		stream, err := session.AcceptStream(context.Background())
 		if err != nil {
@@ -101,14 +123,9 @@ func Listen(ctx context.Context, address net.Address, port net.Port, streamSetti
 		return nil, err
 	}
 
-	quicConfig := &quic.Config{
-		ConnectionIDLength:    12,
-		HandshakeIdleTimeout:  time.Second * 8,
-		MaxIdleTimeout:        time.Second * 45,
-		MaxIncomingStreams:    32,
-		MaxIncomingUniStreams: -1,
-		KeepAlive:             true,
-	}
+	quicConfig := getQuicConfig(config, time.Second*45)
+	quicConfig.MaxIncomingStreams = 32
+	quicConfig.MaxIncomingUniStreams = -1
 
 	conn, err := wrapSysConn(rawConn.(*net.UDPConn), config)
 	if err != nil {
@@ -116,17 +133,25 @@ func Listen(ctx context.Context, address net.Address, port net.Port, streamSetti
 		return nil, err
 	}
 
-	qListener, err := quic.Listen(conn, tlsConfig.GetTLSConfig(), quicConfig)
+	var qListener sessionListener
+	if config.ZeroRttHandshake {
+		var earlyQListener quic.EarlyListener
+		earlyQListener, err = quic.ListenEarly(conn, tlsConfig.GetTLSConfig(), quicConfig)
+		qListener = earlyListener{earlyQListener}
+	} else {
+		qListener, err = quic.Listen(conn, tlsConfig.GetTLSConfig(), quicConfig)
+	}
 	if err != nil {
 		conn.Close()
 		return nil, err
 	}
 
 	listener := &Listener{
-		done:     done.New(),
-		rawConn:  conn,
-		listener: qListener,
-		addConn:  handler,
+		done:      done.New(),
+		rawConn:   conn,
+		listener:  qListener,
+		addConn:   handler,
+		datagrams: config.EnableDatagrams,
 	}
 
 	go listener.keepAccepting()
@@ -11,7 +11,9 @@ import (
 // * use bytespool in buffer_pool.go
 // * set MaxReceivePacketSize to 1452 - 32 (16 bytes auth, 16 bytes head)
 //
-//
+// quic-go v0.25.0 always uses Reno for congestion control, and has no API for clients to migrate
+// connections to new paths. Selecting cubic or bbr and connection migration are left for a later
+// upgrade of quic-go, as recent versions require a newer Go than this module.
 
 const (
 	protocolName   = "quic"
@@ -14,6 +14,7 @@ import (
 	"github.com/v2fly/v2ray-core/v5/common/protocol"
 	"github.com/v2fly/v2ray-core/v5/common/protocol/tls/cert"
 	"github.com/v2fly/v2ray-core/v5/common/serial"
+	"github.com/v2fly/v2ray-core/v5/common/session"
 	"github.com/v2fly/v2ray-core/v5/testing/servers/udp"
 	"github.com/v2fly/v2ray-core/v5/transport/internet"
 	"github.com/v2fly/v2ray-core/v5/transport/internet/headers/wireguard"
@@ -221,3 +222,76 @@ func TestQuicConnectionAuthHeader(t *testing.T) {
 		t.Error(r)
 	}
 }
+
+func TestQuicDatagramConnection(t *testing.T) {
+	port := udp.PickPort()
+
+	listener, err := quic.Listen(context.Background(), net.LocalHostIP, port, &internet.MemoryStreamConfig{
+		ProtocolName: "quic",
+		ProtocolSettings: &quic.Config{
+			EnableDatagrams:  true,
+			ZeroRttHandshake: true,
+		},
+		SecurityType: "tls",
+		SecuritySettings: &tls.Config{
+			Certificate: []*tls.Certificate{
+				tls.ParseCertificate(
+					cert.MustGenerate(nil,
+						cert.DNSNames("www.v2fly.org"),
+					),
+				),
+			},
+		},
+	}, func(conn internet.Connection) {
+		go func() {
+			defer conn.Close()
+
+			b := make([]byte, 2048)
+			for {
+				n, err := conn.Read(b)
+				if err != nil {
+					return
+				}
+				common.Must2(conn.Write(b[:n]))
+			}
+		}()
+	})
+	common.Must(err)
+
+	defer listener.Close()
+
+	time.Sleep(time.Second)
+
+	dctx := session.ContextWithOutbound(context.Background(), &session.Outbound{
+		Target: net.UDPDestination(net.LocalHostIP, net.Port(53)),
+	})
+	conn, err := quic.Dial(dctx, net.TCPDestination(net.LocalHostIP, port), &internet.MemoryStreamConfig{
+		ProtocolName: "quic",
+		ProtocolSettings: &quic.Config{
+			EnableDatagrams:  true,
+			ZeroRttHandshake: true,
+		},
+		SecurityType: "tls",
+		SecuritySettings: &tls.Config{
+			ServerName:    "www.v2fly.org",
+			AllowInsecure: true,
+		},
+	})
+	common.Must(err)
+	defer conn.Close()
+
+	const N = 512
+	b1 := make([]byte, N)
+	common.Must2(rand.Read(b1))
+	b2 := make([]byte, 2048)
+
+	for i := 0; i < 3; i++ {
+		common.Must2(conn.Write(b1))
+
+		n, err := conn.Read(b2)
+		common.Must(err)
+		if r := cmp.Diff(b2[:n], b1); r != "" {
+			t.Error(r)
+		}
+	}
+}
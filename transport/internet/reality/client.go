@@ -0,0 +1,206 @@
+package reality
+
+import (
+	"bytes"
+	"context"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha512"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/binary"
+	"net"
+	"time"
+
+	"golang.org/x/crypto/curve25519"
+
+	v2tls "github.com/v2fly/v2ray-core/v5/transport/internet/tls"
+)
+
+// helloRand returns the random, the session ID and the X25519 private key of the ClientHello,
+// which are the reads of crypto/tls from Config.Rand of their sizes in this order, and reads
+// crypto/rand otherwise. The ClientHello is checked before it is sent, so that the connection
+// fails instead of sending a ClientHello the server can not authenticate, if crypto/tls reads
+// them differently.
+type helloRand struct {
+	pending [][]byte
+}
+
+func newHelloRand(random, sessionID, privateKey []byte) *helloRand {
+	return &helloRand{
+		pending: [][]byte{random, sessionID, privateKey},
+	}
+}
+
+func (r *helloRand) Read(b []byte) (int, error) {
+	// Reads of other sizes, such as a byte read by crypto/ecdh randomly, are not ours.
+	if len(r.pending) > 0 && len(b) == len(r.pending[0]) {
+		n := copy(b, r.pending[0])
+		r.pending = r.pending[1:]
+		return n, nil
+	}
+	return rand.Read(b)
+}
+
+// clientConn seals the session ID of the ClientHello on the wire, and restores the session ID
+// echoed in the ServerHello.
+type clientConn struct {
+	net.Conn
+	config    *Config
+	aead      cipher.AEAD
+	key       []byte
+	random    []byte
+	sessionID []byte
+	keyShare  []byte
+	token     []byte
+	record    []byte
+	readHello bool
+}
+
+func (c *clientConn) Write(b []byte) (int, error) {
+	if c.token != nil {
+		return c.Conn.Write(b)
+	}
+
+	// Only the first record of the first flight is the ClientHello.
+	if len(b) < recordHeaderSize || len(b) < recordHeaderSize+int(binary.BigEndian.Uint16(b[3:])) {
+		return 0, newError("unsupported TLS implementation")
+	}
+	record := append([]byte(nil), b...)
+	hello := record[:recordHeaderSize+int(binary.BigEndian.Uint16(b[3:]))]
+	if err := checkClientHello(hello, c.random, c.sessionID, c.keyShare); err != nil {
+		return 0, err
+	}
+	sealSessionID(c.aead, hello, c.config.ShortId, time.Now())
+	c.token = append([]byte(nil), helloSessionID(hello, handshakeTypeClientHello)...)
+	if _, err := c.Conn.Write(record); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+func (c *clientConn) Read(b []byte) (int, error) {
+	if c.readHello && len(c.record) == 0 {
+		return c.Conn.Read(b)
+	}
+
+	if !c.readHello {
+		record, err := readRecord(c.Conn)
+		if err != nil {
+			return 0, err
+		}
+		if sessionID := helloSessionID(record, handshakeTypeServerHello); sessionID != nil && bytes.Equal(sessionID, c.token) {
+			copy(sessionID, c.sessionID)
+		}
+		c.record = record
+		c.readHello = true
+	}
+
+	n := copy(b, c.record)
+	c.record = c.record[n:]
+	return n, nil
+}
+
+// verifyCertificate checks that the signature of the temporary certificate of the server is the
+// HMAC of its public key.
+func (c *clientConn) verifyCertificate(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+	if len(rawCerts) == 0 {
+		return newError("no certificate")
+	}
+	certificate, err := x509.ParseCertificate(rawCerts[0])
+	if err != nil {
+		return newError("failed to parse certificate").Base(err)
+	}
+	publicKey, ok := certificate.PublicKey.(ed25519.PublicKey)
+	if !ok {
+		return newError("server is not authenticated")
+	}
+	h := hmac.New(sha512.New, c.key)
+	h.Write(publicKey)
+	if !hmac.Equal(h.Sum(nil), certificate.Signature) {
+		return newError("server is not authenticated")
+	}
+	return nil
+}
+
+// checkClientHello returns an error if the ClientHello record does not carry the random, the
+// session ID and the X25519 key share given to crypto/tls.
+func checkClientHello(record, random, sessionID, keyShare []byte) error {
+	if r := helloRandom(record, handshakeTypeClientHello); r == nil || !bytes.Equal(r, random) {
+		return newError("unsupported TLS implementation: unexpected random")
+	}
+	if !bytes.Equal(helloSessionID(record, handshakeTypeClientHello), sessionID) {
+		return newError("unsupported TLS implementation: unexpected session ID")
+	}
+	hello, err := parseClientHello(record)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(hello.keyShare, keyShare) {
+		return newError("unsupported TLS implementation: unexpected key share")
+	}
+	return nil
+}
+
+// clientTLSConfig returns the config of crypto/tls for the ClientHello of a client. The only key
+// share is X25519, so that the server authenticates clients by it.
+func clientTLSConfig(config *Config, rand *helloRand) *tls.Config {
+	return &tls.Config{
+		ServerName:       config.ServerName,
+		NextProtos:       config.NextProtocol,
+		MinVersion:       tls.VersionTLS13,
+		CurvePreferences: []tls.CurveID{tls.X25519},
+		Rand:             rand,
+	}
+}
+
+// Client performs a TLS handshake with the server, and returns the TLS connection.
+func Client(ctx context.Context, rawConn net.Conn, config *Config) (net.Conn, error) {
+	if len(config.PublicKey) != curve25519.PointSize {
+		return nil, newError("invalid public key")
+	}
+
+	// The secret is shared by the key share of the ClientHello, whose private key is read by
+	// crypto/tls from Config.Rand, so the random is random as in any ClientHello.
+	random := make([]byte, randomSize)
+	if _, err := rand.Read(random); err != nil {
+		return nil, err
+	}
+	privateKey := make([]byte, curve25519.ScalarSize)
+	if _, err := rand.Read(privateKey); err != nil {
+		return nil, err
+	}
+	keyShare, err := curve25519.X25519(privateKey, curve25519.Basepoint)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := curve25519.X25519(privateKey, config.PublicKey)
+	if err != nil {
+		return nil, newError("invalid public key").Base(err)
+	}
+
+	aead, key, err := newAuthAEAD(secret, random)
+	if err != nil {
+		return nil, err
+	}
+	cc := &clientConn{
+		Conn:      rawConn,
+		config:    config,
+		aead:      aead,
+		key:       key,
+		random:    random,
+		sessionID: originalSessionID(random),
+		keyShare:  keyShare,
+	}
+	tlsConfig := clientTLSConfig(config, newHelloRand(random, cc.sessionID, privateKey))
+	// The temporary certificate is verified by verifyCertificate instead.
+	tlsConfig.InsecureSkipVerify = true // nolint: gosec
+	tlsConfig.VerifyPeerCertificate = cc.verifyCertificate
+	tlsConn := tls.Client(cc, tlsConfig)
+	if err := v2tls.Handshake(ctx, tlsConn); err != nil {
+		return nil, newError("failed to handshake with ", config.ServerName).Base(err)
+	}
+	return tlsConn, nil
+}
@@ -0,0 +1,52 @@
+package reality
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"net"
+	"testing"
+
+	"golang.org/x/crypto/curve25519"
+
+	"github.com/v2fly/v2ray-core/v5/common"
+)
+
+// TestClientHelloRand fails if crypto/tls does not read the random, the session ID and the X25519
+// private key of the ClientHello from Config.Rand in the order that helloRand expects.
+func TestClientHelloRand(t *testing.T) {
+	random := make([]byte, randomSize)
+	common.Must2(rand.Read(random))
+	sessionID := originalSessionID(random)
+	privateKey := make([]byte, curve25519.ScalarSize)
+	common.Must2(rand.Read(privateKey))
+	keyShare, err := curve25519.X25519(privateKey, curve25519.Basepoint)
+	common.Must(err)
+
+	client, server := net.Pipe()
+	defer server.Close()
+	config := clientTLSConfig(&Config{ServerName: "example.com"}, newHelloRand(random, sessionID, privateKey))
+	config.InsecureSkipVerify = true // nolint: gosec
+	go func() {
+		tls.Client(client, config).Handshake()
+		client.Close()
+	}()
+
+	record, err := readRecord(server)
+	common.Must(err)
+	if r := helloRandom(record, handshakeTypeClientHello); !bytes.Equal(r, random) {
+		t.Error("unexpected random: ", r)
+	}
+	if id := helloSessionID(record, handshakeTypeClientHello); !bytes.Equal(id, sessionID) {
+		t.Error("unexpected session ID: ", id)
+	}
+	hello, err := parseClientHello(record)
+	common.Must(err)
+	if !bytes.Equal(hello.keyShare, keyShare) {
+		t.Error("unexpected key share: ", hello.keyShare)
+	}
+	if hello.serverName != "example.com" {
+		t.Error("unexpected server name: ", hello.serverName)
+	}
+	common.Must(checkClientHello(record, random, sessionID, keyShare))
+}
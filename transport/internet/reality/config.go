@@ -0,0 +1,50 @@
+package reality
+
+import (
+	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/transport/internet"
+)
+
+func (c *Config) getDestination() (net.Destination, error) {
+	if c.DestAddress == nil || c.DestPort == 0 {
+		return net.Destination{}, newError("destination not specified")
+	}
+	return net.TCPDestination(c.DestAddress.AsAddress(), net.Port(c.DestPort)), nil
+}
+
+func (c *Config) acceptsServerName(serverName string) bool {
+	if len(c.ServerNames) == 0 {
+		return true
+	}
+	for _, name := range c.ServerNames {
+		if name == serverName {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Config) acceptsShortID(shortID []byte) bool {
+	ids := c.ShortIds
+	if len(ids) == 0 {
+		ids = [][]byte{nil}
+	}
+	for _, id := range ids {
+		if string(paddedShortID(id)) == string(shortID) {
+			return true
+		}
+	}
+	return false
+}
+
+// ConfigFromStreamSettings fetches Config from stream settings. Nil if not found.
+func ConfigFromStreamSettings(settings *internet.MemoryStreamConfig) *Config {
+	if settings == nil {
+		return nil
+	}
+	config, ok := settings.SecuritySettings.(*Config)
+	if !ok {
+		return nil
+	}
+	return config
+}
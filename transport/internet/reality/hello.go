@@ -0,0 +1,223 @@
+package reality
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
+	"time"
+
+	"golang.org/x/crypto/cryptobyte"
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/hkdf"
+)
+
+const (
+	recordHeaderSize = 5
+	// maxRecordSize is the maximum size of the body of a TLS record, including the expansion of
+	// encryption.
+	maxRecordSize = 16384 + 2048
+
+	contentTypeHandshake = 22
+
+	handshakeTypeClientHello = 1
+	handshakeTypeServerHello = 2
+
+	randomSize    = 32
+	sessionIDSize = 32
+	// sessionIDOffset is the offset of the length of the session ID in both ClientHello and
+	// ServerHello records, after the handshake type, length, legacy version and random.
+	sessionIDOffset = recordHeaderSize + 1 + 3 + 2 + randomSize
+
+	// The session ID of authenticated clients seals the version, reserved bytes, a timestamp
+	// in seconds and the short ID.
+	authVersion   = 1
+	timestampSize = 4
+	shortIDSize   = 8
+	tokenSize     = 4 + timestampSize + shortIDSize
+)
+
+// readRecord reads a TLS record, including its header.
+func readRecord(r io.Reader) ([]byte, error) {
+	header := make([]byte, recordHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	length := int(binary.BigEndian.Uint16(header[3:]))
+	if length > maxRecordSize {
+		return nil, newError("record too large: ", length)
+	}
+	record := make([]byte, recordHeaderSize+length)
+	copy(record, header)
+	if _, err := io.ReadFull(r, record[recordHeaderSize:]); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// helloRandom returns the random of a ClientHello or ServerHello record, or nil if record is
+// not a complete hello of handshakeType with a session ID of full size.
+func helloRandom(record []byte, handshakeType byte) []byte {
+	if len(record) < sessionIDOffset+1+sessionIDSize || record[0] != contentTypeHandshake ||
+		record[recordHeaderSize] != handshakeType || record[sessionIDOffset] != sessionIDSize {
+		return nil
+	}
+	return record[sessionIDOffset-randomSize : sessionIDOffset]
+}
+
+// helloSessionID returns the session ID of a ClientHello or ServerHello record, in place, or nil
+// if record is not a complete hello of handshakeType with a session ID of full size.
+func helloSessionID(record []byte, handshakeType byte) []byte {
+	if helloRandom(record, handshakeType) == nil {
+		return nil
+	}
+	return record[sessionIDOffset+1 : sessionIDOffset+1+sessionIDSize]
+}
+
+const (
+	// extensionServerName is the server_name extension (RFC 6066, Section 3).
+	extensionServerName = 0
+	// extensionKeyShare is the key_share extension (RFC 8446, Section 4.2.8).
+	extensionKeyShare = 51
+	// groupX25519 is the named group of X25519 (RFC 8446, Section 4.2.7).
+	groupX25519 = 29
+)
+
+// clientHello is the fields of a ClientHello that authenticate clients.
+type clientHello struct {
+	serverName string
+	// keyShare is the X25519 key share, or nil if there is none.
+	keyShare []byte
+}
+
+// parseClientHello parses the server name and the X25519 key share of a ClientHello record.
+func parseClientHello(record []byte) (*clientHello, error) {
+	s := cryptobyte.String(record[recordHeaderSize:])
+	var msgType uint8
+	var body cryptobyte.String
+	if !s.ReadUint8(&msgType) || msgType != handshakeTypeClientHello || !s.ReadUint24LengthPrefixed(&body) {
+		return nil, newError("not a ClientHello")
+	}
+
+	var legacyVersion uint16
+	var random, sessionID, cipherSuites, compressionMethods, extensions cryptobyte.String
+	if !body.ReadUint16(&legacyVersion) || !body.ReadBytes((*[]byte)(&random), randomSize) ||
+		!body.ReadUint8LengthPrefixed(&sessionID) || !body.ReadUint16LengthPrefixed(&cipherSuites) ||
+		!body.ReadUint8LengthPrefixed(&compressionMethods) || !body.ReadUint16LengthPrefixed(&extensions) {
+		return nil, newError("malformed ClientHello")
+	}
+
+	hello := &clientHello{}
+	for !extensions.Empty() {
+		var extension uint16
+		var data cryptobyte.String
+		if !extensions.ReadUint16(&extension) || !extensions.ReadUint16LengthPrefixed(&data) {
+			return nil, newError("malformed ClientHello extensions")
+		}
+		switch extension {
+		case extensionServerName:
+			var names cryptobyte.String
+			if !data.ReadUint16LengthPrefixed(&names) {
+				return nil, newError("malformed server name extension")
+			}
+			for !names.Empty() {
+				var nameType uint8
+				var name cryptobyte.String
+				if !names.ReadUint8(&nameType) || !names.ReadUint16LengthPrefixed(&name) {
+					return nil, newError("malformed server name extension")
+				}
+				if nameType == 0 {
+					hello.serverName = string(name)
+					break
+				}
+			}
+		case extensionKeyShare:
+			var shares cryptobyte.String
+			if !data.ReadUint16LengthPrefixed(&shares) {
+				return nil, newError("malformed key share extension")
+			}
+			for !shares.Empty() {
+				var group uint16
+				var key cryptobyte.String
+				if !shares.ReadUint16(&group) || !shares.ReadUint16LengthPrefixed(&key) {
+					return nil, newError("malformed key share extension")
+				}
+				if group == groupX25519 && len(key) == curve25519.PointSize {
+					hello.keyShare = key
+					break
+				}
+			}
+		}
+	}
+	return hello, nil
+}
+
+// originalSessionID returns the session ID that the TLS stacks of both ends see in place of the
+// sealed one on the wire.
+func originalSessionID(random []byte) []byte {
+	h := sha256.Sum256(random)
+	return h[:]
+}
+
+// newAuthAEAD returns the AEAD of the key derived from the X25519 secret shared by the key share
+// of a client and the server, and the random of the ClientHello.
+func newAuthAEAD(secret []byte, random []byte) (cipher.AEAD, []byte, error) {
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, random[:20], []byte("REALITY")), key); err != nil {
+		return nil, nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, nil, err
+	}
+	return aead, key, nil
+}
+
+// additionalData returns a copy of a ClientHello record without its session ID.
+func additionalData(record []byte) []byte {
+	ad := append([]byte(nil), record...)
+	sessionID := helloSessionID(ad, handshakeTypeClientHello)
+	for i := range sessionID {
+		sessionID[i] = 0
+	}
+	return ad
+}
+
+func paddedShortID(shortID []byte) []byte {
+	padded := make([]byte, shortIDSize)
+	copy(padded, shortID)
+	return padded
+}
+
+// sealSessionID replaces the session ID of a ClientHello record with the sealed short ID.
+func sealSessionID(aead cipher.AEAD, record []byte, shortID []byte, now time.Time) {
+	random := helloRandom(record, handshakeTypeClientHello)
+	token := make([]byte, tokenSize)
+	token[0] = authVersion
+	binary.BigEndian.PutUint32(token[4:], uint32(now.Unix()))
+	copy(token[4+timestampSize:], paddedShortID(shortID))
+
+	sealed := aead.Seal(nil, random[20:], token, additionalData(record))
+	copy(helloSessionID(record, handshakeTypeClientHello), sealed)
+}
+
+// openSessionID returns the short ID and the timestamp sealed in the session ID of a ClientHello
+// record.
+func openSessionID(aead cipher.AEAD, record []byte) ([]byte, time.Time, error) {
+	random := helloRandom(record, handshakeTypeClientHello)
+	sessionID := helloSessionID(record, handshakeTypeClientHello)
+	token, err := aead.Open(nil, random[20:], sessionID, additionalData(record))
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	if token[0] != authVersion {
+		return nil, time.Time{}, newError("unknown version ", token[0])
+	}
+	timestamp := time.Unix(int64(binary.BigEndian.Uint32(token[4:])), 0)
+	return token[4+timestampSize:], timestamp, nil
+}
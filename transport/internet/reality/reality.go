@@ -0,0 +1,20 @@
+/*
+Package reality implements a REALITY-style security layer, in which servers borrow the TLS
+handshake of a real destination instead of presenting their own certificate.
+
+The X25519 key share of the ClientHello of a client is its ephemeral public key, so the
+ClientHello looks like that of any TLS 1.3 client. The client derives an authentication key from
+the secret of the key share and the public key of the server, and replaces the session ID
+of the ClientHello with its short ID and a timestamp, sealed by the key with the rest of the
+ClientHello as additional data. The server relays ClientHellos it can not open to the real
+destination byte by byte, so that probes see the real site. Authenticated clients complete a TLS
+1.3 handshake with a temporary certificate whose signature is an HMAC of its public key keyed by
+the authentication key, which only the client can verify.
+
+The original REALITY pairs clients with uTLS fingerprints. uTLS is not available in this tree, so
+clients use the ClientHello of crypto/tls, whose random, session ID and key share are controlled
+through Config.Rand.
+*/
+package reality
+
+//go:generate go run github.com/v2fly/v2ray-core/v5/common/errors/errorgen
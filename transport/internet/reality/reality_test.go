@@ -0,0 +1,172 @@
+package reality_test
+
+import (
+	"context"
+	"crypto/rand"
+	gotls "crypto/tls"
+	"io"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/crypto/curve25519"
+
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/common/protocol/tls/cert"
+	. "github.com/v2fly/v2ray-core/v5/transport/internet/reality"
+	"github.com/v2fly/v2ray-core/v5/transport/internet/tls"
+)
+
+// startDestination starts a TLS server that answers every read with "decoy".
+func startDestination(t *testing.T) (net.Listener, *gotls.Config) {
+	config := (&tls.Config{
+		Certificate: []*tls.Certificate{
+			tls.ParseCertificate(cert.MustGenerate(nil, cert.DNSNames("www.v2fly.org"))),
+		},
+	}).GetTLSConfig()
+	listener, err := gotls.Listen("tcp", "127.0.0.1:0", config)
+	common.Must(err)
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				b := make([]byte, 1024)
+				for {
+					if _, err := conn.Read(b); err != nil {
+						return
+					}
+					if _, err := conn.Write([]byte("decoy")); err != nil {
+						return
+					}
+				}
+			}()
+		}
+	}()
+	return listener, config
+}
+
+func generateKey() ([]byte, []byte) {
+	privateKey := make([]byte, curve25519.ScalarSize)
+	common.Must2(rand.Read(privateKey))
+	publicKey, err := curve25519.X25519(privateKey, curve25519.Basepoint)
+	common.Must(err)
+	return privateKey, publicKey
+}
+
+// startServer starts a server that echoes authenticated connections.
+func startServer(t *testing.T, destination net.Listener, privateKey []byte) net.Listener {
+	server, err := NewServer(&Config{
+		DestAddress:       net.NewIPOrDomain(net.LocalHostIP),
+		DestPort:          uint32(destination.Addr().(*net.TCPAddr).Port),
+		ServerNames:       []string{"www.v2fly.org"},
+		PrivateKey:        privateKey,
+		ShortIds:          [][]byte{{0x0b, 0xad}},
+		MaxTimeDifference: 60,
+	})
+	common.Must(err)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	common.Must(err)
+
+	go func() {
+		for {
+			rawConn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				conn := server.Server(rawConn)
+				defer conn.Close()
+				io.Copy(conn, conn)
+			}()
+		}
+	}()
+	return listener
+}
+
+func dial(listener net.Listener, publicKey []byte, shortID []byte) (net.Conn, error) {
+	rawConn, err := net.Dial("tcp", listener.Addr().String())
+	common.Must(err)
+	conn, err := Client(context.Background(), rawConn, &Config{
+		ServerName:   "www.v2fly.org",
+		PublicKey:    publicKey,
+		ShortId:      shortID,
+		NextProtocol: []string{"h2", "http/1.1"},
+	})
+	if err != nil {
+		rawConn.Close()
+	}
+	return conn, err
+}
+
+func TestReality(t *testing.T) {
+	destination, _ := startDestination(t)
+	defer destination.Close()
+	privateKey, publicKey := generateKey()
+	listener := startServer(t, destination, privateKey)
+	defer listener.Close()
+
+	conn, err := dial(listener, publicKey, []byte{0x0b, 0xad})
+	common.Must(err)
+	defer conn.Close()
+
+	const N = 64 * 1024
+	b1 := make([]byte, N)
+	common.Must2(rand.Read(b1))
+	go conn.Write(b1)
+
+	b2 := make([]byte, N)
+	common.Must2(io.ReadFull(conn, b2))
+	if r := cmp.Diff(b2, b1); r != "" {
+		t.Error(r)
+	}
+}
+
+func TestRealityNotAuthenticated(t *testing.T) {
+	destination, _ := startDestination(t)
+	defer destination.Close()
+	privateKey, publicKey := generateKey()
+	listener := startServer(t, destination, privateKey)
+	defer listener.Close()
+
+	if _, err := dial(listener, publicKey, []byte{0xba, 0xd0}); err == nil {
+		t.Error("expected failure with unknown short ID")
+	}
+
+	_, otherPublicKey := generateKey()
+	if _, err := dial(listener, otherPublicKey, []byte{0x0b, 0xad}); err == nil {
+		t.Error("expected failure with wrong public key")
+	}
+}
+
+func TestRealityProbe(t *testing.T) {
+	destination, destinationConfig := startDestination(t)
+	defer destination.Close()
+	privateKey, _ := generateKey()
+	listener := startServer(t, destination, privateKey)
+	defer listener.Close()
+
+	conn, err := gotls.Dial("tcp", listener.Addr().String(), &gotls.Config{
+		ServerName:         "www.v2fly.org",
+		InsecureSkipVerify: true,
+	})
+	common.Must(err)
+	defer conn.Close()
+
+	certificate := conn.ConnectionState().PeerCertificates[0]
+	if r := cmp.Diff(certificate.Raw, destinationConfig.Certificates[0].Certificate[0]); r != "" {
+		t.Error("unexpected certificate: ", r)
+	}
+
+	common.Must2(conn.Write([]byte("hello")))
+	b := make([]byte, 16)
+	n, err := conn.Read(b)
+	common.Must(err)
+	if string(b[:n]) != "decoy" {
+		t.Error("unexpected response: ", string(b[:n]))
+	}
+}
@@ -0,0 +1,258 @@
+package reality
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha512"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"math/big"
+	"net"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/curve25519"
+
+	"github.com/v2fly/v2ray-core/v5/transport/internet"
+)
+
+// handshakeTimeout is the timeout of the handshake of authenticated clients.
+const handshakeTimeout = time.Second * 16
+
+// Server authenticates connections to servers.
+type Server struct {
+	config *Config
+
+	// certificate is the template of temporary certificates, whose signature is replaced.
+	certificate []byte
+	publicKey   ed25519.PublicKey
+	privateKey  ed25519.PrivateKey
+}
+
+// NewServer creates a Server, with a key pair of temporary certificates.
+func NewServer(config *Config) (*Server, error) {
+	if len(config.PrivateKey) != curve25519.ScalarSize {
+		return nil, newError("invalid private key")
+	}
+	if _, err := config.getDestination(); err != nil {
+		return nil, err
+	}
+
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(0),
+		Subject:      pkix.Name{},
+		NotBefore:    time.Unix(0, 0),
+		NotAfter:     time.Unix(0, 0),
+	}
+	certificate, err := x509.CreateCertificate(rand.Reader, template, template, publicKey, privateKey)
+	if err != nil {
+		return nil, newError("failed to create certificate").Base(err)
+	}
+	return &Server{
+		config:      config,
+		certificate: certificate,
+		publicKey:   publicKey,
+		privateKey:  privateKey,
+	}, nil
+}
+
+// temporaryCertificate returns a certificate whose signature is the HMAC of its public key keyed
+// by the authentication key of a client.
+func (s *Server) temporaryCertificate(key []byte) tls.Certificate {
+	certificate := append([]byte(nil), s.certificate...)
+	h := hmac.New(sha512.New, key)
+	h.Write(s.publicKey)
+	// The signature of Ed25519 is at the end of the certificate.
+	copy(certificate[len(certificate)-ed25519.SignatureSize:], h.Sum(nil))
+	return tls.Certificate{
+		Certificate: [][]byte{certificate},
+		PrivateKey:  s.privateKey,
+	}
+}
+
+// authenticate returns the authentication key of the client of a ClientHello record.
+func (s *Server) authenticate(record []byte) ([]byte, error) {
+	random := helloRandom(record, handshakeTypeClientHello)
+	if random == nil {
+		return nil, newError("not a ClientHello")
+	}
+	hello, err := parseClientHello(record)
+	if err != nil {
+		return nil, err
+	}
+	if !s.config.acceptsServerName(hello.serverName) {
+		return nil, newError("unknown server name ", hello.serverName)
+	}
+	if hello.keyShare == nil {
+		return nil, newError("no X25519 key share")
+	}
+
+	secret, err := curve25519.X25519(s.config.PrivateKey, hello.keyShare)
+	if err != nil {
+		return nil, err
+	}
+	aead, key, err := newAuthAEAD(secret, random)
+	if err != nil {
+		return nil, err
+	}
+	shortID, timestamp, err := openSessionID(aead, record)
+	if err != nil {
+		return nil, err
+	}
+	if !s.config.acceptsShortID(shortID) {
+		return nil, newError("unknown short ID ", shortID)
+	}
+	if maxDiff := time.Duration(s.config.MaxTimeDifference) * time.Second; maxDiff > 0 {
+		if diff := time.Since(timestamp); diff > maxDiff || diff < -maxDiff {
+			return nil, newError("time difference too large: ", diff)
+		}
+	}
+	return key, nil
+}
+
+// Server returns the TLS connection of an authenticated client. Connections that are not
+// authenticated are relayed to the destination, and never return data.
+func (s *Server) Server(rawConn net.Conn) net.Conn {
+	return &serverConn{
+		Conn:   rawConn,
+		server: s,
+	}
+}
+
+func (s *Server) handshake(rawConn net.Conn) (net.Conn, error) {
+	if err := rawConn.SetDeadline(time.Now().Add(handshakeTimeout)); err != nil {
+		return nil, err
+	}
+	record, err := readRecord(rawConn)
+	if err != nil {
+		return nil, newError("failed to read ClientHello").Base(err)
+	}
+
+	key, err := s.authenticate(record)
+	if err != nil {
+		newError("relaying connection that is not authenticated").Base(err).AtDebug().WriteToLog()
+		if err := rawConn.SetDeadline(time.Time{}); err != nil {
+			return nil, err
+		}
+		return nil, s.relay(rawConn, record)
+	}
+
+	random := helloRandom(record, handshakeTypeClientHello)
+	sessionID := helloSessionID(record, handshakeTypeClientHello)
+	token := append([]byte(nil), sessionID...)
+	copy(sessionID, originalSessionID(random))
+	tlsConn := tls.Server(&helloConn{
+		Conn:      rawConn,
+		record:    record,
+		sessionID: originalSessionID(random),
+		token:     token,
+	}, &tls.Config{
+		MinVersion:   tls.VersionTLS13,
+		Certificates: []tls.Certificate{s.temporaryCertificate(key)},
+		NextProtos:   []string{"h2", "http/1.1"},
+	})
+	if err := tlsConn.Handshake(); err != nil {
+		return nil, newError("failed to handshake with authenticated client").Base(err)
+	}
+	if err := rawConn.SetDeadline(time.Time{}); err != nil {
+		return nil, err
+	}
+	return tlsConn, nil
+}
+
+// relay copies the connection to the destination and back until either side closes.
+func (s *Server) relay(rawConn net.Conn, record []byte) error {
+	dest, _ := s.config.getDestination()
+	target, err := internet.DialSystem(context.Background(), dest, nil)
+	if err != nil {
+		return newError("failed to dial destination ", dest).Base(err)
+	}
+	defer target.Close()
+
+	if _, err := target.Write(record); err != nil {
+		return newError("failed to relay to destination").Base(err)
+	}
+	go func() {
+		io.Copy(target, rawConn)
+		target.Close()
+	}()
+	io.Copy(rawConn, target)
+	return newError("connection relayed to ", dest)
+}
+
+// helloConn replays the ClientHello record with the original session ID to the TLS server, and
+// seals the session ID echoed in the ServerHello again on the wire.
+type helloConn struct {
+	net.Conn
+	record    []byte
+	sessionID []byte
+	token     []byte
+	wrote     bool
+}
+
+func (c *helloConn) Read(b []byte) (int, error) {
+	if len(c.record) == 0 {
+		return c.Conn.Read(b)
+	}
+	n := copy(b, c.record)
+	c.record = c.record[n:]
+	return n, nil
+}
+
+func (c *helloConn) Write(b []byte) (int, error) {
+	if c.wrote {
+		return c.Conn.Write(b)
+	}
+	c.wrote = true
+
+	data := append([]byte(nil), b...)
+	if sessionID := helloSessionID(data, handshakeTypeServerHello); sessionID != nil && bytes.Equal(sessionID, c.sessionID) {
+		copy(sessionID, c.token)
+	}
+	if _, err := c.Conn.Write(data); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// serverConn performs the handshake on the first Read or Write, like tls.Server.
+type serverConn struct {
+	net.Conn
+	server *Server
+	once   sync.Once
+	err    error
+	data   net.Conn
+}
+
+func (c *serverConn) handshake() error {
+	c.once.Do(func() {
+		c.data, c.err = c.server.handshake(c.Conn)
+		if c.err != nil {
+			c.Conn.Close()
+		}
+	})
+	return c.err
+}
+
+func (c *serverConn) Read(b []byte) (int, error) {
+	if err := c.handshake(); err != nil {
+		return 0, err
+	}
+	return c.data.Read(b)
+}
+
+func (c *serverConn) Write(b []byte) (int, error) {
+	if err := c.handshake(); err != nil {
+		return 0, err
+	}
+	return c.data.Write(b)
+}
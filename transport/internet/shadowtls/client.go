@@ -0,0 +1,62 @@
+package shadowtls
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"sync"
+
+	v2tls "github.com/v2fly/v2ray-core/v5/transport/internet/tls"
+)
+
+// handshakeConn feeds the TLS handshake of clients one record at a time, so that the TLS client
+// never reads past the handshake, and records the random of the ServerHello.
+type handshakeConn struct {
+	net.Conn
+	record []byte
+	random []byte
+}
+
+func (c *handshakeConn) Read(b []byte) (int, error) {
+	if len(c.record) == 0 {
+		record, err := readRecord(c.Conn)
+		if err != nil {
+			return 0, err
+		}
+		if c.random == nil {
+			c.random = serverRandom(record)
+		}
+		c.record = record
+	}
+
+	n := copy(b, c.record)
+	c.record = c.record[n:]
+	return n, nil
+}
+
+// Client performs a TLS handshake with the handshake server through the server, and returns the
+// data channel.
+func Client(ctx context.Context, rawConn net.Conn, config *Config) (net.Conn, error) {
+	hc := &handshakeConn{Conn: rawConn}
+	tlsConn := tls.Client(hc, &tls.Config{
+		ServerName: config.ServerName,
+		NextProtos: config.NextProtocol,
+		// The certificate belongs to the handshake server. The data channel is authenticated by
+		// the password instead.
+		InsecureSkipVerify: true, // nolint: gosec
+	})
+	if err := v2tls.Handshake(ctx, tlsConn); err != nil {
+		return nil, newError("failed to handshake with ", config.ServerName).Base(err)
+	}
+	if hc.random == nil {
+		return nil, newError("no ServerHello in handshake")
+	}
+
+	return &conn{
+		Conn:        rawConn,
+		readAuth:    newAuthenticator(config.Password, hc.random, directionServer),
+		writeAuth:   newAuthenticator(config.Password, hc.random, directionClient),
+		skipInvalid: true,
+		writeAccess: new(sync.Mutex),
+	}, nil
+}
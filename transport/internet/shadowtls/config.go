@@ -0,0 +1,25 @@
+package shadowtls
+
+import (
+	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/transport/internet"
+)
+
+func (c *Config) getHandshakeDestination() (net.Destination, error) {
+	if c.HandshakeAddress == nil || c.HandshakePort == 0 {
+		return net.Destination{}, newError("handshake server not specified")
+	}
+	return net.TCPDestination(c.HandshakeAddress.AsAddress(), net.Port(c.HandshakePort)), nil
+}
+
+// ConfigFromStreamSettings fetches Config from stream settings. Nil if not found.
+func ConfigFromStreamSettings(settings *internet.MemoryStreamConfig) *Config {
+	if settings == nil {
+		return nil
+	}
+	config, ok := settings.SecuritySettings.(*Config)
+	if !ok {
+		return nil
+	}
+	return config
+}
@@ -0,0 +1,72 @@
+package shadowtls
+
+import (
+	"encoding/binary"
+	"net"
+	"sync"
+)
+
+// conn is the data channel, which carries data in authenticated TLS application data records.
+type conn struct {
+	net.Conn
+	readAuth  *authenticator
+	writeAuth *authenticator
+	pending   []byte
+	// skipInvalid skips records until the first authenticated one. Clients may receive records
+	// relayed from the handshake server before the server switches to the data channel.
+	skipInvalid bool
+
+	// writeAccess is shared with the relay of the handshake on servers.
+	writeAccess *sync.Mutex
+}
+
+func (c *conn) Read(b []byte) (int, error) {
+	for len(c.pending) == 0 {
+		record, err := readRecord(c.Conn)
+		if err != nil {
+			return 0, err
+		}
+		if record[0] == contentTypeApplicationData && len(record) >= recordHeaderSize+tagSize {
+			tag := record[recordHeaderSize : recordHeaderSize+tagSize]
+			data := record[recordHeaderSize+tagSize:]
+			if c.readAuth.verify(data, tag) {
+				c.skipInvalid = false
+				c.pending = data
+				continue
+			}
+		}
+		if !c.skipInvalid {
+			return 0, newError("invalid record in data channel")
+		}
+	}
+
+	n := copy(b, c.pending)
+	c.pending = c.pending[n:]
+	return n, nil
+}
+
+func (c *conn) Write(b []byte) (int, error) {
+	c.writeAccess.Lock()
+	defer c.writeAccess.Unlock()
+
+	written := 0
+	for len(b) > 0 {
+		data := b
+		if len(data) > maxFrameSize {
+			data = data[:maxFrameSize]
+		}
+		record := make([]byte, recordHeaderSize+tagSize+len(data))
+		record[0] = contentTypeApplicationData
+		record[1] = 0x03
+		record[2] = 0x03
+		binary.BigEndian.PutUint16(record[3:], uint16(tagSize+len(data)))
+		copy(record[recordHeaderSize:], c.writeAuth.seal(data))
+		copy(record[recordHeaderSize+tagSize:], data)
+		if _, err := c.Conn.Write(record); err != nil {
+			return written, err
+		}
+		written += len(data)
+		b = b[len(data):]
+	}
+	return written, nil
+}
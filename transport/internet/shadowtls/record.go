@@ -0,0 +1,97 @@
+package shadowtls
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
+)
+
+const (
+	recordHeaderSize = 5
+	// maxRecordSize is the maximum size of the body of a TLS record, including the expansion of
+	// encryption.
+	maxRecordSize = 16384 + 2048
+
+	contentTypeHandshake       = 22
+	contentTypeApplicationData = 23
+
+	handshakeTypeServerHello = 2
+
+	// tagSize is the size of the HMAC prefixed to data of the data channel.
+	tagSize = 8
+	// maxFrameSize is the maximum size of data in a record of the data channel.
+	maxFrameSize = 16384 - tagSize
+
+	directionClient = 'C'
+	directionServer = 'S'
+)
+
+// readRecord reads a TLS record, including its header.
+func readRecord(r io.Reader) ([]byte, error) {
+	header := make([]byte, recordHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	length := int(binary.BigEndian.Uint16(header[3:]))
+	if length > maxRecordSize {
+		return nil, newError("record too large: ", length)
+	}
+	record := make([]byte, recordHeaderSize+length)
+	copy(record, header)
+	if _, err := io.ReadFull(r, record[recordHeaderSize:]); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// serverRandom returns the random of a ServerHello record, or nil if record is not a ServerHello.
+func serverRandom(record []byte) []byte {
+	// The body is the handshake type, length and legacy version, followed by the random.
+	const offset = recordHeaderSize + 1 + 3 + 2
+	if record[0] != contentTypeHandshake || len(record) < offset+32 || record[recordHeaderSize] != handshakeTypeServerHello {
+		return nil
+	}
+	return append([]byte(nil), record[offset:offset+32]...)
+}
+
+// authenticator computes tags of data sent in one direction. Each tag covers the sequence number
+// of the data, so that records can not be replayed or reordered.
+type authenticator struct {
+	key []byte
+	seq uint64
+}
+
+func newAuthenticator(password string, random []byte, direction byte) *authenticator {
+	h := hmac.New(sha256.New, []byte(password))
+	h.Write(random)
+	h.Write([]byte{direction})
+	return &authenticator{
+		key: h.Sum(nil),
+	}
+}
+
+func (a *authenticator) compute(data []byte) []byte {
+	var seq [8]byte
+	binary.BigEndian.PutUint64(seq[:], a.seq)
+	h := hmac.New(sha256.New, a.key)
+	h.Write(seq[:])
+	h.Write(data)
+	return h.Sum(nil)[:tagSize]
+}
+
+// seal returns the tag of the next data.
+func (a *authenticator) seal(data []byte) []byte {
+	tag := a.compute(data)
+	a.seq++
+	return tag
+}
+
+// verify checks the tag of the next data, and only moves to the next one if it is valid.
+func (a *authenticator) verify(data []byte, tag []byte) bool {
+	if !hmac.Equal(a.compute(data), tag) {
+		return false
+	}
+	a.seq++
+	return true
+}
@@ -0,0 +1,156 @@
+package shadowtls
+
+import (
+	"context"
+	"net"
+	"sync"
+
+	"github.com/v2fly/v2ray-core/v5/transport/internet"
+)
+
+// serverConn performs the handshake on the first Read or Write, like tls.Server.
+type serverConn struct {
+	net.Conn
+	config *Config
+	once   sync.Once
+	err    error
+	data   *conn
+}
+
+// Server returns the data channel of a connection to a server. Connections that are not
+// authenticated are relayed to the handshake server, and never return data.
+func Server(rawConn net.Conn, config *Config) net.Conn {
+	return &serverConn{
+		Conn:   rawConn,
+		config: config,
+	}
+}
+
+func (c *serverConn) handshake() error {
+	c.once.Do(func() {
+		c.data, c.err = serverHandshake(c.Conn, c.config)
+		if c.err != nil {
+			c.Conn.Close()
+		}
+	})
+	return c.err
+}
+
+func (c *serverConn) Read(b []byte) (int, error) {
+	if err := c.handshake(); err != nil {
+		return 0, err
+	}
+	return c.data.Read(b)
+}
+
+func (c *serverConn) Write(b []byte) (int, error) {
+	if err := c.handshake(); err != nil {
+		return 0, err
+	}
+	return c.data.Write(b)
+}
+
+// relay is the handshake of a connection relayed to the handshake server.
+type relay struct {
+	client      net.Conn
+	server      net.Conn
+	writeAccess sync.Mutex
+	switched    bool
+	random      []byte
+	randomReady chan struct{}
+}
+
+// relayServer copies records from the handshake server to the client until the connection
+// switches to the data channel.
+func (r *relay) relayServer() {
+	defer func() {
+		r.writeAccess.Lock()
+		if !r.switched {
+			r.client.Close()
+		}
+		r.writeAccess.Unlock()
+	}()
+
+	for {
+		record, err := readRecord(r.server)
+		if err != nil {
+			return
+		}
+		if r.random == nil {
+			if r.random = serverRandom(record); r.random != nil {
+				close(r.randomReady)
+			}
+		}
+
+		r.writeAccess.Lock()
+		if r.switched {
+			r.writeAccess.Unlock()
+			return
+		}
+		_, err = r.client.Write(record)
+		r.writeAccess.Unlock()
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (r *relay) switchToData() {
+	r.writeAccess.Lock()
+	r.switched = true
+	r.writeAccess.Unlock()
+	r.server.Close()
+}
+
+func serverHandshake(rawConn net.Conn, config *Config) (*conn, error) {
+	dest, err := config.getHandshakeDestination()
+	if err != nil {
+		return nil, err
+	}
+	server, err := internet.DialSystem(context.Background(), dest, nil)
+	if err != nil {
+		return nil, newError("failed to dial handshake server ", dest).Base(err)
+	}
+	defer server.Close()
+
+	r := &relay{
+		client:      rawConn,
+		server:      server,
+		randomReady: make(chan struct{}),
+	}
+	go r.relayServer()
+
+	var readAuth *authenticator
+	for {
+		record, err := readRecord(rawConn)
+		if err != nil {
+			return nil, newError("connection not authenticated").Base(err)
+		}
+
+		if readAuth == nil {
+			select {
+			case <-r.randomReady:
+				readAuth = newAuthenticator(config.Password, r.random, directionClient)
+			default:
+			}
+		}
+		if readAuth != nil && record[0] == contentTypeApplicationData && len(record) >= recordHeaderSize+tagSize {
+			tag := record[recordHeaderSize : recordHeaderSize+tagSize]
+			data := record[recordHeaderSize+tagSize:]
+			if readAuth.verify(data, tag) {
+				r.switchToData()
+				return &conn{
+					Conn:        rawConn,
+					readAuth:    readAuth,
+					writeAuth:   newAuthenticator(config.Password, r.random, directionServer),
+					pending:     data,
+					writeAccess: &r.writeAccess,
+				}, nil
+			}
+		}
+
+		if _, err := server.Write(record); err != nil {
+			return nil, newError("failed to relay to handshake server").Base(err)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+/*
+Package shadowtls implements a ShadowTLS-style security layer.
+
+The server relays the TLS handshake of the client to a real handshake server byte by byte, so
+that the handshake, including the certificate, is genuine. After the handshake, the client sends
+TLS application data records whose data is prefixed with an HMAC keyed by the shared password and
+the random of the ServerHello. The server switches to the data channel once a record is
+authenticated, and keeps relaying the connection to the handshake server otherwise.
+
+The data channel is authenticated but not encrypted, so the proxy protocol over it must encrypt
+its own traffic.
+*/
+package shadowtls
+
+//go:generate go run github.com/v2fly/v2ray-core/v5/common/errors/errorgen
@@ -0,0 +1,153 @@
+package shadowtls_test
+
+import (
+	"context"
+	"crypto/rand"
+	gotls "crypto/tls"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/common/protocol/tls/cert"
+	. "github.com/v2fly/v2ray-core/v5/transport/internet/shadowtls"
+	"github.com/v2fly/v2ray-core/v5/transport/internet/tls"
+)
+
+// startHandshakeServer starts a TLS server that answers every read with "decoy".
+func startHandshakeServer(t *testing.T) (net.Listener, *gotls.Config) {
+	config := (&tls.Config{
+		Certificate: []*tls.Certificate{
+			tls.ParseCertificate(cert.MustGenerate(nil, cert.DNSNames("www.v2fly.org"))),
+		},
+	}).GetTLSConfig()
+	listener, err := gotls.Listen("tcp", "127.0.0.1:0", config)
+	common.Must(err)
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				b := make([]byte, 1024)
+				for {
+					if _, err := conn.Read(b); err != nil {
+						return
+					}
+					if _, err := conn.Write([]byte("decoy")); err != nil {
+						return
+					}
+				}
+			}()
+		}
+	}()
+	return listener, config
+}
+
+// startServer starts a server that echoes authenticated connections.
+func startServer(t *testing.T, handshakeServer net.Listener) net.Listener {
+	config := &Config{
+		Password:         "password",
+		HandshakeAddress: net.NewIPOrDomain(net.LocalHostIP),
+		HandshakePort:    uint32(handshakeServer.Addr().(*net.TCPAddr).Port),
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	common.Must(err)
+
+	go func() {
+		for {
+			rawConn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				conn := Server(rawConn, config)
+				defer conn.Close()
+				io.Copy(conn, conn)
+			}()
+		}
+	}()
+	return listener
+}
+
+func dial(t *testing.T, listener net.Listener, password string) net.Conn {
+	rawConn, err := net.Dial("tcp", listener.Addr().String())
+	common.Must(err)
+	conn, err := Client(context.Background(), rawConn, &Config{
+		Password:     password,
+		ServerName:   "www.v2fly.org",
+		NextProtocol: []string{"h2", "http/1.1"},
+	})
+	common.Must(err)
+	return conn
+}
+
+func TestShadowTLS(t *testing.T) {
+	handshakeServer, _ := startHandshakeServer(t)
+	defer handshakeServer.Close()
+	listener := startServer(t, handshakeServer)
+	defer listener.Close()
+
+	conn := dial(t, listener, "password")
+	defer conn.Close()
+
+	const N = 64 * 1024
+	b1 := make([]byte, N)
+	common.Must2(rand.Read(b1))
+	go conn.Write(b1)
+
+	b2 := make([]byte, N)
+	common.Must2(io.ReadFull(conn, b2))
+	if r := cmp.Diff(b2, b1); r != "" {
+		t.Error(r)
+	}
+}
+
+func TestShadowTLSWrongPassword(t *testing.T) {
+	handshakeServer, _ := startHandshakeServer(t)
+	defer handshakeServer.Close()
+	listener := startServer(t, handshakeServer)
+	defer listener.Close()
+
+	conn := dial(t, listener, "invalid")
+	defer conn.Close()
+
+	common.Must2(conn.Write([]byte("test")))
+	common.Must(conn.SetReadDeadline(time.Now().Add(time.Second * 5)))
+	if _, err := conn.Read(make([]byte, 16)); err == nil {
+		t.Error("expected failure with wrong password")
+	}
+}
+
+func TestShadowTLSProbe(t *testing.T) {
+	handshakeServer, handshakeConfig := startHandshakeServer(t)
+	defer handshakeServer.Close()
+	listener := startServer(t, handshakeServer)
+	defer listener.Close()
+
+	conn, err := gotls.Dial("tcp", listener.Addr().String(), &gotls.Config{
+		ServerName:         "www.v2fly.org",
+		InsecureSkipVerify: true,
+	})
+	common.Must(err)
+	defer conn.Close()
+
+	certificate := conn.ConnectionState().PeerCertificates[0]
+	if r := cmp.Diff(certificate.Raw, handshakeConfig.Certificates[0].Certificate[0]); r != "" {
+		t.Error("unexpected certificate: ", r)
+	}
+
+	common.Must2(conn.Write([]byte("hello")))
+	b := make([]byte, 16)
+	n, err := conn.Read(b)
+	common.Must(err)
+	if string(b[:n]) != "decoy" {
+		t.Error("unexpected response: ", string(b[:n]))
+	}
+}
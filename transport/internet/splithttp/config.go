@@ -0,0 +1,50 @@
+package splithttp
+
+import (
+	"net/http"
+
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/transport/internet"
+)
+
+const protocolName = "splithttp"
+
+// GetNormalizedPath returns the path prefix of requests, which starts and ends with "/".
+func (c *Config) GetNormalizedPath() string {
+	path := c.Path
+	if path == "" || path[0] != '/' {
+		path = "/" + path
+	}
+	if path[len(path)-1] != '/' {
+		path += "/"
+	}
+	return path
+}
+
+func (c *Config) GetRequestHeader() http.Header {
+	header := http.Header{}
+	for _, h := range c.Header {
+		header.Add(h.Key, h.Value)
+	}
+	return header
+}
+
+func (c *Config) GetNormalizedMaxUploadSize() int32 {
+	if c.MaxUploadSize <= 0 {
+		return 1024 * 1024
+	}
+	return c.MaxUploadSize
+}
+
+func (c *Config) GetNormalizedMaxConcurrentUploads() int32 {
+	if c.MaxConcurrentUploads <= 0 {
+		return 10
+	}
+	return c.MaxConcurrentUploads
+}
+
+func init() {
+	common.Must(internet.RegisterProtocolConfigCreator(protocolName, func() interface{} {
+		return new(Config)
+	}))
+}
@@ -0,0 +1,272 @@
+package splithttp
+
+import (
+	"bytes"
+	"context"
+	gotls "crypto/tls"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+
+	"golang.org/x/net/http2"
+
+	core "github.com/v2fly/v2ray-core/v5"
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/buf"
+	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/common/session"
+	"github.com/v2fly/v2ray-core/v5/common/uuid"
+	"github.com/v2fly/v2ray-core/v5/transport/internet"
+	"github.com/v2fly/v2ray-core/v5/transport/internet/tls"
+	"github.com/v2fly/v2ray-core/v5/transport/pipe"
+)
+
+type dialerKey struct {
+	dest           net.Destination
+	streamSettings *internet.MemoryStreamConfig
+}
+
+var (
+	globalDialerMap    map[dialerKey]*http.Client
+	globalDialerAccess sync.Mutex
+)
+
+func getHTTPClient(ctx context.Context, dest net.Destination, streamSettings *internet.MemoryStreamConfig) *http.Client {
+	globalDialerAccess.Lock()
+	defer globalDialerAccess.Unlock()
+
+	if globalDialerMap == nil {
+		globalDialerMap = make(map[dialerKey]*http.Client)
+	}
+	key := dialerKey{dest: dest, streamSettings: streamSettings}
+	if client, found := globalDialerMap[key]; found {
+		return client
+	}
+
+	config := streamSettings.ProtocolSettings.(*Config)
+	tlsConfig := tls.ConfigFromStreamSettings(streamSettings)
+	useH2 := config.Mode == Mode_H2 || (config.Mode == Mode_Auto && tlsConfig != nil)
+	detachedContext := core.ToBackgroundDetachedContext(ctx)
+
+	dial := func(ctx context.Context, nextProto string) (net.Conn, error) {
+		conn, err := internet.DialSystem(detachedContext, dest, streamSettings.SocketSettings)
+		if err != nil {
+			return nil, err
+		}
+		if tlsConfig == nil {
+			return conn, nil
+		}
+		tlsConn := gotls.Client(conn, tlsConfig.GetTLSConfig(tls.WithDestination(dest), tls.WithNextProto(nextProto)))
+		if err := tls.Handshake(ctx, tlsConn); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		if p := tlsConn.ConnectionState().NegotiatedProtocol; p != "" && p != nextProto {
+			tlsConn.Close()
+			return nil, newError("unexpected ALPN protocol ", p, "; want ", nextProto)
+		}
+		return tlsConn, nil
+	}
+
+	var transport http.RoundTripper
+	if useH2 {
+		transport = &http2.Transport{
+			// DialTLS has no context of requests, so handshakes are traced in the session that
+			// creates the client.
+			DialTLS: func(network, addr string, _ *gotls.Config) (net.Conn, error) {
+				return dial(detachedContext, http2.NextProtoTLS)
+			},
+			AllowHTTP:          true,
+			DisableCompression: true,
+		}
+	} else {
+		dialHTTP1 := func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return dial(ctx, "http/1.1")
+		}
+		transport = &http.Transport{
+			DialContext:         dialHTTP1,
+			DialTLSContext:      dialHTTP1,
+			MaxIdleConnsPerHost: int(config.GetNormalizedMaxConcurrentUploads()) + 1,
+			DisableCompression:  true,
+		}
+	}
+
+	client := &http.Client{
+		Transport: transport,
+	}
+	globalDialerMap[key] = client
+	return client
+}
+
+// Dial dials a new split HTTP connection to the given destination.
+func Dial(ctx context.Context, dest net.Destination, streamSettings *internet.MemoryStreamConfig) (internet.Connection, error) {
+	newError("creating connection to ", dest).WriteToLog(session.ExportIDToError(ctx))
+
+	config := streamSettings.ProtocolSettings.(*Config)
+	client := getHTTPClient(ctx, dest, streamSettings)
+
+	scheme := "http"
+	if tls.ConfigFromStreamSettings(streamSettings) != nil {
+		scheme = "https"
+	}
+	host := config.Host
+	if host == "" {
+		host = dest.NetAddr()
+	}
+	sessionID := uuid.New()
+	baseURL := url.URL{
+		Scheme: scheme,
+		Host:   dest.NetAddr(),
+		Path:   config.GetNormalizedPath() + sessionID.String(),
+	}
+
+	connCtx, cancel := context.WithCancel(core.ToBackgroundDetachedContext(ctx))
+	newRequest := func(method string, requestURL string, body io.Reader) *http.Request {
+		request, err := http.NewRequestWithContext(connCtx, method, requestURL, body)
+		common.Must(err)
+		request.Host = host
+		request.Header = config.GetRequestHeader()
+		return request
+	}
+
+	downlink := &downlinkReader{ready: make(chan struct{})}
+	go func() {
+		response, err := client.Do(newRequest(http.MethodGet, baseURL.String(), nil)) // nolint: bodyclose
+		if err == nil && response.StatusCode != http.StatusOK {
+			response.Body.Close()
+			err = newError("unexpected status: ", response.Status)
+		}
+		if err != nil {
+			downlink.err = newError("failed to dial downlink to ", dest).Base(err)
+			cancel()
+		} else {
+			downlink.body = response.Body
+		}
+		close(downlink.ready)
+	}()
+
+	uplinkReader, uplinkWriter := pipe.New(pipe.WithSizeLimit(config.GetNormalizedMaxUploadSize()))
+	go func() {
+		uploader := &uploader{
+			client:  client,
+			baseURL: baseURL.String(),
+			request: newRequest,
+			sem:     make(chan struct{}, config.GetNormalizedMaxConcurrentUploads()),
+		}
+		if err := uploader.run(connCtx, uplinkReader, config.GetNormalizedMaxUploadSize()); err != nil {
+			newError("failed to upload to ", dest).Base(err).WriteToLog(session.ExportIDToError(ctx))
+			uplinkReader.Interrupt()
+			cancel()
+		}
+	}()
+
+	return buf.NewConnection(
+		buf.ConnectionInputMulti(uplinkWriter),
+		buf.ConnectionOutput(downlink),
+		buf.ConnectionOnClose(common.ChainedClosable{uplinkWriter, downlink, closerFunc(cancel)}),
+	), nil
+}
+
+func init() {
+	common.Must(internet.RegisterTransportDialer(protocolName, Dial))
+}
+
+type closerFunc func()
+
+func (f closerFunc) Close() error {
+	f()
+	return nil
+}
+
+type uploader struct {
+	client  *http.Client
+	baseURL string
+	request func(method string, requestURL string, body io.Reader) *http.Request
+	sem     chan struct{}
+	seq     uint64
+}
+
+// run sends everything from the reader in POST requests of at most maxSize bytes. Up to
+// cap(u.sem) requests are in flight at the same time.
+func (u *uploader) run(ctx context.Context, reader buf.Reader, maxSize int32) error {
+	errChan := make(chan error, 1)
+	var pending buf.MultiBuffer
+	for {
+		if pending.IsEmpty() {
+			mb, err := reader.ReadMultiBuffer()
+			if err != nil {
+				return nil
+			}
+			pending = mb
+		}
+
+		var chunk buf.MultiBuffer
+		pending, chunk = buf.SplitSize(pending, maxSize)
+		payload := make([]byte, chunk.Len())
+		chunk.Copy(payload)
+		buf.ReleaseMulti(chunk)
+
+		select {
+		case u.sem <- struct{}{}:
+		case err := <-errChan:
+			buf.ReleaseMulti(pending)
+			return err
+		case <-ctx.Done():
+			buf.ReleaseMulti(pending)
+			return ctx.Err()
+		}
+
+		seq := u.seq
+		u.seq++
+		go func() {
+			defer func() { <-u.sem }()
+			if err := u.upload(seq, payload); err != nil {
+				select {
+				case errChan <- err:
+				default:
+				}
+			}
+		}()
+	}
+}
+
+func (u *uploader) upload(seq uint64, payload []byte) error {
+	request := u.request(http.MethodPost, u.baseURL+"/"+strconv.FormatUint(seq, 10), bytes.NewReader(payload))
+	request.ContentLength = int64(len(payload))
+	response, err := u.client.Do(request)
+	if err != nil {
+		return err
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return newError("unexpected status: ", response.Status)
+	}
+	return nil
+}
+
+// downlinkReader reads the response of the downlink request, once it arrives.
+type downlinkReader struct {
+	ready chan struct{}
+	body  io.ReadCloser
+	err   error
+}
+
+func (r *downlinkReader) Read(b []byte) (int, error) {
+	<-r.ready
+	if r.err != nil {
+		return 0, r.err
+	}
+	return r.body.Read(b)
+}
+
+func (r *downlinkReader) Close() error {
+	go func() {
+		<-r.ready
+		if r.body != nil {
+			r.body.Close()
+		}
+	}()
+	return nil
+}
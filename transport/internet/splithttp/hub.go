@@ -0,0 +1,367 @@
+package splithttp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/buf"
+	"github.com/v2fly/v2ray-core/v5/common/net"
+	http_proto "github.com/v2fly/v2ray-core/v5/common/protocol/http"
+	"github.com/v2fly/v2ray-core/v5/common/session"
+	"github.com/v2fly/v2ray-core/v5/common/signal/done"
+	"github.com/v2fly/v2ray-core/v5/transport/internet"
+	"github.com/v2fly/v2ray-core/v5/transport/internet/tls"
+)
+
+const (
+	// sessionTimeout is how long a session waits for its downlink request.
+	sessionTimeout = time.Second * 30
+	// maxPendingSessions is the number of sessions waiting for their downlink requests. Uploads
+	// of further new sessions are rejected.
+	maxPendingSessions = 1024
+	// maxBufferedBytes is the size of uploads buffered by all sessions of a listener. Uploads are
+	// rejected while the buffers are full.
+	maxBufferedBytes = 128 * 1024 * 1024
+)
+
+type httpSession struct {
+	uploadQueue *uploadQueue
+	// connected is closed when the downlink request arrives.
+	connected *done.Instance
+}
+
+type Listener struct {
+	access   sync.Mutex
+	sessions map[string]*httpSession
+	// pending is the number of sessions without downlink requests.
+	pending int
+	// buffered is the size of uploads buffered by all sessions.
+	buffered int64
+
+	server   *http.Server
+	listener net.Listener
+	config   *Config
+	addConn  internet.ConnHandler
+	locker   *internet.FileLocker // for unix domain socket
+}
+
+// getOrCreateSession returns the session of the ID, or creates one. It returns nil if the
+// session is new, and too many sessions are waiting for their downlink requests.
+func (l *Listener) getOrCreateSession(id string) *httpSession {
+	l.access.Lock()
+	defer l.access.Unlock()
+
+	if s, found := l.sessions[id]; found {
+		return s
+	}
+	if l.pending >= maxPendingSessions {
+		return nil
+	}
+	s := &httpSession{
+		uploadQueue: newUploadQueue(int(l.config.GetNormalizedMaxConcurrentUploads()), l.releaseBuffer),
+		connected:   done.New(),
+	}
+	l.sessions[id] = s
+	l.pending++
+
+	// Remove the session if the downlink request never arrives.
+	time.AfterFunc(sessionTimeout, func() {
+		if !s.connected.Done() {
+			l.removeSession(id, s)
+		}
+	})
+	return s
+}
+
+// connectSession marks the session as connected by its downlink request, and returns false if
+// it is already connected.
+func (l *Listener) connectSession(s *httpSession) bool {
+	l.access.Lock()
+	defer l.access.Unlock()
+
+	if s.connected.Done() {
+		return false
+	}
+	s.connected.Close()
+	l.pending--
+	return true
+}
+
+func (l *Listener) removeSession(id string, s *httpSession) {
+	l.access.Lock()
+	if l.sessions[id] == s {
+		delete(l.sessions, id)
+		if !s.connected.Done() {
+			l.pending--
+		}
+	}
+	l.access.Unlock()
+	s.uploadQueue.Close()
+}
+
+// reserveBuffer reserves the size of an upload in the buffers, and returns false if they are full.
+func (l *Listener) reserveBuffer(size int64) bool {
+	l.access.Lock()
+	defer l.access.Unlock()
+
+	if l.buffered+size > maxBufferedBytes {
+		return false
+	}
+	l.buffered += size
+	return true
+}
+
+func (l *Listener) releaseBuffer(size int64) {
+	l.access.Lock()
+	l.buffered -= size
+	l.access.Unlock()
+}
+
+func (l *Listener) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	if l.config.Host != "" && !strings.EqualFold(request.Host, l.config.Host) {
+		writer.WriteHeader(http.StatusNotFound)
+		return
+	}
+	path := l.config.GetNormalizedPath()
+	if !strings.HasPrefix(request.URL.Path, path) {
+		writer.WriteHeader(http.StatusNotFound)
+		return
+	}
+	parts := strings.Split(request.URL.Path[len(path):], "/")
+	if parts[0] == "" {
+		writer.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	sessionID := parts[0]
+
+	switch {
+	case request.Method == http.MethodPost && len(parts) == 2:
+		seq, err := strconv.ParseUint(parts[1], 10, 64)
+		if err != nil {
+			writer.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		l.handleUpload(writer, request, sessionID, seq)
+	case request.Method == http.MethodGet && len(parts) == 1:
+		l.handleDownload(writer, request, sessionID)
+	default:
+		writer.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (l *Listener) handleUpload(writer http.ResponseWriter, request *http.Request, sessionID string, seq uint64) {
+	s := l.getOrCreateSession(sessionID)
+	if s == nil {
+		newError("too many pending sessions, rejecting upload of session ", sessionID).AtWarning().WriteToLog()
+		writer.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	// The maximum size is reserved before reading, and the rest is released after.
+	maxUploadSize := int64(l.config.GetNormalizedMaxUploadSize())
+	if !l.reserveBuffer(maxUploadSize) {
+		newError("upload buffers are full, rejecting upload of session ", sessionID).AtWarning().WriteToLog()
+		writer.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	payload, err := io.ReadAll(io.LimitReader(request.Body, maxUploadSize+1))
+	if err != nil {
+		l.releaseBuffer(maxUploadSize)
+		newError("failed to read upload of session ", sessionID).Base(err).WriteToLog()
+		writer.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if int64(len(payload)) > maxUploadSize {
+		l.releaseBuffer(maxUploadSize)
+		writer.WriteHeader(http.StatusRequestEntityTooLarge)
+		return
+	}
+	l.releaseBuffer(maxUploadSize - int64(len(payload)))
+
+	// The queue releases the payload from the buffers when it is read or dropped.
+	if err := s.uploadQueue.Push(seq, payload); err != nil {
+		newError("failed to push upload of session ", sessionID).Base(err).WriteToLog()
+		writer.WriteHeader(http.StatusConflict)
+		return
+	}
+	writer.WriteHeader(http.StatusOK)
+}
+
+func (l *Listener) handleDownload(writer http.ResponseWriter, request *http.Request, sessionID string) {
+	flusher, ok := writer.(http.Flusher)
+	if !ok {
+		writer.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	s := l.getOrCreateSession(sessionID)
+	if s == nil {
+		newError("too many pending sessions, rejecting session ", sessionID).AtWarning().WriteToLog()
+		writer.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	if !l.connectSession(s) {
+		writer.WriteHeader(http.StatusConflict)
+		return
+	}
+	defer l.removeSession(sessionID, s)
+
+	// Prevent proxies and CDNs from buffering the response.
+	writer.Header().Set("Cache-Control", "no-store")
+	writer.Header().Set("X-Accel-Buffering", "no")
+	writer.Header().Set("Content-Type", "text/event-stream")
+	writer.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	remoteAddr := l.Addr()
+	if dest, err := net.ParseDestination(request.RemoteAddr); err == nil {
+		remoteAddr = &net.TCPAddr{
+			IP:   dest.Address.IP(),
+			Port: int(dest.Port),
+		}
+	}
+	forwardedAddrs := http_proto.ParseXForwardedFor(request.Header)
+	if len(forwardedAddrs) > 0 && forwardedAddrs[0].Family().IsIP() {
+		remoteAddr = &net.TCPAddr{
+			IP:   forwardedAddrs[0].IP(),
+			Port: 0,
+		}
+	}
+
+	finished := done.New()
+	responseWriter := &flushWriter{writer: writer, flusher: flusher, done: finished}
+	conn := buf.NewConnection(
+		buf.ConnectionOutput(s.uploadQueue),
+		buf.ConnectionInput(responseWriter),
+		buf.ConnectionOnClose(common.ChainedClosable{finished, s.uploadQueue}),
+		buf.ConnectionLocalAddr(l.Addr()),
+		buf.ConnectionRemoteAddr(remoteAddr),
+	)
+	l.addConn(conn)
+
+	select {
+	case <-finished.Wait():
+	case <-request.Context().Done():
+	}
+	// The response writer must not be used after the handler returns.
+	responseWriter.finish()
+}
+
+type flushWriter struct {
+	access  sync.Mutex
+	writer  io.Writer
+	flusher http.Flusher
+	done    *done.Instance
+}
+
+func (w *flushWriter) Write(p []byte) (int, error) {
+	w.access.Lock()
+	defer w.access.Unlock()
+
+	if w.done.Done() {
+		return 0, io.ErrClosedPipe
+	}
+	n, err := w.writer.Write(p)
+	if err == nil {
+		w.flusher.Flush()
+	}
+	return n, err
+}
+
+func (w *flushWriter) finish() {
+	w.access.Lock()
+	w.done.Close()
+	w.access.Unlock()
+}
+
+func ListenSplitHTTP(ctx context.Context, address net.Address, port net.Port, streamSettings *internet.MemoryStreamConfig, addConn internet.ConnHandler) (internet.Listener, error) {
+	l := &Listener{
+		sessions: make(map[string]*httpSession),
+		config:   streamSettings.ProtocolSettings.(*Config),
+		addConn:  addConn,
+	}
+
+	var listener net.Listener
+	var err error
+	if port == net.Port(0) { // unix
+		listener, err = internet.ListenSystem(ctx, &net.UnixAddr{
+			Name: address.Domain(),
+			Net:  "unix",
+		}, streamSettings.SocketSettings)
+		if err != nil {
+			return nil, newError("failed to listen unix domain socket(for split HTTP) on ", address).Base(err)
+		}
+		newError("listening unix domain socket(for split HTTP) on ", address).WriteToLog(session.ExportIDToError(ctx))
+		locker := ctx.Value(address.Domain())
+		if locker != nil {
+			l.locker = locker.(*internet.FileLocker)
+		}
+	} else { // tcp
+		listener, err = internet.ListenSystem(ctx, &net.TCPAddr{
+			IP:   address.IP(),
+			Port: int(port),
+		}, streamSettings.SocketSettings)
+		if err != nil {
+			return nil, newError("failed to listen TCP(for split HTTP) on ", address, ":", port).Base(err)
+		}
+		newError("listening TCP(for split HTTP) on ", address, ":", port).WriteToLog(session.ExportIDToError(ctx))
+	}
+
+	if streamSettings.SocketSettings != nil && streamSettings.SocketSettings.AcceptProxyProtocol {
+		newError("accepting PROXY protocol").AtWarning().WriteToLog(session.ExportIDToError(ctx))
+	}
+
+	l.listener = listener
+	config := tls.ConfigFromStreamSettings(streamSettings)
+	if config == nil {
+		l.server = &http.Server{
+			Handler:           h2c.NewHandler(l, &http2.Server{}),
+			ReadHeaderTimeout: time.Second * 4,
+		}
+	} else {
+		l.server = &http.Server{
+			TLSConfig:         config.GetTLSConfig(tls.WithNextProto("h2", "http/1.1")),
+			Handler:           l,
+			ReadHeaderTimeout: time.Second * 4,
+		}
+	}
+
+	go func() {
+		var err error
+		if config == nil {
+			err = l.server.Serve(listener)
+		} else {
+			err = l.server.ServeTLS(listener, "", "")
+		}
+		if err != nil {
+			newError("failed to serve http for split HTTP").Base(err).AtWarning().WriteToLog(session.ExportIDToError(ctx))
+		}
+	}()
+
+	return l, nil
+}
+
+// Addr implements net.Listener.Addr().
+func (l *Listener) Addr() net.Addr {
+	return l.listener.Addr()
+}
+
+// Close implements net.Listener.Close().
+func (l *Listener) Close() error {
+	if l.locker != nil {
+		l.locker.Release()
+	}
+	return l.server.Close()
+}
+
+func init() {
+	common.Must(internet.RegisterTransportListener(protocolName, ListenSplitHTTP))
+}
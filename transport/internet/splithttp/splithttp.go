@@ -0,0 +1,10 @@
+/*
+Package splithttp implements split HTTP transport
+
+Split HTTP transport carries the uplink of a connection as sequenced POST requests, and the downlink
+as the streaming response of a GET request, so that it works through CDNs which only allow plain
+requests and responses.
+*/
+package splithttp
+
+//go:generate go run github.com/v2fly/v2ray-core/v5/common/errors/errorgen
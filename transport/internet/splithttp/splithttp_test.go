@@ -0,0 +1,131 @@
+package splithttp_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/common/protocol/tls/cert"
+	"github.com/v2fly/v2ray-core/v5/testing/servers/tcp"
+	"github.com/v2fly/v2ray-core/v5/transport/internet"
+	. "github.com/v2fly/v2ray-core/v5/transport/internet/splithttp"
+	"github.com/v2fly/v2ray-core/v5/transport/internet/tls"
+)
+
+func testEcho(t *testing.T, streamSettings *internet.MemoryStreamConfig) {
+	port := tcp.PickPort()
+	listen, err := ListenSplitHTTP(context.Background(), net.LocalHostIP, port, streamSettings, func(conn internet.Connection) {
+		go func(c internet.Connection) {
+			defer c.Close()
+			io.Copy(c, c)
+		}(conn)
+	})
+	common.Must(err)
+	defer listen.Close()
+
+	conn, err := Dial(context.Background(), net.TCPDestination(net.DomainAddress("localhost"), port), streamSettings)
+	common.Must(err)
+	defer conn.Close()
+
+	payload := make([]byte, 1024*1024)
+	common.Must2(rand.Read(payload))
+	go func() {
+		for i := 0; i < len(payload); i += 8192 {
+			if _, err := conn.Write(payload[i : i+8192]); err != nil {
+				return
+			}
+		}
+	}()
+
+	response := make([]byte, len(payload))
+	common.Must2(io.ReadFull(conn, response))
+	if !bytes.Equal(payload, response) {
+		t.Error("response mismatch")
+	}
+}
+
+func TestSplitHTTP1(t *testing.T) {
+	testEcho(t, &internet.MemoryStreamConfig{
+		ProtocolName: "splithttp",
+		ProtocolSettings: &Config{
+			Path:          "split",
+			MaxUploadSize: 32 * 1024,
+			Mode:          Mode_HTTP1,
+		},
+	})
+}
+
+func TestSplitH2C(t *testing.T) {
+	testEcho(t, &internet.MemoryStreamConfig{
+		ProtocolName: "splithttp",
+		ProtocolSettings: &Config{
+			Path:                 "/split/",
+			MaxUploadSize:        16 * 1024,
+			MaxConcurrentUploads: 4,
+			Mode:                 Mode_H2,
+		},
+	})
+}
+
+func TestSplitHTTPWithTLS(t *testing.T) {
+	for _, mode := range []Mode{Mode_Auto, Mode_HTTP1} {
+		testEcho(t, &internet.MemoryStreamConfig{
+			ProtocolName: "splithttp",
+			ProtocolSettings: &Config{
+				Host: "www.v2fly.org",
+				Mode: mode,
+			},
+			SecurityType: "tls",
+			SecuritySettings: &tls.Config{
+				AllowInsecure: true,
+				Certificate:   []*tls.Certificate{tls.ParseCertificate(cert.MustGenerate(nil, cert.CommonName("localhost")))},
+			},
+		})
+	}
+}
+
+func TestPendingSessionLimit(t *testing.T) {
+	port := tcp.PickPort()
+	listen, err := ListenSplitHTTP(context.Background(), net.LocalHostIP, port, &internet.MemoryStreamConfig{
+		ProtocolName:     "splithttp",
+		ProtocolSettings: &Config{Path: "/split/"},
+	}, func(conn internet.Connection) {
+		conn.Close()
+	})
+	common.Must(err)
+	defer listen.Close()
+
+	upload := func(session int) int {
+		url := "http://127.0.0.1:" + port.String() + "/split/" + strconv.Itoa(session) + "/0"
+		response, err := http.Post(url, "application/octet-stream", strings.NewReader("payload"))
+		common.Must(err)
+		response.Body.Close()
+		return response.StatusCode
+	}
+
+	// Sessions that never open their downlinks are kept until the limit.
+	for i := 0; i < 1024; i++ {
+		if code := upload(i); code != http.StatusOK {
+			t.Fatal("unexpected status of session ", i, ": ", code)
+		}
+	}
+	if code := upload(1024); code != http.StatusServiceUnavailable {
+		t.Error("expected the session to be rejected, but got ", code)
+	}
+	// Uploads of existing sessions are still accepted.
+	request, err := http.NewRequest(http.MethodPost, "http://127.0.0.1:"+port.String()+"/split/0/1", strings.NewReader("payload"))
+	common.Must(err)
+	response, err := http.DefaultClient.Do(request)
+	common.Must(err)
+	response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		t.Error("unexpected status of existing session: ", response.StatusCode)
+	}
+}
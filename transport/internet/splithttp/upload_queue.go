@@ -0,0 +1,114 @@
+package splithttp
+
+import (
+	"container/heap"
+	"io"
+	"sync"
+)
+
+type packet struct {
+	seq     uint64
+	payload []byte
+}
+
+type packetHeap []*packet
+
+func (h packetHeap) Len() int           { return len(h) }
+func (h packetHeap) Less(i, j int) bool { return h[i].seq < h[j].seq }
+func (h packetHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *packetHeap) Push(x interface{}) {
+	*h = append(*h, x.(*packet))
+}
+
+func (h *packetHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return x
+}
+
+// uploadQueue reassembles the uplink of a session from packets that may arrive out of order.
+type uploadQueue struct {
+	access     sync.Mutex
+	packets    packetHeap
+	current    []byte
+	nextSeq    uint64
+	maxPackets int
+	closed     bool
+	updated    *sync.Cond
+	// release is called with the sizes of packets when they leave the queue.
+	release func(int64)
+}
+
+func newUploadQueue(maxPackets int, release func(int64)) *uploadQueue {
+	q := &uploadQueue{
+		maxPackets: maxPackets,
+		release:    release,
+	}
+	q.updated = sync.NewCond(&q.access)
+	return q
+}
+
+// Push adds a packet to the queue. It blocks while the queue is full, unless the packet is
+// the next one to read, so that a slow reader slows down the uploads. The payload is released
+// if it is not added.
+func (q *uploadQueue) Push(seq uint64, payload []byte) error {
+	q.access.Lock()
+	defer q.access.Unlock()
+
+	for !q.closed && seq != q.nextSeq && len(q.packets) >= q.maxPackets {
+		q.updated.Wait()
+	}
+	if q.closed {
+		q.release(int64(len(payload)))
+		return io.ErrClosedPipe
+	}
+	if seq < q.nextSeq {
+		q.release(int64(len(payload)))
+		return newError("duplicated packet ", seq)
+	}
+	heap.Push(&q.packets, &packet{seq: seq, payload: payload})
+	q.updated.Broadcast()
+	return nil
+}
+
+// Read implements io.Reader.
+func (q *uploadQueue) Read(b []byte) (int, error) {
+	q.access.Lock()
+	defer q.access.Unlock()
+
+	for len(q.current) == 0 {
+		if len(q.packets) > 0 && q.packets[0].seq == q.nextSeq {
+			q.current = heap.Pop(&q.packets).(*packet).payload
+			q.release(int64(len(q.current)))
+			q.nextSeq++
+			q.updated.Broadcast()
+			continue
+		}
+		if q.closed {
+			return 0, io.EOF
+		}
+		q.updated.Wait()
+	}
+
+	n := copy(b, q.current)
+	q.current = q.current[n:]
+	return n, nil
+}
+
+// Close implements io.Closer.
+func (q *uploadQueue) Close() error {
+	q.access.Lock()
+	defer q.access.Unlock()
+
+	q.closed = true
+	for _, p := range q.packets {
+		q.release(int64(len(p.payload)))
+	}
+	q.packets = nil
+	q.updated.Broadcast()
+	return nil
+}
@@ -45,6 +45,9 @@ func hasBindAddr(sockopt *SocketConfig) bool {
 }
 
 func (d *DefaultSystemDialer) Dial(ctx context.Context, src net.Address, dest net.Destination, sockopt *SocketConfig) (net.Conn, error) {
+	if (src == nil || src == net.AnyIP) && sockopt != nil && len(sockopt.BindAddressPool) > 0 {
+		src = SelectAddressFromPool(ctx, sockopt.getBindAddressPool(), sockopt.BindAddressPoolStrategy, dest)
+	}
 	if dest.Network == net.Network_UDP && !hasBindAddr(sockopt) {
 		srcAddr := resolveSrcAddr(net.Network_UDP, src)
 		if srcAddr == nil {
@@ -8,6 +8,8 @@ import (
 	"github.com/v2fly/v2ray-core/v5/common/serial"
 	"github.com/v2fly/v2ray-core/v5/common/session"
 	"github.com/v2fly/v2ray-core/v5/transport/internet"
+	"github.com/v2fly/v2ray-core/v5/transport/internet/reality"
+	"github.com/v2fly/v2ray-core/v5/transport/internet/shadowtls"
 	"github.com/v2fly/v2ray-core/v5/transport/internet/tls"
 )
 
@@ -29,6 +31,24 @@ func Dial(ctx context.Context, dest net.Destination, streamSettings *internet.Me
 			}
 		*/
 		conn = tls.Client(conn, tlsConfig)
+		if err := tls.TraceHandshake(ctx, conn); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	} else if config := shadowtls.ConfigFromStreamSettings(streamSettings); config != nil {
+		shadowConn, err := shadowtls.Client(ctx, conn, config)
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		conn = shadowConn
+	} else if config := reality.ConfigFromStreamSettings(streamSettings); config != nil {
+		realityConn, err := reality.Client(ctx, conn, config)
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		conn = realityConn
 	}
 
 	tcpSettings := streamSettings.ProtocolSettings.(*Config)
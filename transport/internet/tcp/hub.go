@@ -11,17 +11,21 @@ import (
 	"github.com/v2fly/v2ray-core/v5/common/serial"
 	"github.com/v2fly/v2ray-core/v5/common/session"
 	"github.com/v2fly/v2ray-core/v5/transport/internet"
+	"github.com/v2fly/v2ray-core/v5/transport/internet/reality"
+	"github.com/v2fly/v2ray-core/v5/transport/internet/shadowtls"
 	"github.com/v2fly/v2ray-core/v5/transport/internet/tls"
 )
 
 // Listener is an internet.Listener that listens for TCP connections.
 type Listener struct {
-	listener   net.Listener
-	tlsConfig  *gotls.Config
-	authConfig internet.ConnectionAuthenticator
-	config     *Config
-	addConn    internet.ConnHandler
-	locker     *internet.FileLocker // for unix domain socket
+	listener        net.Listener
+	tlsConfig       *gotls.Config
+	shadowTLSConfig *shadowtls.Config
+	realityServer   *reality.Server
+	authConfig      internet.ConnectionAuthenticator
+	config          *Config
+	addConn         internet.ConnHandler
+	locker          *internet.FileLocker // for unix domain socket
 }
 
 // ListenTCP creates a new Listener based on configurations.
@@ -72,6 +76,15 @@ func ListenTCP(ctx context.Context, address net.Address, port net.Port, streamSe
 	if config := tls.ConfigFromStreamSettings(streamSettings); config != nil {
 		l.tlsConfig = config.GetTLSConfig()
 	}
+	l.shadowTLSConfig = shadowtls.ConfigFromStreamSettings(streamSettings)
+	if config := reality.ConfigFromStreamSettings(streamSettings); config != nil {
+		server, err := reality.NewServer(config)
+		if err != nil {
+			listener.Close()
+			return nil, newError("invalid REALITY settings").Base(err).AtError()
+		}
+		l.realityServer = server
+	}
 
 	if tcpSettings.HeaderSettings != nil {
 		headerConfig, err := serial.GetInstanceOf(tcpSettings.HeaderSettings)
@@ -106,6 +119,10 @@ func (v *Listener) keepAccepting() {
 
 		if v.tlsConfig != nil {
 			conn = tls.Server(conn, v.tlsConfig)
+		} else if v.shadowTLSConfig != nil {
+			conn = shadowtls.Server(conn, v.shadowTLSConfig)
+		} else if v.realityServer != nil {
+			conn = v.realityServer.Server(conn)
 		}
 		if v.authConfig != nil {
 			conn = v.authConfig.Server(conn)
@@ -7,6 +7,7 @@ import (
 	"github.com/v2fly/v2ray-core/v5/common"
 	"github.com/v2fly/v2ray-core/v5/common/buf"
 	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/features/tracing"
 )
 
 //go:generate go run github.com/v2fly/v2ray-core/v5/common/errors/errorgen
@@ -41,6 +42,46 @@ func Client(c net.Conn, config *tls.Config) net.Conn {
 	return &Conn{Conn: tlsConn}
 }
 
+// handshaker is a client connection of crypto/tls, or a Conn.
+type handshaker interface {
+	HandshakeContext(ctx context.Context) error
+	ConnectionState() tls.ConnectionState
+}
+
+// Handshake performs the handshake of a client connection, in a span if the context is traced.
+func Handshake(ctx context.Context, conn handshaker) error {
+	ctx, span := tracing.Start(ctx, "tls.handshake")
+	defer span.End()
+
+	if err := conn.HandshakeContext(ctx); err != nil {
+		span.RecordError(err)
+		return err
+	}
+	RecordHandshake(span, conn.ConnectionState())
+	return nil
+}
+
+// RecordHandshake sets the attributes of a span of a TLS handshake by the state of the connection.
+func RecordHandshake(span tracing.Span, state tls.ConnectionState) {
+	span.SetAttribute("server_name", state.ServerName)
+	span.SetAttribute("alpn", state.NegotiatedProtocol)
+	span.SetAttribute("resumed", state.DidResume)
+}
+
+// TraceHandshake performs the handshake of a client connection in a span, if the current span of
+// the context is recorded. Otherwise, the handshake is left to the first read or write of the
+// connection.
+func TraceHandshake(ctx context.Context, conn net.Conn) error {
+	c, ok := conn.(handshaker)
+	if !ok || !tracing.IsRecording(ctx) {
+		return nil
+	}
+	if err := Handshake(ctx, c); err != nil {
+		return newError("failed to complete TLS handshake").Base(err)
+	}
+	return nil
+}
+
 /*
 func copyConfig(c *tls.Config) *utls.Config {
 	return &utls.Config{
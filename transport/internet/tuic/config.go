@@ -0,0 +1,53 @@
+package tuic
+
+import (
+	"time"
+
+	"github.com/lucas-clemente/quic-go"
+
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/transport/internet"
+)
+
+const (
+	protocolName   = "tuic"
+	internalDomain = "tuic.internal.v2fly.org"
+)
+
+func (c *Config) getHeartbeat() time.Duration {
+	if c.Heartbeat > 0 {
+		return time.Second * time.Duration(c.Heartbeat)
+	}
+	return time.Second * 10
+}
+
+func (c *Config) getAuthTimeout() time.Duration {
+	if c.AuthTimeout > 0 {
+		return time.Second * time.Duration(c.AuthTimeout)
+	}
+	return time.Second * 3
+}
+
+func getQuicConfig(config *Config) *quic.Config {
+	quicConfig := &quic.Config{
+		HandshakeIdleTimeout:           time.Second * 8,
+		MaxIdleTimeout:                 time.Second * 30,
+		InitialStreamReceiveWindow:     8 * 1024 * 1024,
+		MaxStreamReceiveWindow:         8 * 1024 * 1024,
+		InitialConnectionReceiveWindow: 20 * 1024 * 1024,
+		MaxConnectionReceiveWindow:     20 * 1024 * 1024,
+		MaxIncomingStreams:             1024,
+		MaxIncomingUniStreams:          1024,
+		EnableDatagrams:                true,
+	}
+	if config.IdleTimeout > 0 {
+		quicConfig.MaxIdleTimeout = time.Second * time.Duration(config.IdleTimeout)
+	}
+	return quicConfig
+}
+
+func init() {
+	common.Must(internet.RegisterProtocolConfigCreator(protocolName, func() interface{} {
+		return new(Config)
+	}))
+}
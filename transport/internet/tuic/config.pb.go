@@ -0,0 +1,283 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.27.1
+// 	protoc        v3.19.2
+// source: transport/internet/tuic/config.proto
+
+package tuic
+
+import (
+	_ "github.com/v2fly/v2ray-core/v5/common/protoext"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type UDPRelayMode int32
+
+const (
+	// UDP packets are carried in QUIC DATAGRAM frames, and fragmented if necessary.
+	UDPRelayMode_NATIVE UDPRelayMode = 0
+	// Each UDP packet is carried in a unidirectional QUIC stream.
+	UDPRelayMode_QUIC UDPRelayMode = 1
+)
+
+// Enum value maps for UDPRelayMode.
+var (
+	UDPRelayMode_name = map[int32]string{
+		0: "NATIVE",
+		1: "QUIC",
+	}
+	UDPRelayMode_value = map[string]int32{
+		"NATIVE": 0,
+		"QUIC":   1,
+	}
+)
+
+func (x UDPRelayMode) Enum() *UDPRelayMode {
+	p := new(UDPRelayMode)
+	*p = x
+	return p
+}
+
+func (x UDPRelayMode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (UDPRelayMode) Descriptor() protoreflect.EnumDescriptor {
+	return file_transport_internet_tuic_config_proto_enumTypes[0].Descriptor()
+}
+
+func (UDPRelayMode) Type() protoreflect.EnumType {
+	return &file_transport_internet_tuic_config_proto_enumTypes[0]
+}
+
+func (x UDPRelayMode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use UDPRelayMode.Descriptor instead.
+func (UDPRelayMode) EnumDescriptor() ([]byte, []int) {
+	return file_transport_internet_tuic_config_proto_rawDescGZIP(), []int{0}
+}
+
+type Config struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Credential sent by clients in the authentication command. On servers, a non-empty uuid
+	// authenticates clients in the transport. Otherwise the inbound proxy authenticates them.
+	Uuid     string `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	Password string `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`
+	// How clients relay UDP packets. Servers reply in the mode of each UDP session.
+	UdpRelayMode UDPRelayMode `protobuf:"varint,3,opt,name=udp_relay_mode,json=udpRelayMode,proto3,enum=v2ray.core.transport.internet.tuic.UDPRelayMode" json:"udp_relay_mode,omitempty"`
+	// Clients send data in 0-RTT packets when resuming a session, and servers accept them.
+	ZeroRttHandshake bool `protobuf:"varint,4,opt,name=zero_rtt_handshake,json=zeroRttHandshake,proto3" json:"zero_rtt_handshake,omitempty"`
+	// Interval in seconds between the heartbeats sent by clients. Default value is 10.
+	Heartbeat int32 `protobuf:"varint,5,opt,name=heartbeat,proto3" json:"heartbeat,omitempty"`
+	// Timeout in seconds without any incoming packet. Default value is 30.
+	IdleTimeout int32 `protobuf:"varint,6,opt,name=idle_timeout,json=idleTimeout,proto3" json:"idle_timeout,omitempty"`
+	// Timeout in seconds for servers to receive the authentication command. Default value is 3.
+	AuthTimeout int32 `protobuf:"varint,7,opt,name=auth_timeout,json=authTimeout,proto3" json:"auth_timeout,omitempty"`
+}
+
+func (x *Config) Reset() {
+	*x = Config{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_transport_internet_tuic_config_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Config) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Config) ProtoMessage() {}
+
+func (x *Config) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_internet_tuic_config_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Config.ProtoReflect.Descriptor instead.
+func (*Config) Descriptor() ([]byte, []int) {
+	return file_transport_internet_tuic_config_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Config) GetUuid() string {
+	if x != nil {
+		return x.Uuid
+	}
+	return ""
+}
+
+func (x *Config) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+func (x *Config) GetUdpRelayMode() UDPRelayMode {
+	if x != nil {
+		return x.UdpRelayMode
+	}
+	return UDPRelayMode_NATIVE
+}
+
+func (x *Config) GetZeroRttHandshake() bool {
+	if x != nil {
+		return x.ZeroRttHandshake
+	}
+	return false
+}
+
+func (x *Config) GetHeartbeat() int32 {
+	if x != nil {
+		return x.Heartbeat
+	}
+	return 0
+}
+
+func (x *Config) GetIdleTimeout() int32 {
+	if x != nil {
+		return x.IdleTimeout
+	}
+	return 0
+}
+
+func (x *Config) GetAuthTimeout() int32 {
+	if x != nil {
+		return x.AuthTimeout
+	}
+	return 0
+}
+
+var File_transport_internet_tuic_config_proto protoreflect.FileDescriptor
+
+var file_transport_internet_tuic_config_proto_rawDesc = []byte{
+	0x0a, 0x24, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x70, 0x6f, 0x72, 0x74, 0x2f, 0x69, 0x6e, 0x74, 0x65,
+	0x72, 0x6e, 0x65, 0x74, 0x2f, 0x74, 0x75, 0x69, 0x63, 0x2f, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x22, 0x76, 0x32, 0x72, 0x61, 0x79, 0x2e, 0x63, 0x6f,
+	0x72, 0x65, 0x2e, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x70, 0x6f, 0x72, 0x74, 0x2e, 0x69, 0x6e, 0x74,
+	0x65, 0x72, 0x6e, 0x65, 0x74, 0x2e, 0x74, 0x75, 0x69, 0x63, 0x1a, 0x20, 0x63, 0x6f, 0x6d, 0x6d,
+	0x6f, 0x6e, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x65, 0x78, 0x74, 0x2f, 0x65, 0x78, 0x74, 0x65,
+	0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xc1, 0x02, 0x0a,
+	0x06, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x12, 0x0a, 0x04, 0x75, 0x75, 0x69, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x75, 0x75, 0x69, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x70,
+	0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70,
+	0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x12, 0x56, 0x0a, 0x0e, 0x75, 0x64, 0x70, 0x5f, 0x72,
+	0x65, 0x6c, 0x61, 0x79, 0x5f, 0x6d, 0x6f, 0x64, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32,
+	0x30, 0x2e, 0x76, 0x32, 0x72, 0x61, 0x79, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x74, 0x72, 0x61,
+	0x6e, 0x73, 0x70, 0x6f, 0x72, 0x74, 0x2e, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x65, 0x74, 0x2e,
+	0x74, 0x75, 0x69, 0x63, 0x2e, 0x55, 0x44, 0x50, 0x52, 0x65, 0x6c, 0x61, 0x79, 0x4d, 0x6f, 0x64,
+	0x65, 0x52, 0x0c, 0x75, 0x64, 0x70, 0x52, 0x65, 0x6c, 0x61, 0x79, 0x4d, 0x6f, 0x64, 0x65, 0x12,
+	0x2c, 0x0a, 0x12, 0x7a, 0x65, 0x72, 0x6f, 0x5f, 0x72, 0x74, 0x74, 0x5f, 0x68, 0x61, 0x6e, 0x64,
+	0x73, 0x68, 0x61, 0x6b, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x10, 0x7a, 0x65, 0x72,
+	0x6f, 0x52, 0x74, 0x74, 0x48, 0x61, 0x6e, 0x64, 0x73, 0x68, 0x61, 0x6b, 0x65, 0x12, 0x1c, 0x0a,
+	0x09, 0x68, 0x65, 0x61, 0x72, 0x74, 0x62, 0x65, 0x61, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x09, 0x68, 0x65, 0x61, 0x72, 0x74, 0x62, 0x65, 0x61, 0x74, 0x12, 0x21, 0x0a, 0x0c, 0x69,
+	0x64, 0x6c, 0x65, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x0b, 0x69, 0x64, 0x6c, 0x65, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x12, 0x21,
+	0x0a, 0x0c, 0x61, 0x75, 0x74, 0x68, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x18, 0x07,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x0b, 0x61, 0x75, 0x74, 0x68, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75,
+	0x74, 0x3a, 0x1d, 0x82, 0xb5, 0x18, 0x19, 0x0a, 0x09, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x70, 0x6f,
+	0x72, 0x74, 0x12, 0x04, 0x74, 0x75, 0x69, 0x63, 0x8a, 0xff, 0x29, 0x04, 0x74, 0x75, 0x69, 0x63,
+	0x2a, 0x24, 0x0a, 0x0c, 0x55, 0x44, 0x50, 0x52, 0x65, 0x6c, 0x61, 0x79, 0x4d, 0x6f, 0x64, 0x65,
+	0x12, 0x0a, 0x0a, 0x06, 0x4e, 0x41, 0x54, 0x49, 0x56, 0x45, 0x10, 0x00, 0x12, 0x08, 0x0a, 0x04,
+	0x51, 0x55, 0x49, 0x43, 0x10, 0x01, 0x42, 0x87, 0x01, 0x0a, 0x26, 0x63, 0x6f, 0x6d, 0x2e, 0x76,
+	0x32, 0x72, 0x61, 0x79, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x70,
+	0x6f, 0x72, 0x74, 0x2e, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x65, 0x74, 0x2e, 0x74, 0x75, 0x69,
+	0x63, 0x50, 0x01, 0x5a, 0x36, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f,
+	0x76, 0x32, 0x66, 0x6c, 0x79, 0x2f, 0x76, 0x32, 0x72, 0x61, 0x79, 0x2d, 0x63, 0x6f, 0x72, 0x65,
+	0x2f, 0x76, 0x35, 0x2f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x70, 0x6f, 0x72, 0x74, 0x2f, 0x69, 0x6e,
+	0x74, 0x65, 0x72, 0x6e, 0x65, 0x74, 0x2f, 0x74, 0x75, 0x69, 0x63, 0xaa, 0x02, 0x22, 0x56, 0x32,
+	0x52, 0x61, 0x79, 0x2e, 0x43, 0x6f, 0x72, 0x65, 0x2e, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x70, 0x6f,
+	0x72, 0x74, 0x2e, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x65, 0x74, 0x2e, 0x54, 0x75, 0x69, 0x63,
+	0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_transport_internet_tuic_config_proto_rawDescOnce sync.Once
+	file_transport_internet_tuic_config_proto_rawDescData = file_transport_internet_tuic_config_proto_rawDesc
+)
+
+func file_transport_internet_tuic_config_proto_rawDescGZIP() []byte {
+	file_transport_internet_tuic_config_proto_rawDescOnce.Do(func() {
+		file_transport_internet_tuic_config_proto_rawDescData = protoimpl.X.CompressGZIP(file_transport_internet_tuic_config_proto_rawDescData)
+	})
+	return file_transport_internet_tuic_config_proto_rawDescData
+}
+
+var file_transport_internet_tuic_config_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_transport_internet_tuic_config_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_transport_internet_tuic_config_proto_goTypes = []interface{}{
+	(UDPRelayMode)(0), // 0: v2ray.core.transport.internet.tuic.UDPRelayMode
+	(*Config)(nil),    // 1: v2ray.core.transport.internet.tuic.Config
+}
+var file_transport_internet_tuic_config_proto_depIdxs = []int32{
+	0, // 0: v2ray.core.transport.internet.tuic.Config.udp_relay_mode:type_name -> v2ray.core.transport.internet.tuic.UDPRelayMode
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_transport_internet_tuic_config_proto_init() }
+func file_transport_internet_tuic_config_proto_init() {
+	if File_transport_internet_tuic_config_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_transport_internet_tuic_config_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Config); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_transport_internet_tuic_config_proto_rawDesc,
+			NumEnums:      1,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_transport_internet_tuic_config_proto_goTypes,
+		DependencyIndexes: file_transport_internet_tuic_config_proto_depIdxs,
+		EnumInfos:         file_transport_internet_tuic_config_proto_enumTypes,
+		MessageInfos:      file_transport_internet_tuic_config_proto_msgTypes,
+	}.Build()
+	File_transport_internet_tuic_config_proto = out.File
+	file_transport_internet_tuic_config_proto_rawDesc = nil
+	file_transport_internet_tuic_config_proto_goTypes = nil
+	file_transport_internet_tuic_config_proto_depIdxs = nil
+}
@@ -0,0 +1,167 @@
+package tuic
+
+import (
+	"io"
+	"sync"
+	"time"
+
+	"github.com/lucas-clemente/quic-go"
+
+	"github.com/v2fly/v2ray-core/v5/common/buf"
+	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/common/protocol"
+	"github.com/v2fly/v2ray-core/v5/common/uuid"
+	"github.com/v2fly/v2ray-core/v5/transport/internet"
+)
+
+// Connection is a connection carried in a TUIC session.
+type Connection interface {
+	internet.Connection
+	// User returns the user accepted by AuthConnection.Authenticate, or nil if the client was
+	// authenticated by the transport.
+	User() *protocol.MemoryUser
+}
+
+// StreamConnection is a connection relayed in a QUIC stream.
+type StreamConnection interface {
+	Connection
+	// Target returns the destination in the Connect command of the stream.
+	Target() net.Destination
+}
+
+// PacketConnection is a UDP session. Each buffer read from it carries one UDP packet with its
+// source as Endpoint, and each buffer written to it is sent to its Endpoint.
+type PacketConnection interface {
+	Connection
+	buf.Reader
+	buf.Writer
+	AssociationID() uint16
+}
+
+// AuthConnection is handed to the connection handler for each new client if the server has no
+// uuid configured, so that the inbound proxy can authenticate the client. The connection carries
+// no data.
+type AuthConnection interface {
+	internet.Connection
+	// UUID returns the UUID sent by the client.
+	UUID() uuid.UUID
+	// VerifyPassword returns whether the token sent by the client was derived from password.
+	VerifyPassword(password string) bool
+	// Authenticate accepts the client as user, or rejects it if user is nil. Closing the
+	// connection without calling Authenticate rejects the client.
+	Authenticate(user *protocol.MemoryUser)
+}
+
+// streamConn is a connection carried in a QUIC stream.
+type streamConn struct {
+	stream  quic.Stream
+	session *sessionState
+	target  net.Destination
+	local   net.Addr
+	remote  net.Addr
+}
+
+func (c *streamConn) Read(b []byte) (int, error) {
+	return c.stream.Read(b)
+}
+
+func (c *streamConn) Write(b []byte) (int, error) {
+	return c.stream.Write(b)
+}
+
+func (c *streamConn) Close() error {
+	c.stream.CancelRead(0)
+	return c.stream.Close()
+}
+
+func (c *streamConn) Target() net.Destination {
+	return c.target
+}
+
+func (c *streamConn) User() *protocol.MemoryUser {
+	return c.session.user
+}
+
+func (c *streamConn) LocalAddr() net.Addr {
+	return c.local
+}
+
+func (c *streamConn) RemoteAddr() net.Addr {
+	return c.remote
+}
+
+func (c *streamConn) SetDeadline(t time.Time) error {
+	return c.stream.SetDeadline(t)
+}
+
+func (c *streamConn) SetReadDeadline(t time.Time) error {
+	return c.stream.SetReadDeadline(t)
+}
+
+func (c *streamConn) SetWriteDeadline(t time.Time) error {
+	return c.stream.SetWriteDeadline(t)
+}
+
+type authConn struct {
+	session quic.Session
+	id      uuid.UUID
+	token   []byte
+	once    sync.Once
+	result  chan *protocol.MemoryUser
+}
+
+func newAuthConn(session quic.Session, id uuid.UUID, token []byte) *authConn {
+	return &authConn{
+		session: session,
+		id:      id,
+		token:   token,
+		result:  make(chan *protocol.MemoryUser, 1),
+	}
+}
+
+func (c *authConn) UUID() uuid.UUID {
+	return c.id
+}
+
+func (c *authConn) VerifyPassword(password string) bool {
+	return verifyToken(c.session, c.id, password, c.token)
+}
+
+func (c *authConn) Authenticate(user *protocol.MemoryUser) {
+	c.once.Do(func() {
+		c.result <- user
+	})
+}
+
+func (c *authConn) Read([]byte) (int, error) {
+	return 0, io.EOF
+}
+
+func (c *authConn) Write([]byte) (int, error) {
+	return 0, io.ErrClosedPipe
+}
+
+func (c *authConn) Close() error {
+	c.Authenticate(nil)
+	return nil
+}
+
+func (c *authConn) LocalAddr() net.Addr {
+	return c.session.LocalAddr()
+}
+
+func (c *authConn) RemoteAddr() net.Addr {
+	return c.session.RemoteAddr()
+}
+
+func (c *authConn) SetDeadline(time.Time) error {
+	return nil
+}
+
+func (c *authConn) SetReadDeadline(time.Time) error {
+	return nil
+}
+
+func (c *authConn) SetWriteDeadline(time.Time) error {
+	return nil
+}
@@ -0,0 +1,198 @@
+package tuic
+
+import (
+	"context"
+	gotls "crypto/tls"
+	"sync"
+	"time"
+
+	"github.com/lucas-clemente/quic-go"
+
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/common/session"
+	"github.com/v2fly/v2ray-core/v5/common/signal/done"
+	"github.com/v2fly/v2ray-core/v5/common/uuid"
+	"github.com/v2fly/v2ray-core/v5/transport/internet"
+	"github.com/v2fly/v2ray-core/v5/transport/internet/tls"
+)
+
+type dialerKey struct {
+	dest           net.Destination
+	streamSettings *internet.MemoryStreamConfig
+}
+
+// clientSession is a session to a server.
+type clientSession struct {
+	*sessionState
+	rawConn net.PacketConn
+}
+
+// sendAuthenticate authenticates the client once the handshake completes. Commands may be sent
+// before, in 0-RTT packets.
+func (s *clientSession) sendAuthenticate(id uuid.UUID, password string) {
+	if !s.handshakeComplete() {
+		return
+	}
+	token, err := token(s.session, id, password)
+	if err == nil {
+		err = s.sendUniStream(encodeAuthenticate(id, token))
+	}
+	if err != nil {
+		newError("failed to authenticate").Base(err).AtWarning().WriteToLog()
+		s.closeWithError(errorCodeAuthFailed, "")
+	}
+}
+
+// heartbeat keeps the session alive while it has open connections that may be idle.
+func (s *clientSession) heartbeat(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.session.SendMessage(encodeHeartbeat()); err != nil && !s.isActive() {
+				return
+			}
+		case <-s.session.Context().Done():
+			return
+		}
+	}
+}
+
+var (
+	globalDialerMap    map[dialerKey]*clientSession
+	globalDialerAccess sync.Mutex
+
+	// clientSessionCache keeps session tickets for 0-RTT handshakes.
+	clientSessionCache = gotls.NewLRUClientSessionCache(128)
+)
+
+func getSession(dest net.Destination, streamSettings *internet.MemoryStreamConfig) (*clientSession, error) {
+	globalDialerAccess.Lock()
+	defer globalDialerAccess.Unlock()
+
+	if globalDialerMap == nil {
+		globalDialerMap = make(map[dialerKey]*clientSession)
+	}
+
+	key := dialerKey{dest, streamSettings}
+	if s, found := globalDialerMap[key]; found && s.isActive() {
+		return s, nil
+	}
+
+	s, err := dialSession(dest, streamSettings)
+	if err != nil {
+		return nil, err
+	}
+	globalDialerMap[key] = s
+
+	go func() {
+		<-s.session.Context().Done()
+		globalDialerAccess.Lock()
+		if globalDialerMap[key] == s {
+			delete(globalDialerMap, key)
+		}
+		globalDialerAccess.Unlock()
+		s.rawConn.Close()
+	}()
+
+	return s, nil
+}
+
+func dialSession(dest net.Destination, streamSettings *internet.MemoryStreamConfig) (*clientSession, error) {
+	config := streamSettings.ProtocolSettings.(*Config)
+	id, err := uuid.ParseString(config.Uuid)
+	if err != nil {
+		return nil, newError("invalid uuid: ", config.Uuid).Base(err)
+	}
+
+	tlsConfig := tls.ConfigFromStreamSettings(streamSettings)
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{
+			ServerName:    internalDomain,
+			AllowInsecure: true,
+		}
+	}
+
+	destAddr, err := net.ResolveUDPAddr("udp", dest.NetAddr())
+	if err != nil {
+		return nil, err
+	}
+
+	rawConn, err := internet.ListenSystemPacket(context.Background(), &net.UDPAddr{
+		IP:   []byte{0, 0, 0, 0},
+		Port: 0,
+	}, streamSettings.SocketSettings)
+	if err != nil {
+		return nil, err
+	}
+
+	var qSession quic.Session
+	goTLSConfig := tlsConfig.GetTLSConfig(tls.WithDestination(dest), tls.WithNextProto("h3"))
+	if config.ZeroRttHandshake {
+		goTLSConfig.ClientSessionCache = clientSessionCache
+		qSession, err = quic.DialEarlyContext(context.Background(), rawConn, destAddr, "", goTLSConfig, getQuicConfig(config))
+	} else {
+		qSession, err = quic.DialContext(context.Background(), rawConn, destAddr, "", goTLSConfig, getQuicConfig(config))
+	}
+	if err != nil {
+		rawConn.Close()
+		return nil, err
+	}
+
+	s := &clientSession{
+		sessionState: &sessionState{
+			session:  qSession,
+			authDone: done.New(),
+		},
+		rawConn: rawConn,
+	}
+	// Clients do not wait for the authentication, which is not answered by servers.
+	s.setAuthenticated(nil, true)
+	s.associations = newAssociationMux(s.sessionState, nil)
+
+	go s.sendAuthenticate(id, config.Password)
+	go s.receiveDatagrams()
+	go s.acceptUniStreams()
+	go s.heartbeat(config.getHeartbeat())
+	return s, nil
+}
+
+// Dial opens a stream to the target of the outbound, or a UDP session if the target is UDP, in the
+// session to dest.
+func Dial(ctx context.Context, dest net.Destination, streamSettings *internet.MemoryStreamConfig) (internet.Connection, error) {
+	newError("creating connection to ", dest).WriteToLog(session.ExportIDToError(ctx))
+
+	outbound := session.OutboundFromContext(ctx)
+	if outbound == nil || !outbound.Target.IsValid() {
+		return nil, newError("target not specified")
+	}
+	target := outbound.Target
+
+	s, err := getSession(dest, streamSettings)
+	if err != nil {
+		return nil, newError("failed to dial session to ", dest).Base(err)
+	}
+
+	if target.Network == net.Network_UDP {
+		config := streamSettings.ProtocolSettings.(*Config)
+		return s.associations.open(config.UdpRelayMode, target)
+	}
+
+	stream, err := s.session.OpenStreamSync(ctx)
+	if err != nil {
+		return nil, newError("failed to open stream").Base(err)
+	}
+	if err := writeConnect(stream, target); err != nil {
+		stream.CancelRead(0)
+		stream.CancelWrite(0)
+		return nil, newError("failed to write connect command").Base(err)
+	}
+	return s.newStreamConn(stream, target), nil
+}
+
+func init() {
+	common.Must(internet.RegisterTransportDialer(protocolName, Dial))
+}
@@ -0,0 +1,227 @@
+package tuic
+
+import (
+	"context"
+	"time"
+
+	"github.com/lucas-clemente/quic-go"
+
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/common/protocol"
+	"github.com/v2fly/v2ray-core/v5/common/protocol/tls/cert"
+	"github.com/v2fly/v2ray-core/v5/common/signal/done"
+	"github.com/v2fly/v2ray-core/v5/common/uuid"
+	"github.com/v2fly/v2ray-core/v5/transport/internet"
+	"github.com/v2fly/v2ray-core/v5/transport/internet/tls"
+)
+
+// sessionListener is either a quic.Listener or a quic.EarlyListener.
+type sessionListener interface {
+	Accept(context.Context) (quic.Session, error)
+	Addr() net.Addr
+	Close() error
+}
+
+type earlyListener struct {
+	quic.EarlyListener
+}
+
+func (l earlyListener) Accept(ctx context.Context) (quic.Session, error) {
+	return l.EarlyListener.Accept(ctx)
+}
+
+// Listener is an internet.Listener that accepts TUIC sessions.
+type Listener struct {
+	rawConn  net.PacketConn
+	listener sessionListener
+	config   *Config
+	uuid     *uuid.UUID
+	done     *done.Instance
+	addConn  internet.ConnHandler
+}
+
+// authenticate verifies the Authenticate command of a session, and returns the user accepted by
+// the inbound proxy.
+func (l *Listener) authenticate(qSession quic.Session, id uuid.UUID, token []byte) (*protocol.MemoryUser, bool) {
+	if l.uuid != nil {
+		if !id.Equals(l.uuid) || !verifyToken(qSession, id, l.config.Password, token) {
+			newError("invalid token of ", id.String(), " from ", qSession.RemoteAddr()).WriteToLog()
+			return nil, false
+		}
+		return nil, true
+	}
+
+	conn := newAuthConn(qSession, id, token)
+	l.addConn(conn)
+	select {
+	case user := <-conn.result:
+		if user == nil {
+			newError("client ", id.String(), " from ", qSession.RemoteAddr(), " rejected by the inbound").WriteToLog()
+			return nil, false
+		}
+		return user, true
+	case <-time.After(l.config.getAuthTimeout()):
+		return nil, false
+	}
+}
+
+func (l *Listener) handleStream(state *sessionState, stream quic.Stream) {
+	common.Must(stream.SetReadDeadline(time.Now().Add(l.config.getAuthTimeout())))
+	command, err := readHeader(stream)
+	if err == nil && command != commandConnect {
+		err = newError("unexpected command: ", command)
+	}
+	if err != nil {
+		newError("invalid stream from ", state.session.RemoteAddr()).Base(err).WriteToLog()
+		state.closeWithError(errorCodeBadCommand, "")
+		return
+	}
+	dest, err := readConnect(stream)
+	if err != nil {
+		newError("failed to read connect command").Base(err).WriteToLog()
+		stream.CancelRead(errorCodeProtocol)
+		stream.CancelWrite(errorCodeProtocol)
+		return
+	}
+	common.Must(stream.SetReadDeadline(time.Time{}))
+
+	if !state.waitAuthenticated() {
+		stream.CancelRead(errorCodeAuthFailed)
+		stream.CancelWrite(errorCodeAuthFailed)
+		return
+	}
+	l.addConn(state.newStreamConn(stream, dest))
+}
+
+func (l *Listener) handleSession(qSession quic.Session) {
+	state := &sessionState{
+		session:  qSession,
+		authDone: done.New(),
+	}
+	state.authenticate = func(id uuid.UUID, token []byte) (*protocol.MemoryUser, bool) {
+		return l.authenticate(qSession, id, token)
+	}
+	state.associations = newAssociationMux(state, func(conn *packetConn) {
+		l.addConn(conn)
+	})
+
+	go state.receiveDatagrams()
+	go state.acceptUniStreams()
+	go func() {
+		select {
+		case <-state.authDone.Wait():
+		case <-qSession.Context().Done():
+		case <-time.After(l.config.getAuthTimeout()):
+			state.setAuthenticated(nil, false)
+			if !state.authOK {
+				newError("authentication timeout for ", qSession.RemoteAddr()).WriteToLog()
+				state.closeWithError(errorCodeAuthTimeout, "authentication timeout")
+			}
+		}
+	}()
+
+	for {
+		stream, err := qSession.AcceptStream(context.Background())
+		if err != nil {
+			newError("failed to accept stream").Base(err).WriteToLog()
+			select {
+			case <-qSession.Context().Done():
+				return
+			case <-l.done.Wait():
+				state.closeWithError(0, "")
+				return
+			default:
+				time.Sleep(time.Second)
+				continue
+			}
+		}
+		go l.handleStream(state, stream)
+	}
+}
+
+func (l *Listener) keepAccepting() {
+	for {
+		qSession, err := l.listener.Accept(context.Background())
+		if err != nil {
+			newError("failed to accept QUIC sessions").Base(err).WriteToLog()
+			if l.done.Done() {
+				break
+			}
+			time.Sleep(time.Second)
+			continue
+		}
+		go l.handleSession(qSession)
+	}
+}
+
+// Addr implements internet.Listener.Addr.
+func (l *Listener) Addr() net.Addr {
+	return l.listener.Addr()
+}
+
+// Close implements internet.Listener.Close.
+func (l *Listener) Close() error {
+	l.done.Close()
+	l.listener.Close()
+	l.rawConn.Close()
+	return nil
+}
+
+// Listen creates a new Listener based on configurations.
+func Listen(ctx context.Context, address net.Address, port net.Port, streamSettings *internet.MemoryStreamConfig, handler internet.ConnHandler) (internet.Listener, error) {
+	if address.Family().IsDomain() {
+		return nil, newError("domain address is not allows for listening tuic")
+	}
+
+	tlsConfig := tls.ConfigFromStreamSettings(streamSettings)
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{
+			Certificate: []*tls.Certificate{tls.ParseCertificate(cert.MustGenerate(nil, cert.DNSNames(internalDomain), cert.CommonName(internalDomain)))},
+		}
+	}
+
+	config := streamSettings.ProtocolSettings.(*Config)
+	listener := &Listener{
+		config:  config,
+		done:    done.New(),
+		addConn: handler,
+	}
+	if config.Uuid != "" {
+		id, err := uuid.ParseString(config.Uuid)
+		if err != nil {
+			return nil, newError("invalid uuid: ", config.Uuid).Base(err)
+		}
+		listener.uuid = &id
+	}
+
+	rawConn, err := internet.ListenSystemPacket(context.Background(), &net.UDPAddr{
+		IP:   address.IP(),
+		Port: int(port),
+	}, streamSettings.SocketSettings)
+	if err != nil {
+		return nil, err
+	}
+
+	goTLSConfig := tlsConfig.GetTLSConfig(tls.WithNextProto("h3"))
+	if config.ZeroRttHandshake {
+		var qListener quic.EarlyListener
+		qListener, err = quic.ListenEarly(rawConn, goTLSConfig, getQuicConfig(config))
+		listener.listener = earlyListener{qListener}
+	} else {
+		listener.listener, err = quic.Listen(rawConn, goTLSConfig, getQuicConfig(config))
+	}
+	if err != nil {
+		rawConn.Close()
+		return nil, err
+	}
+	listener.rawConn = rawConn
+
+	go listener.keepAccepting()
+
+	return listener, nil
+}
+
+func init() {
+	common.Must(internet.RegisterTransportListener(protocolName, Listen))
+}
@@ -0,0 +1,375 @@
+package tuic
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/v2fly/v2ray-core/v5/common/buf"
+	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/common/protocol"
+	"github.com/v2fly/v2ray-core/v5/common/signal/done"
+)
+
+const (
+	// maxDatagramSize keeps each Packet command in one QUIC packet. Larger UDP packets are
+	// fragmented in native mode.
+	maxDatagramSize = 1150
+
+	packetQueueSize = 64
+
+	// maxFragmentedPackets is the number of incomplete fragmented packets kept by an association.
+	maxFragmentedPackets = 16
+)
+
+// associationMux carries UDP sessions, called associations, in Packet commands. Associations are
+// opened by the client, and end with a Dissociate command from the client or when either side is
+// idle.
+type associationMux struct {
+	access sync.Mutex
+	state  *sessionState
+	conns  map[uint16]*packetConn
+	nextID uint16
+	// onConn handles associations opened by the peer. It is nil on clients.
+	onConn func(*packetConn)
+}
+
+func newAssociationMux(state *sessionState, onConn func(*packetConn)) *associationMux {
+	m := &associationMux{
+		state:  state,
+		conns:  make(map[uint16]*packetConn),
+		onConn: onConn,
+	}
+	go func() {
+		<-state.session.Context().Done()
+		m.closeAll()
+	}()
+	return m
+}
+
+func (m *associationMux) newConn(id uint16, mode UDPRelayMode, target net.Destination) *packetConn {
+	c := &packetConn{
+		mux:       m,
+		id:        id,
+		mode:      mode,
+		target:    target,
+		queue:     make(chan *buf.Buffer, packetQueueSize),
+		fragments: make(map[uint16]*fragmentedPacket),
+		done:      done.New(),
+		local:     m.state.session.LocalAddr(),
+		remote:    m.state.session.RemoteAddr(),
+	}
+	m.conns[id] = c
+	return c
+}
+
+// open opens an association on clients, which sends packets to target by default.
+func (m *associationMux) open(mode UDPRelayMode, target net.Destination) (*packetConn, error) {
+	if !m.state.isActive() {
+		return nil, errSessionClosed
+	}
+
+	m.access.Lock()
+	defer m.access.Unlock()
+
+	for i := 0; i < 0x10000; i++ {
+		m.nextID++
+		if _, found := m.conns[m.nextID]; !found {
+			return m.newConn(m.nextID, mode, target), nil
+		}
+	}
+	return nil, newError("too many associations")
+}
+
+func (m *associationMux) remove(id uint16) {
+	m.access.Lock()
+	delete(m.conns, id)
+	m.access.Unlock()
+}
+
+func (m *associationMux) handlePacket(p *packet, mode UDPRelayMode) {
+	m.access.Lock()
+	c, found := m.conns[p.AssociationID]
+	if !found && m.onConn != nil {
+		c = m.newConn(p.AssociationID, mode, net.Destination{})
+		m.access.Unlock()
+		m.onConn(c)
+	} else {
+		m.access.Unlock()
+	}
+	if c != nil {
+		c.push(p, mode)
+	}
+}
+
+func (m *associationMux) dissociate(id uint16) {
+	m.access.Lock()
+	c := m.conns[id]
+	m.access.Unlock()
+
+	if c != nil {
+		c.closeLocal()
+	}
+}
+
+func (m *associationMux) closeAll() {
+	m.access.Lock()
+	conns := make([]*packetConn, 0, len(m.conns))
+	for _, c := range m.conns {
+		conns = append(conns, c)
+	}
+	m.access.Unlock()
+
+	for _, c := range conns {
+		c.closeLocal()
+	}
+}
+
+type fragmentedPacket struct {
+	address   *net.Destination
+	fragments [][]byte
+	received  int
+}
+
+// packetConn is an association. Each buffer read from or written to it is a UDP packet, whose
+// source or destination is its Endpoint.
+type packetConn struct {
+	mux    *associationMux
+	id     uint16
+	target net.Destination
+	queue  chan *buf.Buffer
+	done   *done.Instance
+	local  net.Addr
+	remote net.Addr
+
+	access    sync.Mutex
+	mode      UDPRelayMode
+	packetID  uint16
+	fragments map[uint16]*fragmentedPacket
+}
+
+func (c *packetConn) push(p *packet, mode UDPRelayMode) {
+	if c.done.Done() {
+		return
+	}
+
+	c.access.Lock()
+	if c.mux.onConn != nil {
+		// Servers reply in the mode that the client sends packets in.
+		c.mode = mode
+	}
+	address, payload := c.defragment(p)
+	c.access.Unlock()
+	if address == nil {
+		return
+	}
+
+	for _, b := range buf.MergeBytes(nil, payload) {
+		b.Endpoint = address
+		select {
+		case c.queue <- b:
+		default:
+			// Drop the packet if the reader is too slow, like a UDP socket.
+			b.Release()
+		}
+	}
+}
+
+// defragment returns the UDP packet completed by p, or a nil address if more fragments are
+// expected.
+func (c *packetConn) defragment(p *packet) (*net.Destination, []byte) {
+	if p.FragmentTotal == 1 {
+		return p.Address, p.Payload
+	}
+
+	f, found := c.fragments[p.PacketID]
+	if !found || len(f.fragments) != int(p.FragmentTotal) {
+		if len(c.fragments) >= maxFragmentedPackets {
+			c.fragments = make(map[uint16]*fragmentedPacket)
+		}
+		f = &fragmentedPacket{
+			fragments: make([][]byte, p.FragmentTotal),
+		}
+		c.fragments[p.PacketID] = f
+	}
+	if f.fragments[p.FragmentID] != nil {
+		return nil, nil
+	}
+	f.fragments[p.FragmentID] = p.Payload
+	if p.Address != nil {
+		f.address = p.Address
+	}
+	f.received++
+	if f.received < len(f.fragments) {
+		return nil, nil
+	}
+
+	delete(c.fragments, p.PacketID)
+	return f.address, bytes.Join(f.fragments, nil)
+}
+
+// AssociationID implements PacketConnection.
+func (c *packetConn) AssociationID() uint16 {
+	return c.id
+}
+
+// User implements Connection.
+func (c *packetConn) User() *protocol.MemoryUser {
+	return c.mux.state.user
+}
+
+// ReadMultiBuffer implements buf.Reader. Each buffer holds one UDP packet.
+func (c *packetConn) ReadMultiBuffer() (buf.MultiBuffer, error) {
+	select {
+	case b := <-c.queue:
+		return buf.MultiBuffer{b}, nil
+	case <-c.done.Wait():
+		return nil, io.EOF
+	}
+}
+
+func (c *packetConn) Read(b []byte) (int, error) {
+	select {
+	case buffer := <-c.queue:
+		defer buffer.Release()
+		return copy(b, buffer.Bytes()), nil
+	case <-c.done.Wait():
+		return 0, io.EOF
+	}
+}
+
+// WriteMultiBuffer implements buf.Writer. Each buffer is sent to its Endpoint, or the target of
+// the association if it has none.
+func (c *packetConn) WriteMultiBuffer(mb buf.MultiBuffer) error {
+	defer buf.ReleaseMulti(mb)
+
+	for _, b := range mb {
+		dest := c.target
+		if b.Endpoint != nil {
+			dest = *b.Endpoint
+		}
+		if err := c.writePacket(b.Bytes(), dest); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Write sends b to the target of the association.
+func (c *packetConn) Write(b []byte) (int, error) {
+	if err := c.writePacket(b, c.target); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+func (c *packetConn) writePacket(payload []byte, dest net.Destination) error {
+	if c.done.Done() {
+		return io.ErrClosedPipe
+	}
+	if !dest.IsValid() {
+		return newError("destination not specified")
+	}
+
+	c.access.Lock()
+	c.packetID++
+	p := &packet{
+		AssociationID: c.id,
+		PacketID:      c.packetID,
+		FragmentTotal: 1,
+		Address:       &dest,
+		Payload:       payload,
+	}
+	mode := c.mode
+	c.access.Unlock()
+
+	state := c.mux.state
+	if mode == UDPRelayMode_QUIC {
+		command, err := encodePacket(p)
+		if err != nil {
+			return err
+		}
+		return state.sendUniStream(command)
+	}
+
+	// The first fragment has the largest header, as it carries the address.
+	var header bytes.Buffer
+	if err := writeAddress(&header, &dest); err != nil {
+		return err
+	}
+	fragmentSize := maxDatagramSize - 2 - packetHeaderSize - header.Len()
+	fragments := (len(payload) + fragmentSize - 1) / fragmentSize
+	if fragments == 0 {
+		fragments = 1
+	}
+	if fragments > 255 {
+		return newError("packet too large: ", len(payload))
+	}
+
+	p.FragmentTotal = uint8(fragments)
+	for i := 0; i < fragments; i++ {
+		end := (i + 1) * fragmentSize
+		if end > len(payload) {
+			end = len(payload)
+		}
+		p.FragmentID = uint8(i)
+		p.Payload = payload[i*fragmentSize : end]
+		if i > 0 {
+			p.Address = nil
+		}
+		command, err := encodePacket(p)
+		if err != nil {
+			return err
+		}
+		if err := state.session.SendMessage(command); err != nil {
+			if !state.isActive() {
+				return err
+			}
+			newError("dropping packet of ", len(payload), " bytes").Base(err).AtDebug().WriteToLog()
+			return nil
+		}
+	}
+	return nil
+}
+
+// closeLocal closes the association without notifying the peer.
+func (c *packetConn) closeLocal() {
+	if c.done.Done() {
+		return
+	}
+	c.done.Close()
+	c.mux.remove(c.id)
+}
+
+// Close implements net.Conn. Clients notify servers with a Dissociate command.
+func (c *packetConn) Close() error {
+	if c.done.Done() {
+		return nil
+	}
+	c.closeLocal()
+	if c.mux.onConn == nil && c.mux.state.isActive() {
+		return c.mux.state.sendUniStream(encodeDissociate(c.id))
+	}
+	return nil
+}
+
+func (c *packetConn) LocalAddr() net.Addr {
+	return c.local
+}
+
+func (c *packetConn) RemoteAddr() net.Addr {
+	return c.remote
+}
+
+func (c *packetConn) SetDeadline(time.Time) error {
+	return nil
+}
+
+func (c *packetConn) SetReadDeadline(time.Time) error {
+	return nil
+}
+
+func (c *packetConn) SetWriteDeadline(time.Time) error {
+	return nil
+}
@@ -0,0 +1,258 @@
+package tuic
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/binary"
+	"io"
+
+	"github.com/lucas-clemente/quic-go"
+
+	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/common/uuid"
+)
+
+const (
+	protocolVersion = 0x05
+
+	commandAuthenticate = 0x00
+	commandConnect      = 0x01
+	commandPacket       = 0x02
+	commandDissociate   = 0x03
+	commandHeartbeat    = 0x04
+
+	addressTypeDomain = 0x00
+	addressTypeIPv4   = 0x01
+	addressTypeIPv6   = 0x02
+	addressTypeNone   = 0xff
+
+	tokenSize = 32
+
+	// packetHeaderSize is the size of the body of a Packet command before its address.
+	packetHeaderSize = 2 + 2 + 1 + 1 + 2
+)
+
+// token derives the authentication token of a client from the TLS session of the QUIC connection,
+// so that it can not be replayed on other connections.
+func token(qSession quic.Session, id uuid.UUID, password string) ([]byte, error) {
+	state := qSession.ConnectionState().TLS
+	return state.ExportKeyingMaterial(string(id.Bytes()), []byte(password), tokenSize)
+}
+
+func verifyToken(qSession quic.Session, id uuid.UUID, password string, received []byte) bool {
+	expected, err := token(qSession, id, password)
+	if err != nil {
+		newError("failed to export keying material").Base(err).WriteToLog()
+		return false
+	}
+	return subtle.ConstantTimeCompare(expected, received) == 1
+}
+
+func writeAddress(w *bytes.Buffer, dest *net.Destination) error {
+	if dest == nil {
+		w.WriteByte(addressTypeNone)
+		return nil
+	}
+
+	switch dest.Address.Family() {
+	case net.AddressFamilyIPv4:
+		w.WriteByte(addressTypeIPv4)
+		w.Write(dest.Address.IP().To4())
+	case net.AddressFamilyIPv6:
+		w.WriteByte(addressTypeIPv6)
+		w.Write(dest.Address.IP().To16())
+	case net.AddressFamilyDomain:
+		domain := dest.Address.Domain()
+		if len(domain) > 255 {
+			return newError("domain too long: ", domain)
+		}
+		w.WriteByte(addressTypeDomain)
+		w.WriteByte(byte(len(domain)))
+		w.WriteString(domain)
+	default:
+		return newError("unknown address: ", dest.Address)
+	}
+	var port [2]byte
+	binary.BigEndian.PutUint16(port[:], dest.Port.Value())
+	w.Write(port[:])
+	return nil
+}
+
+// readAddress reads an address, which is nil if its type is none.
+func readAddress(r io.Reader) (*net.Destination, error) {
+	var addrType [1]byte
+	if _, err := io.ReadFull(r, addrType[:]); err != nil {
+		return nil, err
+	}
+
+	var address net.Address
+	switch addrType[0] {
+	case addressTypeNone:
+		return nil, nil
+	case addressTypeIPv4:
+		var ip [4]byte
+		if _, err := io.ReadFull(r, ip[:]); err != nil {
+			return nil, err
+		}
+		address = net.IPAddress(ip[:])
+	case addressTypeIPv6:
+		var ip [16]byte
+		if _, err := io.ReadFull(r, ip[:]); err != nil {
+			return nil, err
+		}
+		address = net.IPAddress(ip[:])
+	case addressTypeDomain:
+		var length [1]byte
+		if _, err := io.ReadFull(r, length[:]); err != nil {
+			return nil, err
+		}
+		domain := make([]byte, length[0])
+		if _, err := io.ReadFull(r, domain); err != nil {
+			return nil, err
+		}
+		address = net.ParseAddress(string(domain))
+	default:
+		return nil, newError("unknown address type: ", addrType[0])
+	}
+
+	var port [2]byte
+	if _, err := io.ReadFull(r, port[:]); err != nil {
+		return nil, err
+	}
+	return &net.Destination{
+		Address: address,
+		Port:    net.PortFromBytes(port[:]),
+	}, nil
+}
+
+// readHeader reads the version and the type of a command.
+func readHeader(r io.Reader) (byte, error) {
+	var header [2]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, err
+	}
+	if header[0] != protocolVersion {
+		return 0, newError("unsupported version: ", header[0])
+	}
+	return header[1], nil
+}
+
+func encodeAuthenticate(id uuid.UUID, token []byte) []byte {
+	command := make([]byte, 0, 2+16+tokenSize)
+	command = append(command, protocolVersion, commandAuthenticate)
+	command = append(command, id.Bytes()...)
+	return append(command, token...)
+}
+
+// readAuthenticate reads the body of an Authenticate command.
+func readAuthenticate(r io.Reader) (uuid.UUID, []byte, error) {
+	var body [16 + tokenSize]byte
+	if _, err := io.ReadFull(r, body[:]); err != nil {
+		return uuid.UUID{}, nil, err
+	}
+	id, err := uuid.ParseBytes(body[:16])
+	if err != nil {
+		return uuid.UUID{}, nil, err
+	}
+	return id, body[16:], nil
+}
+
+func writeConnect(w io.Writer, dest net.Destination) error {
+	var command bytes.Buffer
+	command.Write([]byte{protocolVersion, commandConnect})
+	if err := writeAddress(&command, &dest); err != nil {
+		return err
+	}
+	_, err := w.Write(command.Bytes())
+	return err
+}
+
+// readConnect reads the body of a Connect command.
+func readConnect(r io.Reader) (net.Destination, error) {
+	dest, err := readAddress(r)
+	if err != nil {
+		return net.Destination{}, err
+	}
+	if dest == nil {
+		return net.Destination{}, newError("no address in connect command")
+	}
+	dest.Network = net.Network_TCP
+	return *dest, nil
+}
+
+func encodeDissociate(associationID uint16) []byte {
+	command := []byte{protocolVersion, commandDissociate, 0, 0}
+	binary.BigEndian.PutUint16(command[2:], associationID)
+	return command
+}
+
+func readDissociate(r io.Reader) (uint16, error) {
+	var body [2]byte
+	if _, err := io.ReadFull(r, body[:]); err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint16(body[:]), nil
+}
+
+func encodeHeartbeat() []byte {
+	return []byte{protocolVersion, commandHeartbeat}
+}
+
+// packet is a Packet command, which carries a fragment of a UDP packet.
+type packet struct {
+	AssociationID uint16
+	PacketID      uint16
+	FragmentTotal uint8
+	FragmentID    uint8
+	// Address is only carried in the first fragment.
+	Address *net.Destination
+	Payload []byte
+}
+
+func encodePacket(p *packet) ([]byte, error) {
+	var command bytes.Buffer
+	var header [2 + packetHeaderSize]byte
+	header[0] = protocolVersion
+	header[1] = commandPacket
+	binary.BigEndian.PutUint16(header[2:], p.AssociationID)
+	binary.BigEndian.PutUint16(header[4:], p.PacketID)
+	header[6] = p.FragmentTotal
+	header[7] = p.FragmentID
+	binary.BigEndian.PutUint16(header[8:], uint16(len(p.Payload)))
+	command.Write(header[:])
+	if err := writeAddress(&command, p.Address); err != nil {
+		return nil, err
+	}
+	command.Write(p.Payload)
+	return command.Bytes(), nil
+}
+
+// readPacket reads the body of a Packet command.
+func readPacket(r io.Reader) (*packet, error) {
+	var header [packetHeaderSize]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+	p := &packet{
+		AssociationID: binary.BigEndian.Uint16(header[0:]),
+		PacketID:      binary.BigEndian.Uint16(header[2:]),
+		FragmentTotal: header[4],
+		FragmentID:    header[5],
+	}
+	if p.FragmentTotal == 0 || p.FragmentID >= p.FragmentTotal {
+		return nil, newError("invalid fragment ", p.FragmentID, " of ", p.FragmentTotal)
+	}
+	address, err := readAddress(r)
+	if err != nil {
+		return nil, err
+	}
+	if address != nil {
+		address.Network = net.Network_UDP
+	}
+	p.Address = address
+	p.Payload = make([]byte, binary.BigEndian.Uint16(header[6:]))
+	if _, err := io.ReadFull(r, p.Payload); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
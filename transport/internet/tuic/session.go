@@ -0,0 +1,206 @@
+package tuic
+
+import (
+	"bytes"
+	"context"
+	"sync"
+
+	"github.com/lucas-clemente/quic-go"
+
+	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/common/protocol"
+	"github.com/v2fly/v2ray-core/v5/common/signal/done"
+	"github.com/v2fly/v2ray-core/v5/common/uuid"
+)
+
+// Application error codes to close sessions with.
+const (
+	errorCodeProtocol    = 0xfffffff0
+	errorCodeAuthFailed  = 0xfffffff1
+	errorCodeAuthTimeout = 0xfffffff2
+	errorCodeBadCommand  = 0xfffffff3
+)
+
+var errSessionClosed = newError("session closed")
+
+// sessionState is shared by the connections of a session. Servers handle the commands of a
+// session only after the client is authenticated.
+type sessionState struct {
+	session      quic.Session
+	associations *associationMux
+
+	// authenticate is called with the Authenticate command on servers, and is nil on clients.
+	authenticate func(id uuid.UUID, token []byte) (*protocol.MemoryUser, bool)
+	authOnce     sync.Once
+	authDone     *done.Instance
+	authOK       bool
+	user         *protocol.MemoryUser
+}
+
+func (s *sessionState) isActive() bool {
+	select {
+	case <-s.session.Context().Done():
+		return false
+	default:
+		return true
+	}
+}
+
+// handshakeComplete blocks until the TLS handshake of the session completes, so that keying
+// material can be exported.
+func (s *sessionState) handshakeComplete() bool {
+	if earlySession, ok := s.session.(quic.EarlySession); ok {
+		select {
+		case <-earlySession.HandshakeComplete().Done():
+		case <-s.session.Context().Done():
+			return false
+		}
+	}
+	return s.isActive()
+}
+
+// setAuthenticated records the result of the first authentication of the session.
+func (s *sessionState) setAuthenticated(user *protocol.MemoryUser, ok bool) {
+	s.authOnce.Do(func() {
+		s.user = user
+		s.authOK = ok
+		s.authDone.Close()
+	})
+}
+
+// waitAuthenticated blocks until the client is authenticated, and returns whether it was accepted.
+func (s *sessionState) waitAuthenticated() bool {
+	select {
+	case <-s.authDone.Wait():
+		return s.authOK
+	case <-s.session.Context().Done():
+		return false
+	}
+}
+
+func (s *sessionState) newStreamConn(stream quic.Stream, target net.Destination) *streamConn {
+	return &streamConn{
+		stream:  stream,
+		session: s,
+		target:  target,
+		local:   s.session.LocalAddr(),
+		remote:  s.session.RemoteAddr(),
+	}
+}
+
+// sendUniStream sends a command in a new unidirectional stream.
+func (s *sessionState) sendUniStream(command []byte) error {
+	stream, err := s.session.OpenUniStreamSync(s.session.Context())
+	if err != nil {
+		return err
+	}
+	if _, err := stream.Write(command); err != nil {
+		stream.CancelWrite(0)
+		return err
+	}
+	return stream.Close()
+}
+
+func (s *sessionState) closeWithError(code quic.ApplicationErrorCode, message string) {
+	if err := s.session.CloseWithError(code, message); err != nil {
+		newError("failed to close session").Base(err).WriteToLog()
+	}
+}
+
+func (s *sessionState) receiveDatagrams() {
+	for {
+		data, err := s.session.ReceiveMessage()
+		if err != nil {
+			return
+		}
+
+		reader := bytes.NewReader(data)
+		command, err := readHeader(reader)
+		if err != nil {
+			newError("invalid datagram").Base(err).AtDebug().WriteToLog()
+			continue
+		}
+		switch command {
+		case commandHeartbeat:
+		case commandPacket:
+			p, err := readPacket(reader)
+			if err != nil {
+				newError("invalid packet command").Base(err).AtDebug().WriteToLog()
+				continue
+			}
+			if !s.waitAuthenticated() {
+				return
+			}
+			s.associations.handlePacket(p, UDPRelayMode_NATIVE)
+		default:
+			newError("unexpected command in datagram: ", command).AtDebug().WriteToLog()
+		}
+	}
+}
+
+func (s *sessionState) acceptUniStreams() {
+	for {
+		stream, err := s.session.AcceptUniStream(context.Background())
+		if err != nil {
+			return
+		}
+		go s.handleUniStream(stream)
+	}
+}
+
+func (s *sessionState) handleUniStream(stream quic.ReceiveStream) {
+	defer stream.CancelRead(0)
+
+	command, err := readHeader(stream)
+	if err != nil {
+		newError("failed to read command").Base(err).WriteToLog()
+		return
+	}
+
+	switch command {
+	case commandAuthenticate:
+		if s.authenticate == nil {
+			s.closeWithError(errorCodeBadCommand, "unexpected authenticate command")
+			return
+		}
+		if s.authDone.Done() {
+			return
+		}
+		id, token, err := readAuthenticate(stream)
+		if err != nil {
+			newError("failed to read authenticate command").Base(err).WriteToLog()
+			s.closeWithError(errorCodeProtocol, "invalid authenticate command")
+			return
+		}
+		if !s.handshakeComplete() {
+			return
+		}
+		user, ok := s.authenticate(id, token)
+		s.setAuthenticated(user, ok)
+		if !ok {
+			s.closeWithError(errorCodeAuthFailed, "authentication failed")
+		}
+	case commandPacket:
+		p, err := readPacket(stream)
+		if err != nil {
+			newError("failed to read packet command").Base(err).WriteToLog()
+			return
+		}
+		if !s.waitAuthenticated() {
+			return
+		}
+		s.associations.handlePacket(p, UDPRelayMode_QUIC)
+	case commandDissociate:
+		id, err := readDissociate(stream)
+		if err != nil {
+			newError("failed to read dissociate command").Base(err).WriteToLog()
+			return
+		}
+		if !s.waitAuthenticated() {
+			return
+		}
+		s.associations.dissociate(id)
+	default:
+		s.closeWithError(errorCodeBadCommand, "unexpected command")
+	}
+}
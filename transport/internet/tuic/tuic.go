@@ -0,0 +1,10 @@
+/*
+Package tuic implements the TUIC v5 transport
+
+TUIC transport authenticates clients with a token exported from the TLS session of a QUIC
+connection, and then carries connections in bidirectional QUIC streams and UDP sessions in QUIC
+DATAGRAM frames or unidirectional QUIC streams.
+*/
+package tuic
+
+//go:generate go run github.com/v2fly/v2ray-core/v5/common/errors/errorgen
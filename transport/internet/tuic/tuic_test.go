@@ -0,0 +1,201 @@
+package tuic_test
+
+import (
+	"context"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/buf"
+	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/common/protocol"
+	"github.com/v2fly/v2ray-core/v5/common/protocol/tls/cert"
+	"github.com/v2fly/v2ray-core/v5/common/session"
+	"github.com/v2fly/v2ray-core/v5/common/uuid"
+	"github.com/v2fly/v2ray-core/v5/testing/servers/udp"
+	"github.com/v2fly/v2ray-core/v5/transport/internet"
+	"github.com/v2fly/v2ray-core/v5/transport/internet/tls"
+	"github.com/v2fly/v2ray-core/v5/transport/internet/tuic"
+)
+
+func listen(t *testing.T, port net.Port, config *tuic.Config, handler internet.ConnHandler) internet.Listener {
+	listener, err := tuic.Listen(context.Background(), net.LocalHostIP, port, &internet.MemoryStreamConfig{
+		ProtocolName:     "tuic",
+		ProtocolSettings: config,
+		SecurityType:     "tls",
+		SecuritySettings: &tls.Config{
+			Certificate: []*tls.Certificate{
+				tls.ParseCertificate(
+					cert.MustGenerate(nil,
+						cert.DNSNames("www.v2fly.org"),
+					),
+				),
+			},
+		},
+	}, handler)
+	common.Must(err)
+	return listener
+}
+
+func dial(target net.Destination, port net.Port, config *tuic.Config) (internet.Connection, error) {
+	ctx := session.ContextWithOutbound(context.Background(), &session.Outbound{
+		Target: target,
+	})
+	return tuic.Dial(ctx, net.UDPDestination(net.LocalHostIP, port), &internet.MemoryStreamConfig{
+		ProtocolName:     "tuic",
+		ProtocolSettings: config,
+		SecurityType:     "tls",
+		SecuritySettings: &tls.Config{
+			ServerName:    "www.v2fly.org",
+			AllowInsecure: true,
+		},
+	})
+}
+
+func echo(conn internet.Connection) {
+	go func() {
+		defer conn.Close()
+		buf.Copy(buf.NewReader(conn), buf.NewWriter(conn))
+	}()
+}
+
+func TestTUICConnection(t *testing.T) {
+	id := uuid.New()
+	target := net.TCPDestination(net.DomainAddress("www.v2fly.org"), 443)
+
+	port := udp.PickPort()
+	listener := listen(t, port, &tuic.Config{
+		Uuid:     id.String(),
+		Password: "password",
+	}, func(conn internet.Connection) {
+		if c, ok := conn.(tuic.StreamConnection); !ok || c.Target() != target {
+			t.Error("unexpected connection: ", conn)
+		}
+		echo(conn)
+	})
+	defer listener.Close()
+
+	conn, err := dial(target, port, &tuic.Config{
+		Uuid:     id.String(),
+		Password: "password",
+	})
+	common.Must(err)
+	defer conn.Close()
+
+	const N = 1024 * 64
+	b1 := make([]byte, N)
+	common.Must2(rand.Read(b1))
+	b2 := buf.New()
+
+	for i := 0; i < 2; i++ {
+		common.Must2(conn.Write(b1))
+
+		var received []byte
+		for len(received) < N {
+			b2.Clear()
+			common.Must2(b2.ReadFrom(conn))
+			received = append(received, b2.Bytes()...)
+		}
+		if r := cmp.Diff(received, b1); r != "" {
+			t.Error(r)
+		}
+	}
+}
+
+func TestTUICPacketConnection(t *testing.T) {
+	id := uuid.New()
+	target := net.UDPDestination(net.LocalHostIP, net.Port(53))
+
+	port := udp.PickPort()
+	listener := listen(t, port, &tuic.Config{
+		Uuid:     id.String(),
+		Password: "password",
+	}, func(conn internet.Connection) {
+		if _, ok := conn.(tuic.PacketConnection); !ok {
+			t.Error("not a packet connection")
+		}
+		echo(conn)
+	})
+	defer listener.Close()
+
+	for _, mode := range []tuic.UDPRelayMode{tuic.UDPRelayMode_NATIVE, tuic.UDPRelayMode_QUIC} {
+		conn, err := dial(target, port, &tuic.Config{
+			Uuid:         id.String(),
+			Password:     "password",
+			UdpRelayMode: mode,
+		})
+		common.Must(err)
+
+		// The larger packet is fragmented in native mode.
+		for _, size := range []int{512, 4000} {
+			b1 := make([]byte, size)
+			common.Must2(rand.Read(b1))
+			common.Must2(conn.Write(b1))
+
+			mb, err := conn.(buf.Reader).ReadMultiBuffer()
+			common.Must(err)
+			if len(mb) != 1 || *mb[0].Endpoint != target {
+				t.Error("unexpected packet: ", mb)
+			}
+			if r := cmp.Diff(mb[0].Bytes(), b1); r != "" {
+				t.Error(r)
+			}
+			buf.ReleaseMulti(mb)
+		}
+		common.Must(conn.Close())
+	}
+}
+
+func TestTUICAuthConnection(t *testing.T) {
+	id := uuid.New()
+	user := &protocol.MemoryUser{Email: "love@v2fly.org"}
+	target := net.TCPDestination(net.LocalHostIP, 80)
+
+	port := udp.PickPort()
+	listener := listen(t, port, &tuic.Config{}, func(conn internet.Connection) {
+		switch c := conn.(type) {
+		case tuic.AuthConnection:
+			if c.UUID() == id && c.VerifyPassword("password") {
+				c.Authenticate(user)
+			}
+			c.Close()
+		case tuic.Connection:
+			if c.User() != user {
+				t.Error("unexpected user: ", c.User())
+			}
+			echo(conn)
+		}
+	})
+	defer listener.Close()
+
+	conn, err := dial(target, port, &tuic.Config{
+		Uuid:     id.String(),
+		Password: "invalid",
+	})
+	common.Must(err)
+	common.Must2(conn.Write([]byte("test")))
+	common.Must(conn.SetReadDeadline(time.Now().Add(time.Second * 5)))
+	if _, err := conn.Read(make([]byte, 16)); err == nil {
+		t.Error("expected authentication failure")
+	}
+
+	conn, err = dial(target, port, &tuic.Config{
+		Uuid:             id.String(),
+		Password:         "password",
+		ZeroRttHandshake: true,
+	})
+	common.Must(err)
+	defer conn.Close()
+
+	common.Must2(conn.Write([]byte("test")))
+	common.Must(conn.SetReadDeadline(time.Now().Add(time.Second * 5)))
+	b := make([]byte, 16)
+	n, err := conn.Read(b)
+	common.Must(err)
+	if string(b[:n]) != "test" {
+		t.Error("unexpected response: ", string(b[:n]))
+	}
+}
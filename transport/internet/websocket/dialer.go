@@ -37,27 +37,38 @@ func init() {
 func dialWebsocket(ctx context.Context, dest net.Destination, streamSettings *internet.MemoryStreamConfig) (net.Conn, error) {
 	wsSettings := streamSettings.ProtocolSettings.(*Config)
 
+	protocol := "ws"
+	tlsConfig := tls.ConfigFromStreamSettings(streamSettings)
+	if tlsConfig != nil {
+		protocol = "wss"
+	}
+
+	// TLS is set up in NetDial instead of by the dialer, so that its handshake is traced as that of
+	// TCP. The dialer sees a plain WebSocket URI.
 	dialer := &websocket.Dialer{
 		NetDial: func(network, addr string) (net.Conn, error) {
-			return internet.DialSystem(ctx, dest, streamSettings.SocketSettings)
+			conn, err := internet.DialSystem(ctx, dest, streamSettings.SocketSettings)
+			if err != nil || tlsConfig == nil {
+				return conn, err
+			}
+			tlsConn := tls.Client(conn, tlsConfig.GetTLSConfig(tls.WithDestination(dest), tls.WithNextProto("http/1.1")))
+			if err := tls.TraceHandshake(ctx, tlsConn); err != nil {
+				tlsConn.Close()
+				return nil, err
+			}
+			return tlsConn, nil
 		},
 		ReadBufferSize:   4 * 1024,
 		WriteBufferSize:  4 * 1024,
 		HandshakeTimeout: time.Second * 8,
 	}
 
-	protocol := "ws"
-
-	if config := tls.ConfigFromStreamSettings(streamSettings); config != nil {
-		protocol = "wss"
-		dialer.TLSClientConfig = config.GetTLSConfig(tls.WithDestination(dest), tls.WithNextProto("http/1.1"))
-	}
-
 	host := dest.NetAddr()
 	if (protocol == "ws" && dest.Port == 80) || (protocol == "wss" && dest.Port == 443) {
 		host = dest.Address.String()
 	}
 	uri := protocol + "://" + host + wsSettings.GetNormalizedPath()
+	dialURI := "ws://" + host + wsSettings.GetNormalizedPath()
 
 	if wsSettings.UseBrowserForwarding {
 		var forwarder extension.BrowserForwarder
@@ -84,12 +95,12 @@ func dialWebsocket(ctx context.Context, dest net.Destination, streamSettings *in
 	if wsSettings.MaxEarlyData != 0 {
 		return newConnectionWithDelayedDial(&dialerWithEarlyData{
 			dialer:  dialer,
-			uriBase: uri,
+			uriBase: dialURI,
 			config:  wsSettings,
 		}), nil
 	}
 
-	conn, resp, err := dialer.Dial(uri, wsSettings.GetRequestHeader()) // nolint: bodyclose
+	conn, resp, err := dialer.Dial(dialURI, wsSettings.GetRequestHeader()) // nolint: bodyclose
 	if err != nil {
 		var reason string
 		if resp != nil {